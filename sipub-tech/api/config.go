@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/config/tlsconfig"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/cache"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/services"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/streams"
+)
+
+const EnvPrefix = "API_GATEWAY_"
+
+const (
+	// The webhooks are shared by the gateways in DynamoDB.
+	DynamoDBWebhookStore = "dynamodb"
+	// The webhooks are kept in the memory of a single gateway.
+	MemoryWebhookStore = "memory"
+)
+
+const (
+	// The writes are queued in RabbitMQ and applied in the background.
+	AMQPExecutor = "amqp"
+	// The writes are applied by the movie service before answering.
+	GRPCExecutor = "grpc"
+)
+
+// The configuration of the gateway, loaded from the API_GATEWAY_ variables.
+type Config struct {
+	Port int             `yaml:"port" env:"PORT" validate:"min=1,max=65535" usage:"the port the gateway listens on"`
+	RabbitMQURL string   `yaml:"rabbitmq_url" env:"RABBITMQ_CONNECTION_URL" validate:"required,url" secret:"url" usage:"the url of RabbitMQ"`
+	GRPCURL string       `yaml:"grpc_url" env:"GRPC_CONNECTION_URL" validate:"required,hostname_port" usage:"the address of the gRPC server of the movies service"`
+	Executor string      `yaml:"executor" env:"EXECUTOR" validate:"oneof=amqp grpc" usage:"how the movie writes are sent, queued over amqp or applied over grpc"`
+	GRPC GRPCConfig      `yaml:"grpc"`
+	RabbitMQTLS TLSFilesConfig  `yaml:"rabbitmq_tls"`
+	Cache CacheConfig    `yaml:"cache"`
+	Events EventsConfig  `yaml:"events"`
+	Export ExportConfig  `yaml:"export"`
+	Metrics MetricsConfig  `yaml:"metrics"`
+	Webhooks WebhooksConfig  `yaml:"webhooks"`
+	DynamoDB DynamoDBConfig  `yaml:"dynamodb"`
+}
+
+type GRPCConfig struct {
+	LoadBalancing string                `yaml:"load_balancing" env:"GRPC_LOAD_BALANCING" validate:"oneof=round_robin pick_first" usage:"how the calls are spread over the addresses of the movies service, round_robin or pick_first"`
+	ReadTimeout time.Duration           `yaml:"read_timeout" env:"GRPC_READ_TIMEOUT" validate:"min=1ms" usage:"the deadline of the reads, retries included"`
+	WriteTimeout time.Duration          `yaml:"write_timeout" env:"GRPC_WRITE_TIMEOUT" validate:"min=1ms" usage:"the deadline of the writes of the grpc executor"`
+	RetryMaxAttempts int                `yaml:"retry_max_attempts" env:"GRPC_RETRY_MAX_ATTEMPTS" validate:"min=1,max=5" usage:"how many times a read answered with Unavailable is attempted"`
+	RetryInitialBackoff time.Duration   `yaml:"retry_initial_backoff" env:"GRPC_RETRY_INITIAL_BACKOFF" validate:"min=1ms" usage:"the longest wait before the first retry, doubled up to the max backoff"`
+	RetryMaxBackoff time.Duration       `yaml:"retry_max_backoff" env:"GRPC_RETRY_MAX_BACKOFF" validate:"min=1ms" usage:"the longest wait before a retry"`
+	BreakerThreshold int                `yaml:"breaker_threshold" env:"GRPC_BREAKER_THRESHOLD" validate:"min=1" usage:"how many calls in a row fail before the circuit breaker opens"`
+	BreakerOpenTimeout time.Duration    `yaml:"breaker_open_timeout" env:"GRPC_BREAKER_OPEN_TIMEOUT" validate:"min=1s" usage:"how long the circuit breaker answers 503 before probing the movies service"`
+	TLS bool                            `yaml:"tls" env:"GRPC_TLS" usage:"whether the movies service is called over TLS"`
+	TLSCAFile string                    `yaml:"tls_ca_file" env:"GRPC_TLS_CA_FILE" usage:"the PEM CAs verifying the movies service, the ones of the system when empty"`
+	TLSCertFile string                  `yaml:"tls_cert_file" env:"GRPC_TLS_CERT_FILE" usage:"the PEM client certificate presented to the movies service, for mTLS"`
+	TLSKeyFile string                   `yaml:"tls_key_file" env:"GRPC_TLS_KEY_FILE" usage:"the PEM key of the client certificate"`
+	TLSServerName string                `yaml:"tls_server_name" env:"GRPC_TLS_SERVER_NAME" usage:"the name verified in the certificate of the movies service, the host of grpc_url when empty, required when it is an IP address"`
+}
+
+// The files of the amqps connections, the CAs of the system verifying
+// RabbitMQ when they are empty.
+type TLSFilesConfig struct {
+	CAFile string    `yaml:"ca_file" env:"RABBITMQ_TLS_CA_FILE" usage:"the PEM CAs verifying RabbitMQ, with an amqps url"`
+	CertFile string  `yaml:"cert_file" env:"RABBITMQ_TLS_CERT_FILE" usage:"the PEM client certificate presented to RabbitMQ"`
+	KeyFile string   `yaml:"key_file" env:"RABBITMQ_TLS_KEY_FILE" usage:"the PEM key of the client certificate"`
+	ServerName string  `yaml:"server_name" env:"RABBITMQ_TLS_SERVER_NAME" usage:"the name verified in the certificate of RabbitMQ, the host of rabbitmq_url when empty, required when it is an IP address"`
+}
+
+// Whether any file or the server name is set, the connection then made
+// with them.
+func (config TLSFilesConfig) IsSet() bool {
+	return config.CAFile != "" || config.CertFile != "" || config.KeyFile != "" || config.ServerName != ""
+}
+
+func (config TLSFilesConfig) Files() tlsconfig.Files {
+	return tlsconfig.Files{
+		CA: config.CAFile,
+		Cert: config.CertFile,
+		Key: config.KeyFile,
+		ServerName: config.ServerName,
+		ReloadInterval: tlsconfig.DefaultReloadInterval,
+	}
+}
+
+type CacheConfig struct {
+	Capacity int  `yaml:"capacity" env:"CACHE_CAPACITY" validate:"min=1" usage:"how many movies and pages are cached"`
+}
+
+type EventsConfig struct {
+	ReplayCapacity int  `yaml:"replay_capacity" env:"EVENTS_REPLAY_CAPACITY" validate:"min=0" usage:"how many events are kept for reconnecting subscribers"`
+}
+
+type ExportConfig struct {
+	// The export is only served when there are tokens to authenticate it.
+	Tokens []string  `yaml:"tokens" env:"EXPORT_TOKENS" secret:"true" usage:"the bearer tokens of GET /movies/export, comma separated"`
+}
+
+type MetricsConfig struct {
+	// The metrics are only served when there are tokens to authenticate them.
+	Tokens []string  `yaml:"tokens" env:"METRICS_TOKENS" secret:"true" usage:"the bearer tokens of GET /debug/vars, comma separated"`
+}
+
+type WebhooksConfig struct {
+	// The webhooks are only served when there are tokens to authenticate them.
+	Tokens []string  `yaml:"tokens" env:"WEBHOOKS_TOKENS" secret:"true" usage:"the bearer tokens of /v1/webhooks, comma separated"`
+	Store string     `yaml:"store" env:"WEBHOOKS_STORE" validate:"oneof=dynamodb memory" usage:"where the webhooks are kept, dynamodb, shared by the gateways, or memory, for a single gateway"`
+}
+
+// The DynamoDB keeping the webhooks.
+type DynamoDBConfig struct {
+	// The AWS SDK defaults apply when they are empty.
+	Region string    `yaml:"region" env:"AWS_REGION" usage:"the AWS region of DynamoDB"`
+	Endpoint string  `yaml:"endpoint" env:"DYNAMO_DB_ENDPOINT" validate:"omitempty,url" usage:"the DynamoDB endpoint, as the one of localstack"`
+}
+
+func DefaultConfig() Config {
+	client := services.DefaultGRPCClientConfig()
+	return Config{
+		Port: 8080,
+		Executor: AMQPExecutor,
+		GRPC: GRPCConfig{
+			LoadBalancing: client.LoadBalancing,
+			ReadTimeout: client.ReadTimeout,
+			WriteTimeout: client.WriteTimeout,
+			RetryMaxAttempts: client.RetryMaxAttempts,
+			RetryInitialBackoff: client.RetryInitialBackoff,
+			RetryMaxBackoff: client.RetryMaxBackoff,
+			BreakerThreshold: client.Breaker.FailureThreshold,
+			BreakerOpenTimeout: client.Breaker.OpenTimeout,
+		},
+		Cache: CacheConfig{Capacity: cache.DefaultCapacity},
+		Events: EventsConfig{ReplayCapacity: streams.DefaultReplayCapacity},
+		Webhooks: WebhooksConfig{Store: DynamoDBWebhookStore},
+	}
+}
+
+// The configuration of the gRPC client, failing when the TLS files cannot
+// be loaded.
+func (config GRPCConfig) ClientConfig() (services.GRPCClientConfig, error) {
+	client := services.GRPCClientConfig{
+		LoadBalancing: config.LoadBalancing,
+		ReadTimeout: config.ReadTimeout,
+		WriteTimeout: config.WriteTimeout,
+		RetryMaxAttempts: config.RetryMaxAttempts,
+		RetryInitialBackoff: config.RetryInitialBackoff,
+		RetryMaxBackoff: config.RetryMaxBackoff,
+		Breaker: services.BreakerConfig{
+			FailureThreshold: config.BreakerThreshold,
+			OpenTimeout: config.BreakerOpenTimeout,
+		},
+	}
+	if !config.TLS {
+		return client, nil
+	}
+	reloader, err := tlsconfig.NewReloader(tlsconfig.Files{
+		CA: config.TLSCAFile,
+		Cert: config.TLSCertFile,
+		Key: config.TLSKeyFile,
+		ServerName: config.TLSServerName,
+		ReloadInterval: tlsconfig.DefaultReloadInterval,
+	})
+	if err != nil {
+		return client, fmt.Errorf("failed loading the gRPC TLS files: %w", err)
+	}
+	client.TLS = reloader.ClientConfig()
+	client.TLS.ServerName = config.TLSServerName
+	return client, nil
+}
+
+func (config *Config) Validate() error {
+	if (config.GRPC.TLSCertFile == "") != (config.GRPC.TLSKeyFile == "") {
+		return fmt.Errorf("grpc.tls_cert_file and grpc.tls_key_file (%sGRPC_TLS_CERT_FILE, %sGRPC_TLS_KEY_FILE) must be set together", EnvPrefix, EnvPrefix)
+	}
+	if !config.GRPC.TLS && (config.GRPC.TLSCAFile != "" || config.GRPC.TLSCertFile != "") {
+		return fmt.Errorf("the grpc.tls_ files are only used with grpc.tls (%sGRPC_TLS) set", EnvPrefix)
+	}
+	if (config.RabbitMQTLS.CertFile == "") != (config.RabbitMQTLS.KeyFile == "") {
+		return fmt.Errorf("rabbitmq_tls.cert_file and rabbitmq_tls.key_file (%sRABBITMQ_TLS_CERT_FILE, %sRABBITMQ_TLS_KEY_FILE) must be set together", EnvPrefix, EnvPrefix)
+	}
+	return nil
+}
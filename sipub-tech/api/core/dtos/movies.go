@@ -4,16 +4,32 @@ type DataItem map[string]any
 
 type MovieId int
 
+const MinimumMovieYear = 1880
+
 type CreateMovieDTO struct {
-	Title string `json:"title"`
-	Year string  `json:"year"`
+	Title string `json:"title" binding:"required,max=255"`
+	Year string  `json:"year" binding:"required,movieyear"`
+}
+
+
+// Replaces the title and year of the movie. When Version is set, the
+// update only happens if the movie is still on that version.
+type UpdateMovieDTO struct {
+	ID      MovieId  `json:"id"`
+	Title   string   `json:"title"`
+	Year    string   `json:"year"`
+	Version int      `json:"version,omitempty"`
 }
 
 
 type MovieResponseDTO struct {
-	ID    int     `json:"id"`
-	Title string  `json:"title"`
-	Year  string  `json:"year"`	
+	ID        int     `json:"id"`
+	Title     string  `json:"title"`
+	Year      string  `json:"year"`
+	// Starts at 1 and is incremented on every update of the movie.
+	Version   int     `json:"version"`
+	// Unix milliseconds of the last change, answered in Last-Modified.
+	UpdatedAt int64   `json:"-"`
 }
 
 func (dto *MovieResponseDTO) ToDataItem() DataItem {
@@ -21,6 +37,7 @@ func (dto *MovieResponseDTO) ToDataItem() DataItem {
 		"id":   dto.ID,
 		"title": dto.Title,
 		"year":  dto.Year,
+		"version": dto.Version,
 	}
 }
 
@@ -36,3 +53,49 @@ type MoviesResponseDTO struct {
 	Cursor  int                 `json:"cursor"`
 }
 
+// The most ids fetched by a batch get.
+const MaxBatchGetMovies = 100
+
+type BatchGetMoviesDTO struct {
+	IDs []MovieId  `json:"ids" binding:"required,min=1,max=100,dive,gt=0"`
+}
+
+// The movies found, in the order of their ids, and the ids of the movies
+// that do not exist, each once.
+type MoviesBatchResponseDTO struct {
+	Movies     []*MovieResponseDTO  `json:"movies"`
+	MissingIDs []MovieId            `json:"missing_ids"`
+}
+
+
+
+type MovieEventType string
+
+const (
+	MovieCreated MovieEventType = "MovieCreated"
+	MovieUpdated MovieEventType = "MovieUpdated"
+	MovieDeleted MovieEventType = "MovieDeleted"
+	// Sent on the event streams in place of the missed events when they
+	// can no longer be replayed, so the client must fetch the movies again.
+	MovieEventsReset MovieEventType = "MovieEventsReset"
+)
+
+// A domain event published by the movies service. The movie is the one
+// left by the change, or the removed one for deletions.
+type MovieEventDTO struct {
+	EventID       string            `json:"event_id"`
+	Type          MovieEventType    `json:"type"`
+	// Unix milliseconds of when the movie was changed.
+	OccurredAt    int64             `json:"occurred_at"`
+	CorrelationID string            `json:"correlation_id"`
+	CausationID   string            `json:"causation_id"`
+	Movie         MovieResponseDTO  `json:"movie"`
+}
+
+// Subscribes to the events of the movies of the year, or of every movie
+// when the year is empty. When LastEventID is set, the events after it are
+// replayed first.
+type MovieEventsQueryDTO struct {
+	Year        string
+	LastEventID string
+}
@@ -0,0 +1,95 @@
+package dtos
+
+type OperationId string
+
+// The most items accepted by a bulk request.
+const MaxBulkItems = 1000
+
+type OperationType string
+
+const (
+	BulkCreateMovies OperationType = "BulkCreateMovies"
+	BulkDeleteMovies OperationType = "BulkDeleteMovies"
+)
+
+type OperationStatus string
+
+const (
+	// Some items have no result yet.
+	OperationPending   OperationStatus = "pending"
+	OperationCompleted OperationStatus = "completed"
+)
+
+type OperationItemStatus string
+
+const (
+	OperationItemCreated  OperationItemStatus = "created"
+	OperationItemDeleted  OperationItemStatus = "deleted"
+	OperationItemNotFound OperationItemStatus = "not_found"
+	OperationItemFailed   OperationItemStatus = "failed"
+)
+
+// Creates the movies in the background, each validated as on a single
+// creation.
+type BulkCreateMoviesDTO struct {
+	Movies []CreateMovieDTO  `json:"movies" binding:"required,min=1,max=1000,dive"`
+}
+
+// Deletes the movies in the background, whatever their version.
+type BulkDeleteMoviesDTO struct {
+	IDs []MovieId  `json:"ids" binding:"required,min=1,max=1000,dive,gt=0"`
+}
+
+
+// What happened to an item of an operation, by its index in the request.
+// Created items carry the id of their new movie.
+type OperationItemResultDTO struct {
+	Index   int                  `json:"index"`
+	MovieID MovieId              `json:"movie_id,omitempty"`
+	Status  OperationItemStatus  `json:"status"`
+	Error   string               `json:"error,omitempty"`
+}
+
+// The results of some items of an operation, as reported by the movies
+// service for each batch. They carry the type and size of the operation,
+// so it can be tracked by gateways that did not start it.
+type OperationResultsDTO struct {
+	OperationID OperationId               `json:"operation_id"`
+	Type        OperationType             `json:"type"`
+	Total       int                       `json:"total"`
+	Results     []OperationItemResultDTO  `json:"results"`
+}
+
+type OperationResponseDTO struct {
+	ID          OperationId               `json:"id"`
+	Type        OperationType             `json:"type"`
+	Status      OperationStatus           `json:"status"`
+	// The number of items of the request.
+	Total       int                       `json:"total"`
+	// The items created or deleted.
+	Succeeded   int                       `json:"succeeded"`
+	// The items failed or not found.
+	Failed      int                       `json:"failed"`
+	// The results reported so far, by index.
+	Results     []OperationItemResultDTO  `json:"results"`
+	// Unix milliseconds.
+	CreatedAt   int64                     `json:"created_at"`
+	CompletedAt int64                     `json:"completed_at,omitempty"`
+}
+
+func (dto *OperationResponseDTO) ToDataItem() DataItem {
+	item := DataItem{
+		"id":         dto.ID,
+		"type":       dto.Type,
+		"status":     dto.Status,
+		"total":      dto.Total,
+		"succeeded":  dto.Succeeded,
+		"failed":     dto.Failed,
+		"results":    dto.Results,
+		"created_at": dto.CreatedAt,
+	}
+	if dto.CompletedAt != 0 {
+		item["completed_at"] = dto.CompletedAt
+	}
+	return item
+}
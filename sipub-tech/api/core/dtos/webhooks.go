@@ -0,0 +1,141 @@
+package dtos
+
+type WebhookId string
+
+// Registers the url to receive the movie events by POST. Events and Year
+// filter them, and every event is sent when they are empty.
+// When Secret is empty, one is generated. It is only answered on the
+// creation, to check the signatures of the requests.
+type CreateWebhookDTO struct {
+	URL    string            `json:"url" binding:"required,http_url,publicurl,max=2048"`
+	Events []MovieEventType  `json:"events" binding:"omitempty,dive,movieeventtype"`
+	Year   string            `json:"year" binding:"omitempty,movieyear"`
+	Secret string            `json:"secret" binding:"omitempty,min=16,max=255"`
+}
+
+
+// Replaces the url and the filters of the webhook. An empty Secret keeps
+// the current one, and a nil Enabled keeps the webhook as it is.
+// Enabling a disabled webhook forgets its failures.
+type UpdateWebhookDTO struct {
+	ID      WebhookId         `json:"-"`
+	URL     string            `json:"url" binding:"required,http_url,publicurl,max=2048"`
+	Events  []MovieEventType  `json:"events" binding:"omitempty,dive,movieeventtype"`
+	Year    string            `json:"year" binding:"omitempty,movieyear"`
+	Secret  string            `json:"secret" binding:"omitempty,min=16,max=255"`
+	Enabled *bool             `json:"enabled"`
+}
+
+
+type WebhookResponseDTO struct {
+	ID                  WebhookId         `json:"id"`
+	URL                 string            `json:"url"`
+	Events              []MovieEventType  `json:"events"`
+	Year                string            `json:"year,omitempty"`
+	Enabled             bool              `json:"enabled"`
+	// Why the dispatcher disabled the webhook.
+	DisabledReason      string            `json:"disabled_reason,omitempty"`
+	// Events whose delivery failed in a row, after all the attempts.
+	ConsecutiveFailures int               `json:"consecutive_failures"`
+	// Only answered on creation.
+	Secret              string            `json:"secret,omitempty"`
+	// Unix milliseconds.
+	CreatedAt           int64             `json:"created_at"`
+	UpdatedAt           int64             `json:"updated_at"`
+}
+
+func (dto *WebhookResponseDTO) ToDataItem() DataItem {
+	item := DataItem{
+		"id":                   dto.ID,
+		"url":                  dto.URL,
+		"events":               dto.Events,
+		"enabled":              dto.Enabled,
+		"consecutive_failures": dto.ConsecutiveFailures,
+		"created_at":           dto.CreatedAt,
+		"updated_at":           dto.UpdatedAt,
+	}
+	if dto.Year != "" {
+		item["year"] = dto.Year
+	}
+	if dto.DisabledReason != "" {
+		item["disabled_reason"] = dto.DisabledReason
+	}
+	if dto.Secret != "" {
+		item["secret"] = dto.Secret
+	}
+	return item
+}
+
+// Tells if the event passes the filters of the webhook.
+func (dto *WebhookResponseDTO) Matches(event MovieEventDTO) bool {
+	if dto.Year != "" && dto.Year != event.Movie.Year {
+		return false
+	}
+	if len(dto.Events) == 0 {
+		return true
+	}
+	for _, eventType := range dto.Events {
+		if eventType == event.Type {
+			return true
+		}
+	}
+	return false
+}
+
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// An attempt of sending an event to a webhook.
+type WebhookDeliveryDTO struct {
+	ID          int                    `json:"id"`
+	WebhookID   WebhookId              `json:"webhook_id"`
+	EventID     string                 `json:"event_id"`
+	EventType   MovieEventType         `json:"event_type"`
+	// Starts at 1 and is incremented on every retry of the event.
+	Attempt     int                    `json:"attempt"`
+	Status      WebhookDeliveryStatus  `json:"status"`
+	// The status answered by the receiver, 0 if it did not answer.
+	StatusCode  int                    `json:"status_code"`
+	Error       string                 `json:"error,omitempty"`
+	DurationMs  int64                  `json:"duration_ms"`
+	// Unix milliseconds of when the attempt started.
+	DeliveredAt int64                  `json:"delivered_at"`
+}
+
+func (dto *WebhookDeliveryDTO) ToDataItem() DataItem {
+	item := DataItem{
+		"id":           dto.ID,
+		"webhook_id":   dto.WebhookID,
+		"event_id":     dto.EventID,
+		"event_type":   dto.EventType,
+		"attempt":      dto.Attempt,
+		"status":       dto.Status,
+		"status_code":  dto.StatusCode,
+		"duration_ms":  dto.DurationMs,
+		"delivered_at": dto.DeliveredAt,
+	}
+	if dto.Error != "" {
+		item["error"] = dto.Error
+	}
+	return item
+}
+
+// Queries the deliveries of the webhook from the newest, skipping the
+// ones from the cursor on. An empty Status answers all of them.
+type WebhookDeliveriesQueryDTO struct {
+	WebhookID WebhookId
+	Status    WebhookDeliveryStatus
+	Limit     int
+	Cursor    int
+}
+
+type WebhookDeliveriesResponseDTO struct {
+	Deliveries []*WebhookDeliveryDTO  `json:"deliveries"`
+	// The id of the last delivery answered, 0 when there are no more.
+	Cursor     int                    `json:"cursor"`
+}
@@ -3,25 +3,53 @@ package ports
 import (
 	"context"
 	"fmt"
+	"time"
 	
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
 )
 
 const (
 	ServiceKey = "service"
+	// The service tracking the bulk operations, set along with the movie
+	// service by the bulk routes.
+	OperationServiceKey = "operationService"
+	// The service streaming the movie listings, set along with the query
+	// service, which answers the pages.
+	StreamerServiceKey = "streamerService"
 )
 
 var (
-	ErrMovieNotFound = fmt.Errorf("movie not found")
+	ErrMovieNotFound      = fmt.Errorf("movie not found")
+	ErrInvalidRequest     = fmt.Errorf("invalid request")
+	ErrServiceUnavailable = fmt.Errorf("service unavailable")
+	ErrServiceTimeout     = fmt.Errorf("service timed out")
+	ErrPreconditionFailed = fmt.Errorf("precondition failed")
 )
 
+// An ErrServiceUnavailable known to last, as while the service is failing
+// every call, for the callers to retry after RetryAfter.
+type UnavailableError struct {
+	RetryAfter time.Duration
+	Reason     string
+}
+
+func (err *UnavailableError) Error() string {
+	return fmt.Sprintf("%v: %s, retry after %v", ErrServiceUnavailable, err.Reason, err.RetryAfter)
+}
+
+func (err *UnavailableError) Unwrap() error {
+	return ErrServiceUnavailable
+}
+
 type MovieQueryService interface {
 	MovieOneGetterService
+	MovieManyGetterService
 	MovieAllGetterService
 }
 
 type MovieExecutorService interface {
 	MovieSaverService
+	MovieUpdaterService
 	MovieDeleterService
 }
 
@@ -29,15 +57,61 @@ type MovieOneGetterService interface {
 	GetOne(ctx context.Context, id dtos.MovieId) (dtos.MovieResponseDTO, error)
 }
 
+type MovieManyGetterService interface {
+	GetMany(ctx context.Context, ids []dtos.MovieId) (dtos.MoviesBatchResponseDTO, error)
+}
+
 type MovieAllGetterService interface {
 	GetAll(ctx context.Context, query dtos.MoviesQueryDTO) (dtos.MoviesResponseDTO, error)
 }
 
+// Calls emit with every movie, one at a time, in no order, stopping on
+// the first error it returns.
+type MovieExporterService interface {
+	Export(ctx context.Context, emit func(movie dtos.MovieResponseDTO) error) error
+}
+
+// Calls emit with the movies of the query year after its cursor, as
+// GetAll following every cursor, up to its limit, or every movie when it
+// is 0, stopping on the first error emit returns.
+type MovieStreamerService interface {
+	Stream(ctx context.Context, query dtos.MoviesQueryDTO, emit func(movie dtos.MovieResponseDTO) error) error
+}
+
 type MovieSaverService interface {
 	Save(ctx context.Context, movie dtos.CreateMovieDTO) error
 }
 
+type MovieUpdaterService interface {
+	Update(ctx context.Context, movie dtos.UpdateMovieDTO) error
+}
+
+// When expectedVersion is not 0, the movie is only deleted if it is
+// still on that version.
 type MovieDeleterService interface {
-	Delete(ctx context.Context, id dtos.MovieId) error
+	Delete(ctx context.Context, id dtos.MovieId, expectedVersion int) error
+}
+
+
+// Sends the items of the bulk operations to be applied in the background,
+// in batches starting at the offset of the operation items. Their results
+// are reported to the operation.
+type MovieBulkExecutorService interface {
+	MovieBulkSaverService
+	MovieBulkDeleterService
 }
 
+type MovieBulkSaverService interface {
+	SaveBatch(ctx context.Context, operation dtos.OperationId, total, offset int, movies []dtos.CreateMovieDTO) error
+}
+
+type MovieBulkDeleterService interface {
+	DeleteBatch(ctx context.Context, operation dtos.OperationId, total, offset int, ids []dtos.MovieId) error
+}
+
+// Sends the movie events as they arrive until the context is done,
+// closing the channel. It is also closed if the subscriber falls behind,
+// so it must resume from its last event.
+type MovieEventSubscriberService interface {
+	Subscribe(ctx context.Context, query dtos.MovieEventsQueryDTO) (<-chan dtos.MovieEventDTO, error)
+}
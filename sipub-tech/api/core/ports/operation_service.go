@@ -0,0 +1,34 @@
+package ports
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+)
+
+var (
+	ErrOperationNotFound = fmt.Errorf("operation not found")
+)
+
+// Tracks the bulk operations, from the results reported for their items.
+type OperationService interface {
+	OperationStarterService
+	OperationGetterService
+	OperationRecorderService
+}
+
+// Returns the pending operation, with its id.
+type OperationStarterService interface {
+	Start(ctx context.Context, operationType dtos.OperationType, total int) (dtos.OperationResponseDTO, error)
+}
+
+type OperationGetterService interface {
+	GetOne(ctx context.Context, id dtos.OperationId) (dtos.OperationResponseDTO, error)
+}
+
+// Records the results of the items, once each, completing the operation
+// when every item has one.
+type OperationRecorderService interface {
+	Record(ctx context.Context, results dtos.OperationResultsDTO) error
+}
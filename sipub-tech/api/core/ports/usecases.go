@@ -14,12 +14,76 @@ type GetMoviesCase interface {
 	GetMovies(ctx context.Context, service MovieAllGetterService, query dtos.MoviesQueryDTO) (movies dtos.MoviesResponseDTO, err error)
 }
 
+type BatchGetMoviesCase interface {
+	BatchGetMovies(ctx context.Context, service MovieManyGetterService, dto dtos.BatchGetMoviesDTO) (dtos.MoviesBatchResponseDTO, error)
+}
+
+type ExportMoviesCase interface {
+	ExportMovies(ctx context.Context, service MovieExporterService, emit func(movie dtos.MovieResponseDTO) error) (int, error)
+}
+
+type StreamMoviesCase interface {
+	StreamMovies(
+		ctx context.Context, service MovieStreamerService, query dtos.MoviesQueryDTO, emit func(movie dtos.MovieResponseDTO) error,
+	) (int, error)
+}
+
 type SaveMovieCase interface {
 	SaveMovie(ctx context.Context, service MovieSaverService, movie dtos.CreateMovieDTO) error
 }
 
+type UpdateMovieCase interface {
+	UpdateMovie(ctx context.Context, service MovieUpdaterService, movie dtos.UpdateMovieDTO) error
+}
+
 type DeleteMovieCase interface {
-	DeleteMovie(ctx context.Context, service MovieDeleterService, id dtos.MovieId) error
+	DeleteMovie(ctx context.Context, service MovieDeleterService, id dtos.MovieId, expectedVersion int) error
+}
+
+type StreamMovieEventsCase interface {
+	StreamMovieEvents(
+		ctx context.Context, service MovieEventSubscriberService, query dtos.MovieEventsQueryDTO,
+	) (<-chan dtos.MovieEventDTO, error)
+}
+
+type CreateWebhookCase interface {
+	CreateWebhook(ctx context.Context, service WebhookSaverService, webhook dtos.CreateWebhookDTO) (dtos.WebhookResponseDTO, error)
+}
+
+type GetWebhookCase interface {
+	GetWebhook(ctx context.Context, service WebhookOneGetterService, id dtos.WebhookId) (dtos.WebhookResponseDTO, error)
+}
+
+type GetWebhooksCase interface {
+	GetWebhooks(ctx context.Context, service WebhookAllGetterService) ([]dtos.WebhookResponseDTO, error)
 }
 
+type UpdateWebhookCase interface {
+	UpdateWebhook(ctx context.Context, service WebhookUpdaterService, webhook dtos.UpdateWebhookDTO) (dtos.WebhookResponseDTO, error)
+}
 
+type DeleteWebhookCase interface {
+	DeleteWebhook(ctx context.Context, service WebhookDeleterService, id dtos.WebhookId) error
+}
+
+type GetWebhookDeliveriesCase interface {
+	GetWebhookDeliveries(
+		ctx context.Context, service WebhookDeliveriesGetterService, query dtos.WebhookDeliveriesQueryDTO,
+	) (dtos.WebhookDeliveriesResponseDTO, error)
+}
+
+type BulkSaveMoviesCase interface {
+	BulkSaveMovies(
+		ctx context.Context, service MovieBulkSaverService, operations OperationService, movies dtos.BulkCreateMoviesDTO,
+	) (dtos.OperationResponseDTO, error)
+}
+
+type BulkDeleteMoviesCase interface {
+	BulkDeleteMovies(
+		ctx context.Context, service MovieBulkDeleterService, operations OperationService, ids dtos.BulkDeleteMoviesDTO,
+	) (dtos.OperationResponseDTO, error)
+}
+
+type GetOperationCase interface {
+	GetOperation(ctx context.Context, service OperationGetterService, id dtos.OperationId) (dtos.OperationResponseDTO, error)
+}
@@ -0,0 +1,47 @@
+package ports
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+)
+
+var (
+	ErrWebhookNotFound = fmt.Errorf("webhook not found")
+)
+
+type WebhookService interface {
+	WebhookSaverService
+	WebhookOneGetterService
+	WebhookAllGetterService
+	WebhookUpdaterService
+	WebhookDeleterService
+	WebhookDeliveriesGetterService
+}
+
+// Returns the saved webhook, with its id and secret.
+type WebhookSaverService interface {
+	Save(ctx context.Context, webhook dtos.CreateWebhookDTO) (dtos.WebhookResponseDTO, error)
+}
+
+type WebhookOneGetterService interface {
+	GetOne(ctx context.Context, id dtos.WebhookId) (dtos.WebhookResponseDTO, error)
+}
+
+type WebhookAllGetterService interface {
+	GetAll(ctx context.Context) ([]dtos.WebhookResponseDTO, error)
+}
+
+type WebhookUpdaterService interface {
+	Update(ctx context.Context, webhook dtos.UpdateWebhookDTO) (dtos.WebhookResponseDTO, error)
+}
+
+// Deletes the webhook with its deliveries.
+type WebhookDeleterService interface {
+	Delete(ctx context.Context, id dtos.WebhookId) error
+}
+
+type WebhookDeliveriesGetterService interface {
+	GetDeliveries(ctx context.Context, query dtos.WebhookDeliveriesQueryDTO) (dtos.WebhookDeliveriesResponseDTO, error)
+}
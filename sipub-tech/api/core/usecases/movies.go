@@ -2,6 +2,7 @@ package usecases
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
@@ -18,7 +19,7 @@ func (ucase *GetMovieCase) GetMovie(
 	ctx context.Context, service ports.MovieOneGetterService, id dtos.MovieId,
 ) (movie dtos.MovieResponseDTO, err error) {
 	if movie, err = service.GetOne(ctx, id); err != nil {
-		if err == ports.ErrMovieNotFound {
+		if errors.Is(err, ports.ErrMovieNotFound) {
 			return movie, err
 		}
 		return movie, fmt.Errorf("could not get movie with id %d: %w", id, err)
@@ -41,6 +42,31 @@ func (ucase *GetMoviesCase) GetMovies(
 	return
 }
 
+func NewBatchGetMoviesCase() *BatchGetMoviesCase {
+	return &BatchGetMoviesCase{}
+}
+
+type BatchGetMoviesCase struct {}
+
+// The repeated ids are only asked once.
+func (ucase *BatchGetMoviesCase) BatchGetMovies(
+	ctx context.Context, service ports.MovieManyGetterService, dto dtos.BatchGetMoviesDTO,
+) (movies dtos.MoviesBatchResponseDTO, err error) {
+	seen := make(map[dtos.MovieId]bool, len(dto.IDs))
+	ids := make([]dtos.MovieId, 0, len(dto.IDs))
+	for _, id := range dto.IDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	if movies, err = service.GetMany(ctx, ids); err != nil {
+		return movies, fmt.Errorf("could not get movies with ids %v: %w", ids, err)
+	}
+	return
+}
+
 func NewSaveMovieCase() *SaveMovieCase {
 	return &SaveMovieCase{}
 }
@@ -54,15 +80,95 @@ func (ucase *SaveMovieCase) SaveMovie(ctx context.Context, service ports.MovieSa
 	return nil
 }
 
+func NewUpdateMovieCase() *UpdateMovieCase {
+	return &UpdateMovieCase{}
+}
+
+type UpdateMovieCase struct {}
+
+func (ucase *UpdateMovieCase) UpdateMovie(ctx context.Context, service ports.MovieUpdaterService, movie dtos.UpdateMovieDTO) error {
+	if err := service.Update(ctx, movie); err != nil {
+		return fmt.Errorf("could not update movie %+v: %w", movie, err)
+	}
+	return nil
+}
+
 func NewDeleteMovieCase() *DeleteMovieCase {
 	return &DeleteMovieCase{}
 }
 
 type DeleteMovieCase struct {}
 
-func (ucase *DeleteMovieCase) DeleteMovie(ctx context.Context, service ports.MovieDeleterService, id dtos.MovieId) error {
-	if err := service.Delete(ctx, id); err != nil {
-		return fmt.Errorf("could not delete movie with id %d", id)
+func (ucase *DeleteMovieCase) DeleteMovie(
+	ctx context.Context, service ports.MovieDeleterService, id dtos.MovieId, expectedVersion int,
+) error {
+	if err := service.Delete(ctx, id, expectedVersion); err != nil {
+		return fmt.Errorf("could not delete movie with id %d: %w", id, err)
 	}
 	return nil
 }
+
+func NewStreamMovieEventsCase() *StreamMovieEventsCase {
+	return &StreamMovieEventsCase{}
+}
+
+type StreamMovieEventsCase struct {}
+
+func (ucase *StreamMovieEventsCase) StreamMovieEvents(
+	ctx context.Context, service ports.MovieEventSubscriberService, query dtos.MovieEventsQueryDTO,
+) (<-chan dtos.MovieEventDTO, error) {
+	events, err := service.Subscribe(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("could not subscribe to movie events with query %+v: %w", query, err)
+	}
+	return events, nil
+}
+
+func NewExportMoviesCase() *ExportMoviesCase {
+	return &ExportMoviesCase{}
+}
+
+type ExportMoviesCase struct {}
+
+// Returns how many movies were emitted, even when the export failed
+// midway.
+func (ucase *ExportMoviesCase) ExportMovies(
+	ctx context.Context, service ports.MovieExporterService, emit func(movie dtos.MovieResponseDTO) error,
+) (exported int, err error) {
+	err = service.Export(ctx, func(movie dtos.MovieResponseDTO) error {
+		if err := emit(movie); err != nil {
+			return err
+		}
+		exported++
+		return nil
+	})
+	if err != nil {
+		return exported, fmt.Errorf("could not export movies after %d: %w", exported, err)
+	}
+	return exported, nil
+}
+
+func NewStreamMoviesCase() *StreamMoviesCase {
+	return &StreamMoviesCase{}
+}
+
+type StreamMoviesCase struct {}
+
+// Returns how many movies were emitted, even when the stream failed
+// midway.
+func (ucase *StreamMoviesCase) StreamMovies(
+	ctx context.Context, service ports.MovieStreamerService, query dtos.MoviesQueryDTO,
+	emit func(movie dtos.MovieResponseDTO) error,
+) (streamed int, err error) {
+	err = service.Stream(ctx, query, func(movie dtos.MovieResponseDTO) error {
+		if err := emit(movie); err != nil {
+			return err
+		}
+		streamed++
+		return nil
+	})
+	if err != nil {
+		return streamed, fmt.Errorf("could not stream movies with query %+v after %d: %w", query, streamed, err)
+	}
+	return streamed, nil
+}
@@ -163,6 +163,155 @@ func (svc *MockMovieAllGetterService) GetAll(ctx context.Context, query dtos.Mov
 	return svc.MoviesToReturn, svc.ReturnedError
 }
 
+func TestBatchGetMoviesCase(t *testing.T) {
+	usecase := usecases.NewBatchGetMoviesCase()
+	t.Run("should ask the service for each id once, in order", func(t *testing.T) {
+		movies := dtos.MoviesBatchResponseDTO{
+			Movies:     []*dtos.MovieResponseDTO{{ID: 3, Title: "a movie", Year: "1995", Version: 1}},
+			MissingIDs: []dtos.MovieId{5},
+		}
+		service := &MockMovieManyGetterService{MoviesToReturn: movies}
+
+		response, err := usecase.BatchGetMovies(context.Background(), service, dtos.BatchGetMoviesDTO{IDs: []dtos.MovieId{3, 5, 3}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, movies, response)
+		assert.Equal(t, []dtos.MovieId{3, 5}, service.IDsPassed)
+	})
+
+	t.Run("should keep the error of the service in the chain", func(t *testing.T) {
+		err := fmt.Errorf("random error")
+		_, returnedErr := usecase.BatchGetMovies(
+			context.Background(), &MockMovieManyGetterService{ReturnedError: err}, dtos.BatchGetMoviesDTO{IDs: []dtos.MovieId{1}},
+		)
+
+		assert.ErrorIs(t, returnedErr, err)
+		assert.NotEqual(t, err, returnedErr)
+	})
+}
+
+
+type MockMovieManyGetterService struct {
+	ports.MovieManyGetterService
+
+	MoviesToReturn  dtos.MoviesBatchResponseDTO
+	ReturnedError   error
+	IDsPassed       []dtos.MovieId
+}
+
+func (svc *MockMovieManyGetterService) GetMany(ctx context.Context, ids []dtos.MovieId) (dtos.MoviesBatchResponseDTO, error) {
+	svc.IDsPassed = ids
+	return svc.MoviesToReturn, svc.ReturnedError
+}
+
+func TestExportMoviesCase(t *testing.T) {
+	usecase := usecases.NewExportMoviesCase()
+	movies := []dtos.MovieResponseDTO{{ID: 1, Title: "first"}, {ID: 2, Title: "second"}, {ID: 3, Title: "third"}}
+
+	t.Run("should emit every movie of the service, counting them", func(t *testing.T) {
+		var emitted []dtos.MovieResponseDTO
+		exported, err := usecase.ExportMovies(context.Background(), &MockMovieExporterService{Movies: movies}, func(movie dtos.MovieResponseDTO) error {
+			emitted = append(emitted, movie)
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, exported)
+		assert.Equal(t, movies, emitted)
+	})
+
+	t.Run("should stop on the first error of emit, counting the movies emitted", func(t *testing.T) {
+		diskFull := fmt.Errorf("disk full")
+		exported, err := usecase.ExportMovies(context.Background(), &MockMovieExporterService{Movies: movies}, func(movie dtos.MovieResponseDTO) error {
+			if movie.ID == 2 {
+				return diskFull
+			}
+			return nil
+		})
+
+		assert.ErrorIs(t, err, diskFull)
+		assert.Equal(t, 1, exported)
+	})
+
+	t.Run("should wrap the errors of the service", func(t *testing.T) {
+		service := &MockMovieExporterService{Movies: movies, ReturnedError: ports.ErrServiceUnavailable}
+		_, err := usecase.ExportMovies(context.Background(), service, func(movie dtos.MovieResponseDTO) error {
+			return nil
+		})
+
+		assert.ErrorIs(t, err, ports.ErrServiceUnavailable)
+	})
+}
+
+// Emits the movies, or fails with ReturnedError before any when set.
+type MockMovieExporterService struct {
+	Movies        []dtos.MovieResponseDTO
+	ReturnedError error
+}
+
+func (svc *MockMovieExporterService) Export(ctx context.Context, emit func(movie dtos.MovieResponseDTO) error) error {
+	if svc.ReturnedError != nil {
+		return svc.ReturnedError
+	}
+	for _, movie := range svc.Movies {
+		if err := emit(movie); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestStreamMoviesCase(t *testing.T) {
+	usecase := usecases.NewStreamMoviesCase()
+	movies := []dtos.MovieResponseDTO{{ID: 1, Title: "first"}, {ID: 2, Title: "second"}, {ID: 3, Title: "third"}}
+	query := dtos.MoviesQueryDTO{Year: "1995", Cursor: 7, Limit: 50}
+
+	t.Run("should emit the movies of the query, counting them", func(t *testing.T) {
+		service := &MockMovieStreamerService{Movies: movies}
+		var emitted []dtos.MovieResponseDTO
+		streamed, err := usecase.StreamMovies(context.Background(), service, query, func(movie dtos.MovieResponseDTO) error {
+			emitted = append(emitted, movie)
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, streamed)
+		assert.Equal(t, movies, emitted)
+		assert.Equal(t, query, service.QueryPassed)
+	})
+
+	t.Run("should stop on the first error of emit, counting the movies emitted", func(t *testing.T) {
+		gone := fmt.Errorf("client gone")
+		streamed, err := usecase.StreamMovies(context.Background(), &MockMovieStreamerService{Movies: movies}, query, func(movie dtos.MovieResponseDTO) error {
+			if movie.ID == 3 {
+				return gone
+			}
+			return nil
+		})
+
+		assert.ErrorIs(t, err, gone)
+		assert.Equal(t, 2, streamed)
+	})
+}
+
+// Emits the movies, recording the query.
+type MockMovieStreamerService struct {
+	Movies      []dtos.MovieResponseDTO
+	QueryPassed dtos.MoviesQueryDTO
+}
+
+func (svc *MockMovieStreamerService) Stream(
+	ctx context.Context, query dtos.MoviesQueryDTO, emit func(movie dtos.MovieResponseDTO) error,
+) error {
+	svc.QueryPassed = query
+	for _, movie := range svc.Movies {
+		if err := emit(movie); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func TestSaveMovieCase(t *testing.T) {
 	usecase := usecases.NewSaveMovieCase()
 	t.Run("should pass movie to service when called.", func(t *testing.T) {
@@ -224,9 +373,9 @@ func (svc *MockMovieSaverService) Save(ctx context.Context, movie dtos.CreateMov
 func TestDeleteMovieCase(t *testing.T) {
 	usecase := usecases.NewDeleteMovieCase()
 	t.Run("should pass movie id to service when called.", func(t *testing.T) {
-		assertion := func(id dtos.MovieId) bool {
+		assertion := func(id dtos.MovieId, version int) bool {
 			service := &MockMovieDeleterService{}
-			err := usecase.DeleteMovie(context.Background(), service, id)
+			err := usecase.DeleteMovie(context.Background(), service, id, version)
 			if err != nil {
 				t.Logf("Error returned when should not have error: %v", err)
 			}
@@ -234,6 +383,9 @@ func TestDeleteMovieCase(t *testing.T) {
 			if !assert.Equal(t, id, service.IdPassed) {
 				return false
 			}
+			if !assert.Equal(t, version, service.VersionPassed) {
+				return false
+			}
 			return true
 		}
 		if err := quick.Check(assertion, nil); err != nil {
@@ -247,7 +399,7 @@ func TestDeleteMovieCase(t *testing.T) {
 			service := &MockMovieDeleterService{
 				ReturnedError: err,
 			}
-			returnedErr := usecase.DeleteMovie(context.Background(), service, id)
+			returnedErr := usecase.DeleteMovie(context.Background(), service, id, 0)
 			if returnedErr == nil {
 				t.Logf("Error not returned when should have error")
 				return false
@@ -265,16 +417,57 @@ func TestDeleteMovieCase(t *testing.T) {
 	})	
 }
 
+func TestStreamMovieEventsCase(t *testing.T) {
+	usecase := usecases.NewStreamMovieEventsCase()
+	t.Run("should subscribe to the service with the query", func(t *testing.T) {
+		assertion := func(query dtos.MovieEventsQueryDTO) bool {
+			service := &MockMovieEventSubscriberService{}
+			events, err := usecase.StreamMovieEvents(context.Background(), service, query)
+
+			return err == nil && events != nil && assert.Equal(t, query, service.QueryPassed)
+		}
+		if err := quick.Check(assertion, nil); err != nil {
+			t.Errorf("assertion failed: %v", err)
+		}
+	})
+
+	t.Run("should keep the error of the service in the chain", func(t *testing.T) {
+		err := fmt.Errorf("random error")
+		_, returnedErr := usecase.StreamMovieEvents(
+			context.Background(), &MockMovieEventSubscriberService{ReturnedError: err}, dtos.MovieEventsQueryDTO{},
+		)
+
+		assert.ErrorIs(t, returnedErr, err)
+	})
+}
+
+
+type MockMovieEventSubscriberService struct {
+	ReturnedError error
+	QueryPassed   dtos.MovieEventsQueryDTO
+}
+
+func (svc *MockMovieEventSubscriberService) Subscribe(
+	ctx context.Context, query dtos.MovieEventsQueryDTO,
+) (<-chan dtos.MovieEventDTO, error) {
+	svc.QueryPassed = query
+	if svc.ReturnedError != nil {
+		return nil, svc.ReturnedError
+	}
+	return make(chan dtos.MovieEventDTO), nil
+}
 
 type MockMovieDeleterService struct {
 	ports.MovieDeleterService
 
 	ReturnedError error
 	IdPassed      dtos.MovieId
+	VersionPassed int
 }
 
-func (svc *MockMovieDeleterService) Delete(ctx context.Context, id dtos.MovieId) error {
+func (svc *MockMovieDeleterService) Delete(ctx context.Context, id dtos.MovieId, expectedVersion int) error {
 	svc.IdPassed = id
+	svc.VersionPassed = expectedVersion
 	return svc.ReturnedError
 }
 
@@ -0,0 +1,110 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+)
+
+const (
+	// Items sent to the movies service in each message.
+	BulkBatchSize = 100
+
+	BulkItemNotSentMessage = "the item could not be sent to the movies service"
+)
+
+func NewBulkSaveMoviesCase() *BulkSaveMoviesCase {
+	return &BulkSaveMoviesCase{}
+}
+
+type BulkSaveMoviesCase struct {}
+
+func (ucase *BulkSaveMoviesCase) BulkSaveMovies(
+	ctx context.Context, service ports.MovieBulkSaverService, operations ports.OperationService, dto dtos.BulkCreateMoviesDTO,
+) (dtos.OperationResponseDTO, error) {
+	total := len(dto.Movies)
+	return sendInBatches(
+		ctx, operations, dtos.BulkCreateMovies, dto.Movies,
+		func(ctx context.Context, operation dtos.OperationId, offset int, batch []dtos.CreateMovieDTO) error {
+			return service.SaveBatch(ctx, operation, total, offset, batch)
+		},
+	)
+}
+
+func NewBulkDeleteMoviesCase() *BulkDeleteMoviesCase {
+	return &BulkDeleteMoviesCase{}
+}
+
+type BulkDeleteMoviesCase struct {}
+
+func (ucase *BulkDeleteMoviesCase) BulkDeleteMovies(
+	ctx context.Context, service ports.MovieBulkDeleterService, operations ports.OperationService, dto dtos.BulkDeleteMoviesDTO,
+) (dtos.OperationResponseDTO, error) {
+	total := len(dto.IDs)
+	return sendInBatches(
+		ctx, operations, dtos.BulkDeleteMovies, dto.IDs,
+		func(ctx context.Context, operation dtos.OperationId, offset int, batch []dtos.MovieId) error {
+			return service.DeleteBatch(ctx, operation, total, offset, batch)
+		},
+	)
+}
+
+func NewGetOperationCase() *GetOperationCase {
+	return &GetOperationCase{}
+}
+
+type GetOperationCase struct {}
+
+func (ucase *GetOperationCase) GetOperation(
+	ctx context.Context, service ports.OperationGetterService, id dtos.OperationId,
+) (operation dtos.OperationResponseDTO, err error) {
+	if operation, err = service.GetOne(ctx, id); err != nil {
+		return operation, fmt.Errorf("could not get operation with id %q: %w", id, err)
+	}
+	return
+}
+
+// Starts the operation and sends its items in batches of BulkBatchSize.
+// When a batch can't be sent, the sending stops and its items and the
+// following ones are recorded as failed. It only fails when not even the
+// first batch was sent.
+func sendInBatches[Item any](
+	ctx context.Context,
+	operations ports.OperationService,
+	operationType dtos.OperationType,
+	items []Item,
+	send func(ctx context.Context, operation dtos.OperationId, offset int, batch []Item) error,
+) (dtos.OperationResponseDTO, error) {
+	operation, err := operations.Start(ctx, operationType, len(items))
+	if err != nil {
+		return operation, fmt.Errorf("could not start %s operation of %d items: %w", operationType, len(items), err)
+	}
+
+	for offset := 0; offset < len(items); offset += BulkBatchSize {
+		batch := items[offset:min(offset + BulkBatchSize, len(items))]
+		err := send(ctx, operation.ID, offset, batch)
+		if err == nil {
+			continue
+		}
+
+		results := dtos.OperationResultsDTO{OperationID: operation.ID, Type: operationType, Total: len(items)}
+		for index := offset; index < len(items); index++ {
+			results.Results = append(results.Results, dtos.OperationItemResultDTO{
+				Index: index, Status: dtos.OperationItemFailed, Error: BulkItemNotSentMessage,
+			})
+		}
+		if recordErr := operations.Record(ctx, results); recordErr != nil {
+			err = fmt.Errorf("%w (and failed recording it: %v)", err, recordErr)
+		}
+		if offset == 0 {
+			return operation, fmt.Errorf("could not send the items of operation %s: %w", operation.ID, err)
+		}
+		if current, getErr := operations.GetOne(ctx, operation.ID); getErr == nil {
+			operation = current
+		}
+		break
+	}
+	return operation, nil
+}
@@ -0,0 +1,125 @@
+package usecases_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/usecases"
+)
+
+func TestBulkSaveMoviesCase(t *testing.T) {
+	usecase := usecases.NewBulkSaveMoviesCase()
+	movies := make([]dtos.CreateMovieDTO, 2*usecases.BulkBatchSize + 1)
+
+	t.Run("should send the movies in batches, with their offsets and the size of the operation", func(t *testing.T) {
+		service := &MockMovieBulkService{}
+		operation, err := usecase.BulkSaveMovies(
+			context.Background(), service, &FakeOperationService{}, dtos.BulkCreateMoviesDTO{Movies: movies},
+		)
+
+		require.NoError(t, err)
+		assert.Equal(t, dtos.OperationPending, operation.Status)
+		assert.Equal(t, []int{0, usecases.BulkBatchSize, 2*usecases.BulkBatchSize}, service.OffsetsPassed)
+		assert.Equal(t, []int{usecases.BulkBatchSize, usecases.BulkBatchSize, 1}, service.SizesPassed)
+		for _, id := range service.OperationsPassed {
+			assert.Equal(t, operation.ID, id)
+		}
+	})
+
+	t.Run("should record the items not sent as failed", func(t *testing.T) {
+		service := &MockMovieBulkService{FailFrom: 1, ReturnedError: fmt.Errorf("broker down")}
+		store := &FakeOperationService{}
+		operation, err := usecase.BulkSaveMovies(context.Background(), service, store, dtos.BulkCreateMoviesDTO{Movies: movies})
+
+		require.NoError(t, err)
+		assert.Len(t, operation.Results, len(movies) - usecases.BulkBatchSize)
+		assert.Equal(t, usecases.BulkItemNotSentMessage, operation.Results[0].Error)
+		assert.Equal(t, usecases.BulkBatchSize, operation.Results[0].Index)
+	})
+
+	t.Run("should fail when not even the first batch was sent", func(t *testing.T) {
+		err := fmt.Errorf("broker down")
+		service := &MockMovieBulkService{ReturnedError: err}
+		_, returnedErr := usecase.BulkSaveMovies(
+			context.Background(), service, &FakeOperationService{}, dtos.BulkCreateMoviesDTO{Movies: movies},
+		)
+
+		assert.ErrorIs(t, returnedErr, err)
+		assert.Len(t, service.OffsetsPassed, 1)
+	})
+}
+
+func TestBulkDeleteMoviesCase(t *testing.T) {
+	t.Run("should send the ids to the service", func(t *testing.T) {
+		service := &MockMovieBulkService{}
+		operation, err := usecases.NewBulkDeleteMoviesCase().BulkDeleteMovies(
+			context.Background(), service, &FakeOperationService{}, dtos.BulkDeleteMoviesDTO{IDs: []dtos.MovieId{1, 2}},
+		)
+
+		require.NoError(t, err)
+		assert.Equal(t, dtos.BulkDeleteMovies, operation.Type)
+		assert.Equal(t, 2, operation.Total)
+		assert.Equal(t, []int{2}, service.SizesPassed)
+	})
+}
+
+
+// Records the batches, failing the ones from the batch FailFrom with
+// ReturnedError.
+type MockMovieBulkService struct {
+	ReturnedError    error
+	FailFrom         int
+	OffsetsPassed    []int
+	SizesPassed      []int
+	OperationsPassed []dtos.OperationId
+}
+
+func (service *MockMovieBulkService) SaveBatch(
+	ctx context.Context, operation dtos.OperationId, total, offset int, movies []dtos.CreateMovieDTO,
+) error {
+	return service.record(operation, offset, len(movies))
+}
+
+func (service *MockMovieBulkService) DeleteBatch(
+	ctx context.Context, operation dtos.OperationId, total, offset int, ids []dtos.MovieId,
+) error {
+	return service.record(operation, offset, len(ids))
+}
+
+func (service *MockMovieBulkService) record(operation dtos.OperationId, offset, size int) error {
+	service.OperationsPassed = append(service.OperationsPassed, operation)
+	service.OffsetsPassed = append(service.OffsetsPassed, offset)
+	service.SizesPassed = append(service.SizesPassed, size)
+	if len(service.OffsetsPassed) > service.FailFrom {
+		return service.ReturnedError
+	}
+	return nil
+}
+
+// Keeps the last operation started, with the results recorded for it.
+type FakeOperationService struct {
+	operation dtos.OperationResponseDTO
+}
+
+func (service *FakeOperationService) Start(
+	ctx context.Context, operationType dtos.OperationType, total int,
+) (dtos.OperationResponseDTO, error) {
+	service.operation = dtos.OperationResponseDTO{
+		ID: "operation", Type: operationType, Status: dtos.OperationPending, Total: total,
+	}
+	return service.operation, nil
+}
+
+func (service *FakeOperationService) GetOne(ctx context.Context, id dtos.OperationId) (dtos.OperationResponseDTO, error) {
+	return service.operation, nil
+}
+
+func (service *FakeOperationService) Record(ctx context.Context, results dtos.OperationResultsDTO) error {
+	service.operation.Results = append(service.operation.Results, results.Results...)
+	return nil
+}
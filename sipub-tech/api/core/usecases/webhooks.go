@@ -0,0 +1,114 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+)
+
+const (
+	// Bytes of the generated secrets, answered in hex.
+	WebhookSecretSize = 32
+)
+
+func NewCreateWebhookCase() *CreateWebhookCase {
+	return &CreateWebhookCase{}
+}
+
+type CreateWebhookCase struct {}
+
+// Generates the secret of the webhook when none is sent.
+func (ucase *CreateWebhookCase) CreateWebhook(
+	ctx context.Context, service ports.WebhookSaverService, webhook dtos.CreateWebhookDTO,
+) (dtos.WebhookResponseDTO, error) {
+	if webhook.Secret == "" {
+		secret := make([]byte, WebhookSecretSize)
+		if _, err := rand.Read(secret); err != nil {
+			return dtos.WebhookResponseDTO{}, fmt.Errorf("could not generate webhook secret: %w", err)
+		}
+		webhook.Secret = hex.EncodeToString(secret)
+	}
+
+	saved, err := service.Save(ctx, webhook)
+	if err != nil {
+		return saved, fmt.Errorf("could not save webhook for %q: %w", webhook.URL, err)
+	}
+	return saved, nil
+}
+
+func NewGetWebhookCase() *GetWebhookCase {
+	return &GetWebhookCase{}
+}
+
+type GetWebhookCase struct {}
+
+func (ucase *GetWebhookCase) GetWebhook(
+	ctx context.Context, service ports.WebhookOneGetterService, id dtos.WebhookId,
+) (webhook dtos.WebhookResponseDTO, err error) {
+	if webhook, err = service.GetOne(ctx, id); err != nil {
+		return webhook, fmt.Errorf("could not get webhook with id %q: %w", id, err)
+	}
+	return
+}
+
+func NewGetWebhooksCase() *GetWebhooksCase {
+	return &GetWebhooksCase{}
+}
+
+type GetWebhooksCase struct {}
+
+func (ucase *GetWebhooksCase) GetWebhooks(
+	ctx context.Context, service ports.WebhookAllGetterService,
+) (webhooks []dtos.WebhookResponseDTO, err error) {
+	if webhooks, err = service.GetAll(ctx); err != nil {
+		return webhooks, fmt.Errorf("could not get webhooks: %w", err)
+	}
+	return
+}
+
+func NewUpdateWebhookCase() *UpdateWebhookCase {
+	return &UpdateWebhookCase{}
+}
+
+type UpdateWebhookCase struct {}
+
+func (ucase *UpdateWebhookCase) UpdateWebhook(
+	ctx context.Context, service ports.WebhookUpdaterService, webhook dtos.UpdateWebhookDTO,
+) (updated dtos.WebhookResponseDTO, err error) {
+	if updated, err = service.Update(ctx, webhook); err != nil {
+		return updated, fmt.Errorf("could not update webhook with id %q: %w", webhook.ID, err)
+	}
+	return
+}
+
+func NewDeleteWebhookCase() *DeleteWebhookCase {
+	return &DeleteWebhookCase{}
+}
+
+type DeleteWebhookCase struct {}
+
+func (ucase *DeleteWebhookCase) DeleteWebhook(ctx context.Context, service ports.WebhookDeleterService, id dtos.WebhookId) error {
+	if err := service.Delete(ctx, id); err != nil {
+		return fmt.Errorf("could not delete webhook with id %q: %w", id, err)
+	}
+	return nil
+}
+
+func NewGetWebhookDeliveriesCase() *GetWebhookDeliveriesCase {
+	return &GetWebhookDeliveriesCase{}
+}
+
+type GetWebhookDeliveriesCase struct {}
+
+func (ucase *GetWebhookDeliveriesCase) GetWebhookDeliveries(
+	ctx context.Context, service ports.WebhookDeliveriesGetterService, query dtos.WebhookDeliveriesQueryDTO,
+) (deliveries dtos.WebhookDeliveriesResponseDTO, err error) {
+	if deliveries, err = service.GetDeliveries(ctx, query); err != nil {
+		return deliveries, fmt.Errorf("could not get deliveries with query %+v: %w", query, err)
+	}
+	return
+}
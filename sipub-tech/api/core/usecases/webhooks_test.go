@@ -0,0 +1,104 @@
+package usecases_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/usecases"
+)
+
+func TestCreateWebhookCase(t *testing.T) {
+	usecase := usecases.NewCreateWebhookCase()
+	t.Run("should pass the webhook with its secret to the service", func(t *testing.T) {
+		assertion := func(url, secret string) bool {
+			if secret == "" {
+				return true
+			}
+			service := &MockWebhookSaverService{}
+			webhook := dtos.CreateWebhookDTO{URL: url, Secret: secret}
+			_, err := usecase.CreateWebhook(context.Background(), service, webhook)
+
+			return err == nil && assert.Equal(t, webhook, service.WebhookPassed)
+		}
+		if err := quick.Check(assertion, nil); err != nil {
+			t.Errorf("assertion failed: %v", err)
+		}
+	})
+
+	t.Run("should generate a different secret for each webhook sent without one", func(t *testing.T) {
+		first, second := &MockWebhookSaverService{}, &MockWebhookSaverService{}
+		usecase.CreateWebhook(context.Background(), first, dtos.CreateWebhookDTO{URL: "http://localhost"})
+		usecase.CreateWebhook(context.Background(), second, dtos.CreateWebhookDTO{URL: "http://localhost"})
+
+		assert.Len(t, first.WebhookPassed.Secret, 2*usecases.WebhookSecretSize)
+		assert.NotEqual(t, first.WebhookPassed.Secret, second.WebhookPassed.Secret)
+	})
+
+	t.Run("should keep the error of the service in the chain", func(t *testing.T) {
+		err := fmt.Errorf("random error")
+		_, returnedErr := usecase.CreateWebhook(
+			context.Background(), &MockWebhookSaverService{ReturnedError: err}, dtos.CreateWebhookDTO{},
+		)
+
+		assert.ErrorIs(t, returnedErr, err)
+	})
+}
+
+
+type MockWebhookSaverService struct {
+	ports.WebhookSaverService
+
+	ReturnedError error
+	WebhookPassed dtos.CreateWebhookDTO
+}
+
+func (svc *MockWebhookSaverService) Save(ctx context.Context, webhook dtos.CreateWebhookDTO) (dtos.WebhookResponseDTO, error) {
+	svc.WebhookPassed = webhook
+	return dtos.WebhookResponseDTO{URL: webhook.URL, Secret: webhook.Secret}, svc.ReturnedError
+}
+
+
+func TestGetWebhookDeliveriesCase(t *testing.T) {
+	usecase := usecases.NewGetWebhookDeliveriesCase()
+	t.Run("should pass the query to the service", func(t *testing.T) {
+		assertion := func(id string, limit, cursor int) bool {
+			service := &MockWebhookDeliveriesGetterService{}
+			query := dtos.WebhookDeliveriesQueryDTO{WebhookID: dtos.WebhookId(id), Limit: limit, Cursor: cursor}
+			_, err := usecase.GetWebhookDeliveries(context.Background(), service, query)
+
+			return err == nil && assert.Equal(t, query, service.QueryPassed)
+		}
+		if err := quick.Check(assertion, nil); err != nil {
+			t.Errorf("assertion failed: %v", err)
+		}
+	})
+
+	t.Run("should keep ports.ErrWebhookNotFound in the chain", func(t *testing.T) {
+		_, err := usecase.GetWebhookDeliveries(
+			context.Background(),
+			&MockWebhookDeliveriesGetterService{ReturnedError: ports.ErrWebhookNotFound},
+			dtos.WebhookDeliveriesQueryDTO{},
+		)
+
+		assert.ErrorIs(t, err, ports.ErrWebhookNotFound)
+	})
+}
+
+
+type MockWebhookDeliveriesGetterService struct {
+	ReturnedError error
+	QueryPassed   dtos.WebhookDeliveriesQueryDTO
+}
+
+func (svc *MockWebhookDeliveriesGetterService) GetDeliveries(
+	ctx context.Context, query dtos.WebhookDeliveriesQueryDTO,
+) (dtos.WebhookDeliveriesResponseDTO, error) {
+	svc.QueryPassed = query
+	return dtos.WebhookDeliveriesResponseDTO{}, svc.ReturnedError
+}
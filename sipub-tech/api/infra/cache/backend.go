@@ -0,0 +1,19 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Stores the encoded values of the cache. The LRUBackend keeps them in
+// memory; an external cache shared by the gateways can be used by
+// implementing it.
+type Backend interface {
+	// Tells if the key was found, returning its value.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Stores the value until the ttl passes, or the backend evicts it.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	// Deletes every key starting with the prefix.
+	DeletePrefix(ctx context.Context, prefix string) error
+}
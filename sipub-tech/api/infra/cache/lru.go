@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultCapacity = 10_000
+)
+
+// Keeps up to capacity entries in memory, evicting the least recently
+// used one when full. Expired entries are dropped when read.
+func NewLRUBackend(capacity int) *LRUBackend {
+	return &LRUBackend{
+		capacity: capacity,
+		entries:  map[string]*list.Element{},
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+type LRUBackend struct {
+	Backend
+
+	mutex    sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	// From the most to the least recently used.
+	order    *list.List
+	now      func() time.Time
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// Replaces the clock used to expire the entries.
+func (backend *LRUBackend) SetClock(now func() time.Time) {
+	backend.mutex.Lock()
+	defer backend.mutex.Unlock()
+	backend.now = now
+}
+
+func (backend *LRUBackend) Len() int {
+	backend.mutex.Lock()
+	defer backend.mutex.Unlock()
+	return backend.order.Len()
+}
+
+func (backend *LRUBackend) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	backend.mutex.Lock()
+	defer backend.mutex.Unlock()
+
+	element, ok := backend.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := element.Value.(*lruEntry)
+	if !backend.now().Before(entry.expiresAt) {
+		backend.remove(element)
+		return nil, false, nil
+	}
+
+	backend.order.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+func (backend *LRUBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	backend.mutex.Lock()
+	defer backend.mutex.Unlock()
+
+	expiresAt := backend.now().Add(ttl)
+	if element, ok := backend.entries[key]; ok {
+		entry := element.Value.(*lruEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		backend.order.MoveToFront(element)
+		return nil
+	}
+
+	if backend.capacity <= 0 {
+		return nil
+	}
+	for backend.order.Len() >= backend.capacity {
+		backend.remove(backend.order.Back())
+	}
+	backend.entries[key] = backend.order.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+	return nil
+}
+
+func (backend *LRUBackend) Delete(ctx context.Context, keys ...string) error {
+	backend.mutex.Lock()
+	defer backend.mutex.Unlock()
+
+	for _, key := range keys {
+		if element, ok := backend.entries[key]; ok {
+			backend.remove(element)
+		}
+	}
+	return nil
+}
+
+func (backend *LRUBackend) DeletePrefix(ctx context.Context, prefix string) error {
+	backend.mutex.Lock()
+	defer backend.mutex.Unlock()
+
+	for key, element := range backend.entries {
+		if strings.HasPrefix(key, prefix) {
+			backend.remove(element)
+		}
+	}
+	return nil
+}
+
+func (backend *LRUBackend) remove(element *list.Element) {
+	backend.order.Remove(element)
+	delete(backend.entries, element.Value.(*lruEntry).key)
+}
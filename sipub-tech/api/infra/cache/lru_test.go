@@ -0,0 +1,78 @@
+package cache_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/cache"
+)
+
+func TestLRUBackend(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should return the stored values", func(t *testing.T) {
+		assertion := func(key string, value []byte) bool {
+			backend := cache.NewLRUBackend(10)
+			backend.Set(ctx, key, value, time.Minute)
+
+			stored, found, err := backend.Get(ctx, key)
+			return assert.NoError(t, err) && assert.True(t, found) && assert.Equal(t, value, stored)
+		}
+		if err := quick.Check(assertion, nil); err != nil {
+			t.Errorf("Failed checking assertion: %v", err)
+		}
+	})
+
+	t.Run("should evict the least recently used entry when full", func(t *testing.T) {
+		backend := cache.NewLRUBackend(2)
+		backend.Set(ctx, "a", []byte("a"), time.Minute)
+		backend.Set(ctx, "b", []byte("b"), time.Minute)
+		backend.Get(ctx, "a")
+		backend.Set(ctx, "c", []byte("c"), time.Minute)
+
+		_, foundA, _ := backend.Get(ctx, "a")
+		_, foundB, _ := backend.Get(ctx, "b")
+		_, foundC, _ := backend.Get(ctx, "c")
+		assert.True(t, foundA)
+		assert.False(t, foundB)
+		assert.True(t, foundC)
+		assert.Equal(t, 2, backend.Len())
+	})
+
+	t.Run("should expire the entries after their ttl", func(t *testing.T) {
+		now := time.Date(2026, time.October, 19, 10, 0, 0, 0, time.UTC)
+		backend := cache.NewLRUBackend(10)
+		backend.SetClock(func() time.Time { return now })
+		backend.Set(ctx, "a", []byte("a"), time.Second)
+
+		now = now.Add(999 * time.Millisecond)
+		_, found, _ := backend.Get(ctx, "a")
+		assert.True(t, found)
+
+		now = now.Add(time.Millisecond)
+		_, found, _ = backend.Get(ctx, "a")
+		assert.False(t, found)
+		assert.Equal(t, 0, backend.Len())
+	})
+
+	t.Run("should delete the keys and the prefixes", func(t *testing.T) {
+		backend := cache.NewLRUBackend(10)
+		for index := range 3 {
+			backend.Set(ctx, fmt.Sprintf("movies:%d", index), []byte{}, time.Minute)
+		}
+		backend.Set(ctx, "movie:1", []byte{}, time.Minute)
+		backend.Set(ctx, "movie:2", []byte{}, time.Minute)
+
+		backend.DeletePrefix(ctx, "movies:")
+		backend.Delete(ctx, "movie:1", "movie:3")
+
+		_, found, _ := backend.Get(ctx, "movie:2")
+		assert.True(t, found)
+		assert.Equal(t, 1, backend.Len())
+	})
+}
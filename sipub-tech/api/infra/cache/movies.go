@@ -0,0 +1,210 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+)
+
+const (
+	movieKeyPrefix  = "movie:"
+	moviesKeyPrefix = "movies:"
+)
+
+// How long the movies are kept. They match the max-age answered to the
+// clients, as a change is announced to both the same way.
+type Config struct {
+	MovieTTL  time.Duration
+	MoviesTTL time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		MovieTTL:  30 * time.Second,
+		MoviesTTL: 5 * time.Second,
+	}
+}
+
+// Counters of the cache lookups, answered by CachedMovieService.Stats.
+type Stats struct {
+	Hits          int64  `json:"hits"`
+	Misses        int64  `json:"misses"`
+	Invalidations int64  `json:"invalidations"`
+	// Failures of the backend or of the encoding, answered from the
+	// service instead.
+	Errors        int64  `json:"errors"`
+}
+
+// Answers the queries from the backend, reading through the service on
+// misses.
+// Pages of movies are dropped on every change, as any of them may now
+// hold the changed movie, while single movies are only dropped on their
+// own changes. Errors are never cached.
+func NewCachedMovieService(service ports.MovieQueryService, backend Backend, config Config) *CachedMovieService {
+	return &CachedMovieService{
+		service: service,
+		backend: backend,
+		config:  config,
+	}
+}
+
+type CachedMovieService struct {
+	ports.MovieQueryService
+
+	service ports.MovieQueryService
+	backend Backend
+	config  Config
+
+	// Incremented on every invalidation, so values read from the service
+	// while a change is announced are not cached.
+	generation    atomic.Int64
+
+	hits          atomic.Int64
+	misses        atomic.Int64
+	invalidations atomic.Int64
+	errors        atomic.Int64
+}
+
+func (service *CachedMovieService) GetOne(ctx context.Context, id dtos.MovieId) (dtos.MovieResponseDTO, error) {
+	var movie dtos.MovieResponseDTO
+	key := fmt.Sprintf("%s%d", movieKeyPrefix, id)
+
+	if service.lookup(ctx, key, &movie) {
+		return movie, nil
+	}
+
+	generation := service.generation.Load()
+	movie, err := service.service.GetOne(ctx, id)
+	if err != nil {
+		return movie, err
+	}
+	service.store(ctx, key, movie, service.config.MovieTTL, generation)
+	return movie, nil
+}
+
+// Shares the copies of the single movies, only asking the service for the
+// movies not cached. The missing ids are not cached.
+func (service *CachedMovieService) GetMany(ctx context.Context, ids []dtos.MovieId) (dtos.MoviesBatchResponseDTO, error) {
+	cached := make(map[dtos.MovieId]*dtos.MovieResponseDTO, len(ids))
+	var uncached []dtos.MovieId
+	for _, id := range ids {
+		var movie dtos.MovieResponseDTO
+		if service.lookup(ctx, fmt.Sprintf("%s%d", movieKeyPrefix, id), &movie) {
+			cached[id] = &movie
+		} else {
+			uncached = append(uncached, id)
+		}
+	}
+
+	movies := dtos.MoviesBatchResponseDTO{MissingIDs: []dtos.MovieId{}}
+	if len(uncached) > 0 {
+		generation := service.generation.Load()
+		fetched, err := service.service.GetMany(ctx, uncached)
+		if err != nil {
+			return fetched, err
+		}
+		for _, movie := range fetched.Movies {
+			service.store(ctx, fmt.Sprintf("%s%d", movieKeyPrefix, movie.ID), *movie, service.config.MovieTTL, generation)
+			cached[dtos.MovieId(movie.ID)] = movie
+		}
+		movies.MissingIDs = append(movies.MissingIDs, fetched.MissingIDs...)
+	}
+
+	movies.Movies = make([]*dtos.MovieResponseDTO, 0, len(cached))
+	for _, id := range ids {
+		if movie, ok := cached[id]; ok {
+			movies.Movies = append(movies.Movies, movie)
+		}
+	}
+	return movies, nil
+}
+
+func (service *CachedMovieService) GetAll(ctx context.Context, query dtos.MoviesQueryDTO) (dtos.MoviesResponseDTO, error) {
+	var movies dtos.MoviesResponseDTO
+	key := fmt.Sprintf("%s%s:%d:%d", moviesKeyPrefix, query.Year, query.Limit, query.Cursor)
+
+	if service.lookup(ctx, key, &movies) {
+		return movies, nil
+	}
+
+	generation := service.generation.Load()
+	movies, err := service.service.GetAll(ctx, query)
+	if err != nil {
+		return movies, err
+	}
+	service.store(ctx, key, movies, service.config.MoviesTTL, generation)
+	return movies, nil
+}
+
+// Drops the copies affected by the event.
+func (service *CachedMovieService) Invalidate(ctx context.Context, event dtos.MovieEventDTO) error {
+	service.generation.Add(1)
+	service.invalidations.Add(1)
+
+	if event.Type != dtos.MovieCreated {
+		if err := service.backend.Delete(ctx, fmt.Sprintf("%s%d", movieKeyPrefix, event.Movie.ID)); err != nil {
+			service.errors.Add(1)
+			return fmt.Errorf("failed invalidating movie %d: %w", event.Movie.ID, err)
+		}
+	}
+	if err := service.backend.DeletePrefix(ctx, moviesKeyPrefix); err != nil {
+		service.errors.Add(1)
+		return fmt.Errorf("failed invalidating the pages of movies: %w", err)
+	}
+	return nil
+}
+
+func (service *CachedMovieService) Stats() Stats {
+	return Stats{
+		Hits:          service.hits.Load(),
+		Misses:        service.misses.Load(),
+		Invalidations: service.invalidations.Load(),
+		Errors:        service.errors.Load(),
+	}
+}
+
+func (service *CachedMovieService) lookup(ctx context.Context, key string, target any) bool {
+	encoded, found, err := service.backend.Get(ctx, key)
+	if err != nil {
+		log.Printf("Failed reading %q from the cache: %v", key, err)
+		service.errors.Add(1)
+	}
+	if err != nil || !found {
+		service.misses.Add(1)
+		return false
+	}
+
+	if err := gob.NewDecoder(bytes.NewReader(encoded)).Decode(target); err != nil {
+		log.Printf("Failed decoding %q from the cache: %v", key, err)
+		service.errors.Add(1)
+		service.misses.Add(1)
+		return false
+	}
+	service.hits.Add(1)
+	return true
+}
+
+func (service *CachedMovieService) store(ctx context.Context, key string, value any, ttl time.Duration, generation int64) {
+	if service.generation.Load() != generation {
+		return
+	}
+
+	// Unlike JSON, gob keeps the UpdatedAt answered in Last-Modified.
+	var encoded bytes.Buffer
+	if err := gob.NewEncoder(&encoded).Encode(value); err != nil {
+		log.Printf("Failed encoding %q to the cache: %v", key, err)
+		service.errors.Add(1)
+		return
+	}
+	if err := service.backend.Set(ctx, key, encoded.Bytes(), ttl); err != nil {
+		log.Printf("Failed writing %q to the cache: %v", key, err)
+		service.errors.Add(1)
+	}
+}
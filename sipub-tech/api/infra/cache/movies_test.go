@@ -0,0 +1,169 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/cache"
+)
+
+func TestCachedMovieService(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should read a movie through the service once", func(t *testing.T) {
+		assertion := func(movie dtos.MovieResponseDTO) bool {
+			service := &CountingQueryService{Movie: movie}
+			cached := cache.NewCachedMovieService(service, cache.NewLRUBackend(10), cache.DefaultConfig())
+
+			first, err := cached.GetOne(ctx, dtos.MovieId(movie.ID))
+			if !assert.NoError(t, err) {
+				return false
+			}
+			second, err := cached.GetOne(ctx, dtos.MovieId(movie.ID))
+
+			return assert.NoError(t, err) &&
+				assert.Equal(t, movie, first) &&
+				assert.Equal(t, movie, second) &&
+				assert.Equal(t, 1, service.GetOneCalls) &&
+				assert.Equal(t, cache.Stats{Hits: 1, Misses: 1}, cached.Stats())
+		}
+		if err := quick.Check(assertion, nil); err != nil {
+			t.Errorf("Failed checking assertion: %v", err)
+		}
+	})
+
+	t.Run("should cache the pages by their query", func(t *testing.T) {
+		service := &CountingQueryService{Movies: dtos.MoviesResponseDTO{
+			Movies: []*dtos.MovieResponseDTO{{ID: 1, Title: "a movie", Year: "1990", Version: 2}},
+			Cursor: 1,
+		}}
+		cached := cache.NewCachedMovieService(service, cache.NewLRUBackend(10), cache.DefaultConfig())
+
+		query := dtos.MoviesQueryDTO{Year: "1990", Limit: 10}
+		cached.GetAll(ctx, query)
+		movies, err := cached.GetAll(ctx, query)
+		cached.GetAll(ctx, dtos.MoviesQueryDTO{Year: "1990", Limit: 10, Cursor: 1})
+
+		assert.NoError(t, err)
+		assert.Equal(t, service.Movies, movies)
+		assert.Equal(t, 2, service.GetAllCalls)
+	})
+
+	t.Run("should only ask the service for the movies not cached, in a single batch", func(t *testing.T) {
+		service := &CountingQueryService{
+			Movie: dtos.MovieResponseDTO{ID: 2, Title: "cached", Year: "1995", Version: 1},
+			Batch: dtos.MoviesBatchResponseDTO{
+				Movies:     []*dtos.MovieResponseDTO{{ID: 1, Title: "fetched", Year: "2001", Version: 3}},
+				MissingIDs: []dtos.MovieId{3},
+			},
+		}
+		cached := cache.NewCachedMovieService(service, cache.NewLRUBackend(10), cache.DefaultConfig())
+		cached.GetOne(ctx, 2)
+
+		movies, err := cached.GetMany(ctx, []dtos.MovieId{1, 2, 3})
+
+		assert.NoError(t, err)
+		assert.Equal(t, [][]dtos.MovieId{{1, 3}}, service.GetManyIDs)
+		assert.Equal(t, []*dtos.MovieResponseDTO{service.Batch.Movies[0], &service.Movie}, movies.Movies)
+		assert.Equal(t, []dtos.MovieId{3}, movies.MissingIDs)
+
+		fetched, err := cached.GetOne(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, *service.Batch.Movies[0], fetched)
+		assert.Equal(t, 1, service.GetOneCalls)
+	})
+
+	t.Run("should not cache errors", func(t *testing.T) {
+		service := &CountingQueryService{Error: ports.ErrMovieNotFound}
+		cached := cache.NewCachedMovieService(service, cache.NewLRUBackend(10), cache.DefaultConfig())
+
+		cached.GetOne(ctx, 1)
+		_, err := cached.GetOne(ctx, 1)
+
+		assert.ErrorIs(t, err, ports.ErrMovieNotFound)
+		assert.Equal(t, 2, service.GetOneCalls)
+	})
+
+	t.Run("should drop the changed movie and every page on a change", func(t *testing.T) {
+		service := &CountingQueryService{Movie: dtos.MovieResponseDTO{ID: 1}}
+		cached := cache.NewCachedMovieService(service, cache.NewLRUBackend(10), cache.DefaultConfig())
+
+		cached.GetOne(ctx, 1)
+		cached.GetOne(ctx, 2)
+		cached.GetAll(ctx, dtos.MoviesQueryDTO{})
+		err := cached.Invalidate(ctx, dtos.MovieEventDTO{Type: dtos.MovieUpdated, Movie: dtos.MovieResponseDTO{ID: 1}})
+		cached.GetOne(ctx, 1)
+		cached.GetOne(ctx, 2)
+		cached.GetAll(ctx, dtos.MoviesQueryDTO{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, service.GetOneCalls)
+		assert.Equal(t, 2, service.GetAllCalls)
+		assert.Equal(t, int64(1), cached.Stats().Invalidations)
+	})
+
+	t.Run("should only drop the pages when a movie is created", func(t *testing.T) {
+		service := &CountingQueryService{}
+		cached := cache.NewCachedMovieService(service, cache.NewLRUBackend(10), cache.DefaultConfig())
+
+		cached.GetOne(ctx, 1)
+		cached.GetAll(ctx, dtos.MoviesQueryDTO{})
+		cached.Invalidate(ctx, dtos.MovieEventDTO{Type: dtos.MovieCreated, Movie: dtos.MovieResponseDTO{ID: 3}})
+		cached.GetOne(ctx, 1)
+		cached.GetAll(ctx, dtos.MoviesQueryDTO{})
+
+		assert.Equal(t, 1, service.GetOneCalls)
+		assert.Equal(t, 2, service.GetAllCalls)
+	})
+
+	t.Run("should not cache a movie read while a change is announced", func(t *testing.T) {
+		service := &CountingQueryService{Movie: dtos.MovieResponseDTO{ID: 1, Version: 1}}
+		cached := cache.NewCachedMovieService(service, cache.NewLRUBackend(10), cache.DefaultConfig())
+		service.BeforeReturning = func() {
+			cached.Invalidate(ctx, dtos.MovieEventDTO{Type: dtos.MovieUpdated, Movie: dtos.MovieResponseDTO{ID: 1}})
+		}
+
+		cached.GetOne(ctx, 1)
+		service.BeforeReturning = nil
+		cached.GetOne(ctx, 1)
+
+		assert.Equal(t, 2, service.GetOneCalls)
+	})
+}
+
+type CountingQueryService struct {
+	ports.MovieQueryService
+
+	Movie           dtos.MovieResponseDTO
+	Movies          dtos.MoviesResponseDTO
+	Batch           dtos.MoviesBatchResponseDTO
+	Error           error
+	BeforeReturning func()
+
+	GetOneCalls     int
+	GetAllCalls     int
+	GetManyIDs      [][]dtos.MovieId
+}
+
+func (service *CountingQueryService) GetOne(ctx context.Context, id dtos.MovieId) (dtos.MovieResponseDTO, error) {
+	service.GetOneCalls++
+	if service.BeforeReturning != nil {
+		service.BeforeReturning()
+	}
+	return service.Movie, service.Error
+}
+
+func (service *CountingQueryService) GetAll(ctx context.Context, query dtos.MoviesQueryDTO) (dtos.MoviesResponseDTO, error) {
+	service.GetAllCalls++
+	return service.Movies, service.Error
+}
+
+func (service *CountingQueryService) GetMany(ctx context.Context, ids []dtos.MovieId) (dtos.MoviesBatchResponseDTO, error) {
+	service.GetManyIDs = append(service.GetManyIDs, ids)
+	return service.Batch, service.Error
+}
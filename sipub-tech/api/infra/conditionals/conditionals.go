@@ -0,0 +1,109 @@
+package conditionals
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+)
+
+const (
+	ETagHeader              = "ETag"
+	LastModifiedHeader      = "Last-Modified"
+	CacheControlHeader      = "Cache-Control"
+	IfMatchHeader           = "If-Match"
+	IfNoneMatchHeader       = "If-None-Match"
+	IfModifiedSinceHeader   = "If-Modified-Since"
+
+	// Movies change rarely, so shared caches may keep them for a while,
+	// revalidating with the ETag afterwards.
+	MovieCacheControl  = "public, max-age=30, must-revalidate"
+	// Pages change whenever any of their movies do, so they are kept for
+	// less time.
+	MoviesCacheControl = "public, max-age=5, must-revalidate"
+)
+
+// Strong ETag of a movie, changing whenever the movie is updated.
+func MovieETag(movie dtos.MovieResponseDTO) string {
+	return fmt.Sprintf(`"%d-%d"`, movie.ID, movie.Version)
+}
+
+// Weak ETag of a page of movies. Pages with the same movies, on the same
+// versions and with the same cursor, are equivalent.
+func MoviesETag(movies dtos.MoviesResponseDTO) string {
+	hash := fnv.New64a()
+	for _, movie := range movies.Movies {
+		if movie == nil {
+			continue
+		}
+		fmt.Fprintf(hash, "%d-%d;", movie.ID, movie.Version)
+	}
+	fmt.Fprintf(hash, "cursor=%d", movies.Cursor)
+	return fmt.Sprintf(`W/"%x"`, hash.Sum64())
+}
+
+// When the movie was last changed, with the second precision of the
+// Last-Modified header. It is zero for movies without a change date.
+func LastModified(movie dtos.MovieResponseDTO) time.Time {
+	if movie.UpdatedAt == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(movie.UpdatedAt).UTC().Truncate(time.Second)
+}
+
+// Tells if the client already has the current representation, following
+// RFC 9110: If-None-Match is compared weakly and, when it is sent,
+// If-Modified-Since is ignored.
+func NotModified(request *http.Request, etag string, lastModified time.Time) bool {
+	if request.Method != http.MethodGet && request.Method != http.MethodHead {
+		return false
+	}
+
+	if ifNoneMatch := request.Header.Get(IfNoneMatchHeader); ifNoneMatch != "" {
+		return Matches(ifNoneMatch, etag, false)
+	}
+
+	ifModifiedSince := request.Header.Get(IfModifiedSinceHeader)
+	if ifModifiedSince == "" || lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+	return !lastModified.After(since)
+}
+
+// Tells if the list of ETags of a conditional header matches the ETag.
+// If-Match uses the strong comparison, where weak ETags never match, and
+// If-None-Match the weak one.
+func Matches(header, etag string, strong bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	if strong && isWeak(etag) {
+		return false
+	}
+
+	for candidate := range strings.SplitSeq(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if strong && isWeak(candidate) {
+			continue
+		}
+		if opaqueTag(candidate) == opaqueTag(etag) {
+			return true
+		}
+	}
+	return false
+}
+
+func isWeak(etag string) bool {
+	return strings.HasPrefix(etag, "W/")
+}
+
+func opaqueTag(etag string) string {
+	return strings.TrimPrefix(etag, "W/")
+}
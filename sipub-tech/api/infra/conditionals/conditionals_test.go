@@ -0,0 +1,122 @@
+package conditionals_test
+
+import (
+	"net/http"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/conditionals"
+)
+
+func TestMovieETag(t *testing.T) {
+	t.Run("should change only when the id or the version change", func(t *testing.T) {
+		assertion := func(movie dtos.MovieResponseDTO, title string) bool {
+			renamed := movie
+			renamed.Title = title
+			updated := movie
+			updated.Version++
+
+			etag := conditionals.MovieETag(movie)
+			return etag == conditionals.MovieETag(renamed) &&
+				etag != conditionals.MovieETag(updated) &&
+				etag[0] == '"'
+		}
+		if err := quick.Check(assertion, nil); err != nil {
+			t.Errorf("Failed checking assertion: %v", err)
+		}
+	})
+}
+
+func TestMoviesETag(t *testing.T) {
+	movies := dtos.MoviesResponseDTO{
+		Movies: []*dtos.MovieResponseDTO{{ID: 1, Version: 1}, {ID: 2, Version: 3}},
+		Cursor: 2,
+	}
+	etag := conditionals.MoviesETag(movies)
+
+	assert.Regexp(t, `^W/"[0-9a-f]+"$`, etag)
+
+	movies.Movies[1].Version = 4
+	assert.NotEqual(t, etag, conditionals.MoviesETag(movies))
+}
+
+func TestMatches(t *testing.T) {
+	cases := []struct {
+		header   string
+		etag     string
+		strong   bool
+		expected bool
+	}{
+		{`"1-2"`, `"1-2"`, true, true},
+		{`"1-1", "1-2"`, `"1-2"`, true, true},
+		{`"1-1"`, `"1-2"`, true, false},
+		{`W/"1-2"`, `"1-2"`, true, false},
+		{`W/"1-2"`, `"1-2"`, false, true},
+		{`"abc"`, `W/"abc"`, false, true},
+		{`"abc"`, `W/"abc"`, true, false},
+		{`*`, `"1-2"`, true, true},
+	}
+	for _, testCase := range cases {
+		assert.Equalf(
+			t, testCase.expected, conditionals.Matches(testCase.header, testCase.etag, testCase.strong),
+			"header %s with etag %s (strong: %t)", testCase.header, testCase.etag, testCase.strong,
+		)
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	lastModified := time.Date(2026, time.October, 19, 10, 0, 0, 0, time.UTC)
+	etag := `"1-2"`
+
+	request := func(method string, headers map[string]string) *http.Request {
+		req, _ := http.NewRequest(method, "/movies/1", nil)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		return req
+	}
+
+	t.Run("should be modified when no condition is sent", func(t *testing.T) {
+		assert.False(t, conditionals.NotModified(request("GET", nil), etag, lastModified))
+	})
+
+	t.Run("should compare If-None-Match weakly", func(t *testing.T) {
+		assert.True(t, conditionals.NotModified(request("GET", map[string]string{"If-None-Match": `W/"1-2"`}), etag, lastModified))
+		assert.False(t, conditionals.NotModified(request("GET", map[string]string{"If-None-Match": `"1-1"`}), etag, lastModified))
+	})
+
+	t.Run("should ignore If-Modified-Since when If-None-Match is sent", func(t *testing.T) {
+		headers := map[string]string{
+			"If-None-Match":     `"1-1"`,
+			"If-Modified-Since": lastModified.Add(time.Hour).Format(http.TimeFormat),
+		}
+		assert.False(t, conditionals.NotModified(request("GET", headers), etag, lastModified))
+	})
+
+	t.Run("should compare If-Modified-Since with the last modification", func(t *testing.T) {
+		notModified := map[string]string{"If-Modified-Since": lastModified.Format(http.TimeFormat)}
+		modified := map[string]string{"If-Modified-Since": lastModified.Add(-time.Second).Format(http.TimeFormat)}
+		malformed := map[string]string{"If-Modified-Since": "yesterday"}
+
+		assert.True(t, conditionals.NotModified(request("GET", notModified), etag, lastModified))
+		assert.False(t, conditionals.NotModified(request("GET", modified), etag, lastModified))
+		assert.False(t, conditionals.NotModified(request("GET", malformed), etag, lastModified))
+		assert.False(t, conditionals.NotModified(request("GET", notModified), etag, time.Time{}))
+	})
+
+	t.Run("should only apply to reads", func(t *testing.T) {
+		assert.False(t, conditionals.NotModified(request("DELETE", map[string]string{"If-None-Match": etag}), etag, lastModified))
+	})
+}
+
+func TestLastModified(t *testing.T) {
+	assert.True(t, conditionals.LastModified(dtos.MovieResponseDTO{}).IsZero())
+
+	updatedAt := time.Date(2026, time.October, 19, 10, 0, 0, 500_000_000, time.UTC)
+	lastModified := conditionals.LastModified(dtos.MovieResponseDTO{UpdatedAt: updatedAt.UnixMilli()})
+	assert.Equal(t, updatedAt.Truncate(time.Second), lastModified)
+}
@@ -0,0 +1,201 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/middlewares"
+)
+
+const (
+	LastEventIDHeader = "Last-Event-ID"
+	// Browsers can't set headers on WebSockets, nor on the first request
+	// of an EventSource, so the last event id may also be in the query.
+	QueryLastEventIDKey = "last_event_id"
+
+	EventStreamContentType = "text/event-stream"
+)
+
+var (
+	// How often idle streams send something, so proxies don't close them.
+	StreamHeartbeatInterval = 15 * time.Second
+
+	upgrader = websocket.Upgrader{}
+)
+
+// This route streams the created, updated and deleted movies, only of the
+// year in the query when there is one.
+//
+// It answers with Server-Sent Events, whose ids are the event ids, or
+// with a WebSocket of JSON events when the request asks for an upgrade.
+// With Last-Event-ID, the events after it are sent first, or a
+// MovieEventsReset event if it is no longer buffered.
+func (controller *MovieController) StreamMovieEventsHandler(usecase ports.StreamMovieEventsCase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		service, exists := controller.getService(ctx)
+		if !exists {
+			return
+		}
+
+		svc, ok := service.(ports.MovieEventSubscriberService)
+		if !ok {
+			controller.internalServerError(ctx, "The movie event stream is not configured.", "Service malformed.")
+			return
+		}
+
+		dto, exists := ctx.Get(middlewares.DtoKey)
+		if !exists {
+			controller.internalServerError(ctx, "The request could not be processed.", "DTO Parser middleware did not set context.")
+			return
+		}
+
+		moviesQuery, ok := dto.(*dtos.MoviesQueryDTO)
+		if !ok {
+			controller.internalServerError(ctx, "The request could not be processed.", "DTO Parser middleware set malformed context.")
+			return
+		}
+
+		query := dtos.MovieEventsQueryDTO{
+			Year:        moviesQuery.Year,
+			LastEventID: ctx.GetHeader(LastEventIDHeader),
+		}
+		if query.LastEventID == "" {
+			query.LastEventID = ctx.Query(QueryLastEventIDKey)
+		}
+
+		if websocket.IsWebSocketUpgrade(ctx.Request) {
+			controller.streamWebSocket(ctx, usecase, svc, query)
+			return
+		}
+		controller.streamServerSentEvents(ctx, usecase, svc, query)
+	}
+}
+
+func (controller *MovieController) streamServerSentEvents(
+	ctx *gin.Context, usecase ports.StreamMovieEventsCase, service ports.MovieEventSubscriberService, query dtos.MovieEventsQueryDTO,
+) {
+	events, err := usecase.StreamMovieEvents(ctx.Request.Context(), service, query)
+	if err != nil {
+		controller.errorFrom(ctx, err, fmt.Sprintf("Failed to subscribe to movie events with query %+v: %v", query, err))
+		return
+	}
+
+	ctx.Header("Content-Type", EventStreamContentType)
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	// Stops nginx from buffering the stream.
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(StreamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := controller.writeServerSentEvent(ctx, event); err != nil {
+				log.Printf("Failed streaming movie event %+v: %v", event, err)
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(ctx.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case <-ctx.Request.Context().Done():
+			return
+		}
+		ctx.Writer.Flush()
+	}
+}
+
+// The reset event has no id, so the client keeps resuming from the last
+// event it got.
+func (controller *MovieController) writeServerSentEvent(ctx *gin.Context, event dtos.MovieEventDTO) error {
+	data, err := json.Marshal(controller.presenter.PresentMovieEvent(event))
+	if err != nil {
+		return fmt.Errorf("failed encoding event: %w", err)
+	}
+	if event.EventID != "" {
+		if _, err := fmt.Fprintf(ctx.Writer, "id: %s\n", event.EventID); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(ctx.Writer, "event: %s\ndata: %s\n\n", event.Type, data)
+	return err
+}
+
+func (controller *MovieController) streamWebSocket(
+	ctx *gin.Context, usecase ports.StreamMovieEventsCase, service ports.MovieEventSubscriberService, query dtos.MovieEventsQueryDTO,
+) {
+	conn, err := upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+	if err != nil {
+		// The upgrader already answered the request.
+		log.Printf("Failed upgrading movie event stream to a WebSocket: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	// The request context is not canceled when a hijacked connection is
+	// closed, so the closing is noticed by reading from it.
+	streamCtx, cancel := context.WithCancel(ctx.Request.Context())
+	defer cancel()
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	events, err := usecase.StreamMovieEvents(streamCtx, service, query)
+	if err != nil {
+		log.Printf("Failed to subscribe to movie events with query %+v: %v", query, err)
+		conn.WriteControl(
+			websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseInternalServerErr, "The movie event stream is unavailable."),
+			time.Now().Add(time.Second),
+		)
+		return
+	}
+
+	heartbeat := time.NewTicker(StreamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				conn.WriteControl(
+					websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "Fell behind the movie events, resume from the last one."),
+					time.Now().Add(time.Second),
+				)
+				return
+			}
+			if err := conn.WriteJSON(controller.presenter.PresentMovieEvent(event)); err != nil {
+				log.Printf("Failed streaming movie event %+v: %v", event, err)
+				return
+			}
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(time.Second)); err != nil {
+				return
+			}
+		case <-streamCtx.Done():
+			return
+		}
+	}
+}
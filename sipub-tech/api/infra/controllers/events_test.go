@@ -0,0 +1,132 @@
+package controllers_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/usecases"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/controllers"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/middlewares"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/streams"
+)
+
+func TestStreamMovieEventsHandler(t *testing.T) {
+	broker := streams.NewMovieEventBroker(10, 10)
+	server := httptest.NewServer(eventsRouter(broker))
+	defer server.Close()
+
+	first := dtos.MovieEventDTO{EventID: "event-1", Type: dtos.MovieCreated, Movie: dtos.MovieResponseDTO{ID: 1, Year: "1995"}}
+	second := dtos.MovieEventDTO{EventID: "event-2", Type: dtos.MovieDeleted, Movie: dtos.MovieResponseDTO{ID: 2, Year: "2006"}}
+	third := dtos.MovieEventDTO{EventID: "event-3", Type: dtos.MovieUpdated, Movie: dtos.MovieResponseDTO{ID: 1, Year: "1995"}}
+	broker.Publish(context.Background(), first)
+
+	t.Run("should stream the events as Server-Sent Events, resuming after Last-Event-ID", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", server.URL + "/movies/events", nil)
+		req.Header.Set(controllers.LastEventIDHeader, first.EventID)
+		response, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer response.Body.Close()
+
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, controllers.EventStreamContentType, response.Header.Get("Content-Type"))
+
+		waitForSubscribers(t, broker, 1)
+		broker.Publish(context.Background(), second)
+
+		reader := bufio.NewReader(response.Body)
+		assert.Equal(t, []string{"id: event-2", "event: MovieDeleted", `data: {"event_id":"event-2","type":"MovieDeleted","occurred_at":0,"correlation_id":"","causation_id":"","movie":{"id":2,"title":"","year":"2006","version":0}}`}, readServerSentEvent(t, reader))
+	})
+
+	t.Run("should send a reset without id when the last event is unknown", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", server.URL + "/movies/events?last_event_id=missing", nil)
+		response, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer response.Body.Close()
+
+		lines := readServerSentEvent(t, bufio.NewReader(response.Body))
+		assert.Equal(t, "event: MovieEventsReset", lines[0])
+	})
+
+	t.Run("should stream the events of the year over a WebSocket", func(t *testing.T) {
+		url := "ws" + strings.TrimPrefix(server.URL, "http") + "/movies/events?year=1995&last_event_id=event-1"
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		waitForSubscribers(t, broker, 1)
+		broker.Publish(context.Background(), third)
+
+		var received dtos.MovieEventDTO
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		require.NoError(t, conn.ReadJSON(&received))
+		assert.Equal(t, third, received, "the event of 2006 should have been filtered out")
+
+		conn.Close()
+		waitForSubscribers(t, broker, 0)
+	})
+
+	t.Run("should refuse malformed years", func(t *testing.T) {
+		response, err := http.Get(server.URL + "/movies/events?year=nineteen")
+		require.NoError(t, err)
+		defer response.Body.Close()
+
+		assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode)
+	})
+
+	t.Run("should answer 500 without the event stream", func(t *testing.T) {
+		handler := controllers.NewMovieController().StreamMovieEventsHandler(usecases.NewStreamMovieEventsCase())
+		req, _ := http.NewRequest("GET", "/movies/events", nil)
+		ctx, writer := getContext(req)
+		ctx.Set(ports.ServiceKey, &FakeQueryService{})
+		ctx.Set(middlewares.DtoKey, &dtos.MoviesQueryDTO{})
+
+		handler(ctx)
+
+		assert.Equal(t, http.StatusInternalServerError, writer.Status())
+	})
+}
+
+func eventsRouter(service ports.MovieEventSubscriberService) *gin.Engine {
+	router := gin.New()
+	router.GET(
+		"/movies/events",
+		middlewares.AddMovieEventSubscriber(service),
+		middlewares.ParseQueryParameters(),
+		controllers.NewMovieController().StreamMovieEventsHandler(usecases.NewStreamMovieEventsCase()),
+	)
+	return router
+}
+
+// Waits for the subscribers still connected, as the earlier subtests
+// leave once their response is closed.
+func waitForSubscribers(t *testing.T, broker *streams.MovieEventBroker, subscribers int) {
+	assert.Eventually(t, func() bool { return broker.Subscribers() == subscribers }, time.Second, time.Millisecond)
+}
+
+// The lines of the next event, skipping the heartbeats.
+func readServerSentEvent(t *testing.T, reader *bufio.Reader) []string {
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" && len(lines) > 0 {
+			return lines
+		}
+		if line != "" && !strings.HasPrefix(line, ":") {
+			lines = append(lines, line)
+		}
+	}
+}
@@ -0,0 +1,333 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/errors"
+	infraPorts "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/ports"
+)
+
+const (
+	JSONContentType   = "application/json"
+	NDJSONContentType = "application/x-ndjson"
+	CSVContentType    = "text/csv"
+
+	// Overrides the Accept header, for clients that can't set it.
+	QueryExportFormatKey = "format"
+
+	// Sent as trailers, as the export may fail after the status is sent.
+	ExportStatusTrailer = "X-Export-Status"
+	ExportCountTrailer  = "X-Export-Count"
+	ExportComplete      = "complete"
+	ExportFailed        = "failed"
+
+	// How many movies are written between flushes of the response.
+	exportFlushEvery = 100
+)
+
+var (
+	// The formats of the export, by the value of the format query.
+	ExportFormats = map[string]string{
+		"json":   JSONContentType,
+		"ndjson": NDJSONContentType,
+		"csv":    CSVContentType,
+	}
+)
+
+func NewExportController() *ExportController {
+	return &ExportController{}
+}
+
+type ExportController struct {
+	infraPorts.ExportController
+}
+
+
+// This route is responsible for exporting every movie, in no order, in
+// the format of the importer of the movies service.
+//
+// It streams a JSON array, NDJSON or CSV with a header, negotiated from
+// the Accept header, or chosen with the format query. As the status is
+// sent with the first movie, a failure afterwards leaves the body
+// truncated, the JSON array unclosed, and the X-Export-Status trailer
+// failed.
+func (controller *ExportController) ExportMoviesHandler(usecase ports.ExportMoviesCase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		svc, ok := getService[ports.MovieExporterService](ctx)
+		if !ok {
+			return
+		}
+
+		contentType, ok := negotiateExportFormat(ctx)
+		if !ok {
+			errors.AbortWithProblem(ctx, errors.NewProblem(
+				errors.CodeNotAcceptable,
+				"The movies are exported as application/json, application/x-ndjson or text/csv.",
+			))
+			return
+		}
+
+		var writer exportWriter
+		exported, err := usecase.ExportMovies(ctx.Request.Context(), svc, func(movie dtos.MovieResponseDTO) error {
+			if writer == nil {
+				writer = startExport(ctx, contentType)
+			}
+			if err := writer.Write(movie); err != nil {
+				return err
+			}
+			if writer.Written() % exportFlushEvery == 0 {
+				ctx.Writer.Flush()
+			}
+			return nil
+		})
+
+		if err != nil && writer == nil {
+			errorFrom(ctx, err, fmt.Sprintf("Failed to export movies: %v", err))
+			return
+		}
+		if writer == nil {
+			writer = startExport(ctx, contentType)
+		}
+
+		status := ExportComplete
+		if err != nil {
+			log.Printf("Export failed after %d movies: %v", exported, err)
+			status = ExportFailed
+		} else if err := writer.Close(); err != nil {
+			log.Printf("Failed ending the export after %d movies: %v", exported, err)
+			status = ExportFailed
+		}
+		ctx.Writer.Header().Set(ExportStatusTrailer, status)
+		ctx.Writer.Header().Set(ExportCountTrailer, strconv.Itoa(exported))
+	}
+}
+
+// Picks the format of the query, or the one Accept prefers, JSON when it
+// accepts anything.
+func negotiateExportFormat(ctx *gin.Context) (string, bool) {
+	if format := ctx.Query(QueryExportFormatKey); format != "" {
+		contentType, ok := ExportFormats[strings.ToLower(format)]
+		return contentType, ok
+	}
+
+	ranges := acceptedMediaRanges(ctx.GetHeader("Accept"))
+	if ranges == nil {
+		return JSONContentType, true
+	}
+	chosen, chosenQuality := "", 0.0
+	for _, mediaRange := range ranges {
+		var contentType string
+		switch mediaRange.mediaType {
+		case JSONContentType, NDJSONContentType, CSVContentType:
+			contentType = mediaRange.mediaType
+		case "*/*", "application/*":
+			contentType = JSONContentType
+		case "text/*":
+			contentType = CSVContentType
+		default:
+			continue
+		}
+		if mediaRange.quality > chosenQuality {
+			chosen, chosenQuality = contentType, mediaRange.quality
+		}
+	}
+	return chosen, chosen != ""
+}
+
+type acceptedMediaRange struct {
+	mediaType string
+	quality   float64
+}
+
+// Parses the media ranges of an Accept header, skipping the malformed
+// ones, or nil when it is empty.
+func acceptedMediaRanges(accept string) []acceptedMediaRange {
+	if strings.TrimSpace(accept) == "" {
+		return nil
+	}
+	ranges := []acceptedMediaRange{}
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if rawQuality, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(rawQuality, 64); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, acceptedMediaRange{mediaType: mediaType, quality: quality})
+	}
+	return ranges
+}
+
+// Sends the status and the headers, announcing the trailers.
+func startExport(ctx *gin.Context, contentType string) exportWriter {
+	header := ctx.Writer.Header()
+	header.Set("Content-Type", contentType)
+	header.Set("Trailer", ExportStatusTrailer + ", " + ExportCountTrailer)
+	header.Set("X-Accel-Buffering", "no")
+	if extension := exportExtension(contentType); extension != "" {
+		header.Set("Content-Disposition", `attachment; filename="movies.` + extension + `"`)
+	}
+	ctx.Status(http.StatusOK)
+	ctx.Writer.WriteHeaderNow()
+
+	switch contentType {
+	case NDJSONContentType:
+		return &ndjsonExportWriter{encoder: json.NewEncoder(ctx.Writer)}
+	case CSVContentType:
+		return &csvExportWriter{writer: csv.NewWriter(ctx.Writer)}
+	default:
+		return &jsonExportWriter{writer: ctx.Writer}
+	}
+}
+
+func exportExtension(contentType string) string {
+	for extension, format := range ExportFormats {
+		if format == contentType {
+			return extension
+		}
+	}
+	return ""
+}
+
+// The fields of the movies as the importer of the movies service reads
+// them.
+type ExportedMovie struct {
+	ID        dtos.MovieId  `json:"id"`
+	Title     string        `json:"title"`
+	Year      string        `json:"year"`
+	Version   int           `json:"version"`
+	UpdatedAt int64         `json:"updated_at"`
+}
+
+func newExportedMovie(movie dtos.MovieResponseDTO) ExportedMovie {
+	return ExportedMovie{
+		ID:        dtos.MovieId(movie.ID),
+		Title:     movie.Title,
+		Year:      movie.Year,
+		Version:   movie.Version,
+		UpdatedAt: movie.UpdatedAt,
+	}
+}
+
+type exportWriter interface {
+	Write(movie dtos.MovieResponseDTO) error
+	// How many movies were written.
+	Written() int
+	// Ends the file, once every movie is written.
+	Close() error
+}
+
+type jsonExportWriter struct {
+	writer  io.Writer
+	written int
+}
+
+func (writer *jsonExportWriter) Write(movie dtos.MovieResponseDTO) error {
+	data, err := json.Marshal(newExportedMovie(movie))
+	if err != nil {
+		return fmt.Errorf("failed encoding movie %d: %w", movie.ID, err)
+	}
+	separator := ",\n"
+	if writer.written == 0 {
+		separator = "[\n"
+	}
+	if _, err := io.WriteString(writer.writer, separator); err != nil {
+		return err
+	}
+	if _, err := writer.writer.Write(data); err != nil {
+		return err
+	}
+	writer.written++
+	return nil
+}
+
+func (writer *jsonExportWriter) Written() int {
+	return writer.written
+}
+
+func (writer *jsonExportWriter) Close() error {
+	end := "\n]\n"
+	if writer.written == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(writer.writer, end)
+	return err
+}
+
+type ndjsonExportWriter struct {
+	encoder *json.Encoder
+	written int
+}
+
+func (writer *ndjsonExportWriter) Write(movie dtos.MovieResponseDTO) error {
+	if err := writer.encoder.Encode(newExportedMovie(movie)); err != nil {
+		return err
+	}
+	writer.written++
+	return nil
+}
+
+func (writer *ndjsonExportWriter) Written() int {
+	return writer.written
+}
+
+func (writer *ndjsonExportWriter) Close() error {
+	return nil
+}
+
+// The header is written with the first movie, or on Close when there is
+// none.
+type csvExportWriter struct {
+	writer  *csv.Writer
+	started bool
+	written int
+}
+
+func (writer *csvExportWriter) Write(movie dtos.MovieResponseDTO) error {
+	writer.start()
+	writer.writer.Write([]string{
+		strconv.Itoa(movie.ID),
+		movie.Title,
+		movie.Year,
+		strconv.Itoa(movie.Version),
+		strconv.FormatInt(movie.UpdatedAt, 10),
+	})
+	writer.written++
+	if writer.written % exportFlushEvery == 0 {
+		writer.writer.Flush()
+	}
+	return writer.writer.Error()
+}
+
+func (writer *csvExportWriter) Written() int {
+	return writer.written
+}
+
+func (writer *csvExportWriter) Close() error {
+	writer.start()
+	writer.writer.Flush()
+	return writer.writer.Error()
+}
+
+func (writer *csvExportWriter) start() {
+	if !writer.started {
+		writer.started = true
+		writer.writer.Write([]string{"id", "title", "year", "version", "updated_at"})
+	}
+}
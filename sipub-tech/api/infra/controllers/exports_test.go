@@ -0,0 +1,130 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/usecases"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/controllers"
+	infraDtos "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/errors"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/middlewares"
+)
+
+func TestExportController(t *testing.T) {
+	movies := []dtos.MovieResponseDTO{
+		{ID: 8, Title: "Sneeze, the", Year: "1894", Version: 2, UpdatedAt: 1700000000000},
+		{ID: 12, Title: "Train", Year: "1896", Version: 1},
+	}
+	export := func(service ports.MovieExporterService, path, accept string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.GET(
+			"/movies/export",
+			middlewares.AddMovieExporterService(service),
+			controllers.NewExportController().ExportMoviesHandler(usecases.NewExportMoviesCase()),
+		)
+		req := httptest.NewRequest("GET", path, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, req)
+		return response
+	}
+
+	t.Run("should stream a JSON array when anything is accepted", func(t *testing.T) {
+		for _, accept := range []string{"", "*/*", "text/html, application/json;q=0.5"} {
+			response := export(&FakeExporterService{movies: movies}, "/movies/export", accept)
+
+			require.Equal(t, http.StatusOK, response.Code, accept)
+			assert.Equal(t, controllers.JSONContentType, response.Header().Get("Content-Type"))
+			var exported []controllers.ExportedMovie
+			require.NoError(t, json.Unmarshal(response.Body.Bytes(), &exported))
+			assert.Equal(t, []controllers.ExportedMovie{
+				{ID: 8, Title: "Sneeze, the", Year: "1894", Version: 2, UpdatedAt: 1700000000000},
+				{ID: 12, Title: "Train", Year: "1896", Version: 1},
+			}, exported)
+			assert.Equal(t, controllers.ExportComplete, response.Header().Get(controllers.ExportStatusTrailer))
+			assert.Equal(t, "2", response.Header().Get(controllers.ExportCountTrailer))
+		}
+	})
+
+	t.Run("should stream the format Accept prefers", func(t *testing.T) {
+		response := export(&FakeExporterService{movies: movies}, "/movies/export", "application/json;q=0.2, text/csv;q=0.9, application/x-ndjson;q=0.5")
+
+		require.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, controllers.CSVContentType, response.Header().Get("Content-Type"))
+		assert.Equal(t, "id,title,year,version,updated_at\n8,\"Sneeze, the\",1894,2,1700000000000\n12,Train,1896,1,0\n", response.Body.String())
+	})
+
+	t.Run("should stream the format of the query over Accept", func(t *testing.T) {
+		response := export(&FakeExporterService{movies: movies}, "/movies/export?format=ndjson", "text/csv")
+
+		require.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, controllers.NDJSONContentType, response.Header().Get("Content-Type"))
+		assert.Equal(t,
+			"{\"id\":8,\"title\":\"Sneeze, the\",\"year\":\"1894\",\"version\":2,\"updated_at\":1700000000000}\n" +
+				"{\"id\":12,\"title\":\"Train\",\"year\":\"1896\",\"version\":1,\"updated_at\":0}\n",
+			response.Body.String(),
+		)
+	})
+
+	t.Run("should answer a closed array and a header alone when there are no movies", func(t *testing.T) {
+		assert.Equal(t, "[]\n", export(&FakeExporterService{}, "/movies/export", "").Body.String())
+		assert.Equal(t, "id,title,year,version,updated_at\n", export(&FakeExporterService{}, "/movies/export?format=csv", "").Body.String())
+	})
+
+	t.Run("should answer 406 for formats other than JSON, NDJSON and CSV", func(t *testing.T) {
+		for _, request := range [][2]string{{"/movies/export", "text/html"}, {"/movies/export", "application/json;q=0"}, {"/movies/export?format=xml", ""}} {
+			response := export(&FakeExporterService{movies: movies}, request[0], request[1])
+
+			require.Equal(t, http.StatusNotAcceptable, response.Code, request)
+			var body infraDtos.ProblemDetails
+			require.NoError(t, json.Unmarshal(response.Body.Bytes(), &body))
+			assert.Equal(t, string(errors.CodeNotAcceptable), body.Code)
+		}
+	})
+
+	t.Run("should answer a problem when the export fails before the first movie", func(t *testing.T) {
+		service := &FakeExporterService{errorReturned: fmt.Errorf("%w: connection refused", ports.ErrServiceUnavailable)}
+		response := export(service, "/movies/export", "")
+
+		assert.Equal(t, http.StatusServiceUnavailable, response.Code)
+		assert.Equal(t, errors.ProblemContentType, response.Header().Get("Content-Type"))
+	})
+
+	t.Run("should leave the array unclosed and the status failed when the export fails midway", func(t *testing.T) {
+		service := &FakeExporterService{movies: movies, errorReturned: fmt.Errorf("%w: stream reset", ports.ErrServiceUnavailable)}
+		response := export(service, "/movies/export", "")
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Error(t, json.Unmarshal(response.Body.Bytes(), &[]controllers.ExportedMovie{}))
+		assert.Equal(t, controllers.ExportFailed, response.Header().Get(controllers.ExportStatusTrailer))
+		assert.Equal(t, "2", response.Header().Get(controllers.ExportCountTrailer))
+	})
+}
+
+// Emits the movies, then fails with errorReturned when set.
+type FakeExporterService struct {
+	movies        []dtos.MovieResponseDTO
+	errorReturned error
+}
+
+func (service *FakeExporterService) Export(ctx context.Context, emit func(movie dtos.MovieResponseDTO) error) error {
+	for _, movie := range service.movies {
+		if err := emit(movie); err != nil {
+			return err
+		}
+	}
+	return service.errorReturned
+}
@@ -5,40 +5,46 @@ import (
 	"log"
 	"net/http"
 	"strconv"
-	"strings"
+	"time"
 	
 	"github.com/gin-gonic/gin"
 	
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/conditionals"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/errors"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/middlewares"
 	infraPorts "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/ports"
 	infraDtos "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/presenters"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/validators"
 )
 
 func NewMovieController() *MovieController {
-	return &MovieController{}
+	return NewMovieControllerWithPresenter(presenters.NewV1MoviePresenter())
+}
+
+// Creates a controller answering with the bodies mapped by the presenter,
+// so each API version can reuse the same handlers.
+func NewMovieControllerWithPresenter(presenter infraPorts.MoviePresenter) *MovieController {
+	validators.RegisterMovieValidators()
+	return &MovieController{
+		presenter: presenter,
+	}
 }
 
 type MovieController struct {
 	infraPorts.MovieController
+
+	presenter infraPorts.MoviePresenter
 }
 
 
 
 // This route is responsible for getting one movie from the
 // repository by its ID.
-// It returns a JSONResponse with the Movie inside of it.
-//
-// swagger:route GET /movies/:id get_movie
-//  Get a movie from the repository by its id.
-//  Parameters:
-//    + name: id
-//      in: path
-//      type: number
-//      example: 14
-//      description: The id of the movie to fetch
+// It returns a JSONResponse with the Movie inside of it, with a strong
+// ETag and Last-Modified, or 304 if the client already has it.
 func (controller *MovieController) GetMovieHandler(usecase ports.GetMovieCase) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		service, ok := controller.getService(ctx)
@@ -48,7 +54,7 @@ func (controller *MovieController) GetMovieHandler(usecase ports.GetMovieCase) g
 
 		svc, ok := service.(ports.MovieOneGetterService)
 		if !ok {
-			controller.internalServerError(ctx, "service unavailable.", "Service malformed.")
+			controller.internalServerError(ctx, "The movie service is not configured.", "Service malformed.")
 			return
 		}
 
@@ -59,16 +65,16 @@ func (controller *MovieController) GetMovieHandler(usecase ports.GetMovieCase) g
 
 		movie, err := usecase.GetMovie(ctx, svc, dtos.MovieId(id))
 		if err != nil {
-			if strings.Contains(err.Error(), ports.ErrMovieNotFound.Error())  {
-				ctx.JSON(http.StatusNotFound, errors.MovieNotFoundErrorResponse)
-				ctx.Abort()
-				return
-			}
-			controller.internalServerError(ctx, "path broken.", fmt.Sprintf("Failed to fetch movie with id %d: %v", id, err))
+			controller.errorFrom(ctx, err, fmt.Sprintf("Failed to fetch movie with id %d: %v", id, err))
 			return
 		}
 
-		ctx.JSON(http.StatusOK, infraDtos.NewJSONResponse(&movie))
+		etag := conditionals.MovieETag(movie)
+		if controller.notModified(ctx, etag, conditionals.LastModified(movie), conditionals.MovieCacheControl) {
+			return
+		}
+
+		ctx.JSON(http.StatusOK, controller.presenter.PresentMovie(&movie))
 	}
 }
 
@@ -77,25 +83,8 @@ func (controller *MovieController) GetMovieHandler(usecase ports.GetMovieCase) g
 // repository, possiblt filtering them by year, limiting, and skipping
 // all movies before the cursor.
 //
-// It returns a PaginatedJSONResponse with the Movie inside of it.
-//
-// swagger:route GET /movies/  get_movies
-//  Get multiple movies from the repository.
-//  Parameters:
-//      + name: limit
-//        type: number
-//        in: query
-//        example: 500
-//      + name: cursor
-//        type: number
-//        in: query
-//        example: 50
-//        description: the id of the last movie fetched, so it will be skipped.
-//      + name: year
-//        type: number
-//        in: query
-//        description: the year of the movies to query from.
-//        example: 1995
+// It returns a PaginatedJSONResponse with the Movie inside of it, with a
+// weak ETag, or 304 if the client already has the page.
 func (controller *MovieController) GetMoviesHandler(usecase ports.GetMoviesCase) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		service, exists := controller.getService(ctx)
@@ -105,34 +94,72 @@ func (controller *MovieController) GetMoviesHandler(usecase ports.GetMoviesCase)
 
 		svc, ok := service.(ports.MovieAllGetterService)
 		if !ok {
-			controller.internalServerError(ctx, "service unavailable.", "Service malformed.")
+			controller.internalServerError(ctx, "The movie service is not configured.", "Service malformed.")
 			return
 		}
 
 		dto, exists := ctx.Get(middlewares.DtoKey)
 		if !exists {
-			controller.internalServerError(ctx, "path broken.", "DTO Parser middleware did not set context.")
+			controller.internalServerError(ctx, "The request could not be processed.", "DTO Parser middleware did not set context.")
 			return
 		}
 
 		query, ok := dto.(*dtos.MoviesQueryDTO)
 		if !ok {
-			controller.internalServerError(ctx, "path broken.", "DTO Parser middleware set malformed context.")
+			controller.internalServerError(ctx, "The request could not be processed.", "DTO Parser middleware set malformed context.")
 			return
 		}
 
 		movies, err := usecase.GetMovies(ctx, svc, *query)
 		if err != nil {
-			controller.internalServerError(ctx, "path broken.", fmt.Sprintf("Failed to fetch movies with query %+v: %v", query, err))
+			controller.errorFrom(ctx, err, fmt.Sprintf("Failed to fetch movies with query %+v: %v", query, err))
+			return
+		}
+
+		if controller.notModified(ctx, conditionals.MoviesETag(movies), time.Time{}, conditionals.MoviesCacheControl) {
+			return
+		}
+
+		ctx.JSON(http.StatusOK, controller.presenter.PresentMovies(movies, *query))
+	}
+}
+
+// This route is responsible for getting the movies with the ids passed
+// in the JSON body, as a BatchGetMoviesDTO, at once.
+//
+// It returns a BatchJSONResponse with the movies found, in the order of
+// their ids, and the ids of the movies that do not exist.
+func (controller *MovieController) BatchGetMoviesHandler(usecase ports.BatchGetMoviesCase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		service, exists := controller.getService(ctx)
+		if !exists {
+			return
+		}
+
+		svc, ok := service.(ports.MovieManyGetterService)
+		if !ok {
+			controller.internalServerError(ctx, "The movie service is not configured.", "Service malformed.")
+			return
+		}
+
+		var dto dtos.BatchGetMoviesDTO
+
+		if err := ctx.ShouldBindJSON(&dto); err != nil {
+			if fields, ok := validators.FieldErrors(err); ok {
+				controller.validationFailedError(ctx, fields, fmt.Sprintf("Body %+v failed validation: %v", dto, err))
+				return
+			}
+			controller.malformedRequestError(ctx, "The body must be a JSON object with a list of integer ids.", fmt.Sprintf("Body could not be marshalled: %v", err))
 			return
 		}
 
-		dtos := make([]infraDtos.JsonData, len(movies.Movies))
-		for index, movie := range movies.Movies {
-			dtos[index] = movie
-		} 
+		movies, err := usecase.BatchGetMovies(ctx, svc, dto)
+		if err != nil {
+			controller.errorFrom(ctx, err, fmt.Sprintf("Failed to fetch movies with ids %v: %v", dto.IDs, err))
+			return
+		}
 
-		ctx.JSON(http.StatusOK, infraDtos.NewPaginatedResponse(dtos, query.Limit, movies.Cursor))
+		ctx.JSON(http.StatusOK, controller.presenter.PresentMovieBatch(movies))
 	}
 }
 
@@ -141,11 +168,10 @@ func (controller *MovieController) GetMoviesHandler(usecase ports.GetMoviesCase)
 // It is processed in the background
 // A CreateModelDTO should be passed in the JSON body
 //
-// It returns an empty body.
+// The title is required and the year must be a 4-digit year between
+// 1880 and the current year, otherwise the field errors are returned.
 //
-// swagger:route POST /movies/  create_movie
-//  Create a movie in the repository. This operation runs on the background.
-//  
+// It returns an empty body.
 func (controller *MovieController) SaveMovieHandler(usecase ports.SaveMovieCase) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		service, exists := controller.getService(ctx)
@@ -155,19 +181,23 @@ func (controller *MovieController) SaveMovieHandler(usecase ports.SaveMovieCase)
 
 		svc, ok := service.(ports.MovieSaverService)
 		if !ok {
-			controller.internalServerError(ctx, "service unavailable.", "Service malformed.")
+			controller.internalServerError(ctx, "The movie service is not configured.", "Service malformed.")
 			return
 		}
 
 		var dto dtos.CreateMovieDTO
 
-		if err := ctx.ShouldBind(&dto); err != nil {
-			controller.unprocessableEntityError(ctx, "body malformed.", fmt.Sprintf("Body could not be marshalled: %v", err))
+		if err := ctx.ShouldBindJSON(&dto); err != nil {
+			if fields, ok := validators.FieldErrors(err); ok {
+				controller.validationFailedError(ctx, fields, fmt.Sprintf("Body %+v failed validation: %v", dto, err))
+				return
+			}
+			controller.malformedRequestError(ctx, "The body must be a JSON object with a string title and year.", fmt.Sprintf("Body could not be marshalled: %v", err))
 			return
 		}
 
 		if err := usecase.SaveMovie(ctx, svc, dto); err != nil {
-			controller.internalServerError(ctx, "path broken", fmt.Sprintf("Could not create movie with body %+v: %v", dto, err))
+			controller.errorFrom(ctx, err, fmt.Sprintf("Could not create movie with body %+v: %v", dto, err))
 			return
 		}
 
@@ -176,19 +206,66 @@ func (controller *MovieController) SaveMovieHandler(usecase ports.SaveMovieCase)
 }
 
 
-// This route is responsible for deleting a movie from the repository by its id.
+// This route is responsible for updating the title and year of a movie.
 // It is processed in the background
+// A CreateMovieDTO should be passed in the JSON body, validated as on
+// creation.
+//
+// With If-Match, the update is only applied on the matched version.
 //
 // It returns an empty body.
+func (controller *MovieController) UpdateMovieHandler(usecase ports.UpdateMovieCase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		service, exists := controller.getService(ctx)
+		if !exists {
+			return
+		}
+
+		svc, ok := service.(ports.MovieUpdaterService)
+		if !ok {
+			controller.internalServerError(ctx, "The movie service is not configured.", "Service malformed.")
+			return
+		}
+
+		id, ok := controller.getId(ctx)
+		if !ok {
+			return
+		}
+
+		var body dtos.CreateMovieDTO
+
+		if err := ctx.ShouldBindJSON(&body); err != nil {
+			if fields, ok := validators.FieldErrors(err); ok {
+				controller.validationFailedError(ctx, fields, fmt.Sprintf("Body %+v failed validation: %v", body, err))
+				return
+			}
+			controller.malformedRequestError(ctx, "The body must be a JSON object with a string title and year.", fmt.Sprintf("Body could not be marshalled: %v", err))
+			return
+		}
+
+		dto := dtos.UpdateMovieDTO{
+			ID:      dtos.MovieId(id),
+			Title:   body.Title,
+			Year:    body.Year,
+			Version: ctx.GetInt(middlewares.ExpectedVersionKey),
+		}
+
+		if err := usecase.UpdateMovie(ctx, svc, dto); err != nil {
+			controller.errorFrom(ctx, err, fmt.Sprintf("Could not update movie with body %+v: %v", dto, err))
+			return
+		}
+
+		ctx.Status(http.StatusAccepted)
+	}
+}
+
+
+// This route is responsible for deleting a movie from the repository by its id.
+// It is processed in the background
 //
-// swagger:route DELETE /movies/:id  delete_movie
-//  Delete a movie by its id. This operation runs in the background.
-//  Parameters:
-//    + name: id
-//      in: path
-//      type: number
-//      example: 14
-//      description: The id of the movie to fetch
+// With If-Match, the movie is only deleted on the matched version.
+//
+// It returns an empty body.
 func (controller *MovieController) DeleteMovieHandler(usecase ports.DeleteMovieCase) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		service, exists := controller.getService(ctx)
@@ -198,7 +275,7 @@ func (controller *MovieController) DeleteMovieHandler(usecase ports.DeleteMovieC
 
 		svc, ok := service.(ports.MovieDeleterService)
 		if !ok {
-			controller.internalServerError(ctx, "service unavailable.", "Service malformed.")
+			controller.internalServerError(ctx, "The movie service is not configured.", "Service malformed.")
 			return
 		}
 
@@ -207,8 +284,9 @@ func (controller *MovieController) DeleteMovieHandler(usecase ports.DeleteMovieC
 			return
 		}
 
-		if err := usecase.DeleteMovie(ctx, svc, dtos.MovieId(id)); err != nil {
-			controller.internalServerError(ctx, "path broken", fmt.Sprintf("Could not delete movie with id %d: %v", id, err))
+		expectedVersion := ctx.GetInt(middlewares.ExpectedVersionKey)
+		if err := usecase.DeleteMovie(ctx, svc, dtos.MovieId(id), expectedVersion); err != nil {
+			controller.errorFrom(ctx, err, fmt.Sprintf("Could not delete movie with id %d: %v", id, err))
 			return
 		}
 
@@ -217,22 +295,46 @@ func (controller *MovieController) DeleteMovieHandler(usecase ports.DeleteMovieC
 }
 
 
+// Sets the validators and cache headers of the representation, and
+// answers 304 if the conditions of the request show the client has it.
+func (controller *MovieController) notModified(ctx *gin.Context, etag string, lastModified time.Time, cacheControl string) bool {
+	ctx.Header(conditionals.ETagHeader, etag)
+	ctx.Header(conditionals.CacheControlHeader, cacheControl)
+	if !lastModified.IsZero() {
+		ctx.Header(conditionals.LastModifiedHeader, lastModified.Format(http.TimeFormat))
+	}
+
+	if !conditionals.NotModified(ctx.Request, etag, lastModified) {
+		return false
+	}
+	ctx.Status(http.StatusNotModified)
+	return true
+}
+
 func (controller *MovieController) internalServerError(ctx *gin.Context, msg, logging string ) {
 	log.Println(logging)
-	ctx.JSON(http.StatusInternalServerError, errors.InternalServerError(msg))
-	ctx.Abort()
+	errors.AbortWithProblem(ctx, errors.NewProblem(errors.CodeInternalError, msg))
+}
+
+func (controller *MovieController) malformedRequestError(ctx *gin.Context, msg, logging string ) {
+	log.Println(logging)
+	errors.AbortWithProblem(ctx, errors.NewProblem(errors.CodeMalformedRequest, msg))
+}
+
+func (controller *MovieController) validationFailedError(ctx *gin.Context, fields []infraDtos.FieldError, logging string) {
+	log.Println(logging)
+	errors.AbortWithProblem(ctx, errors.ValidationFailed(fields))
 }
 
-func (controller *MovieController) unprocessableEntityError(ctx *gin.Context, msg, logging string ) {
+func (controller *MovieController) errorFrom(ctx *gin.Context, err error, logging string) {
 	log.Println(logging)
-	ctx.JSON(http.StatusUnprocessableEntity, errors.UnprocessableEntity(msg))
-	ctx.Abort()	
+	errors.AbortWithError(ctx, err)
 }
 
 func (controller *MovieController) getService(ctx *gin.Context) (any, bool) {
 	service, exists := ctx.Get(ports.ServiceKey)
 	if !exists {
-		controller.internalServerError(ctx, "service unavailable.", "Service not set to context.")
+		controller.internalServerError(ctx, "The movie service is not configured.", "Service not set to context.")
 	}
 	return service, exists
 }
@@ -240,7 +342,7 @@ func (controller *MovieController) getService(ctx *gin.Context) (any, bool) {
 func (controller *MovieController) getId(ctx *gin.Context) (int, bool) {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		controller.unprocessableEntityError(ctx, "malformed id.", fmt.Sprintf("Could not parse id: %v", err))
+		controller.malformedRequestError(ctx, "The movie id must be an integer.", fmt.Sprintf("Could not parse id: %v", err))
 		return 0, false
 	}
 	return id, true
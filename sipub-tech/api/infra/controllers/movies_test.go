@@ -8,8 +8,10 @@ import (
 	"net/http"
 	//"runtime"
 	"strconv"
+	"strings"
 	"testing"
 	"testing/quick"
+	"time"
 	
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"	
@@ -18,6 +20,7 @@ import (
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
 	infraDtos "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/dtos"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/controllers"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/errors"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/middlewares"
 )
 
@@ -68,7 +71,7 @@ func TestMovieController(t *testing.T) {
 
 				handler(ctx)
 
-				var body infraDtos.ErrorResponse
+				var body infraDtos.ProblemDetails
 
 				if !assert.True(t, ctx.IsAborted()) {
 					return false
@@ -101,7 +104,7 @@ func TestMovieController(t *testing.T) {
 
 				handler(ctx)
 
-				var body infraDtos.ErrorResponse
+				var body infraDtos.ProblemDetails
 
 				if !assert.True(t, ctx.IsAborted()) {
 					return false
@@ -116,15 +119,153 @@ func TestMovieController(t *testing.T) {
 					return false
 				}
 
-				return true
+				if !assert.Equal(t, "application/problem+json", writer.Header().Get("Content-Type")) {
+					return false
+				}
+
+				if !assert.Equal(t, string(errors.CodeMovieNotFound), body.Code) {
+					return false
+				}
+
+				return assert.Equal(t, fmt.Sprintf("/movies/%d", id), body.Instance)
 			}
 			if err := quick.Check(assertion, nil); err != nil {
 				t.Errorf("Failed checking assertion: %v", err)
 			}
 		})
+
+		t.Run("return the problem derived from the error returned by the usecase", func(t *testing.T) {
+			cases := []struct {
+				err    error
+				status int
+				code   errors.Code
+			}{
+				{fmt.Errorf("wrapped: %w", ports.ErrMovieNotFound), 404, errors.CodeMovieNotFound},
+				{fmt.Errorf("wrapped: %w", ports.ErrServiceUnavailable), 503, errors.CodeUpstreamUnavailable},
+				{fmt.Errorf("wrapped: %w", ports.ErrServiceTimeout), 504, errors.CodeUpstreamTimeout},
+				{fmt.Errorf("wrapped: %w", ports.ErrInvalidRequest), 422, errors.CodeValidationFailed},
+				{fmt.Errorf("unknown error"), 500, errors.CodeInternalError},
+			}
+			for _, testCase := range cases {
+				handler := controller.GetMovieHandler(&MockGetMovieCase{ErrorReturned: testCase.err})
+
+				req, _ := http.NewRequest("GET", "/movies/1", nil)
+				ctx, writer := getContext(req)
+				ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: "1"})
+				ctx.Set(ports.ServiceKey, &FakeQueryService{})
+
+				handler(ctx)
+
+				var body infraDtos.ProblemDetails
+				if err := json.Unmarshal(writer.Body, &body); err != nil {
+					t.Fatalf("Error unmarshalling body %s: %v", writer.Body, err)
+				}
+
+				assert.Equal(t, testCase.status, writer.Status())
+				assert.Equal(t, testCase.status, body.Status)
+				assert.Equal(t, string(testCase.code), body.Code)
+				assert.NotEmpty(t, body.Type)
+				assert.NotEmpty(t, body.Title)
+			}
+		})
+
+		t.Run("return Retry-After along with the 503 of an open circuit breaker", func(t *testing.T) {
+			err := fmt.Errorf("wrapped: %w", &ports.UnavailableError{RetryAfter: 2500 * time.Millisecond, Reason: "circuit open"})
+			handler := controller.GetMovieHandler(&MockGetMovieCase{ErrorReturned: err})
+
+			req, _ := http.NewRequest("GET", "/movies/1", nil)
+			ctx, writer := getContext(req)
+			ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: "1"})
+			ctx.Set(ports.ServiceKey, &FakeQueryService{})
+
+			handler(ctx)
+
+			assert.Equal(t, 503, writer.Status())
+			assert.Equal(t, "3", writer.Header().Get("Retry-After"))
+		})
+	})
+
+	t.Run("the function returned by controllers.MovieController.GetMovieHandler must handle conditional requests", func(t *testing.T) {
+		updatedAt := time.Date(2026, time.October, 19, 10, 0, 0, 0, time.UTC)
+		movie := dtos.MovieResponseDTO{ID: 14, Title: "a movie", Year: "1990", Version: 3, UpdatedAt: updatedAt.UnixMilli()}
+
+		get := func(headers map[string]string) (*gin.Context, *FakeWriter) {
+			handler := controller.GetMovieHandler(&MockGetMovieCase{MovieReturned: movie})
+
+			req, _ := http.NewRequest("GET", "/movies/14", nil)
+			for key, value := range headers {
+				req.Header.Set(key, value)
+			}
+			ctx, writer := getContext(req)
+			ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: "14"})
+			ctx.Set(ports.ServiceKey, &FakeQueryService{})
+
+			handler(ctx)
+			return ctx, writer
+		}
+
+		t.Run("return the strong ETag, Last-Modified and Cache-Control", func(t *testing.T) {
+			_, writer := get(nil)
+
+			assert.Equal(t, 200, writer.Status())
+			assert.Equal(t, `"14-3"`, writer.Header().Get("ETag"))
+			assert.Equal(t, updatedAt.Format(http.TimeFormat), writer.Header().Get("Last-Modified"))
+			assert.NotEmpty(t, writer.Header().Get("Cache-Control"))
+		})
+
+		t.Run("return not modified when If-None-Match has the ETag", func(t *testing.T) {
+			_, writer := get(map[string]string{"If-None-Match": `"14-2", "14-3"`})
+
+			assert.Equal(t, 304, writer.Status())
+			assert.Empty(t, writer.Body)
+			assert.Equal(t, `"14-3"`, writer.Header().Get("ETag"))
+		})
+
+		t.Run("return not modified when the movie did not change since If-Modified-Since", func(t *testing.T) {
+			_, writer := get(map[string]string{"If-Modified-Since": updatedAt.Add(time.Minute).Format(http.TimeFormat)})
+			assert.Equal(t, 304, writer.Status())
+
+			_, writer = get(map[string]string{"If-Modified-Since": updatedAt.Add(-time.Minute).Format(http.TimeFormat)})
+			assert.Equal(t, 200, writer.Status())
+		})
+
+		t.Run("return the movie when If-None-Match has an outdated ETag", func(t *testing.T) {
+			_, writer := get(map[string]string{"If-None-Match": `"14-2"`})
+
+			assert.Equal(t, 200, writer.Status())
+			assert.NotEmpty(t, writer.Body)
+		})
 	})
 
 	t.Run("the function returned by controllers.MovieController.GetMoviesHandler must", func(t *testing.T) {
+		t.Run("return a weak ETag and not modified when If-None-Match has it", func(t *testing.T) {
+			movies := dtos.MoviesResponseDTO{Movies: []*dtos.MovieResponseDTO{{ID: 1, Version: 2}}, Cursor: 1}
+			get := func(ifNoneMatch string) *FakeWriter {
+				handler := controller.GetMoviesHandler(&MockGetMoviesCase{MoviesReturned: movies})
+
+				req, _ := http.NewRequest("GET", "/movies", nil)
+				if ifNoneMatch != "" {
+					req.Header.Set("If-None-Match", ifNoneMatch)
+				}
+				ctx, writer := getContext(req)
+				ctx.Set(middlewares.DtoKey, &dtos.MoviesQueryDTO{})
+				ctx.Set(ports.ServiceKey, &FakeQueryService{})
+
+				handler(ctx)
+				return writer
+			}
+
+			writer := get("")
+			etag := writer.Header().Get("ETag")
+			assert.Equal(t, 200, writer.Status())
+			assert.True(t, strings.HasPrefix(etag, `W/"`), "ETag %s is not weak", etag)
+			assert.Empty(t, writer.Header().Get("Last-Modified"))
+
+			writer = get(etag)
+			assert.Equal(t, 304, writer.Status())
+			assert.Empty(t, writer.Body)
+		})
+
 		t.Run("return a paginated response", func(t *testing.T) {
 			assertion := func() bool {
 				handler := controller.GetMoviesHandler(&MockGetMoviesCase{})
@@ -160,16 +301,68 @@ func TestMovieController(t *testing.T) {
 
 	})
 
+	t.Run("the function returned by controllers.MovieController.BatchGetMoviesHandler must", func(t *testing.T) {
+		batchGet := func(body string, usecase *MockBatchGetMoviesCase) *FakeWriter {
+			handler := controller.BatchGetMoviesHandler(usecase)
+
+			req, _ := http.NewRequest("POST", "/movies/batch-get", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			ctx, writer := getContext(req)
+			ctx.Set(ports.ServiceKey, &FakeQueryService{})
+
+			handler(ctx)
+			return writer
+		}
+
+		t.Run("return the movies found and the ids missing", func(t *testing.T) {
+			usecase := &MockBatchGetMoviesCase{MoviesReturned: dtos.MoviesBatchResponseDTO{
+				Movies:     []*dtos.MovieResponseDTO{{ID: 1, Title: "a movie", Year: "1995", Version: 1}},
+				MissingIDs: []dtos.MovieId{2},
+			}}
+			writer := batchGet(`{"ids": [1, 2]}`, usecase)
+
+			assert.Equal(t, 200, writer.Status())
+			assert.Equal(t, []dtos.MovieId{1, 2}, usecase.DTOPassed.IDs)
+			var body infraDtos.BatchJSONResponse[dtos.MovieId]
+			if assert.NoError(t, json.Unmarshal(writer.Body, &body)) {
+				assert.Len(t, body.Data, 1)
+				assert.Equal(t, []dtos.MovieId{2}, body.MissingIDs)
+			}
+		})
+
+		t.Run("refuse invalid ids and too many of them", func(t *testing.T) {
+			ids := make([]string, dtos.MaxBatchGetMovies + 1)
+			for index := range ids {
+				ids[index] = strconv.Itoa(index + 1)
+			}
+			for _, body := range []string{`{"ids": []}`, `{"ids": [1, 0]}`, `{"ids": [` + strings.Join(ids, ",") + `]}`} {
+				usecase := &MockBatchGetMoviesCase{}
+				writer := batchGet(body, usecase)
+
+				assert.Equal(t, 422, writer.Status(), "body %s", body)
+				assert.Nil(t, usecase.DTOPassed.IDs)
+			}
+		})
+
+		t.Run("refuse malformed bodies", func(t *testing.T) {
+			writer := batchGet(`{"ids": "1,2"}`, &MockBatchGetMoviesCase{})
+			assert.Equal(t, 422, writer.Status())
+			assert.Contains(t, string(writer.Body), string(errors.CodeMalformedRequest))
+		})
+	})
+
 	t.Run("the function returned by controllers.MovieController.SaveMovieHandler must", func(t *testing.T) {
 		t.Run("return a success response with no body", func(t *testing.T) {
-			assertion := func(movie dtos.CreateMovieDTO) bool {
+			assertion := func(title string, yearOffset uint16) bool {
 				handler := controller.SaveMovieHandler(&StubSaveMovieCase{})
 
+				movie := validCreateMovieDTO(title, yearOffset)
 				movieBytes, err := json.Marshal(movie)
 				if err != nil {
 					t.Logf("Failed marshalling dto %+v: %v", movie, err)
 				}
 				req, _ := http.NewRequest("POST", "/movies/", bytes.NewReader(movieBytes))
+				req.Header.Set("Content-Type", "application/json")
 				ctx, writer := getContext(req)
 				ctx.Set(ports.ServiceKey, &FakeExecutorService{})
 
@@ -207,7 +400,7 @@ func TestMovieController(t *testing.T) {
 
 				handler(ctx)
 
-				var body infraDtos.ErrorResponse
+				var body infraDtos.ProblemDetails
 
 				if !assert.True(t, ctx.IsAborted()) {
 					return false
@@ -229,9 +422,159 @@ func TestMovieController(t *testing.T) {
 			}
 		})
 
+		t.Run("return the field errors if the body failed validation", func(t *testing.T) {
+			currentYear := strconv.Itoa(time.Now().Year() + 1)
+			cases := map[string]struct {
+				body   string
+				fields []string
+			}{
+				"empty body":         {`{}`, []string{"title", "year"}},
+				"blank title":        {`{"title": "", "year": "1990"}`, []string{"title"}},
+				"long title":         {fmt.Sprintf(`{"title": %q, "year": "1990"}`, strings.Repeat("a", 256)), []string{"title"}},
+				"year before 1880":   {`{"title": "a movie", "year": "1879"}`, []string{"year"}},
+				"year in the future": {fmt.Sprintf(`{"title": "a movie", "year": %q}`, currentYear), []string{"year"}},
+				"year not 4-digit":   {`{"title": "a movie", "year": "01990"}`, []string{"year"}},
+				"year not a number":  {`{"title": "a movie", "year": "abcd"}`, []string{"year"}},
+			}
+			for name, testCase := range cases {
+				t.Run(name, func(t *testing.T) {
+					handler := controller.SaveMovieHandler(&StubSaveMovieCase{})
+
+					req, _ := http.NewRequest("POST", "/movies/", strings.NewReader(testCase.body))
+					req.Header.Set("Content-Type", "application/json")
+					ctx, writer := getContext(req)
+					ctx.Set(ports.ServiceKey, &FakeExecutorService{})
+
+					handler(ctx)
+
+					assert.True(t, ctx.IsAborted())
+					assert.Equal(t, 422, writer.Status())
+
+					var body infraDtos.ProblemDetails
+					if err := json.Unmarshal(writer.Body, &body); err != nil {
+						t.Fatalf("Error unmarshalling body %s: %v", writer.Body, err)
+					}
+
+					fields := make([]string, len(body.Violations))
+					for index, field := range body.Violations {
+						fields[index] = field.Field
+					}
+					assert.ElementsMatch(t, testCase.fields, fields)
+					assert.Equal(t, string(errors.CodeValidationFailed), body.Code)
+				})
+			}
+		})
+
+		t.Run("return an unprocessable entity response if a field has the wrong type", func(t *testing.T) {
+			handler := controller.SaveMovieHandler(&StubSaveMovieCase{})
+
+			req, _ := http.NewRequest("POST", "/movies/", strings.NewReader(`{"title": 10, "year": 1990}`))
+			req.Header.Set("Content-Type", "application/json")
+			ctx, writer := getContext(req)
+			ctx.Set(ports.ServiceKey, &FakeExecutorService{})
+
+			handler(ctx)
+
+			assert.True(t, ctx.IsAborted())
+			assert.Equal(t, 422, writer.Status())
+		})
+
+	})
+
+	t.Run("the function returned by controllers.MovieController.UpdateMovieHandler must", func(t *testing.T) {
+		put := func(usecase ports.UpdateMovieCase, id, body string, expectedVersion *int) (*gin.Context, *FakeWriter) {
+			handler := controller.UpdateMovieHandler(usecase)
+
+			req, _ := http.NewRequest("PUT", "/movies/"+id, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			ctx, writer := getContext(req)
+			ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: id})
+			ctx.Set(ports.ServiceKey, &FakeExecutorService{})
+			if expectedVersion != nil {
+				ctx.Set(middlewares.ExpectedVersionKey, *expectedVersion)
+			}
+
+			handler(ctx)
+			return ctx, writer
+		}
+
+		t.Run("return accepted and pass the movie with the expected version to the usecase", func(t *testing.T) {
+			assertion := func(id uint16, title string, yearOffset uint16, version uint8) bool {
+				usecase := &MockUpdateMovieCase{}
+				movie := validCreateMovieDTO(title, yearOffset)
+				body, _ := json.Marshal(movie)
+				expectedVersion := int(version)
+
+				ctx, writer := put(usecase, strconv.Itoa(int(id)), string(body), &expectedVersion)
+
+				expected := dtos.UpdateMovieDTO{
+					ID:      dtos.MovieId(id),
+					Title:   movie.Title,
+					Year:    movie.Year,
+					Version: expectedVersion,
+				}
+				return assert.False(t, ctx.IsAborted()) &&
+					assert.Equal(t, 202, writer.Status()) &&
+					assert.Equal(t, expected, usecase.MoviePassed)
+			}
+			if err := quick.Check(assertion, nil); err != nil {
+				t.Errorf("Failed checking assertion: %v", err)
+			}
+		})
+
+		t.Run("update unconditionally without If-Match", func(t *testing.T) {
+			usecase := &MockUpdateMovieCase{}
+			_, writer := put(usecase, "1", `{"title": "a movie", "year": "1990"}`, nil)
+
+			assert.Equal(t, 202, writer.Status())
+			assert.Equal(t, 0, usecase.MoviePassed.Version)
+		})
+
+		t.Run("return the field errors if the body failed validation", func(t *testing.T) {
+			ctx, writer := put(&MockUpdateMovieCase{}, "1", `{"title": "", "year": "1879"}`, nil)
+
+			assert.True(t, ctx.IsAborted())
+			assert.Equal(t, 422, writer.Status())
+
+			var body infraDtos.ProblemDetails
+			if err := json.Unmarshal(writer.Body, &body); err != nil {
+				t.Fatalf("Error unmarshalling body %s: %v", writer.Body, err)
+			}
+			assert.Len(t, body.Violations, 2)
+		})
+
+		t.Run("return an unprocessable entity response if the passed id is not an integer", func(t *testing.T) {
+			ctx, writer := put(&MockUpdateMovieCase{}, "abc", `{"title": "a movie", "year": "1990"}`, nil)
+
+			assert.True(t, ctx.IsAborted())
+			assert.Equal(t, 422, writer.Status())
+		})
+
+		t.Run("return the problem derived from the error returned by the usecase", func(t *testing.T) {
+			usecase := &MockUpdateMovieCase{ErrorReturned: fmt.Errorf("wrapped: %w", ports.ErrServiceUnavailable)}
+			_, writer := put(usecase, "1", `{"title": "a movie", "year": "1990"}`, nil)
+
+			assert.Equal(t, 503, writer.Status())
+		})
 	})
 
 	t.Run("the function returned by controllers.MovieController.DeleteMovieHandler must", func(t *testing.T) {
+		t.Run("pass the version matched by If-Match to the usecase", func(t *testing.T) {
+			usecase := &StubDeleteMovieCase{}
+			handler := controller.DeleteMovieHandler(usecase)
+
+			req, _ := http.NewRequest("DELETE", "/movies/1", nil)
+			ctx, writer := getContext(req)
+			ctx.Params = append(ctx.Params, gin.Param{Key: "id", Value: "1"})
+			ctx.Set(ports.ServiceKey, &FakeExecutorService{})
+			ctx.Set(middlewares.ExpectedVersionKey, 4)
+
+			handler(ctx)
+
+			assert.Equal(t, 204, writer.Status())
+			assert.Equal(t, 4, usecase.VersionPassed)
+		})
+
 		t.Run("return a success response with no body", func(t *testing.T) {
 			assertion := func(id uint16) bool {
 				handler := controller.DeleteMovieHandler(&StubDeleteMovieCase{})
@@ -269,7 +612,7 @@ func TestMovieController(t *testing.T) {
 
 				handler(ctx)
 				
-				var body infraDtos.ErrorResponse
+				var body infraDtos.ProblemDetails
 
 				if !assert.True(t, ctx.IsAborted()) {
 					return false
@@ -296,24 +639,40 @@ func TestMovieController(t *testing.T) {
 type MockGetMovieCase struct {
 	ports.GetMovieCase
 
+	MovieReturned dtos.MovieResponseDTO
 	ErrorReturned error
 }
 
 func (usecase *MockGetMovieCase) GetMovie(
 	ctx context.Context, service ports.MovieOneGetterService, id dtos.MovieId,
 ) (movie dtos.MovieResponseDTO, err error) {
-	err = usecase.ErrorReturned
-	return 
+	return usecase.MovieReturned, usecase.ErrorReturned
 }
 
 type MockGetMoviesCase struct {
 	ports.GetMoviesCase
+
+	MoviesReturned dtos.MoviesResponseDTO
 }
 
 func (usecase *MockGetMoviesCase) GetMovies(
 	ctx context.Context, service ports.MovieAllGetterService, query dtos.MoviesQueryDTO,
 ) (movies dtos.MoviesResponseDTO, err error) {
-	return
+	return usecase.MoviesReturned, nil
+}
+
+type MockBatchGetMoviesCase struct {
+	ports.BatchGetMoviesCase
+
+	MoviesReturned dtos.MoviesBatchResponseDTO
+	DTOPassed      dtos.BatchGetMoviesDTO
+}
+
+func (usecase *MockBatchGetMoviesCase) BatchGetMovies(
+	ctx context.Context, service ports.MovieManyGetterService, dto dtos.BatchGetMoviesDTO,
+) (dtos.MoviesBatchResponseDTO, error) {
+	usecase.DTOPassed = dto
+	return usecase.MoviesReturned, nil
 }
 
 type StubSaveMovieCase struct {
@@ -324,11 +683,28 @@ func (usecase *StubSaveMovieCase) SaveMovie(ctx context.Context, service ports.M
 	return nil
 }
 
+type MockUpdateMovieCase struct {
+	ports.UpdateMovieCase
+
+	MoviePassed   dtos.UpdateMovieDTO
+	ErrorReturned error
+}
+
+func (usecase *MockUpdateMovieCase) UpdateMovie(ctx context.Context, service ports.MovieUpdaterService, movie dtos.UpdateMovieDTO) error {
+	usecase.MoviePassed = movie
+	return usecase.ErrorReturned
+}
+
 type StubDeleteMovieCase struct {
 	ports.DeleteMovieCase
+
+	VersionPassed int
 }
 
-func (usecase *StubDeleteMovieCase) DeleteMovie(ctx context.Context, service ports.MovieDeleterService, id dtos.MovieId) error {
+func (usecase *StubDeleteMovieCase) DeleteMovie(
+	ctx context.Context, service ports.MovieDeleterService, id dtos.MovieId, expectedVersion int,
+) error {
+	usecase.VersionPassed = expectedVersion
 	return nil
 }
 
@@ -351,13 +727,29 @@ func (service *FakeExecutorService) Save(ctx context.Context, movie dtos.CreateM
 	return nil
 }
 	
-func (service *FakeExecutorService) Delete(ctx context.Context, id dtos.MovieId) error {
+func (service *FakeExecutorService) Update(ctx context.Context, movie dtos.UpdateMovieDTO) error {
+	return nil
+}
+	
+func (service *FakeExecutorService) Delete(ctx context.Context, id dtos.MovieId, expectedVersion int) error {
 	return nil
 }
 
  
 
 
+func validCreateMovieDTO(title string, yearOffset uint16) dtos.CreateMovieDTO {
+	title = "movie " + title
+	if len(title) > 255 {
+		title = title[:255]
+	}
+	years := time.Now().Year() - dtos.MinimumMovieYear + 1
+	return dtos.CreateMovieDTO{
+		Title: title,
+		Year:  strconv.Itoa(dtos.MinimumMovieYear + int(yearOffset)%years),
+	}
+}
+
 func getContext(req *http.Request) (*gin.Context, *FakeWriter) {
 	writer := &FakeWriter{HeadersMapping: make(http.Header)}
 	return &gin.Context{
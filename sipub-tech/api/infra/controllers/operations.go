@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/errors"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/middlewares"
+	infraPorts "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/presenters"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/validators"
+)
+
+func NewOperationController() *OperationController {
+	validators.RegisterMovieValidators()
+	return &OperationController{
+		presenter: presenters.NewV1OperationPresenter(),
+	}
+}
+
+type OperationController struct {
+	infraPorts.OperationController
+
+	presenter infraPorts.OperationPresenter
+}
+
+
+// This route is responsible for creating many movies in the background.
+// A BulkCreateMoviesDTO should be passed in the JSON body.
+//
+// It returns a JSONResponse with the pending operation, and its location.
+func (controller *OperationController) BulkSaveMoviesHandler(usecase ports.BulkSaveMoviesCase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		svc, ok := getService[ports.MovieBulkSaverService](ctx)
+		if !ok {
+			return
+		}
+		operations, ok := getServiceFrom[ports.OperationService](ctx, ports.OperationServiceKey)
+		if !ok {
+			return
+		}
+
+		var dto dtos.BulkCreateMoviesDTO
+		if !bindBulkBody(ctx, &dto, "The body must be a JSON object with a list of movies, each with a string title and year.") {
+			return
+		}
+
+		operation, err := usecase.BulkSaveMovies(ctx, svc, operations, dto)
+		if err != nil {
+			errorFrom(ctx, err, fmt.Sprintf("Could not create %d movies: %v", len(dto.Movies), err))
+			return
+		}
+
+		controller.accepted(ctx, &operation)
+	}
+}
+
+
+// This route is responsible for deleting many movies in the background.
+// A BulkDeleteMoviesDTO should be passed in the JSON body.
+//
+// It returns a JSONResponse with the pending operation, and its location.
+func (controller *OperationController) BulkDeleteMoviesHandler(usecase ports.BulkDeleteMoviesCase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		svc, ok := getService[ports.MovieBulkDeleterService](ctx)
+		if !ok {
+			return
+		}
+		operations, ok := getServiceFrom[ports.OperationService](ctx, ports.OperationServiceKey)
+		if !ok {
+			return
+		}
+
+		var dto dtos.BulkDeleteMoviesDTO
+		if !bindBulkBody(ctx, &dto, "The body must be a JSON object with a list of integer ids.") {
+			return
+		}
+
+		operation, err := usecase.BulkDeleteMovies(ctx, svc, operations, dto)
+		if err != nil {
+			errorFrom(ctx, err, fmt.Sprintf("Could not delete movies with ids %v: %v", dto.IDs, err))
+			return
+		}
+
+		controller.accepted(ctx, &operation)
+	}
+}
+
+
+// This route is responsible for getting how a bulk operation is going,
+// with the results of its items so far.
+//
+// It returns a JSONResponse with the operation.
+func (controller *OperationController) GetOperationHandler(usecase ports.GetOperationCase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		svc, ok := getServiceFrom[ports.OperationGetterService](ctx, ports.OperationServiceKey)
+		if !ok {
+			return
+		}
+
+		id := dtos.OperationId(ctx.Param("id"))
+		operation, err := usecase.GetOperation(ctx, svc, id)
+		if err != nil {
+			errorFrom(ctx, err, fmt.Sprintf("Failed to fetch operation with id %q: %v", id, err))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, controller.presenter.PresentOperation(&operation))
+	}
+}
+
+
+func (controller *OperationController) accepted(ctx *gin.Context, operation *dtos.OperationResponseDTO) {
+	path := strings.TrimSuffix(ctx.GetString(middlewares.OperationsPathKey), "/")
+	ctx.Header("Location", path + "/" + string(operation.ID))
+	ctx.JSON(http.StatusAccepted, controller.presenter.PresentOperation(operation))
+}
+
+func bindBulkBody(ctx *gin.Context, dto any, malformed string) bool {
+	if err := ctx.ShouldBindJSON(dto); err != nil {
+		if fields, ok := validators.FieldErrors(err); ok {
+			log.Printf("Bulk body failed validation: %v", err)
+			errors.AbortWithProblem(ctx, errors.ValidationFailed(fields))
+			return false
+		}
+		log.Printf("Bulk body could not be marshalled: %v", err)
+		errors.AbortWithProblem(ctx, errors.NewProblem(errors.CodeMalformedRequest, malformed))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,136 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/usecases"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/controllers"
+	infraDtos "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/errors"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/middlewares"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/operations"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/presenters"
+)
+
+func TestOperationController(t *testing.T) {
+	store := operations.NewMemoryStore(operations.DefaultCapacity)
+	service := &FakeBulkExecutorService{}
+	router := operationsRouter(service, store)
+
+	var created dtos.OperationResponseDTO
+	t.Run("should accept the movies in batches, answering the pending operation and its location", func(t *testing.T) {
+		movies := make([]string, usecases.BulkBatchSize + 1)
+		for index := range movies {
+			movies[index] = fmt.Sprintf(`{"title": "movie %d", "year": "1995"}`, index)
+		}
+		response := doJSON(router, "POST", "/movies/bulk", `{"movies": [` + strings.Join(movies, ",") + `]}`)
+
+		require.Equal(t, http.StatusAccepted, response.Code)
+		var body presenters.V1OperationBody
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &body))
+		created = body.Data
+		assert.Equal(t, "/v1/operations/" + string(created.ID), response.Header().Get("Location"))
+		assert.Equal(t, dtos.OperationPending, created.Status)
+		assert.Equal(t, dtos.BulkCreateMovies, created.Type)
+		assert.Equal(t, usecases.BulkBatchSize + 1, created.Total)
+		assert.Equal(t, []int{0, usecases.BulkBatchSize}, service.offsets)
+	})
+
+	t.Run("should answer the operation with the results recorded", func(t *testing.T) {
+		require.NoError(t, store.Record(context.Background(), dtos.OperationResultsDTO{
+			OperationID: created.ID,
+			Results: []dtos.OperationItemResultDTO{{Index: 1, MovieID: 14, Status: dtos.OperationItemCreated}},
+		}))
+
+		response := doJSON(router, "GET", "/v1/operations/" + string(created.ID), "")
+		require.Equal(t, http.StatusOK, response.Code)
+		var body presenters.V1OperationBody
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &body))
+		assert.Equal(t, 1, body.Data.Succeeded)
+		assert.Equal(t, []dtos.OperationItemResultDTO{{Index: 1, MovieID: 14, Status: dtos.OperationItemCreated}}, body.Data.Results)
+	})
+
+	t.Run("should refuse invalid items with their indexes", func(t *testing.T) {
+		response := doJSON(router, "DELETE", "/movies/bulk", `{"ids": [3, 0, -1]}`)
+
+		require.Equal(t, http.StatusUnprocessableEntity, response.Code)
+		var body infraDtos.ProblemDetails
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &body))
+		fields := make([]string, len(body.Violations))
+		for index, violation := range body.Violations {
+			fields[index] = violation.Field
+		}
+		assert.ElementsMatch(t, []string{"ids[1]", "ids[2]"}, fields)
+	})
+
+	t.Run("should refuse empty requests", func(t *testing.T) {
+		response := doJSON(router, "POST", "/movies/bulk", `{"movies": []}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	})
+
+	t.Run("should answer 503 when no batch could be sent", func(t *testing.T) {
+		service.errorReturned = fmt.Errorf("%w: broker down", ports.ErrServiceUnavailable)
+		defer func() { service.errorReturned = nil }()
+
+		response := doJSON(router, "DELETE", "/movies/bulk", `{"ids": [3]}`)
+		assert.Equal(t, http.StatusServiceUnavailable, response.Code)
+	})
+
+	t.Run("should answer 404 for unknown operations", func(t *testing.T) {
+		response := doJSON(router, "GET", "/v1/operations/unknown", "")
+
+		require.Equal(t, http.StatusNotFound, response.Code)
+		var body infraDtos.ProblemDetails
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &body))
+		assert.Equal(t, string(errors.CodeOperationNotFound), body.Code)
+	})
+}
+
+func operationsRouter(service ports.MovieBulkExecutorService, store ports.OperationService) *gin.Engine {
+	controller := controllers.NewOperationController()
+	router := gin.New()
+	bulk := router.Group(
+		"/movies/bulk",
+		middlewares.AddMovieBulkExecutorService(service),
+		middlewares.AddOperationService(store, "/v1/operations"),
+	)
+	bulk.POST("", controller.BulkSaveMoviesHandler(usecases.NewBulkSaveMoviesCase()))
+	bulk.DELETE("", controller.BulkDeleteMoviesHandler(usecases.NewBulkDeleteMoviesCase()))
+	router.GET(
+		"/v1/operations/:id",
+		middlewares.AddOperationService(store, "/v1/operations"),
+		controller.GetOperationHandler(usecases.NewGetOperationCase()),
+	)
+	return router
+}
+
+// Records the offsets of the batches sent, failing them with errorReturned.
+type FakeBulkExecutorService struct {
+	offsets       []int
+	errorReturned error
+}
+
+func (service *FakeBulkExecutorService) SaveBatch(
+	ctx context.Context, operation dtos.OperationId, total, offset int, movies []dtos.CreateMovieDTO,
+) error {
+	service.offsets = append(service.offsets, offset)
+	return service.errorReturned
+}
+
+func (service *FakeBulkExecutorService) DeleteBatch(
+	ctx context.Context, operation dtos.OperationId, total, offset int, ids []dtos.MovieId,
+) error {
+	service.offsets = append(service.offsets, offset)
+	return service.errorReturned
+}
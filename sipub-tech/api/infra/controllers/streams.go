@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/middlewares"
+	infraPorts "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/ports"
+)
+
+const (
+	// Sent as trailers, as the stream may fail after the status is sent.
+	StreamStatusTrailer = "X-Stream-Status"
+	StreamCountTrailer  = "X-Stream-Count"
+	StreamComplete      = "complete"
+	StreamFailed        = "failed"
+)
+
+func NewMovieStreamController() *MovieStreamController {
+	return &MovieStreamController{}
+}
+
+type MovieStreamController struct {
+	infraPorts.MovieStreamController
+}
+
+// This route is responsible for streaming the movie listings as NDJSON,
+// one movie as in the data of the pages per line, when Accept prefers
+// application/x-ndjson over application/json. Otherwise it passes the
+// request on to the pages.
+//
+// Every movie after the cursor is streamed, up to the limit when given,
+// the movie service reading the next page only once the client took the
+// previous ones. As the status is sent with the first movie, a failure
+// afterwards leaves the body truncated and the X-Stream-Status trailer
+// failed.
+func (controller *MovieStreamController) StreamMoviesHandler(usecase ports.StreamMoviesCase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !prefersNDJSON(ctx.GetHeader("Accept")) {
+			ctx.Next()
+			return
+		}
+
+		svc, ok := getServiceFrom[ports.MovieStreamerService](ctx, ports.StreamerServiceKey)
+		if !ok {
+			return
+		}
+
+		dto, exists := ctx.Get(middlewares.DtoKey)
+		if !exists {
+			internalServerError(ctx, "The request could not be processed.", "DTO Parser middleware did not set context.")
+			return
+		}
+		query, ok := dto.(*dtos.MoviesQueryDTO)
+		if !ok {
+			internalServerError(ctx, "The request could not be processed.", "DTO Parser middleware set malformed context.")
+			return
+		}
+
+		// Answered here, the pages must not be.
+		ctx.Abort()
+
+		var encoder *json.Encoder
+		written := 0
+		streamed, err := usecase.StreamMovies(ctx.Request.Context(), svc, *query, func(movie dtos.MovieResponseDTO) error {
+			if encoder == nil {
+				encoder = startStream(ctx)
+			}
+			if err := encoder.Encode(movie); err != nil {
+				return err
+			}
+			if written++; written % exportFlushEvery == 0 {
+				ctx.Writer.Flush()
+			}
+			return nil
+		})
+		if err != nil && encoder == nil {
+			errorFrom(ctx, err, fmt.Sprintf("Failed to stream movies with query %+v: %v", query, err))
+			return
+		}
+		if encoder == nil {
+			startStream(ctx)
+		}
+
+		status := StreamComplete
+		if err != nil {
+			log.Printf("Stream failed after %d movies: %v", streamed, err)
+			status = StreamFailed
+		}
+		ctx.Writer.Header().Set(StreamStatusTrailer, status)
+		ctx.Writer.Header().Set(StreamCountTrailer, strconv.Itoa(streamed))
+	}
+}
+
+// Whether Accept ranks NDJSON above JSON, which answers the ties, so
+// the clients accepting anything keep getting the pages.
+func prefersNDJSON(accept string) bool {
+	ndjsonQuality, jsonQuality := 0.0, 0.0
+	for _, mediaRange := range acceptedMediaRanges(accept) {
+		switch mediaRange.mediaType {
+		case NDJSONContentType:
+			ndjsonQuality = max(ndjsonQuality, mediaRange.quality)
+		case JSONContentType, "application/*", "*/*":
+			jsonQuality = max(jsonQuality, mediaRange.quality)
+		}
+	}
+	return ndjsonQuality > jsonQuality
+}
+
+// Sends the status and the headers, announcing the trailers.
+func startStream(ctx *gin.Context) *json.Encoder {
+	header := ctx.Writer.Header()
+	header.Set("Content-Type", NDJSONContentType)
+	header.Set("Trailer", StreamStatusTrailer + ", " + StreamCountTrailer)
+	header.Set("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	ctx.Writer.WriteHeaderNow()
+	return json.NewEncoder(ctx.Writer)
+}
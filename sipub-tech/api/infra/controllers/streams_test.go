@@ -0,0 +1,118 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/usecases"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/controllers"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/errors"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/middlewares"
+)
+
+func TestMovieStreamController(t *testing.T) {
+	movies := []dtos.MovieResponseDTO{
+		{ID: 8, Title: "Sneeze, the", Year: "1894", Version: 2, UpdatedAt: 1700000000000},
+		{ID: 12, Title: "Train", Year: "1896", Version: 1},
+	}
+	list := func(service ports.MovieStreamerService, accept string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.GET(
+			"/movies/",
+			middlewares.ParseQueryParameters(),
+			middlewares.AddMovieStreamerService(service),
+			controllers.NewMovieStreamController().StreamMoviesHandler(usecases.NewStreamMoviesCase()),
+			func(ctx *gin.Context) { ctx.String(http.StatusOK, "pages") },
+		)
+		req := httptest.NewRequest("GET", "/movies/?year=1894&limit=2", nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, req)
+		return response
+	}
+
+	t.Run("should stream a movie per line when NDJSON is preferred", func(t *testing.T) {
+		for _, accept := range []string{"application/x-ndjson", "application/json;q=0.5, application/x-ndjson"} {
+			service := &FakeStreamerService{movies: movies}
+			response := list(service, accept)
+
+			require.Equal(t, http.StatusOK, response.Code, accept)
+			assert.Equal(t, controllers.NDJSONContentType, response.Header().Get("Content-Type"))
+			assert.Equal(t,
+				"{\"id\":8,\"title\":\"Sneeze, the\",\"year\":\"1894\",\"version\":2}\n" +
+					"{\"id\":12,\"title\":\"Train\",\"year\":\"1896\",\"version\":1}\n",
+				response.Body.String(),
+			)
+			assert.Equal(t, dtos.MoviesQueryDTO{Year: "1894", Limit: 2}, service.queryPassed)
+			assert.Equal(t, controllers.StreamComplete, response.Header().Get(controllers.StreamStatusTrailer))
+			assert.Equal(t, "2", response.Header().Get(controllers.StreamCountTrailer))
+		}
+	})
+
+	t.Run("should pass the request on to the pages unless NDJSON is preferred", func(t *testing.T) {
+		for _, accept := range []string{"", "*/*", "application/json", "application/x-ndjson, */*", "application/x-ndjson;q=0.5, application/json"} {
+			service := &FakeStreamerService{movies: movies}
+			response := list(service, accept)
+
+			assert.Equal(t, "pages", response.Body.String(), accept)
+			assert.Equal(t, dtos.MoviesQueryDTO{}, service.queryPassed)
+		}
+	})
+
+	t.Run("should answer a problem when the stream fails before any movie", func(t *testing.T) {
+		service := &FakeStreamerService{errorReturned: fmt.Errorf("%w: connection refused", ports.ErrServiceUnavailable)}
+		response := list(service, "application/x-ndjson")
+
+		assert.Equal(t, http.StatusServiceUnavailable, response.Code)
+		assert.Equal(t, errors.ProblemContentType, response.Header().Get("Content-Type"))
+	})
+
+	t.Run("should leave the status failed when the stream fails midway", func(t *testing.T) {
+		service := &FakeStreamerService{movies: movies, errorReturned: fmt.Errorf("%w: stream reset", ports.ErrServiceUnavailable)}
+		response := list(service, "application/x-ndjson")
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, controllers.StreamFailed, response.Header().Get(controllers.StreamStatusTrailer))
+		assert.Equal(t, "2", response.Header().Get(controllers.StreamCountTrailer))
+	})
+
+	t.Run("should stream an empty body when there is no movie", func(t *testing.T) {
+		response := list(&FakeStreamerService{}, "application/x-ndjson")
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Empty(t, response.Body.String())
+		assert.Equal(t, controllers.StreamComplete, response.Header().Get(controllers.StreamStatusTrailer))
+		assert.Equal(t, "0", response.Header().Get(controllers.StreamCountTrailer))
+	})
+}
+
+// Emits the movies, recording the query, then fails with errorReturned
+// when set.
+type FakeStreamerService struct {
+	movies        []dtos.MovieResponseDTO
+	errorReturned error
+	queryPassed   dtos.MoviesQueryDTO
+}
+
+func (service *FakeStreamerService) Stream(
+	ctx context.Context, query dtos.MoviesQueryDTO, emit func(movie dtos.MovieResponseDTO) error,
+) error {
+	service.queryPassed = query
+	for _, movie := range service.movies {
+		if err := emit(movie); err != nil {
+			return err
+		}
+	}
+	return service.errorReturned
+}
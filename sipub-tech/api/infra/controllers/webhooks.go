@@ -0,0 +1,239 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/errors"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/middlewares"
+	infraPorts "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/presenters"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/validators"
+)
+
+func NewWebhookController() *WebhookController {
+	validators.RegisterMovieValidators()
+	return &WebhookController{
+		presenter: presenters.NewV1WebhookPresenter(),
+	}
+}
+
+type WebhookController struct {
+	infraPorts.WebhookController
+
+	presenter infraPorts.WebhookPresenter
+}
+
+
+// This route is responsible for registering a url to receive the movie
+// events.
+// A CreateWebhookDTO should be passed in the JSON body.
+//
+// It returns a JSONResponse with the webhook, the only one carrying its
+// secret, and its location.
+func (controller *WebhookController) CreateWebhookHandler(usecase ports.CreateWebhookCase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		svc, ok := getService[ports.WebhookSaverService](ctx)
+		if !ok {
+			return
+		}
+
+		var dto dtos.CreateWebhookDTO
+		if !controller.bindBody(ctx, &dto) {
+			return
+		}
+
+		webhook, err := usecase.CreateWebhook(ctx, svc, dto)
+		if err != nil {
+			errorFrom(ctx, err, fmt.Sprintf("Could not create webhook for %q: %v", dto.URL, err))
+			return
+		}
+
+		ctx.Header("Location", strings.TrimSuffix(ctx.Request.URL.Path, "/") + "/" + string(webhook.ID))
+		ctx.JSON(http.StatusCreated, controller.presenter.PresentWebhook(&webhook))
+	}
+}
+
+
+// This route is responsible for listing every webhook.
+//
+// It returns a ListJSONResponse with the webhooks, without their secrets.
+func (controller *WebhookController) GetWebhooksHandler(usecase ports.GetWebhooksCase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		svc, ok := getService[ports.WebhookAllGetterService](ctx)
+		if !ok {
+			return
+		}
+
+		webhooks, err := usecase.GetWebhooks(ctx, svc)
+		if err != nil {
+			errorFrom(ctx, err, fmt.Sprintf("Failed to fetch webhooks: %v", err))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, controller.presenter.PresentWebhooks(webhooks))
+	}
+}
+
+
+// This route is responsible for getting a webhook by its id, with how
+// its deliveries are going.
+//
+// It returns a JSONResponse with the webhook, without its secret.
+func (controller *WebhookController) GetWebhookHandler(usecase ports.GetWebhookCase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		svc, ok := getService[ports.WebhookOneGetterService](ctx)
+		if !ok {
+			return
+		}
+
+		id := dtos.WebhookId(ctx.Param("id"))
+		webhook, err := usecase.GetWebhook(ctx, svc, id)
+		if err != nil {
+			errorFrom(ctx, err, fmt.Sprintf("Failed to fetch webhook with id %q: %v", id, err))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, controller.presenter.PresentWebhook(&webhook))
+	}
+}
+
+
+// This route is responsible for replacing the url and the filters of a
+// webhook, rotating its secret, and enabling or disabling it.
+// An UpdateWebhookDTO should be passed in the JSON body.
+//
+// It returns a JSONResponse with the updated webhook.
+func (controller *WebhookController) UpdateWebhookHandler(usecase ports.UpdateWebhookCase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		svc, ok := getService[ports.WebhookUpdaterService](ctx)
+		if !ok {
+			return
+		}
+
+		var dto dtos.UpdateWebhookDTO
+		if !controller.bindBody(ctx, &dto) {
+			return
+		}
+		dto.ID = dtos.WebhookId(ctx.Param("id"))
+
+		webhook, err := usecase.UpdateWebhook(ctx, svc, dto)
+		if err != nil {
+			errorFrom(ctx, err, fmt.Sprintf("Could not update webhook with id %q: %v", dto.ID, err))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, controller.presenter.PresentWebhook(&webhook))
+	}
+}
+
+
+// This route is responsible for deleting a webhook by its id, which
+// stops its deliveries.
+//
+// It returns an empty body.
+func (controller *WebhookController) DeleteWebhookHandler(usecase ports.DeleteWebhookCase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		svc, ok := getService[ports.WebhookDeleterService](ctx)
+		if !ok {
+			return
+		}
+
+		id := dtos.WebhookId(ctx.Param("id"))
+		if err := usecase.DeleteWebhook(ctx, svc, id); err != nil {
+			errorFrom(ctx, err, fmt.Sprintf("Could not delete webhook with id %q: %v", id, err))
+			return
+		}
+
+		ctx.Status(http.StatusNoContent)
+	}
+}
+
+
+// This route is responsible for getting the log of the attempts of
+// sending the events to a webhook, from the newest, possibly filtering
+// them by status, limiting, and skipping all deliveries from the cursor.
+//
+// It returns a PaginatedJSONResponse with the deliveries.
+func (controller *WebhookController) GetWebhookDeliveriesHandler(usecase ports.GetWebhookDeliveriesCase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		svc, ok := getService[ports.WebhookDeliveriesGetterService](ctx)
+		if !ok {
+			return
+		}
+
+		dto, exists := ctx.Get(middlewares.DtoKey)
+		if !exists {
+			internalServerError(ctx, "The request could not be processed.", "DTO Parser middleware did not set context.")
+			return
+		}
+		query, ok := dto.(*dtos.WebhookDeliveriesQueryDTO)
+		if !ok {
+			internalServerError(ctx, "The request could not be processed.", "DTO Parser middleware set malformed context.")
+			return
+		}
+		query.WebhookID = dtos.WebhookId(ctx.Param("id"))
+
+		deliveries, err := usecase.GetWebhookDeliveries(ctx, svc, *query)
+		if err != nil {
+			errorFrom(ctx, err, fmt.Sprintf("Failed to fetch deliveries with query %+v: %v", query, err))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, controller.presenter.PresentWebhookDeliveries(deliveries, *query))
+	}
+}
+
+
+func (controller *WebhookController) bindBody(ctx *gin.Context, dto any) bool {
+	if err := ctx.ShouldBindJSON(dto); err != nil {
+		if fields, ok := validators.FieldErrors(err); ok {
+			log.Printf("Body %+v failed validation: %v", dto, err)
+			errors.AbortWithProblem(ctx, errors.ValidationFailed(fields))
+			return false
+		}
+		log.Printf("Body could not be marshalled: %v", err)
+		errors.AbortWithProblem(ctx, errors.NewProblem(
+			errors.CodeMalformedRequest,
+			"The body must be a JSON object with a string url, and optionally a list of events, a year and a secret.",
+		))
+		return false
+	}
+	return true
+}
+
+// Gets the service set by the middleware, answering 500 when it is
+// missing or does not implement the route.
+func getService[Service any](ctx *gin.Context) (Service, bool) {
+	return getServiceFrom[Service](ctx, ports.ServiceKey)
+}
+
+func getServiceFrom[Service any](ctx *gin.Context, key string) (Service, bool) {
+	var svc Service
+	service, exists := ctx.Get(key)
+	if !exists {
+		internalServerError(ctx, "The service is not configured.", fmt.Sprintf("Service %q not set to context.", key))
+		return svc, false
+	}
+	svc, ok := service.(Service)
+	if !ok {
+		internalServerError(ctx, "The service is not configured.", fmt.Sprintf("Service %q malformed.", key))
+	}
+	return svc, ok
+}
+
+func internalServerError(ctx *gin.Context, msg, logging string) {
+	log.Println(logging)
+	errors.AbortWithProblem(ctx, errors.NewProblem(errors.CodeInternalError, msg))
+}
+
+func errorFrom(ctx *gin.Context, err error, logging string) {
+	log.Println(logging)
+	errors.AbortWithError(ctx, err)
+}
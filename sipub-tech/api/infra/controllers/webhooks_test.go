@@ -0,0 +1,147 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/usecases"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/controllers"
+	infraDtos "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/errors"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/middlewares"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/presenters"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/webhooks"
+)
+
+func TestWebhookController(t *testing.T) {
+	store := webhooks.NewMemoryStore(webhooks.DefaultDeliveryLogCapacity)
+	router := webhooksRouter(store)
+
+	var created dtos.WebhookResponseDTO
+	t.Run("should create the webhook, answering its secret and location", func(t *testing.T) {
+		response := doJSON(router, "POST", "/webhooks/", `{"url": "https://partner.example/hooks", "events": ["MovieDeleted"], "year": "1995"}`)
+
+		require.Equal(t, http.StatusCreated, response.Code)
+		var body presenters.V1WebhookBody
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &body))
+		created = body.Data
+		assert.Equal(t, "/webhooks/" + string(created.ID), response.Header().Get("Location"))
+		assert.Equal(t, "https://partner.example/hooks", created.URL)
+		assert.Equal(t, []dtos.MovieEventType{dtos.MovieDeleted}, created.Events)
+		assert.Len(t, created.Secret, 2*usecases.WebhookSecretSize)
+		assert.True(t, created.Enabled)
+	})
+
+	t.Run("should refuse invalid webhooks with the failed fields", func(t *testing.T) {
+		response := doJSON(router, "POST", "/webhooks/", `{"url": "ftp://partner.example", "events": ["MovieRenamed"], "secret": "short"}`)
+
+		require.Equal(t, http.StatusUnprocessableEntity, response.Code)
+		var body infraDtos.ProblemDetails
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &body))
+		fields := make([]string, len(body.Violations))
+		for index, violation := range body.Violations {
+			fields[index] = violation.Field
+		}
+		assert.ElementsMatch(t, []string{"url", "events[0]", "secret"}, fields)
+	})
+
+	t.Run("should list and get the webhooks without their secrets", func(t *testing.T) {
+		response := doJSON(router, "GET", "/webhooks/", "")
+		require.Equal(t, http.StatusOK, response.Code)
+		assert.NotContains(t, response.Body.String(), created.Secret)
+
+		var list presenters.V1WebhooksList
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &list))
+		assert.Len(t, list.Data, 1)
+
+		response = doJSON(router, "GET", "/webhooks/" + string(created.ID), "")
+		require.Equal(t, http.StatusOK, response.Code)
+		assert.NotContains(t, response.Body.String(), created.Secret)
+	})
+
+	t.Run("should replace the webhook", func(t *testing.T) {
+		response := doJSON(router, "PUT", "/webhooks/" + string(created.ID), `{"url": "https://partner.example/v2/hooks", "enabled": false}`)
+
+		require.Equal(t, http.StatusOK, response.Code)
+		var body presenters.V1WebhookBody
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &body))
+		assert.Equal(t, "https://partner.example/v2/hooks", body.Data.URL)
+		assert.Equal(t, []dtos.MovieEventType{}, body.Data.Events)
+		assert.False(t, body.Data.Enabled)
+	})
+
+	t.Run("should answer the deliveries of the webhook from the newest", func(t *testing.T) {
+		for _, status := range []dtos.WebhookDeliveryStatus{dtos.WebhookDeliveryFailed, dtos.WebhookDeliverySucceeded} {
+			store.RecordDelivery(context.Background(), dtos.WebhookDeliveryDTO{WebhookID: created.ID, EventID: string(status), Status: status})
+		}
+
+		response := doJSON(router, "GET", "/webhooks/" + string(created.ID) + "/deliveries?status=failed", "")
+
+		require.Equal(t, http.StatusOK, response.Code)
+		var page presenters.V1WebhookDeliveriesPage
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &page))
+		if assert.Len(t, page.Data, 1) {
+			assert.Equal(t, "failed", page.Data[0].EventID)
+		}
+	})
+
+	t.Run("should delete the webhook, answering not found afterwards", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, doJSON(router, "DELETE", "/webhooks/" + string(created.ID), "").Code)
+
+		for _, method := range []string{"GET", "DELETE"} {
+			response := doJSON(router, method, "/webhooks/" + string(created.ID), "")
+			require.Equal(t, http.StatusNotFound, response.Code)
+			var body infraDtos.ProblemDetails
+			require.NoError(t, json.Unmarshal(response.Body.Bytes(), &body))
+			assert.Equal(t, string(errors.CodeWebhookNotFound), body.Code)
+		}
+		response := doJSON(router, "GET", "/webhooks/" + string(created.ID) + "/deliveries", "")
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("should answer 500 without the webhook service", func(t *testing.T) {
+		handler := controllers.NewWebhookController().GetWebhooksHandler(usecases.NewGetWebhooksCase())
+		req, _ := http.NewRequest("GET", "/webhooks/", nil)
+		ctx, writer := getContext(req)
+		ctx.Set(ports.ServiceKey, &FakeQueryService{})
+
+		handler(ctx)
+
+		assert.Equal(t, http.StatusInternalServerError, writer.Status())
+	})
+}
+
+func webhooksRouter(service ports.WebhookService) *gin.Engine {
+	controller := controllers.NewWebhookController()
+	router := gin.New()
+	group := router.Group("/webhooks", middlewares.AddWebhookService(service))
+	group.POST("/", controller.CreateWebhookHandler(usecases.NewCreateWebhookCase()))
+	group.GET("/", controller.GetWebhooksHandler(usecases.NewGetWebhooksCase()))
+	group.GET("/:id", controller.GetWebhookHandler(usecases.NewGetWebhookCase()))
+	group.PUT("/:id", controller.UpdateWebhookHandler(usecases.NewUpdateWebhookCase()))
+	group.DELETE("/:id", controller.DeleteWebhookHandler(usecases.NewDeleteWebhookCase()))
+	group.GET(
+		"/:id/deliveries",
+		middlewares.ParseWebhookDeliveriesQueryParameters(),
+		controller.GetWebhookDeliveriesHandler(usecases.NewGetWebhookDeliveriesCase()),
+	)
+	return router
+}
+
+func doJSON(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, req)
+	return response
+}
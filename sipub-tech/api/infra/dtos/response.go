@@ -4,33 +4,24 @@ import (
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
 )
 
-type ErrorMessage error
-
 type JsonData interface {
 	ToDataItem() dtos.DataItem
 }
 
 
-// Error Response
-func NewErrorResponse(msg ErrorMessage) *ErrorResponse {
-	return &ErrorResponse{
-		Details: ErrorDetails{
-			Message: msg.Error(),
-		},
-	}
-}
-
-type ErrorResponse struct {
-	Details ErrorDetails  `json:"details"`
+// Problem Details Response (RFC 7807)
+type ProblemDetails struct {
+	Type       string        `json:"type"`
+	Title      string        `json:"title"`
+	Status     int           `json:"status"`
+	Detail     string        `json:"detail,omitempty"`
+	Instance   string        `json:"instance,omitempty"`
+	Code       string        `json:"code"`
+	Violations []FieldError  `json:"violations,omitempty"`
 }
 
-func (err *ErrorResponse) Copy() *ErrorResponse {
-	return &ErrorResponse{
-		Details: err.Details,
-	}
-} 
-
-type ErrorDetails struct {
+type FieldError struct {
+	Field   string  `json:"field"`
 	Message string  `json:"message"`
 }
 
@@ -44,6 +35,16 @@ type JSONResponse struct {
 }
 
 
+// List Response, for the collections small enough to be answered at once
+func NewListResponse(data []JsonData) *ListJSONResponse {
+	return &ListJSONResponse{Data: structArrayToDataItemArray(data)}
+}
+
+type ListJSONResponse struct {
+	Data []dtos.DataItem  `json:"data"`
+}
+
+
 // Paginated Response
 func NewPaginatedResponse(data []JsonData, limit, cursor int) *PaginatedJSONResponse {
 	dataItems := structArrayToDataItemArray(data)
@@ -60,6 +61,20 @@ type PaginatedJSONResponse struct {
 	Cursor int         `json:"cursor"`
 }
 
+// Batch Response, for the items fetched by their ids, with the ids of
+// the items that do not exist
+func NewBatchResponse[Id any](data []JsonData, missingIds []Id) *BatchJSONResponse[Id] {
+	return &BatchJSONResponse[Id]{
+		Data:       structArrayToDataItemArray(data),
+		MissingIDs: missingIds,
+	}
+}
+
+type BatchJSONResponse[Id any] struct {
+	Data       []dtos.DataItem  `json:"data"`
+	MissingIDs []Id             `json:"missing_ids"`
+}
+
 func structArrayToDataItemArray(data []JsonData) []dtos.DataItem {
 	response := make([]dtos.DataItem, len(data)) 
 	for index, item := range data {
@@ -0,0 +1,335 @@
+package entrypoints
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/conditionals"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/controllers"
+	infraDtos "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/middlewares"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/openapi"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/presenters"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/validators"
+)
+
+const (
+	OpenAPIPath   = "/openapi.json"
+	SwaggerUIPath = "/docs"
+
+	yearPattern = "^[0-9]{4}$"
+)
+
+var (
+	APIInfo = openapi.Info{
+		Title:       "Sipub Tech Movies API",
+		Version:     CurrentVersion,
+		Description: "Queries movies through gRPC and creates, updates or deletes them in the background through RabbitMQ.",
+	}
+)
+
+// Documents the movie routes answered with the V1MoviePresenter.
+// Every route registered by addMovieRoutes must be documented here, or
+// Setup fails.
+func V1MovieRoutes() []openapi.Route {
+	tags := []string{"movies"}
+	idParameter := &openapi.Parameter{
+		Name:        "id",
+		In:          "path",
+		Description: "The id of the movie.",
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "integer", Examples: []any{14}},
+	}
+	ifNoneMatchParameter := &openapi.Parameter{
+		Name:        conditionals.IfNoneMatchHeader,
+		In:          "header",
+		Description: "ETags already held by the client. Answered with 304 when one of them matches.",
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	ifMatchParameter := &openapi.Parameter{
+		Name:        conditionals.IfMatchHeader,
+		In:          "header",
+		Description: "The ETag of the movie being changed. Answered with 412 when the movie is no longer on it.",
+		Schema:      &openapi.Schema{Type: "string", Examples: []any{`"14-3"`}},
+	}
+	cacheHeaders := func(lastModified bool) map[string]*openapi.Header {
+		headers := map[string]*openapi.Header{
+			conditionals.ETagHeader: {
+				Description: "The version of the representation, to be sent back in If-None-Match or If-Match.",
+				Schema:      &openapi.Schema{Type: "string"},
+			},
+			conditionals.CacheControlHeader: {
+				Description: "How long shared caches may keep the representation.",
+				Schema:      &openapi.Schema{Type: "string"},
+			},
+		}
+		if lastModified {
+			headers[conditionals.LastModifiedHeader] = &openapi.Header{
+				Description: "When the movie was last changed, as an HTTP date.",
+				Schema:      &openapi.Schema{Type: "string"},
+			}
+		}
+		return headers
+	}
+
+	return []openapi.Route{
+		{
+			Method:      http.MethodGet,
+			Path:        "/movies/",
+			OperationID: "get_movies",
+			Summary:     "Get multiple movies from the repository.",
+			Description: "Movies are ordered by id. Pass the returned cursor to fetch the next page. " +
+				"When Accept prefers application/x-ndjson over application/json, every movie after the cursor, up to the limit when given, " +
+				"is streamed instead, one per line, the " + controllers.StreamStatusTrailer + " trailer failed when the body is truncated.",
+			Tags:        tags,
+			Parameters: []*openapi.Parameter{
+				{
+					Name:        middlewares.QueryYearKey,
+					In:          "query",
+					Description: "The year of the movies to query from.",
+					Schema: &openapi.Schema{
+						Type:     "string",
+						Pattern:  yearPattern,
+						Examples: []any{"1995"},
+					},
+				},
+				{
+					Name:        middlewares.QueryLimitKey,
+					In:          "query",
+					Description: "The maximum number of movies returned.",
+					Schema:      &openapi.Schema{Type: "integer", Minimum: intPointer(1), Examples: []any{500}},
+				},
+				{
+					Name:        middlewares.QueryCursorKey,
+					In:          "query",
+					Description: "The id of the last movie fetched, so it will be skipped.",
+					Schema:      &openapi.Schema{Type: "integer", Minimum: intPointer(1), Examples: []any{50}},
+				},
+				ifNoneMatchParameter,
+			},
+			Responses: append(
+				[]openapi.RouteResponse{
+					{Status: http.StatusOK, Description: "A page of movies.", Body: presenters.V1MoviesPage{}, Headers: cacheHeaders(false)},
+					{Status: http.StatusNotModified, Description: "The page did not change.", Headers: cacheHeaders(false)},
+				},
+				problems(http.StatusUnprocessableEntity, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusGatewayTimeout)...,
+			),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/movies/:id",
+			OperationID: "get_movie",
+			Summary:     "Get a movie from the repository by its id.",
+			Tags:        tags,
+			Parameters: []*openapi.Parameter{
+				idParameter,
+				ifNoneMatchParameter,
+				{
+					Name:        conditionals.IfModifiedSinceHeader,
+					In:          "header",
+					Description: "Answered with 304 when the movie did not change since this HTTP date. Ignored if If-None-Match is sent.",
+					Schema:      &openapi.Schema{Type: "string"},
+				},
+			},
+			Responses: append(
+				[]openapi.RouteResponse{
+					{Status: http.StatusOK, Description: "The movie.", Body: presenters.V1MovieBody{}, Headers: cacheHeaders(true)},
+					{Status: http.StatusNotModified, Description: "The movie did not change.", Headers: cacheHeaders(true)},
+				},
+				problems(http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusGatewayTimeout)...,
+			),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/movies/batch-get",
+			OperationID: "batch_get_movies",
+			Summary:     "Get up to 100 movies by their ids at once.",
+			Description: "The movies found come in the order of their ids, and the ids of the movies that do not exist " +
+				"are answered in missing_ids. Repeated ids are answered once.",
+			Tags:        tags,
+			RequestBody: dtos.BatchGetMoviesDTO{},
+			Responses: append(
+				[]openapi.RouteResponse{{Status: http.StatusOK, Description: "The movies found and the ids missing.", Body: presenters.V1MoviesBatch{}}},
+				problems(http.StatusUnprocessableEntity, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusGatewayTimeout)...,
+			),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/movies/events",
+			OperationID: "stream_movie_events",
+			Summary:     "Stream the created, updated and deleted movies.",
+			Description: "Answered with Server-Sent Events, whose ids are the event ids and whose event names are the event types, " +
+				"or with a WebSocket of the same events as JSON messages when an upgrade is requested. " +
+				"Events missed since Last-Event-ID are sent first, or a MovieEventsReset event when they are no longer buffered, " +
+				"in which case the movies must be fetched again.",
+			Tags:        tags,
+			Parameters: []*openapi.Parameter{
+				{
+					Name:        middlewares.QueryYearKey,
+					In:          "query",
+					Description: "Only stream the events of the movies of this year.",
+					Schema: &openapi.Schema{
+						Type:     "string",
+						Pattern:  yearPattern,
+						Examples: []any{"1995"},
+					},
+				},
+				{
+					Name:        controllers.LastEventIDHeader,
+					In:          "header",
+					Description: "The id of the last event received, to resume the stream from it.",
+					Schema:      &openapi.Schema{Type: "string"},
+				},
+				{
+					Name:        controllers.QueryLastEventIDKey,
+					In:          "query",
+					Description: "Same as Last-Event-ID, for clients that can't set headers. The header takes precedence.",
+					Schema:      &openapi.Schema{Type: "string"},
+				},
+			},
+			Responses: append(
+				[]openapi.RouteResponse{
+					{
+						Status:      http.StatusOK,
+						Description: "The stream of movie events.",
+						Body:        dtos.MovieEventDTO{},
+						ContentType: controllers.EventStreamContentType,
+					},
+					{Status: http.StatusSwitchingProtocols, Description: "The stream of movie events, over a WebSocket."},
+				},
+				problems(http.StatusUnprocessableEntity, http.StatusInternalServerError)...,
+			),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/movies/",
+			OperationID: "create_movie",
+			Summary:     "Create a movie in the repository. This operation runs in the background.",
+			Tags:        tags,
+			RequestBody: dtos.CreateMovieDTO{},
+			Responses: append(
+				[]openapi.RouteResponse{{Status: http.StatusCreated, Description: "The movie creation was queued.", Body: struct{}{}}},
+				problems(http.StatusUnprocessableEntity, http.StatusInternalServerError, http.StatusServiceUnavailable)...,
+			),
+		},
+		{
+			Method:      http.MethodPut,
+			Path:        "/movies/:id",
+			OperationID: "update_movie",
+			Summary:     "Replace the title and the year of a movie. This operation runs in the background.",
+			Description: "With If-Match, the movie is only updated if it is still on the matched version.",
+			Tags:        tags,
+			Parameters:  []*openapi.Parameter{idParameter, ifMatchParameter},
+			RequestBody: dtos.CreateMovieDTO{},
+			Responses: append(
+				[]openapi.RouteResponse{{Status: http.StatusAccepted, Description: "The movie update was queued."}},
+				problems(http.StatusPreconditionFailed, http.StatusUnprocessableEntity, http.StatusInternalServerError, http.StatusServiceUnavailable)...,
+			),
+		},
+		{
+			Method:      http.MethodDelete,
+			Path:        "/movies/:id",
+			OperationID: "delete_movie",
+			Summary:     "Delete a movie by its id. This operation runs in the background.",
+			Description: "With If-Match, the movie is only deleted if it is still on the matched version.",
+			Tags:        tags,
+			Parameters:  []*openapi.Parameter{idParameter, ifMatchParameter},
+			Responses: append(
+				[]openapi.RouteResponse{{Status: http.StatusNoContent, Description: "The movie deletion was queued."}},
+				problems(http.StatusPreconditionFailed, http.StatusUnprocessableEntity, http.StatusInternalServerError, http.StatusServiceUnavailable)...,
+			),
+		},
+	}
+}
+
+func (entrypoint *GinEntrypoint) addDocumentationHandlers() {
+	var mounts, legacyMounts []openapi.Mount
+	for _, version := range entrypoint.versions {
+		routes := version.MovieRoutes
+		if entrypoint.operationController != nil {
+			routes = append(routes[:len(routes):len(routes)], BulkMovieRoutes()...)
+		}
+		if entrypoint.exportController != nil {
+			routes = append(routes[:len(routes):len(routes)], ExportRoutes()...)
+		}
+		mounts = append(mounts, openapi.Mount{
+			Prefix:     "/" + version.Name,
+			Name:       version.Name,
+			Deprecated: version.Deprecation != nil,
+			Routes:     routes,
+		})
+		if version.Name == entrypoint.legacyVersion {
+			legacyMounts = append(legacyMounts, openapi.Mount{
+				Prefix:     "",
+				Name:       "legacy",
+				Deprecated: true,
+				Routes:     routes,
+			})
+		}
+	}
+	if entrypoint.webhookController != nil {
+		mounts = append(mounts, openapi.Mount{
+			Prefix: "/" + CurrentVersion,
+			Name:   CurrentVersion,
+			Routes: WebhookRoutes(),
+		})
+	}
+	if entrypoint.operationController != nil {
+		mounts = append(mounts, openapi.Mount{
+			Prefix: "/" + CurrentVersion,
+			Name:   CurrentVersion,
+			Routes: OperationRoutes(),
+		})
+	}
+	// The legacy mount has no prefix, so it is matched last.
+	mounts = append(mounts, legacyMounts...)
+
+	generator := openapi.NewGenerator(APIInfo, mounts)
+	generator.AddBindingRule(validators.MovieYearTag, func(schema *openapi.Schema, _ string) {
+		schema.Pattern = yearPattern
+		schema.Description = "A 4-digit year between " + strconv.Itoa(dtos.MinimumMovieYear) + " and the current year."
+	})
+	generator.AddBindingRule(validators.MovieEventTypeTag, func(schema *openapi.Schema, _ string) {
+		for _, eventType := range validators.MovieEventTypes {
+			schema.Enum = append(schema.Enum, eventType)
+		}
+	})
+	generator.AddBindingRule("http_url", func(schema *openapi.Schema, _ string) {
+		schema.Format = "uri"
+	})
+	if entrypoint.exportController != nil || entrypoint.webhookController != nil {
+		generator.AddSecurityScheme(BearerTokenSecurity, openapi.SecurityScheme{
+			Type:        "http",
+			Scheme:      "bearer",
+			Description: "One of the tokens configured in the gateway.",
+		})
+	}
+	generator.Ignore(http.MethodGet, OpenAPIPath)
+	generator.Ignore(http.MethodGet, SwaggerUIPath)
+	generator.Ignore(http.MethodGet, MetricsPath)
+
+	document, err := generator.Generate(entrypoint.engine.Routes())
+	if err != nil {
+		panic(err)
+	}
+
+	entrypoint.engine.GET(OpenAPIPath, openapi.DocumentHandler(document))
+	entrypoint.engine.GET(SwaggerUIPath, openapi.SwaggerUIHandler(APIInfo.Title, OpenAPIPath))
+}
+
+func problems(statuses ...int) []openapi.RouteResponse {
+	responses := make([]openapi.RouteResponse, len(statuses))
+	for index, status := range statuses {
+		responses[index] = openapi.RouteResponse{
+			Status:  status,
+			Body:    infraDtos.ProblemDetails{},
+			Problem: true,
+		}
+	}
+	return responses
+}
+
+func intPointer(value int) *int {
+	return &value
+}
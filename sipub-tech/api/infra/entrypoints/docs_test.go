@@ -0,0 +1,130 @@
+package entrypoints_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/controllers"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/entrypoints"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/openapi"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/operations"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/webhooks"
+)
+
+func TestGinEntrypointDocumentation(t *testing.T) {
+	entrypoint := entrypoints.NewGinEntrypoint(
+		&FakeExecutorService{},
+		&FakeQueryService{},
+		&FakeEventSubscriber{},
+		&MockMovieController{},
+	)
+	entrypoint.RegisterWebhooks(webhooks.NewMemoryStore(10), []string{"token"}, controllers.NewWebhookController())
+	entrypoint.RegisterBulkOperations(
+		&FakeBulkExecutorService{}, operations.NewMemoryStore(10), controllers.NewOperationController(),
+	)
+	entrypoint.RegisterExport(&FakeExporterService{}, []string{"token"}, controllers.NewExportController())
+	entrypoint.RegisterMetrics([]string{"token"})
+	entrypoint.Setup()
+	engine := entrypoint.GetEngine()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", entrypoints.OpenAPIPath, nil)
+	engine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var document openapi.Document
+	if err := json.Unmarshal(w.Body.Bytes(), &document); err != nil {
+		t.Fatalf("Failed to unmarshal document: %v", err)
+	}
+	assert.Equal(t, openapi.Version, document.OpenAPI)
+
+	t.Run("should document every route registered in the engine", func(t *testing.T) {
+		registered := map[string]bool{}
+		for _, route := range engine.Routes() {
+			switch route.Path {
+			case entrypoints.OpenAPIPath, entrypoints.SwaggerUIPath, entrypoints.MetricsPath:
+				continue
+			}
+			path := strings.ReplaceAll(route.Path, ":id", "{id}")
+			method := strings.ToLower(route.Method)
+			registered[method+" "+path] = true
+
+			operation, ok := document.Paths[path][method]
+			if assert.Truef(t, ok, "route %s %s is not documented", route.Method, route.Path) {
+				assert.NotEmpty(t, operation.Responses)
+			}
+		}
+
+		for path, item := range document.Paths {
+			for method := range item {
+				assert.Truef(t, registered[method+" "+path], "documented route %s %s is not registered", method, path)
+			}
+		}
+	})
+
+	t.Run("should document problem responses and deprecate the unversioned routes", func(t *testing.T) {
+		current := document.Paths["/v1/movies/{id}"]["get"]
+		assert.False(t, current.Deprecated)
+		assert.Contains(t, current.Responses["404"].Content, openapi.ProblemContentType)
+		assert.Contains(t, current.Responses["200"].Content, openapi.JSONContentType)
+
+		legacy := document.Paths["/movies/{id}"]["get"]
+		assert.True(t, legacy.Deprecated)
+		assert.Contains(t, legacy.Responses["200"].Headers, "Sunset")
+	})
+
+	t.Run("should document the webhooks only under the current version", func(t *testing.T) {
+		assert.Contains(t, document.Paths, "/v1/webhooks/{id}/deliveries")
+		assert.NotContains(t, document.Paths, "/webhooks/")
+		assert.Equal(t, "v1_create_webhook", document.Paths["/v1/webhooks/"]["post"].OperationID)
+
+		events := document.Components.Schemas["CreateWebhookDTO"].Properties["events"]
+		assert.Equal(t, []any{"MovieCreated", "MovieUpdated", "MovieDeleted"}, events.Items.Enum)
+		assert.Equal(t, "uri", document.Components.Schemas["CreateWebhookDTO"].Properties["url"].Format)
+		assert.Equal(t, []openapi.SecurityRequirement{{entrypoints.BearerTokenSecurity: {}}}, document.Paths["/v1/webhooks/"]["post"].Security)
+		assert.Contains(t, document.Paths["/v1/webhooks/{id}/deliveries"]["get"].Responses, "401")
+	})
+
+	t.Run("should document the bulk routes with every version and the operations under the current one", func(t *testing.T) {
+		assert.Contains(t, document.Paths["/v1/movies/bulk"], "post")
+		assert.Contains(t, document.Paths["/v1/movies/bulk"], "delete")
+		assert.True(t, document.Paths["/movies/bulk"]["post"].Deprecated)
+		assert.Contains(t, document.Paths["/v1/operations/{id}"]["get"].Responses, "404")
+		assert.NotContains(t, document.Paths, "/operations/{id}")
+	})
+
+	t.Run("should document the export as authenticated with a bearer token", func(t *testing.T) {
+		export := document.Paths["/v1/movies/export"]["get"]
+		assert.Equal(t, []openapi.SecurityRequirement{{entrypoints.BearerTokenSecurity: {}}}, export.Security)
+		assert.Contains(t, export.Responses, "401")
+		assert.Contains(t, export.Responses, "406")
+		assert.Equal(t, "bearer", document.Components.SecuritySchemes[entrypoints.BearerTokenSecurity].Scheme)
+		assert.Nil(t, document.Paths["/v1/movies/{id}"]["get"].Security)
+	})
+
+	t.Run("should document the year as a string", func(t *testing.T) {
+		for _, parameter := range document.Paths["/v1/movies/"]["get"].Parameters {
+			if parameter.Name == "year" {
+				assert.Equal(t, "string", parameter.Schema.Type)
+			}
+		}
+		year := document.Components.Schemas["CreateMovieDTO"].Properties["year"]
+		assert.Equal(t, "string", year.Type)
+		assert.NotEmpty(t, year.Pattern)
+	})
+
+	t.Run("should serve the swagger ui pointing to the document", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", entrypoints.SwaggerUIPath, nil)
+		engine.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), entrypoints.OpenAPIPath)
+	})
+}
@@ -0,0 +1,97 @@
+package entrypoints
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/usecases"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/controllers"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/middlewares"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/openapi"
+	infraPorts "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/ports"
+)
+
+const (
+	// The security scheme of the routes authenticated with a bearer token.
+	BearerTokenSecurity = "bearerToken"
+)
+
+// Serves the movie export along with the movie routes of every version,
+// only to the requests with one of the tokens.
+// It must be called before Setup.
+func (entrypoint *GinEntrypoint) RegisterExport(
+	service ports.MovieExporterService, tokens []string, controller infraPorts.ExportController,
+) {
+	entrypoint.exportMovieService = service
+	entrypoint.exportTokens = tokens
+	entrypoint.exportController = controller
+}
+
+func (entrypoint *GinEntrypoint) addExportRoutes(router *gin.RouterGroup) {
+	if entrypoint.exportController == nil {
+		return
+	}
+
+	router.GET(
+		"/movies/export",
+		middlewares.RequireBearerToken(entrypoint.exportTokens),
+		middlewares.AddMovieExporterService(entrypoint.exportMovieService),
+		entrypoint.exportController.ExportMoviesHandler(usecases.NewExportMoviesCase()),
+	)
+}
+
+// Documents the export route, relative to the version it is mounted on.
+func ExportRoutes() []openapi.Route {
+	formats := make([]any, 0, len(controllers.ExportFormats))
+	for format := range controllers.ExportFormats {
+		formats = append(formats, format)
+	}
+	sort.Slice(formats, func(i, j int) bool { return formats[i].(string) < formats[j].(string) })
+
+	trailers := map[string]*openapi.Header{
+		"Trailer": {Description: "Announces the X-Export-Status and X-Export-Count trailers.", Schema: &openapi.Schema{Type: "string"}},
+		controllers.ExportStatusTrailer: {
+			Description: "A trailer, complete when every movie was exported, or failed when the body is truncated.",
+			Schema:      &openapi.Schema{Type: "string", Enum: []any{controllers.ExportComplete, controllers.ExportFailed}},
+		},
+		controllers.ExportCountTrailer: {Description: "A trailer, with how many movies were exported.", Schema: &openapi.Schema{Type: "integer"}},
+	}
+
+	return []openapi.Route{
+		{
+			Method:      http.MethodGet,
+			Path:        "/movies/export",
+			OperationID: "export_movies",
+			Summary:     "Export every movie, in no order, in a file the importer of the movies service reads back.",
+			Description: "Streamed as a JSON array, NDJSON or CSV with a header, as negotiated from Accept, JSON when anything is accepted. " +
+				"The status is sent with the first movie, so a failure afterwards truncates the body, " +
+				"leaving the JSON array unclosed and the X-Export-Status trailer failed.",
+			Tags:        []string{"movies"},
+			Security:    []string{BearerTokenSecurity},
+			Parameters: []*openapi.Parameter{{
+				Name:        controllers.QueryExportFormatKey,
+				In:          "query",
+				Description: "The format of the export, taking precedence over Accept.",
+				Schema:      &openapi.Schema{Type: "string", Enum: formats},
+			}},
+			Responses: append(
+				[]openapi.RouteResponse{{
+					Status:      http.StatusOK,
+					Description: "The movies, as a JSON array, or as NDJSON or CSV with the same fields.",
+					Body:        []controllers.ExportedMovie{},
+					Headers:     trailers,
+				}},
+				problems(
+					http.StatusUnauthorized,
+					http.StatusNotAcceptable,
+					http.StatusInternalServerError,
+					http.StatusServiceUnavailable,
+					http.StatusGatewayTimeout,
+				)...,
+			),
+		},
+	}
+}
@@ -1,45 +1,135 @@
 package entrypoints
 
 import (
+	"expvar"
+	"fmt"
+
 	"github.com/gin-gonic/gin"
 
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/middlewares"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/openapi"
 	infraPorts "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/ports"
 )
 
+const (
+	CurrentVersion = "v1"
+
+	// Answers the variables published with expvar, such as the cache
+	// counters, when RegisterMetrics is called.
+	MetricsPath = "/debug/vars"
+)
+
+// A version of the API, mounted under /<Name>.
+// Versions with a Deprecation announce it in the response headers.
+// MovieRoutes documents the movie routes in the OpenAPI document, and
+// defaults to V1MovieRoutes.
+type APIVersion struct {
+	Name            string
+	MovieController infraPorts.MovieController
+	Deprecation     *middlewares.Deprecation
+	MovieRoutes     []openapi.Route
+}
 
 func NewGinEntrypoint(
 	executorMovieService   ports.MovieExecutorService,
 	queryMovieService      ports.MovieQueryService,
+	movieEventSubscriber   ports.MovieEventSubscriberService,
 	movieController   infraPorts.MovieController,
 ) *GinEntrypoint {
 	return &GinEntrypoint{
 		executorMovieService: executorMovieService,
 		queryMovieService: queryMovieService,
+		movieEventSubscriber: movieEventSubscriber,
 
-		movieController: movieController,
+		versions: []APIVersion{
+			{Name: CurrentVersion, MovieController: movieController, MovieRoutes: V1MovieRoutes()},
+		},
+		legacyVersion: CurrentVersion,
+		legacyDeprecation: LegacyRoutesDeprecation,
 	}
 }
 
 type GinEntrypoint struct {
 	executorMovieService       ports.MovieExecutorService
 	queryMovieService          ports.MovieQueryService
+	movieEventSubscriber       ports.MovieEventSubscriberService
+	webhookService             ports.WebhookService
+	webhookTokens              []string
+	webhookController          infraPorts.WebhookController
+	bulkMovieService           ports.MovieBulkExecutorService
+	operationService           ports.OperationService
+	operationController        infraPorts.OperationController
+	exportMovieService         ports.MovieExporterService
+	exportTokens               []string
+	exportController           infraPorts.ExportController
+	movieStreamerService       ports.MovieStreamerService
+	movieStreamController      infraPorts.MovieStreamController
+	metricsTokens              []string
 
-	movieController     infraPorts.MovieController
+	versions                   []APIVersion
+	legacyVersion              string
+	legacyDeprecation          middlewares.Deprecation
 
 	engine                     *gin.Engine
 }
 
+// Adds a version of the API, replacing the one with the same name.
+// It must be called before Setup.
+func (entrypoint *GinEntrypoint) RegisterVersion(version APIVersion) {
+	if version.MovieRoutes == nil {
+		version.MovieRoutes = V1MovieRoutes()
+	}
+	for index, registered := range entrypoint.versions {
+		if registered.Name == version.Name {
+			entrypoint.versions[index] = version
+			return
+		}
+	}
+	entrypoint.versions = append(entrypoint.versions, version)
+}
+
+// Chooses which version answers the deprecated unversioned routes, and
+// how their deprecation is announced.
+// It must be called before Setup.
+func (entrypoint *GinEntrypoint) SetLegacyVersion(name string, deprecation middlewares.Deprecation) {
+	entrypoint.legacyVersion = name
+	entrypoint.legacyDeprecation = deprecation
+}
+
+// Serves the variables published with expvar, only to the requests with
+// one of the tokens, as they describe the process, its command line
+// included.
+// It must be called before Setup.
+func (entrypoint *GinEntrypoint) RegisterMetrics(tokens []string) {
+	entrypoint.metricsTokens = tokens
+}
+
 func (entrypoint *GinEntrypoint) Setup() {
 	router := gin.Default()
 
 	entrypoint.engine = router
 
 	entrypoint.addMovieHandlers()
+	entrypoint.addWebhookHandlers()
+	entrypoint.addOperationHandlers()
+	entrypoint.addMetricsHandler()
+	entrypoint.addDocumentationHandlers()
+}
+
+func (entrypoint *GinEntrypoint) addMetricsHandler() {
+	if len(entrypoint.metricsTokens) == 0 {
+		return
+	}
+	entrypoint.engine.GET(
+		MetricsPath,
+		middlewares.RequireBearerToken(entrypoint.metricsTokens),
+		gin.WrapH(expvar.Handler()),
+	)
 }
 
-func (entrypoint *GinEntrypoint) Serve() {
-	if err := entrypoint.engine.Run(); err != nil { // listen and serve on 0.0.0.0:8080
+func (entrypoint *GinEntrypoint) Serve(listeningPort int) {
+	if err := entrypoint.engine.Run(fmt.Sprintf(":%d", listeningPort)); err != nil {
 		panic("gin engine failed to run")
 	}
 }
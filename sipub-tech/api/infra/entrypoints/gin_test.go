@@ -15,7 +15,10 @@ import (
 
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/controllers"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/entrypoints"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/middlewares"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/webhooks"
 )
 
 func TestGinEntrypoint(t *testing.T) {
@@ -24,14 +27,30 @@ func TestGinEntrypoint(t *testing.T) {
 
 	movieController := &MockMovieController{}
 
+	eventSubscriber := &FakeEventSubscriber{}
+
 	entrypoint := entrypoints.NewGinEntrypoint(
 		executorService,
 		queryService,
+		eventSubscriber,
 		movieController,
 	)
 	entrypoint.Setup()
 	engine := entrypoint.GetEngine()
 	
+	t.Run("should call StreamMovieEventsHandler with the parsed year when hit a GET to /movies/events", func(t *testing.T) {
+		for _, path := range []string{"/movies/events?year=1995", "/v1/movies/events?year=1995"} {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", path, nil)
+
+			engine.ServeHTTP(w, req)
+
+			assert.Equal(t, eventSubscriber, movieController.StreamMovieEventsService)
+			assert.Equal(t, "1995", movieController.StreamMovieEventsDTO.Year)
+			assert.Nil(t, movieController.GetMovieService, "the events route must not be taken for a movie id")
+		}
+	})
+
 	t.Run("should call GetMoviesHandler when hit a GET to /movies/", func(t *testing.T) {
 		w := httptest.NewRecorder()
 		req, _ := http.NewRequest("GET", "/movies/", nil)
@@ -72,6 +91,15 @@ func TestGinEntrypoint(t *testing.T) {
 		assert.Equal(t, queryService, movieController.GetMovieService)
 	})
 
+	t.Run("should call BatchGetMoviesHandler with the query service when hit a POST to /movies/batch-get", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/v1/movies/batch-get", nil)
+
+		engine.ServeHTTP(w, req)
+
+		assert.Equal(t, queryService, movieController.BatchGetMoviesService)
+	})
+
 	t.Run("should call SaveMovieHandler when hit a POST to /movies", func(t *testing.T) {
 		w := httptest.NewRecorder()
 		req, _ := http.NewRequest("POST", "/movies/", nil)
@@ -91,6 +119,216 @@ func TestGinEntrypoint(t *testing.T) {
 	})
 }
 
+func TestGinEntrypointVersions(t *testing.T) {
+	executorService := &FakeExecutorService{}
+	queryService := &FakeQueryService{}
+
+	v1Controller := &MockMovieController{}
+	v2Controller := &MockMovieController{}
+
+	v1Deprecation := middlewares.Deprecation{
+		Since:           time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Sunset:          time.Date(2031, time.January, 1, 0, 0, 0, 0, time.UTC),
+		SuccessorPrefix: "/v2",
+	}
+
+	entrypoint := entrypoints.NewGinEntrypoint(executorService, queryService, &FakeEventSubscriber{}, v1Controller)
+	entrypoint.Setup()
+	engine := entrypoint.GetEngine()
+
+	t.Run("should serve the movie routes under /v1 without deprecation headers", func(t *testing.T) {
+		for _, method := range []string{"GET", "DELETE"} {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(method, "/v1/movies/75", nil)
+
+			engine.ServeHTTP(w, req)
+
+			assert.Equal(t, 204, w.Code)
+			assert.Empty(t, w.Header().Get("Deprecation"))
+			assert.Empty(t, w.Header().Get("Sunset"))
+		}
+		assert.Equal(t, queryService, v1Controller.GetMovieService)
+		assert.Equal(t, executorService, v1Controller.DeleteMovieService)
+	})
+
+	t.Run("should mark the unversioned routes as deprecated pointing to /v1", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/movies/75", nil)
+
+		engine.ServeHTTP(w, req)
+
+		deprecation := entrypoints.LegacyRoutesDeprecation
+		assert.Equal(t, fmt.Sprintf("@%d", deprecation.Since.Unix()), w.Header().Get("Deprecation"))
+		assert.Equal(t, deprecation.Sunset.Format(http.TimeFormat), w.Header().Get("Sunset"))
+		assert.Equal(t, `</v1/movies/75>; rel="successor-version"`, w.Header().Get("Link"))
+	})
+
+	t.Run("should serve a new version with its own controller and deprecate the old one", func(t *testing.T) {
+		entrypoint := entrypoints.NewGinEntrypoint(executorService, queryService, &FakeEventSubscriber{}, v1Controller)
+		entrypoint.RegisterVersion(entrypoints.APIVersion{Name: "v1", MovieController: v1Controller, Deprecation: &v1Deprecation})
+		entrypoint.RegisterVersion(entrypoints.APIVersion{Name: "v2", MovieController: v2Controller})
+		entrypoint.Setup()
+		engine := entrypoint.GetEngine()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/v2/movies/", nil)
+		engine.ServeHTTP(w, req)
+
+		assert.Equal(t, executorService, v2Controller.SaveMovieService)
+		assert.Empty(t, w.Header().Get("Deprecation"))
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/v1/movies/75", nil)
+		engine.ServeHTTP(w, req)
+
+		assert.Equal(t, fmt.Sprintf("@%d", v1Deprecation.Since.Unix()), w.Header().Get("Deprecation"))
+		assert.Equal(t, "Wed, 01 Jan 2031 00:00:00 GMT", w.Header().Get("Sunset"))
+		assert.Equal(t, `</v2/movies/75>; rel="successor-version"`, w.Header().Get("Link"))
+	})
+}
+
+func TestGinEntrypointExport(t *testing.T) {
+	exporter := &FakeExporterService{Movies: []dtos.MovieResponseDTO{{ID: 75, Title: "a movie", Year: "1995", Version: 1}}}
+	movieController := &MockMovieController{}
+
+	entrypoint := entrypoints.NewGinEntrypoint(&FakeExecutorService{}, &FakeQueryService{}, &FakeEventSubscriber{}, movieController)
+	entrypoint.RegisterExport(exporter, []string{"first-token", "second-token"}, controllers.NewExportController())
+	entrypoint.Setup()
+	engine := entrypoint.GetEngine()
+
+	t.Run("should refuse the requests without a valid token under every version", func(t *testing.T) {
+		for _, authorization := range []string{"", "Bearer wrong-token", "Basic second-token"} {
+			for _, path := range []string{"/v1/movies/export", "/movies/export"} {
+				w := httptest.NewRecorder()
+				req, _ := http.NewRequest("GET", path, nil)
+				if authorization != "" {
+					req.Header.Set("Authorization", authorization)
+				}
+
+				engine.ServeHTTP(w, req)
+
+				assert.Equal(t, http.StatusUnauthorized, w.Code, "%s with %q", path, authorization)
+				assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+			}
+		}
+	})
+
+	t.Run("should export the movies with any of the tokens, the route not taken for an id", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/v1/movies/export", nil)
+		req.Header.Set("Authorization", "Bearer second-token")
+		req.Header.Set("Accept", "application/x-ndjson")
+
+		engine.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{"id": 75, "title": "a movie", "year": "1995", "version": 1, "updated_at": 0}`, w.Body.String())
+		assert.Nil(t, movieController.GetMovieService)
+	})
+}
+
+func TestGinEntrypointMetrics(t *testing.T) {
+	t.Run("should not serve the metrics without tokens", func(t *testing.T) {
+		entrypoint := entrypoints.NewGinEntrypoint(&FakeExecutorService{}, &FakeQueryService{}, &FakeEventSubscriber{}, &MockMovieController{})
+		entrypoint.Setup()
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", entrypoints.MetricsPath, nil)
+
+		entrypoint.GetEngine().ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("should only serve the metrics to the requests with a token", func(t *testing.T) {
+		entrypoint := entrypoints.NewGinEntrypoint(&FakeExecutorService{}, &FakeQueryService{}, &FakeEventSubscriber{}, &MockMovieController{})
+		entrypoint.RegisterMetrics([]string{"metrics-token"})
+		entrypoint.Setup()
+		engine := entrypoint.GetEngine()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", entrypoints.MetricsPath, nil)
+		engine.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		w = httptest.NewRecorder()
+		req.Header.Set("Authorization", "Bearer metrics-token")
+		engine.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"cmdline"`)
+	})
+}
+
+func TestGinEntrypointWebhooks(t *testing.T) {
+	entrypoint := entrypoints.NewGinEntrypoint(&FakeExecutorService{}, &FakeQueryService{}, &FakeEventSubscriber{}, &MockMovieController{})
+	entrypoint.RegisterWebhooks(webhooks.NewMemoryStore(10), []string{"webhooks-token"}, controllers.NewWebhookController())
+	entrypoint.Setup()
+	engine := entrypoint.GetEngine()
+
+	t.Run("should refuse the requests without a valid token", func(t *testing.T) {
+		for _, authorization := range []string{"", "Bearer wrong-token"} {
+			for _, route := range [][2]string{{"GET", "/v1/webhooks/"}, {"POST", "/v1/webhooks/"}, {"DELETE", "/v1/webhooks/an-id"}} {
+				w := httptest.NewRecorder()
+				req, _ := http.NewRequest(route[0], route[1], nil)
+				if authorization != "" {
+					req.Header.Set("Authorization", authorization)
+				}
+
+				engine.ServeHTTP(w, req)
+
+				assert.Equal(t, http.StatusUnauthorized, w.Code, "%v with %q", route, authorization)
+			}
+		}
+	})
+
+	t.Run("should serve the webhooks with the token", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/v1/webhooks/", nil)
+		req.Header.Set("Authorization", "Bearer webhooks-token")
+
+		engine.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestGinEntrypointMovieStream(t *testing.T) {
+	streamer := &FakeStreamerService{Movies: []dtos.MovieResponseDTO{{ID: 75, Title: "a movie", Year: "1995", Version: 1}}}
+	queryService := &FakeQueryService{}
+	movieController := &MockMovieController{}
+
+	entrypoint := entrypoints.NewGinEntrypoint(&FakeExecutorService{}, queryService, &FakeEventSubscriber{}, movieController)
+	entrypoint.RegisterMovieStream(streamer, controllers.NewMovieStreamController())
+	entrypoint.Setup()
+	engine := entrypoint.GetEngine()
+
+	t.Run("should stream the movies of the parsed query under every version when NDJSON is preferred", func(t *testing.T) {
+		for _, path := range []string{"/v1/movies/?year=1995&cursor=50", "/movies/?year=1995&cursor=50"} {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", path, nil)
+			req.Header.Set("Accept", "application/x-ndjson")
+
+			engine.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code, path)
+			assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+			assert.JSONEq(t, `{"id": 75, "title": "a movie", "year": "1995", "version": 1}`, w.Body.String())
+			assert.Equal(t, dtos.MoviesQueryDTO{Year: "1995", Cursor: 50}, streamer.QueryPassed)
+			assert.Nil(t, movieController.GetMoviesService, "the pages must not be answered along with the stream")
+		}
+	})
+
+	t.Run("should answer the pages otherwise", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/v1/movies/", nil)
+		req.Header.Set("Accept", "application/json, application/x-ndjson;q=0.5")
+
+		engine.ServeHTTP(w, req)
+
+		assert.Equal(t, queryService, movieController.GetMoviesService)
+	})
+}
+
 type MockMovieController struct {
 	GetMovieService     any
 	GetMovieError       error
@@ -99,11 +337,19 @@ type MockMovieController struct {
 	GetMoviesError      error
 	GetMoviesDTO        dtos.MoviesQueryDTO
 
+	BatchGetMoviesService any
+
 	SaveMovieService    any
 	SaveMovieError      error
 
+	UpdateMovieService  any
+	UpdateMovieError    error
+
 	DeleteMovieService  any
 	DeleteMovieError    any
+
+	StreamMovieEventsService  any
+	StreamMovieEventsDTO      dtos.MoviesQueryDTO
 }
 
 func (controller *MockMovieController) GetMovieHandler(usecase ports.GetMovieCase) gin.HandlerFunc {
@@ -140,6 +386,13 @@ func (controller *MockMovieController) GetMoviesHandler(usecase ports.GetMoviesC
 	}
 }
 
+func (controller *MockMovieController) BatchGetMoviesHandler(usecase ports.BatchGetMoviesCase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		controller.BatchGetMoviesService, _ = ctx.Get(ports.ServiceKey)
+		ctx.JSON(204, http.NoBody)
+	}
+}
+
 func (controller *MockMovieController) SaveMovieHandler(usecase ports.SaveMovieCase) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		service, exists := ctx.Get(ports.ServiceKey)
@@ -152,6 +405,18 @@ func (controller *MockMovieController) SaveMovieHandler(usecase ports.SaveMovieC
 	}
 }
 
+func (controller *MockMovieController) UpdateMovieHandler(usecase ports.UpdateMovieCase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		service, exists := ctx.Get(ports.ServiceKey)
+		if !exists {
+			controller.UpdateMovieError = fmt.Errorf("executor service not set")
+		}
+
+		controller.UpdateMovieService = service
+		ctx.JSON(204, http.NoBody)
+	}
+}
+
 func (controller *MockMovieController) DeleteMovieHandler(usecase ports.DeleteMovieCase)  gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		service, exists := ctx.Get(ports.ServiceKey)
@@ -164,6 +429,20 @@ func (controller *MockMovieController) DeleteMovieHandler(usecase ports.DeleteMo
 	}
 }
 
+func (controller *MockMovieController) StreamMovieEventsHandler(usecase ports.StreamMovieEventsCase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		controller.StreamMovieEventsService, _ = ctx.Get(ports.ServiceKey)
+		if dto, ok := ctx.MustGet(middlewares.DtoKey).(*dtos.MoviesQueryDTO); ok {
+			controller.StreamMovieEventsDTO = *dto
+		}
+		ctx.JSON(204, http.NoBody)
+	}
+}
+
+type FakeEventSubscriber struct {
+	ports.MovieEventSubscriberService
+}
+
 type FakeQueryService struct {
 	ports.MovieQueryService
 }
@@ -185,8 +464,47 @@ func (service *FakeExecutorService) Save(ctx context.Context, movie dtos.CreateM
 	return nil
 }
 	
-func (service *FakeExecutorService) Delete(ctx context.Context, id dtos.MovieId) error {
+func (service *FakeExecutorService) Update(ctx context.Context, movie dtos.UpdateMovieDTO) error {
+	return nil
+}
+	
+func (service *FakeExecutorService) Delete(ctx context.Context, id dtos.MovieId, expectedVersion int) error {
 	return nil
 }
 
  
+
+type FakeExporterService struct {
+	Movies []dtos.MovieResponseDTO
+}
+
+func (service *FakeExporterService) Export(ctx context.Context, emit func(movie dtos.MovieResponseDTO) error) error {
+	for _, movie := range service.Movies {
+		if err := emit(movie); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Emits the movies, recording the query.
+type FakeStreamerService struct {
+	Movies      []dtos.MovieResponseDTO
+	QueryPassed dtos.MoviesQueryDTO
+}
+
+func (service *FakeStreamerService) Stream(
+	ctx context.Context, query dtos.MoviesQueryDTO, emit func(movie dtos.MovieResponseDTO) error,
+) error {
+	service.QueryPassed = query
+	for _, movie := range service.Movies {
+		if err := emit(movie); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type FakeBulkExecutorService struct {
+	ports.MovieBulkExecutorService
+}
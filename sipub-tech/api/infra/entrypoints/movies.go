@@ -1,37 +1,105 @@
 package entrypoints
 
 import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/usecases"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/middlewares"
+	infraPorts "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/ports"
 )
 
+var (
+	// The unversioned /movies routes are kept as an alias of the legacy
+	// version until their sunset.
+	LegacyRoutesDeprecation = middlewares.Deprecation{
+		Since:           time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC),
+		Sunset:          time.Date(2027, time.April, 19, 0, 0, 0, 0, time.UTC),
+		SuccessorPrefix: "/" + CurrentVersion,
+	}
+)
+
+// Streams the movie listings as NDJSON to the clients preferring it, on
+// the movie routes of every version.
+// It must be called before Setup.
+func (entrypoint *GinEntrypoint) RegisterMovieStream(
+	service ports.MovieStreamerService, controller infraPorts.MovieStreamController,
+) {
+	entrypoint.movieStreamerService = service
+	entrypoint.movieStreamController = controller
+}
+
 func (entrypoint *GinEntrypoint) addMovieHandlers() {
-	queryGroup := entrypoint.engine.Group(
+	for _, version := range entrypoint.versions {
+		prefix := "/" + version.Name
+		group := entrypoint.engine.Group(prefix)
+		if version.Deprecation != nil {
+			group.Use(middlewares.Deprecated(*version.Deprecation, prefix))
+		}
+		entrypoint.addMovieRoutes(group, version.MovieController)
+
+		if version.Name == entrypoint.legacyVersion {
+			legacyGroup := entrypoint.engine.Group("", middlewares.Deprecated(entrypoint.legacyDeprecation, ""))
+			entrypoint.addMovieRoutes(legacyGroup, version.MovieController)
+		}
+	}
+}
+
+func (entrypoint *GinEntrypoint) addMovieRoutes(router *gin.RouterGroup, controller infraPorts.MovieController) {
+	queryGroup := router.Group(
 		"/movies",
 		middlewares.AddMovieQueryService(entrypoint.queryMovieService),
 	)
-	queryGroup.GET(
-		"/",
-		middlewares.ParseQueryParameters(),
-		entrypoint.movieController.GetMoviesHandler(usecases.NewGetMoviesCase()),
-	)
+	listHandlers := []gin.HandlerFunc{middlewares.ParseQueryParameters()}
+	if entrypoint.movieStreamController != nil {
+		listHandlers = append(
+			listHandlers,
+			middlewares.AddMovieStreamerService(entrypoint.movieStreamerService),
+			entrypoint.movieStreamController.StreamMoviesHandler(usecases.NewStreamMoviesCase()),
+		)
+	}
+	queryGroup.GET("/", append(listHandlers, controller.GetMoviesHandler(usecases.NewGetMoviesCase()))...)
 	queryGroup.GET(
 		"/:id",
-		entrypoint.movieController.GetMovieHandler(usecases.NewGetMovieCase()),
+		controller.GetMovieHandler(usecases.NewGetMovieCase()),
+	)
+	// A POST, as the ids may not fit in the url, that changes nothing.
+	queryGroup.POST(
+		"/batch-get",
+		controller.BatchGetMoviesHandler(usecases.NewBatchGetMoviesCase()),
+	)
+
+	// Registered apart from the queries, as it is answered by the events
+	// instead of the movie service.
+	router.GET(
+		"/movies/events",
+		middlewares.AddMovieEventSubscriber(entrypoint.movieEventSubscriber),
+		middlewares.ParseQueryParameters(),
+		controller.StreamMovieEventsHandler(usecases.NewStreamMovieEventsCase()),
 	)
 
-	executorGroup := entrypoint.engine.Group(
+	executorGroup := router.Group(
 		"/movies",
 		middlewares.AddMovieExecutorService(entrypoint.executorMovieService),
 	)
 
 	executorGroup.POST(
 		"/",
-		entrypoint.movieController.SaveMovieHandler(usecases.NewSaveMovieCase()),
+		controller.SaveMovieHandler(usecases.NewSaveMovieCase()),
+	)
+	executorGroup.PUT(
+		"/:id",
+		middlewares.CheckIfMatch(entrypoint.queryMovieService),
+		controller.UpdateMovieHandler(usecases.NewUpdateMovieCase()),
 	)
 	executorGroup.DELETE(
 		"/:id",
-		entrypoint.movieController.DeleteMovieHandler(usecases.NewDeleteMovieCase()),
+		middlewares.CheckIfMatch(entrypoint.queryMovieService),
+		controller.DeleteMovieHandler(usecases.NewDeleteMovieCase()),
 	)
-	
+
+	entrypoint.addBulkMovieRoutes(router)
+	entrypoint.addExportRoutes(router)
 }
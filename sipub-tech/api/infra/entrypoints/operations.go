@@ -0,0 +1,132 @@
+package entrypoints
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/usecases"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/middlewares"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/openapi"
+	infraPorts "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/presenters"
+)
+
+const (
+	OperationsPath = "/" + CurrentVersion + "/operations"
+)
+
+// Serves the bulk movie routes along with the movie routes of every
+// version, and the operations they start under the current version.
+// It must be called before Setup.
+func (entrypoint *GinEntrypoint) RegisterBulkOperations(
+	service ports.MovieBulkExecutorService, operations ports.OperationService, controller infraPorts.OperationController,
+) {
+	entrypoint.bulkMovieService = service
+	entrypoint.operationService = operations
+	entrypoint.operationController = controller
+}
+
+func (entrypoint *GinEntrypoint) addBulkMovieRoutes(router *gin.RouterGroup) {
+	if entrypoint.operationController == nil {
+		return
+	}
+	controller := entrypoint.operationController
+
+	group := router.Group(
+		"/movies/bulk",
+		middlewares.AddMovieBulkExecutorService(entrypoint.bulkMovieService),
+		middlewares.AddOperationService(entrypoint.operationService, OperationsPath),
+	)
+	group.POST(
+		"",
+		controller.BulkSaveMoviesHandler(usecases.NewBulkSaveMoviesCase()),
+	)
+	group.DELETE(
+		"",
+		controller.BulkDeleteMoviesHandler(usecases.NewBulkDeleteMoviesCase()),
+	)
+}
+
+func (entrypoint *GinEntrypoint) addOperationHandlers() {
+	if entrypoint.operationController == nil {
+		return
+	}
+
+	entrypoint.engine.GET(
+		OperationsPath + "/:id",
+		middlewares.AddOperationService(entrypoint.operationService, OperationsPath),
+		entrypoint.operationController.GetOperationHandler(usecases.NewGetOperationCase()),
+	)
+}
+
+// Documents the bulk movie routes, relative to the version they are
+// mounted on.
+func BulkMovieRoutes() []openapi.Route {
+	tags := []string{"movies"}
+	accepted := []openapi.RouteResponse{{
+		Status:      http.StatusAccepted,
+		Description: "The pending operation.",
+		Body:        presenters.V1OperationBody{},
+		Headers: map[string]*openapi.Header{
+			"Location": {Description: "The path of the operation.", Schema: &openapi.Schema{Type: "string"}},
+		},
+	}}
+
+	return []openapi.Route{
+		{
+			Method:      http.MethodPost,
+			Path:        "/movies/bulk",
+			OperationID: "bulk_create_movies",
+			Summary:     "Create up to 1000 movies. This operation runs in the background.",
+			Description: "Each movie is validated as on a single creation, and the invalid ones fail alone. " +
+				"The result of each movie, with its id when created, is reported in the operation.",
+			Tags:        tags,
+			RequestBody: dtos.BulkCreateMoviesDTO{},
+			Responses: append(
+				accepted,
+				problems(http.StatusUnprocessableEntity, http.StatusInternalServerError, http.StatusServiceUnavailable)...,
+			),
+		},
+		{
+			Method:      http.MethodDelete,
+			Path:        "/movies/bulk",
+			OperationID: "bulk_delete_movies",
+			Summary:     "Delete up to 1000 movies by their ids. This operation runs in the background.",
+			Description: "The movies are deleted whatever their version. The missing ones are reported as not_found in the operation.",
+			Tags:        tags,
+			RequestBody: dtos.BulkDeleteMoviesDTO{},
+			Responses: append(
+				accepted,
+				problems(http.StatusUnprocessableEntity, http.StatusInternalServerError, http.StatusServiceUnavailable)...,
+			),
+		},
+	}
+}
+
+// Documents the operation routes, relative to the current version.
+func OperationRoutes() []openapi.Route {
+	return []openapi.Route{
+		{
+			Method:      http.MethodGet,
+			Path:        "/operations/:id",
+			OperationID: "get_operation",
+			Summary:     "Get how a bulk operation is going, with the results of its items so far.",
+			Description: "The operation is completed when every item has a result. Only the last operations are kept.",
+			Tags:        []string{"operations"},
+			Parameters: []*openapi.Parameter{{
+				Name:        "id",
+				In:          "path",
+				Description: "The id of the operation.",
+				Required:    true,
+				Schema:      &openapi.Schema{Type: "string", Format: "uuid"},
+			}},
+			Responses: append(
+				[]openapi.RouteResponse{{Status: http.StatusOK, Description: "The operation.", Body: presenters.V1OperationBody{}}},
+				problems(http.StatusNotFound, http.StatusInternalServerError)...,
+			),
+		},
+	}
+}
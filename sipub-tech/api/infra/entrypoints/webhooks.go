@@ -0,0 +1,193 @@
+package entrypoints
+
+import (
+	"net/http"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/usecases"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/middlewares"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/openapi"
+	infraPorts "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/presenters"
+)
+
+// Serves the webhooks under the current version. They are not part of
+// the movie routes, so they are neither versioned with them nor aliased
+// by the unversioned routes. As the export, they are only served to the
+// requests with one of the tokens.
+// It must be called before Setup.
+func (entrypoint *GinEntrypoint) RegisterWebhooks(
+	service ports.WebhookService, tokens []string, controller infraPorts.WebhookController,
+) {
+	entrypoint.webhookService = service
+	entrypoint.webhookTokens = tokens
+	entrypoint.webhookController = controller
+}
+
+func (entrypoint *GinEntrypoint) addWebhookHandlers() {
+	if entrypoint.webhookController == nil {
+		return
+	}
+	controller := entrypoint.webhookController
+
+	group := entrypoint.engine.Group(
+		"/" + CurrentVersion + "/webhooks",
+		middlewares.RequireBearerToken(entrypoint.webhookTokens),
+		middlewares.AddWebhookService(entrypoint.webhookService),
+	)
+	group.POST(
+		"/",
+		controller.CreateWebhookHandler(usecases.NewCreateWebhookCase()),
+	)
+	group.GET(
+		"/",
+		controller.GetWebhooksHandler(usecases.NewGetWebhooksCase()),
+	)
+	group.GET(
+		"/:id",
+		controller.GetWebhookHandler(usecases.NewGetWebhookCase()),
+	)
+	group.PUT(
+		"/:id",
+		controller.UpdateWebhookHandler(usecases.NewUpdateWebhookCase()),
+	)
+	group.DELETE(
+		"/:id",
+		controller.DeleteWebhookHandler(usecases.NewDeleteWebhookCase()),
+	)
+	group.GET(
+		"/:id/deliveries",
+		middlewares.ParseWebhookDeliveriesQueryParameters(),
+		controller.GetWebhookDeliveriesHandler(usecases.NewGetWebhookDeliveriesCase()),
+	)
+}
+
+// Documents the webhook routes, relative to the current version.
+func WebhookRoutes() []openapi.Route {
+	tags := []string{"webhooks"}
+	idParameter := &openapi.Parameter{
+		Name:        "id",
+		In:          "path",
+		Description: "The id of the webhook.",
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "string", Format: "uuid"},
+	}
+
+	return []openapi.Route{
+		{
+			Method:      http.MethodPost,
+			Path:        "/webhooks/",
+			OperationID: "create_webhook",
+			Summary:     "Register a url to receive the movie events.",
+			Description: "The events are POSTed as JSON, signed in the Webhook-Signature header as v1=<hex HMAC-SHA256 of " +
+				"\"<Webhook-Id>.<Webhook-Timestamp>.<body>\"> keyed by the secret. Failed attempts are retried with an " +
+				"exponential backoff, and the webhook is disabled after too many events fail in a row. " +
+				"The secret is generated when not sent, and only answered here.",
+			Tags:        tags,
+			Security:    []string{BearerTokenSecurity},
+			RequestBody: dtos.CreateWebhookDTO{},
+			Responses: append(
+				[]openapi.RouteResponse{{
+					Status:      http.StatusCreated,
+					Description: "The webhook, with its secret.",
+					Body:        presenters.V1WebhookBody{},
+					Headers: map[string]*openapi.Header{
+						"Location": {Description: "The path of the webhook.", Schema: &openapi.Schema{Type: "string"}},
+					},
+				}},
+				problems(http.StatusUnauthorized, http.StatusUnprocessableEntity, http.StatusInternalServerError)...,
+			),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/webhooks/",
+			OperationID: "get_webhooks",
+			Summary:     "Get every webhook.",
+			Tags:        tags,
+			Security:    []string{BearerTokenSecurity},
+			Responses: append(
+				[]openapi.RouteResponse{{Status: http.StatusOK, Description: "The webhooks.", Body: presenters.V1WebhooksList{}}},
+				problems(http.StatusUnauthorized, http.StatusInternalServerError)...,
+			),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/webhooks/:id",
+			OperationID: "get_webhook",
+			Summary:     "Get a webhook by its id.",
+			Tags:        tags,
+			Security:    []string{BearerTokenSecurity},
+			Parameters:  []*openapi.Parameter{idParameter},
+			Responses: append(
+				[]openapi.RouteResponse{{Status: http.StatusOK, Description: "The webhook.", Body: presenters.V1WebhookBody{}}},
+				problems(http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError)...,
+			),
+		},
+		{
+			Method:      http.MethodPut,
+			Path:        "/webhooks/:id",
+			OperationID: "update_webhook",
+			Summary:     "Replace the url and the filters of a webhook.",
+			Description: "An empty secret keeps the current one. Enabling a disabled webhook forgets its failures.",
+			Tags:        tags,
+			Security:    []string{BearerTokenSecurity},
+			Parameters:  []*openapi.Parameter{idParameter},
+			RequestBody: dtos.UpdateWebhookDTO{},
+			Responses: append(
+				[]openapi.RouteResponse{{Status: http.StatusOK, Description: "The updated webhook.", Body: presenters.V1WebhookBody{}}},
+				problems(http.StatusUnauthorized, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusInternalServerError)...,
+			),
+		},
+		{
+			Method:      http.MethodDelete,
+			Path:        "/webhooks/:id",
+			OperationID: "delete_webhook",
+			Summary:     "Delete a webhook with its deliveries.",
+			Tags:        tags,
+			Security:    []string{BearerTokenSecurity},
+			Parameters:  []*openapi.Parameter{idParameter},
+			Responses: append(
+				[]openapi.RouteResponse{{Status: http.StatusNoContent, Description: "The webhook was deleted."}},
+				problems(http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError)...,
+			),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/webhooks/:id/deliveries",
+			OperationID: "get_webhook_deliveries",
+			Summary:     "Get the attempts of sending the events to a webhook, from the newest.",
+			Description: "Only the last attempts of each webhook are kept. Pass the returned cursor to fetch the next page.",
+			Tags:        tags,
+			Security:    []string{BearerTokenSecurity},
+			Parameters: []*openapi.Parameter{
+				idParameter,
+				{
+					Name:        middlewares.QueryStatusKey,
+					In:          "query",
+					Description: "Only get the attempts with this status.",
+					Schema: &openapi.Schema{
+						Type: "string",
+						Enum: []any{dtos.WebhookDeliverySucceeded, dtos.WebhookDeliveryFailed},
+					},
+				},
+				{
+					Name:        middlewares.QueryLimitKey,
+					In:          "query",
+					Description: "The maximum number of attempts returned.",
+					Schema:      &openapi.Schema{Type: "integer", Minimum: intPointer(1), Examples: []any{50}},
+				},
+				{
+					Name:        middlewares.QueryCursorKey,
+					In:          "query",
+					Description: "The id of the last attempt fetched, so it and the newer ones are skipped.",
+					Schema:      &openapi.Schema{Type: "integer", Minimum: intPointer(1)},
+				},
+			},
+			Responses: append(
+				[]openapi.RouteResponse{{Status: http.StatusOK, Description: "A page of attempts.", Body: presenters.V1WebhookDeliveriesPage{}}},
+				problems(http.StatusUnauthorized, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusInternalServerError)...,
+			),
+		},
+	}
+}
@@ -1,30 +1,124 @@
 package errors
 
 import (
-	"fmt"
-	
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/dtos"
 )
 
+const (
+	ProblemContentType = "application/problem+json"
+	problemTypePrefix  = "/problems/"
+)
+
+// Machine readable codes of the problems returned by the API.
+// Clients should branch on them instead of on the detail message.
+type Code string
+
+const (
+	CodeMovieNotFound       Code = "MOVIE_NOT_FOUND"
+	CodeWebhookNotFound     Code = "WEBHOOK_NOT_FOUND"
+	CodeOperationNotFound   Code = "OPERATION_NOT_FOUND"
+	CodeValidationFailed    Code = "VALIDATION_FAILED"
+	CodeMalformedRequest    Code = "MALFORMED_REQUEST"
+	CodeUnauthorized        Code = "UNAUTHORIZED"
+	CodeNotAcceptable       Code = "NOT_ACCEPTABLE"
+	CodePreconditionFailed  Code = "PRECONDITION_FAILED"
+	CodeUpstreamUnavailable Code = "UPSTREAM_UNAVAILABLE"
+	CodeUpstreamTimeout     Code = "UPSTREAM_TIMEOUT"
+	CodeInternalError       Code = "INTERNAL_ERROR"
+)
+
+type problemKind struct {
+	status int
+	title  string
+	slug   string
+}
+
 var (
-	BadRequestResponse = dtos.NewErrorResponse(dtos.ErrorMessage(fmt.Errorf("bad request")))
-	UnprocessableEntityResponse = dtos.NewErrorResponse(
-		dtos.ErrorMessage(fmt.Errorf("unprocessable Entity: ")),
-	)
-	InternalServerErrorResponse = dtos.NewErrorResponse(dtos.ErrorMessage(fmt.Errorf("internal Server Error")))
-	MovieNotFoundErrorResponse = dtos.NewErrorResponse(dtos.ErrorMessage(fmt.Errorf("movie not found")))
+	problemKinds = map[Code]problemKind{
+		CodeMovieNotFound:       {http.StatusNotFound, "Movie not found", "movie-not-found"},
+		CodeWebhookNotFound:     {http.StatusNotFound, "Webhook not found", "webhook-not-found"},
+		CodeOperationNotFound:   {http.StatusNotFound, "Operation not found", "operation-not-found"},
+		CodeValidationFailed:    {http.StatusUnprocessableEntity, "Validation failed", "validation-failed"},
+		CodeMalformedRequest:    {http.StatusUnprocessableEntity, "Malformed request", "malformed-request"},
+		CodeUnauthorized:        {http.StatusUnauthorized, "Unauthorized", "unauthorized"},
+		CodeNotAcceptable:       {http.StatusNotAcceptable, "Not acceptable", "not-acceptable"},
+		CodePreconditionFailed:  {http.StatusPreconditionFailed, "Precondition failed", "precondition-failed"},
+		CodeUpstreamUnavailable: {http.StatusServiceUnavailable, "Upstream unavailable", "upstream-unavailable"},
+		CodeUpstreamTimeout:     {http.StatusGatewayTimeout, "Upstream timeout", "upstream-timeout"},
+		CodeInternalError:       {http.StatusInternalServerError, "Internal server error", "internal-error"},
+	}
 )
 
-func InternalServerError(message string) *dtos.ErrorResponse {
-	err := InternalServerErrorResponse.Copy()
-	err.Details.Message += ": " + message
-	return err
+func NewProblem(code Code, detail string) *dtos.ProblemDetails {
+	kind, ok := problemKinds[code]
+	if !ok {
+		code, kind = CodeInternalError, problemKinds[CodeInternalError]
+	}
+	return &dtos.ProblemDetails{
+		Type:   problemTypePrefix + kind.slug,
+		Title:  kind.title,
+		Status: kind.status,
+		Detail: detail,
+		Code:   string(code),
+	}
+}
+
+func ValidationFailed(violations []dtos.FieldError) *dtos.ProblemDetails {
+	problem := NewProblem(CodeValidationFailed, "One or more fields are invalid.")
+	problem.Violations = violations
+	return problem
 }
 
-func UnprocessableEntity(message string) *dtos.ErrorResponse {
-	err := UnprocessableEntityResponse.Copy()
-	err.Details.Message += ": " + message
-	return err
+// Derives the problem from the errors declared in the ports, so any
+// error wrapping them is mapped to the same code.
+// Unknown errors become an internal error without leaking their message.
+func FromError(err error) *dtos.ProblemDetails {
+	switch {
+	case errors.Is(err, ports.ErrMovieNotFound):
+		return NewProblem(CodeMovieNotFound, "The requested movie does not exist.")
+	case errors.Is(err, ports.ErrWebhookNotFound):
+		return NewProblem(CodeWebhookNotFound, "The requested webhook does not exist.")
+	case errors.Is(err, ports.ErrOperationNotFound):
+		return NewProblem(CodeOperationNotFound, "The requested operation does not exist or is no longer tracked.")
+	case errors.Is(err, ports.ErrPreconditionFailed):
+		return NewProblem(CodePreconditionFailed, "The movie is not on the version sent in If-Match.")
+	case errors.Is(err, ports.ErrInvalidRequest):
+		return NewProblem(CodeValidationFailed, "The movies service refused the request.")
+	case errors.Is(err, ports.ErrServiceUnavailable):
+		return NewProblem(CodeUpstreamUnavailable, "The movies service is unavailable.")
+	case errors.Is(err, ports.ErrServiceTimeout):
+		return NewProblem(CodeUpstreamTimeout, "The movies service did not answer in time.")
+	default:
+		return NewProblem(CodeInternalError, "The request could not be processed.")
+	}
 }
 
+// Writes the problem of the error, as FromError, telling the client when
+// to retry when the service is known to be unavailable for a while.
+func AbortWithError(ctx *gin.Context, err error) {
+	var unavailable *ports.UnavailableError
+	if errors.As(err, &unavailable) {
+		seconds := max(1, int(math.Ceil(unavailable.RetryAfter.Seconds())))
+		ctx.Header("Retry-After", strconv.Itoa(seconds))
+	}
+	AbortWithProblem(ctx, FromError(err))
+}
 
+// Writes the problem as application/problem+json, using the request path
+// as the instance when none is set, and aborts the chain.
+func AbortWithProblem(ctx *gin.Context, problem *dtos.ProblemDetails) {
+	if problem.Instance == "" && ctx.Request != nil && ctx.Request.URL != nil {
+		problem.Instance = ctx.Request.URL.Path
+	}
+	ctx.Header("Content-Type", ProblemContentType)
+	ctx.JSON(problem.Status, problem)
+	ctx.Abort()
+}
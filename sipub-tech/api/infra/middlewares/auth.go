@@ -0,0 +1,52 @@
+package middlewares
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/errors"
+)
+
+const (
+	BearerScheme = "Bearer"
+)
+
+// Aborts with 401 the requests without one of the tokens in a bearer
+// Authorization header. The tokens are compared by their hashes in
+// constant time, so neither their contents nor their lengths leak. With no
+// tokens, every request is refused.
+func RequireBearerToken(tokens []string) gin.HandlerFunc {
+	hashes := make([][sha256.Size]byte, 0, len(tokens))
+	for _, token := range tokens {
+		if token != "" {
+			hashes = append(hashes, sha256.Sum256([]byte(token)))
+		}
+	}
+
+	return func(ctx *gin.Context) {
+		scheme, token, _ := strings.Cut(ctx.GetHeader("Authorization"), " ")
+		if !strings.EqualFold(scheme, BearerScheme) || token == "" {
+			unauthorized(ctx, "A bearer token is required in the Authorization header.")
+			return
+		}
+
+		hash := sha256.Sum256([]byte(strings.TrimSpace(token)))
+		matched := 0
+		for _, allowed := range hashes {
+			matched |= subtle.ConstantTimeCompare(hash[:], allowed[:])
+		}
+		if matched == 0 {
+			unauthorized(ctx, "The bearer token is not valid.")
+			return
+		}
+		ctx.Next()
+	}
+}
+
+func unauthorized(ctx *gin.Context, detail string) {
+	ctx.Header("WWW-Authenticate", BearerScheme)
+	errors.AbortWithProblem(ctx, errors.NewProblem(errors.CodeUnauthorized, detail))
+}
@@ -0,0 +1,55 @@
+package middlewares_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	infraDtos "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/errors"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/middlewares"
+)
+
+func TestRequireBearerToken(t *testing.T) {
+	authorize := func(tokens []string, authorization string) *FakeWriter {
+		req, _ := http.NewRequest("GET", "/movies/export", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		ctx := getContext(req)
+		middlewares.RequireBearerToken(tokens)(ctx)
+		writer := ctx.Writer.(*FakeWriter)
+		if ctx.IsAborted() && writer.StatusCode == 0 {
+			t.Errorf("Aborted without answering")
+		}
+		return writer
+	}
+
+	t.Run("should pass the requests with any of the tokens", func(t *testing.T) {
+		for _, authorization := range []string{"Bearer first", "bearer second", "Bearer  second "} {
+			writer := authorize([]string{"first", "second"}, authorization)
+			assert.Zero(t, writer.StatusCode, authorization)
+		}
+	})
+
+	t.Run("should abort with 401 without a valid bearer token", func(t *testing.T) {
+		for _, authorization := range []string{"", "Bearer", "Bearer third", "Basic first", "first"} {
+			writer := authorize([]string{"first", "second"}, authorization)
+
+			assert.Equal(t, http.StatusUnauthorized, writer.StatusCode, authorization)
+			assert.Equal(t, "Bearer", writer.Header().Get("WWW-Authenticate"))
+			var body infraDtos.ProblemDetails
+			if assert.NoError(t, json.Unmarshal(writer.Body, &body)) {
+				assert.Equal(t, string(errors.CodeUnauthorized), body.Code)
+			}
+		}
+	})
+
+	t.Run("should refuse every request without tokens", func(t *testing.T) {
+		for _, tokens := range [][]string{nil, {""}} {
+			assert.Equal(t, http.StatusUnauthorized, authorize(tokens, "Bearer token").StatusCode)
+		}
+	})
+}
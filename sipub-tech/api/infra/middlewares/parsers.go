@@ -3,7 +3,6 @@ package middlewares
 import (
 	"fmt"
 	"log"
-	"net/http"
 	"strconv"
 	"time"
 	
@@ -11,6 +10,7 @@ import (
 	
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
 	infraDtos "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/errors"
 )
 
 const (
@@ -18,6 +18,7 @@ const (
 	QueryYearKey   = "year"
 	QueryLimitKey  = "limit"
 	QueryCursorKey = "cursor"
+	QueryStatusKey = "status"
 )
 
 func ParseQueryParameters() gin.HandlerFunc {
@@ -28,8 +29,7 @@ func ParseQueryParameters() gin.HandlerFunc {
 			ctx.Query(QueryCursorKey),
 		)
 		if err != nil {
-			ctx.JSON(http.StatusUnprocessableEntity, infraDtos.NewErrorResponse(infraDtos.ErrorMessage(err)))
-			ctx.Abort()
+			errors.AbortWithProblem(ctx, errors.ValidationFailed(queryViolations(err)))
 			return
 		}
 		
@@ -44,26 +44,78 @@ func ParseQueryParameters() gin.HandlerFunc {
 	}
 }
 
+// Parses the limit, the cursor and the status of the deliveries of a
+// webhook. The webhook is set from the path by the handler.
+func ParseWebhookDeliveriesQueryParameters() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		limit, cursor, status, err := parseDeliveriesQueryParams(
+			ctx.Query(QueryLimitKey),
+			ctx.Query(QueryCursorKey),
+			ctx.Query(QueryStatusKey),
+		)
+		if err != nil {
+			errors.AbortWithProblem(ctx, errors.ValidationFailed(queryViolations(err)))
+			return
+		}
+
+		dto := &dtos.WebhookDeliveriesQueryDTO{
+			Status: status,
+			Limit:  limit,
+			Cursor: cursor,
+		}
+
+		ctx.Set(DtoKey, dto)
+		ctx.Next()
+	}
+}
+
 func parseQueryParams(year, limit, cursor string) (parsedYear string, parsedLimit, parsedCursor int, err error) {	
 	parsedLimit, err = parseLimit(limit)
 	if err != nil {
-		err = parseErrorAndLog("limit", limit, "a positive integer.", err)
+		err = parseErrorAndLog("limit", limit, "a positive integer", err)
 		return
 	}
 
-	const minimumYear = 1880
 	currentYear := time.Now().Year()
-	parsedYear, err = parseYear(year, minimumYear, currentYear)
+	parsedYear, err = parseYear(year, dtos.MinimumMovieYear, currentYear)
 	if err != nil {
-		err = parseErrorAndLog("year", year, fmt.Sprintf("an year between %d and %d", minimumYear, currentYear), err)
+		err = parseErrorAndLog("year", year, fmt.Sprintf("an year between %d and %d", dtos.MinimumMovieYear, currentYear), err)
 		return
 	}
 	
 	parsedCursor, err = parseCursor(cursor)
 	if err != nil {
-		err = parseErrorAndLog("cursor", cursor, "a positive integer.", err)
+		err = parseErrorAndLog("cursor", cursor, "a positive integer", err)
+		return
+	}
+	return
+}
+
+func parseDeliveriesQueryParams(
+	limit, cursor, status string,
+) (parsedLimit, parsedCursor int, parsedStatus dtos.WebhookDeliveryStatus, err error) {
+	parsedLimit, err = parseLimit(limit)
+	if err != nil {
+		err = parseErrorAndLog("limit", limit, "a positive integer", err)
+		return
+	}
+
+	parsedCursor, err = parseCursor(cursor)
+	if err != nil {
+		err = parseErrorAndLog("cursor", cursor, "a positive integer", err)
 		return
 	}
+
+	parsedStatus = dtos.WebhookDeliveryStatus(status)
+	switch parsedStatus {
+	case "", dtos.WebhookDeliverySucceeded, dtos.WebhookDeliveryFailed:
+	default:
+		err = parseErrorAndLog(
+			"status", status,
+			fmt.Sprintf("%q or %q", dtos.WebhookDeliverySucceeded, dtos.WebhookDeliveryFailed),
+			fmt.Errorf("unknown delivery status"),
+		)
+	}
 	return
 }
 
@@ -115,7 +167,28 @@ func parseCursor(cursor string) (int, error) {
 }
 
 
+// Error returned when a query parameter is malformed, telling which
+// parameter failed and what it must be.
+type QueryParameterError struct {
+	Parameter string
+	MustBe    string
+}
+
+func (err *QueryParameterError) Error() string {
+	return fmt.Sprintf("error parsing request. Query %q malformed. It must be %s.", err.Parameter, err.MustBe)
+}
+
 func parseErrorAndLog(field, original, mustBe string, err error) error {
 	log.Printf("Failed parsing %s %q: %v", field, original, err)
-	return fmt.Errorf("error parsing request. Query %q malformed. It must be %s", field, mustBe) 
+	return &QueryParameterError{Parameter: field, MustBe: mustBe}
+}
+
+func queryViolations(err error) []infraDtos.FieldError {
+	queryErr, ok := err.(*QueryParameterError)
+	if !ok {
+		return nil
+	}
+	return []infraDtos.FieldError{
+		{Field: queryErr.Parameter, Message: fmt.Sprintf("must be %s.", queryErr.MustBe)},
+	}
 }
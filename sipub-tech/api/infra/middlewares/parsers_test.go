@@ -1,6 +1,7 @@
 package middlewares_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"math/rand/v2"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 	"github.com/stretchr/testify/assert"	
 
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	infraDtos "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/dtos"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/middlewares"
 )
 
@@ -158,6 +160,30 @@ func TestParseQueryParameters(t *testing.T) {
 		}
 	})
 
+	t.Run("should answer a problem pointing to the malformed query", func(t *testing.T) {
+		path := fmt.Sprintf("/movies/?year=%d", minimumYear-1)
+		req, _ := http.NewRequest("GET", path, nil)
+		ctx := getContext(req)
+
+		queryParser(ctx)
+		if !ctx.IsAborted() {
+			t.Errorf("Context was not aborted when year was incorrect")
+		}
+
+		writer := ctx.Writer.(*FakeWriter)
+		var body infraDtos.ProblemDetails
+		if err := json.Unmarshal(writer.Body, &body); err != nil {
+			t.Fatalf("Error unmarshalling body %s: %v", writer.Body, err)
+		}
+
+		assert.Equal(t, 422, writer.Status())
+		assert.Equal(t, "application/problem+json", writer.Header().Get("Content-Type"))
+		assert.Equal(t, "VALIDATION_FAILED", body.Code)
+		if assert.Len(t, body.Violations, 1) {
+			assert.Equal(t, "year", body.Violations[0].Field)
+		}
+	})
+
 	t.Run("should fail to parse if given year after maximum", func(t *testing.T) {
 		limit, cursor := rand.Int32(), rand.Int32()
 		year := rand.IntN(minimumYear) + maximumYear
@@ -229,6 +255,42 @@ func TestParseQueryParameters(t *testing.T) {
 	})
 }
 
+func TestParseWebhookDeliveriesQueryParameters(t *testing.T) {
+	queryParser := middlewares.ParseWebhookDeliveriesQueryParameters()
+
+	t.Run("should parse the limit, the cursor and the status", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/webhooks/id/deliveries?limit=10&cursor=20&status=failed", nil)
+		ctx := getContext(req)
+
+		queryParser(ctx)
+
+		assert.False(t, ctx.IsAborted())
+		dto, _ := ctx.Get(middlewares.DtoKey)
+		expected := dtos.WebhookDeliveriesQueryDTO{Limit: 10, Cursor: 20, Status: dtos.WebhookDeliveryFailed}
+		assert.Equal(t, &expected, dto)
+	})
+
+	t.Run("should answer a problem pointing to the unknown status", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/webhooks/id/deliveries?status=pending", nil)
+		ctx := getContext(req)
+
+		queryParser(ctx)
+		if !ctx.IsAborted() {
+			t.Fatalf("Context was not aborted when status was unknown")
+		}
+
+		writer := ctx.Writer.(*FakeWriter)
+		var body infraDtos.ProblemDetails
+		if err := json.Unmarshal(writer.Body, &body); err != nil {
+			t.Fatalf("Error unmarshalling body %s: %v", writer.Body, err)
+		}
+		assert.Equal(t, 422, writer.Status())
+		if assert.Len(t, body.Violations, 1) {
+			assert.Equal(t, "status", body.Violations[0].Field)
+		}
+	})
+}
+
 func getContext(req *http.Request) *gin.Context {
 	return &gin.Context{
 		Request: req,
@@ -0,0 +1,67 @@
+package middlewares
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/conditionals"
+	httpErrors "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/errors"
+)
+
+const (
+	ExpectedVersionKey = "expectedVersion"
+)
+
+// Checks the If-Match header of the writes on the movie with the :id
+// parameter against its current ETag, aborting with 412 when it does not
+// match or the movie does not exist.
+// When it matches, the version of the movie is set to ExpectedVersionKey
+// so the write is only applied if the movie is still on it.
+// Requests without If-Match are passed on unconditionally.
+func CheckIfMatch(service ports.MovieOneGetterService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ifMatch := ctx.GetHeader(conditionals.IfMatchHeader)
+		if ifMatch == "" {
+			ctx.Next()
+			return
+		}
+
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			// The handler answers the malformed id.
+			ctx.Next()
+			return
+		}
+
+		movie, err := service.GetOne(ctx, dtos.MovieId(id))
+		if errors.Is(err, ports.ErrMovieNotFound) {
+			abortPreconditionFailed(ctx, fmt.Sprintf("movie %d does not exist", id))
+			return
+		} else if err != nil {
+			log.Printf("Failed checking If-Match of movie %d: %v", id, err)
+			httpErrors.AbortWithError(ctx, err)
+			return
+		}
+
+		etag := conditionals.MovieETag(movie)
+		ctx.Header(conditionals.ETagHeader, etag)
+		if !conditionals.Matches(ifMatch, etag, true) {
+			abortPreconditionFailed(ctx, fmt.Sprintf("movie %d is on %s, not %s", id, etag, ifMatch))
+			return
+		}
+
+		ctx.Set(ExpectedVersionKey, movie.Version)
+		ctx.Next()
+	}
+}
+
+func abortPreconditionFailed(ctx *gin.Context, logging string) {
+	log.Println(logging)
+	httpErrors.AbortWithProblem(ctx, httpErrors.FromError(ports.ErrPreconditionFailed))
+}
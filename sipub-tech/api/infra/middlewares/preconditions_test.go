@@ -0,0 +1,94 @@
+package middlewares_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/middlewares"
+	infraDtos "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/dtos"
+)
+
+func TestCheckIfMatch(t *testing.T) {
+	movie := dtos.MovieResponseDTO{ID: 14, Title: "a movie", Year: "1990", Version: 3}
+	service := &StubMovieGetter{Movie: movie}
+
+	request := func(ifMatch string) *gin.Context {
+		req, _ := http.NewRequest("DELETE", "/movies/14", nil)
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		ctx := getContext(req)
+		ctx.Params = gin.Params{{Key: "id", Value: "14"}}
+		return ctx
+	}
+
+	t.Run("should pass requests without If-Match unconditionally", func(t *testing.T) {
+		ctx := request("")
+		middlewares.CheckIfMatch(service)(ctx)
+
+		assert.False(t, ctx.IsAborted())
+		_, exists := ctx.Get(middlewares.ExpectedVersionKey)
+		assert.False(t, exists)
+	})
+
+	t.Run("should set the expected version when the ETag matches", func(t *testing.T) {
+		ctx := request(`"14-2", "14-3"`)
+		middlewares.CheckIfMatch(service)(ctx)
+
+		assert.False(t, ctx.IsAborted())
+		assert.Equal(t, 3, ctx.GetInt(middlewares.ExpectedVersionKey))
+	})
+
+	t.Run("should abort with 412 when the ETag does not match", func(t *testing.T) {
+		for _, ifMatch := range []string{`"14-2"`, `W/"14-3"`} {
+			ctx := request(ifMatch)
+			middlewares.CheckIfMatch(service)(ctx)
+
+			assertPreconditionFailed(t, ctx)
+			assert.Equal(t, `"14-3"`, ctx.Writer.Header().Get("ETag"))
+		}
+	})
+
+	t.Run("should abort with 412 when the movie does not exist", func(t *testing.T) {
+		ctx := request("*")
+		middlewares.CheckIfMatch(&StubMovieGetter{Err: ports.ErrMovieNotFound})(ctx)
+
+		assertPreconditionFailed(t, ctx)
+	})
+
+	t.Run("should answer the service errors", func(t *testing.T) {
+		ctx := request("*")
+		middlewares.CheckIfMatch(&StubMovieGetter{Err: ports.ErrServiceUnavailable})(ctx)
+
+		assert.True(t, ctx.IsAborted())
+		assert.Equal(t, http.StatusServiceUnavailable, ctx.Writer.Status())
+	})
+}
+
+func assertPreconditionFailed(t *testing.T, ctx *gin.Context) {
+	t.Helper()
+	assert.True(t, ctx.IsAborted())
+	assert.Equal(t, http.StatusPreconditionFailed, ctx.Writer.Status())
+
+	var problem infraDtos.ProblemDetails
+	if err := json.Unmarshal(ctx.Writer.(*FakeWriter).Body, &problem); err != nil {
+		t.Fatalf("Failed to unmarshal body: %v", err)
+	}
+	assert.Equal(t, "PRECONDITION_FAILED", problem.Code)
+}
+
+type StubMovieGetter struct {
+	Movie dtos.MovieResponseDTO
+	Err   error
+}
+
+func (service *StubMovieGetter) GetOne(ctx context.Context, id dtos.MovieId) (dtos.MovieResponseDTO, error) {
+	return service.Movie, service.Err
+}
@@ -6,6 +6,12 @@ import (
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
 )
 
+const (
+	// The path the operations are answered under, set with the operation
+	// service.
+	OperationsPathKey = "operationsPath"
+)
+
 func AddMovieQueryService(service ports.MovieQueryService) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		ctx.Set(ports.ServiceKey, service)
@@ -19,3 +25,48 @@ func AddMovieExecutorService(service ports.MovieExecutorService) gin.HandlerFunc
 		ctx.Next()
 	}
 }
+
+func AddMovieEventSubscriber(service ports.MovieEventSubscriberService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(ports.ServiceKey, service)
+		ctx.Next()
+	}
+}
+
+func AddWebhookService(service ports.WebhookService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(ports.ServiceKey, service)
+		ctx.Next()
+	}
+}
+
+func AddMovieBulkExecutorService(service ports.MovieBulkExecutorService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(ports.ServiceKey, service)
+		ctx.Next()
+	}
+}
+
+// Sets the service tracking the operations, and the path they are
+// answered under, for the location of the new ones.
+func AddOperationService(service ports.OperationService, path string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(ports.OperationServiceKey, service)
+		ctx.Set(OperationsPathKey, path)
+		ctx.Next()
+	}
+}
+
+func AddMovieExporterService(service ports.MovieExporterService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(ports.ServiceKey, service)
+		ctx.Next()
+	}
+}
+
+func AddMovieStreamerService(service ports.MovieStreamerService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(ports.StreamerServiceKey, service)
+		ctx.Next()
+	}
+}
@@ -64,7 +64,11 @@ func (service *FakeExecutorService) Save(ctx context.Context, movie dtos.CreateM
 	return nil
 }
 	
-func (service *FakeExecutorService) Delete(ctx context.Context, id dtos.MovieId) error {
+func (service *FakeExecutorService) Update(ctx context.Context, movie dtos.UpdateMovieDTO) error {
+	return nil
+}
+	
+func (service *FakeExecutorService) Delete(ctx context.Context, id dtos.MovieId, expectedVersion int) error {
 	return nil
 }
 
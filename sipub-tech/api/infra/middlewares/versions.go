@@ -0,0 +1,35 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Describes when a group of routes was deprecated, when it will be
+// removed, and the prefix of the routes replacing it.
+type Deprecation struct {
+	Since           time.Time
+	Sunset          time.Time
+	SuccessorPrefix string
+}
+
+// Announces the deprecation of the routes under prefix with the
+// Deprecation (RFC 9745), Sunset (RFC 8594) and successor-version Link
+// headers.
+func Deprecated(deprecation Deprecation, prefix string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Header("Deprecation", fmt.Sprintf("@%d", deprecation.Since.Unix()))
+		if !deprecation.Sunset.IsZero() {
+			ctx.Header("Sunset", deprecation.Sunset.UTC().Format(http.TimeFormat))
+		}
+		if deprecation.SuccessorPrefix != "" {
+			successor := deprecation.SuccessorPrefix + strings.TrimPrefix(ctx.Request.URL.Path, prefix)
+			ctx.Header("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+		}
+		ctx.Next()
+	}
+}
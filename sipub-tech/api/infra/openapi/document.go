@@ -0,0 +1,95 @@
+package openapi
+
+const (
+	Version = "3.1.0"
+)
+
+// The subset of the OpenAPI 3.1 document used by the API.
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+}
+
+type Info struct {
+	Title       string  `json:"title"`
+	Version     string  `json:"version"`
+	Description string  `json:"description,omitempty"`
+}
+
+// Operations of a path, keyed by the lowercase http method.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+	Security    []SecurityRequirement `json:"security,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+}
+
+type Parameter struct {
+	Name        string   `json:"name"`
+	In          string   `json:"in"`
+	Description string   `json:"description,omitempty"`
+	Required    bool     `json:"required,omitempty"`
+	Schema      *Schema  `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]MediaType  `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema  `json:"schema"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType  `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string   `json:"description,omitempty"`
+	Schema      *Schema  `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema          `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme  `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type        string  `json:"type"`
+	Scheme      string  `json:"scheme,omitempty"`
+	Description string  `json:"description,omitempty"`
+}
+
+// The scopes required of each security scheme, by its name.
+type SecurityRequirement map[string][]string
+
+// A JSON Schema (draft 2020-12), as used by OpenAPI 3.1.
+type Schema struct {
+	Ref                  string              `json:"$ref,omitempty"`
+	Type                 string              `json:"type,omitempty"`
+	Format               string              `json:"format,omitempty"`
+	Description          string              `json:"description,omitempty"`
+	Properties           map[string]*Schema  `json:"properties,omitempty"`
+	Required             []string            `json:"required,omitempty"`
+	Items                *Schema             `json:"items,omitempty"`
+	AdditionalProperties *Schema             `json:"additionalProperties,omitempty"`
+	MinLength            *int                `json:"minLength,omitempty"`
+	MaxLength            *int                `json:"maxLength,omitempty"`
+	Minimum              *int                `json:"minimum,omitempty"`
+	Maximum              *int                `json:"maximum,omitempty"`
+	Pattern              string              `json:"pattern,omitempty"`
+	Enum                 []any               `json:"enum,omitempty"`
+	Examples             []any               `json:"examples,omitempty"`
+}
@@ -0,0 +1,259 @@
+package openapi
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	JSONContentType    = "application/json"
+	ProblemContentType = "application/problem+json"
+)
+
+var (
+	ginParamPattern = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+)
+
+// Documents a route, with the path relative to the prefix it is mounted on
+// and in gin notation (e.g. /movies/:id).
+type Route struct {
+	Method      string
+	Path        string
+	OperationID string
+	Summary     string
+	Description string
+	Tags        []string
+	Parameters  []*Parameter
+	// A zero value of the type bound from the JSON body, nil if none.
+	RequestBody any
+	Responses   []RouteResponse
+	// The names of the security schemes, any of which authenticates the
+	// route. Public routes have none.
+	Security    []string
+}
+
+type RouteResponse struct {
+	Status      int
+	Description string
+	// A zero value of the type of the body, nil if the response has none.
+	Body        any
+	// Serves the body as application/problem+json.
+	Problem     bool
+	// The media type of the body, when it is neither JSON nor a problem.
+	ContentType string
+	Headers     map[string]*Header
+}
+
+// A prefix where the documented routes are mounted, such as an API
+// version. Deprecated mounts add the deprecation headers to the responses.
+type Mount struct {
+	Prefix     string
+	Name       string
+	Deprecated bool
+	Routes     []Route
+}
+
+func NewGenerator(info Info, mounts []Mount) *Generator {
+	rules := make(map[string]BindingRule, len(DefaultBindingRules))
+	for tag, rule := range DefaultBindingRules {
+		rules[tag] = rule
+	}
+	return &Generator{
+		info:            info,
+		mounts:          mounts,
+		bindingRules:    rules,
+		ignored:         map[string]bool{},
+		securitySchemes: map[string]*SecurityScheme{},
+	}
+}
+
+// Builds the OpenAPI document from the routes registered in gin and the
+// route documentation.
+type Generator struct {
+	info            Info
+	mounts          []Mount
+	bindingRules    map[string]BindingRule
+	ignored         map[string]bool
+	securitySchemes map[string]*SecurityScheme
+}
+
+// Adds the rule used to document a custom binding tag.
+func (generator *Generator) AddBindingRule(tag string, rule BindingRule) {
+	generator.bindingRules[tag] = rule
+}
+
+// Declares a security scheme the routes name in their Security.
+func (generator *Generator) AddSecurityScheme(name string, scheme SecurityScheme) {
+	generator.securitySchemes[name] = &scheme
+}
+
+// Leaves a gin route out of the document, such as the documentation
+// routes themselves.
+func (generator *Generator) Ignore(method, path string) {
+	generator.ignored[routeKey(method, path)] = true
+}
+
+// Generates the document for the routes registered in gin.
+// It fails when a registered route is not documented, or when a
+// documented route is not registered in any mount, so the document never
+// drifts from the engine.
+func (generator *Generator) Generate(registered gin.RoutesInfo) (*Document, error) {
+	schemas := newSchemaRegistry(generator.bindingRules)
+	document := &Document{
+		OpenAPI: Version,
+		Info:    generator.info,
+		Paths:   map[string]PathItem{},
+	}
+
+	documented := map[string]bool{}
+	var undocumented []string
+	for _, info := range registered {
+		key := routeKey(info.Method, info.Path)
+		if generator.ignored[key] {
+			continue
+		}
+
+		route, mount, ok := generator.findRoute(info.Method, info.Path)
+		if !ok {
+			undocumented = append(undocumented, key)
+			continue
+		}
+		documented[routeKey(route.Method, mount.Prefix+route.Path)] = true
+
+		path := toOpenAPIPath(info.Path)
+		if document.Paths[path] == nil {
+			document.Paths[path] = PathItem{}
+		}
+		document.Paths[path][strings.ToLower(info.Method)] = generator.operation(schemas, route, mount)
+	}
+
+	var unrouted []string
+	for _, mount := range generator.mounts {
+		for _, route := range mount.Routes {
+			key := routeKey(route.Method, mount.Prefix+route.Path)
+			if !documented[key] {
+				unrouted = append(unrouted, key)
+			}
+		}
+	}
+
+	if len(undocumented) > 0 || len(unrouted) > 0 {
+		slices.Sort(undocumented)
+		slices.Sort(unrouted)
+		return nil, fmt.Errorf(
+			"openapi document drifted from the routes. Undocumented routes: %v. Documented routes not registered: %v",
+			undocumented,
+			unrouted,
+		)
+	}
+
+	document.Components.Schemas = schemas.schemas
+	if len(generator.securitySchemes) > 0 {
+		document.Components.SecuritySchemes = generator.securitySchemes
+	}
+	return document, nil
+}
+
+func (generator *Generator) findRoute(method, path string) (Route, Mount, bool) {
+	for _, mount := range generator.mounts {
+		relative, ok := strings.CutPrefix(path, mount.Prefix)
+		if !ok {
+			continue
+		}
+		for _, route := range mount.Routes {
+			if route.Method == method && route.Path == relative {
+				return route, mount, true
+			}
+		}
+	}
+	return Route{}, Mount{}, false
+}
+
+func (generator *Generator) operation(schemas *schemaRegistry, route Route, mount Mount) *Operation {
+	operationID := route.OperationID
+	if mount.Name != "" {
+		operationID = mount.Name + "_" + operationID
+	}
+
+	operation := &Operation{
+		OperationID: operationID,
+		Summary:     route.Summary,
+		Description: route.Description,
+		Tags:        route.Tags,
+		Deprecated:  mount.Deprecated,
+		Parameters:  route.Parameters,
+		Responses:   map[string]*Response{},
+	}
+	for _, scheme := range route.Security {
+		operation.Security = append(operation.Security, SecurityRequirement{scheme: {}})
+	}
+
+	if route.RequestBody != nil {
+		operation.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]MediaType{
+				JSONContentType: {Schema: schemas.schemaOf(route.RequestBody)},
+			},
+		}
+	}
+
+	for _, routeResponse := range route.Responses {
+		response := &Response{Description: routeResponse.Description}
+		if routeResponse.Description == "" {
+			response.Description = http.StatusText(routeResponse.Status)
+		}
+		if routeResponse.Body != nil {
+			contentType := JSONContentType
+			if routeResponse.Problem {
+				contentType = ProblemContentType
+			} else if routeResponse.ContentType != "" {
+				contentType = routeResponse.ContentType
+			}
+			response.Content = map[string]MediaType{
+				contentType: {Schema: schemas.schemaOf(routeResponse.Body)},
+			}
+		}
+		if len(routeResponse.Headers) > 0 || mount.Deprecated {
+			response.Headers = map[string]*Header{}
+			maps.Copy(response.Headers, routeResponse.Headers)
+		}
+		if mount.Deprecated {
+			maps.Copy(response.Headers, deprecationHeaders())
+		}
+		operation.Responses[strconv.Itoa(routeResponse.Status)] = response
+	}
+
+	return operation
+}
+
+func deprecationHeaders() map[string]*Header {
+	return map[string]*Header{
+		"Deprecation": {
+			Description: "When the route was deprecated, as an RFC 9745 date.",
+			Schema:      &Schema{Type: "string"},
+		},
+		"Sunset": {
+			Description: "When the route will be removed, as an HTTP date.",
+			Schema:      &Schema{Type: "string"},
+		},
+		"Link": {
+			Description: "The successor-version of the route.",
+			Schema:      &Schema{Type: "string"},
+		},
+	}
+}
+
+func toOpenAPIPath(path string) string {
+	return ginParamPattern.ReplaceAllString(path, "{$1}")
+}
+
+func routeKey(method, path string) string {
+	return method + " " + path
+}
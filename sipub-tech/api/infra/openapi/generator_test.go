@@ -0,0 +1,236 @@
+package openapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/openapi"
+)
+
+type widget struct {
+	Name    string   `json:"name" binding:"required,max=10"`
+	Count   int      `json:"count" binding:"min=1"`
+	Tags    []string `json:"tags,omitempty"`
+	Child   *widget  `json:"child,omitempty"`
+	private string
+}
+
+func TestGenerator(t *testing.T) {
+	routes := []openapi.Route{
+		{
+			Method:      http.MethodGet,
+			Path:        "/widgets/:id",
+			OperationID: "get_widget",
+			Responses: []openapi.RouteResponse{
+				{Status: http.StatusOK, Body: widget{}},
+				{Status: http.StatusNotFound, Body: struct{ Detail string `json:"detail"` }{}, Problem: true},
+			},
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/widgets/",
+			OperationID: "create_widget",
+			RequestBody: widget{},
+			Responses:   []openapi.RouteResponse{{Status: http.StatusNoContent}},
+		},
+	}
+	registered := gin.RoutesInfo{
+		{Method: http.MethodGet, Path: "/v1/widgets/:id"},
+		{Method: http.MethodPost, Path: "/v1/widgets/"},
+	}
+
+	t.Run("should generate the document of the registered routes", func(t *testing.T) {
+		generator := openapi.NewGenerator(openapi.Info{Title: "Widgets", Version: "v1"}, []openapi.Mount{
+			{Prefix: "/v1", Name: "v1", Routes: routes},
+		})
+		document, err := generator.Generate(registered)
+
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, openapi.Version, document.OpenAPI)
+
+		operation := document.Paths["/v1/widgets/{id}"]["get"]
+		if assert.NotNil(t, operation) {
+			assert.Equal(t, "v1_get_widget", operation.OperationID)
+			assert.Equal(t, "Not Found", operation.Responses["404"].Description)
+			assert.Contains(t, operation.Responses["404"].Content, openapi.ProblemContentType)
+			assert.Equal(t, "#/components/schemas/widget", operation.Responses["200"].Content[openapi.JSONContentType].Schema.Ref)
+		}
+		assert.Empty(t, document.Paths["/v1/widgets/"]["post"].Responses["204"].Content)
+
+		schema := document.Components.Schemas["widget"]
+		if assert.NotNil(t, schema) {
+			assert.ElementsMatch(t, []string{"name"}, schema.Required)
+			assert.Equal(t, 10, *schema.Properties["name"].MaxLength)
+			assert.Equal(t, 1, *schema.Properties["count"].Minimum)
+			assert.Equal(t, "array", schema.Properties["tags"].Type)
+			assert.Equal(t, "#/components/schemas/widget", schema.Properties["child"].Ref)
+			assert.NotContains(t, schema.Properties, "private")
+		}
+	})
+
+	t.Run("should add the deprecation headers to deprecated mounts", func(t *testing.T) {
+		generator := openapi.NewGenerator(openapi.Info{}, []openapi.Mount{
+			{Prefix: "/v1", Name: "v1", Deprecated: true, Routes: routes},
+		})
+		document, err := generator.Generate(registered)
+
+		if !assert.NoError(t, err) {
+			return
+		}
+		operation := document.Paths["/v1/widgets/{id}"]["get"]
+		assert.True(t, operation.Deprecated)
+		assert.Contains(t, operation.Responses["200"].Headers, "Sunset")
+	})
+
+	t.Run("should merge the documented response headers with the deprecation ones", func(t *testing.T) {
+		headed := []openapi.Route{{
+			Method: http.MethodGet,
+			Path:   "/widgets/:id",
+			Responses: []openapi.RouteResponse{{
+				Status:  http.StatusOK,
+				Headers: map[string]*openapi.Header{"ETag": {Schema: &openapi.Schema{Type: "string"}}},
+			}},
+		}}
+		generator := openapi.NewGenerator(openapi.Info{}, []openapi.Mount{
+			{Prefix: "/v1", Name: "v1", Routes: headed},
+			{Prefix: "", Name: "legacy", Deprecated: true, Routes: headed},
+		})
+		document, err := generator.Generate(gin.RoutesInfo{
+			{Method: http.MethodGet, Path: "/v1/widgets/:id"},
+			{Method: http.MethodGet, Path: "/widgets/:id"},
+		})
+
+		if !assert.NoError(t, err) {
+			return
+		}
+		current := document.Paths["/v1/widgets/{id}"]["get"].Responses["200"].Headers
+		assert.Contains(t, current, "ETag")
+		assert.NotContains(t, current, "Sunset")
+
+		legacy := document.Paths["/widgets/{id}"]["get"].Responses["200"].Headers
+		assert.Contains(t, legacy, "ETag")
+		assert.Contains(t, legacy, "Sunset")
+		assert.Len(t, headed[0].Responses[0].Headers, 1)
+	})
+
+	t.Run("should serve the bodies with their own content type", func(t *testing.T) {
+		streamed := []openapi.Route{{
+			Method: http.MethodGet,
+			Path:   "/widgets/events",
+			Responses: []openapi.RouteResponse{
+				{Status: http.StatusOK, Body: widget{}, ContentType: "text/event-stream"},
+			},
+		}}
+		generator := openapi.NewGenerator(openapi.Info{}, []openapi.Mount{{Prefix: "/v1", Name: "v1", Routes: streamed}})
+		document, err := generator.Generate(gin.RoutesInfo{{Method: http.MethodGet, Path: "/v1/widgets/events"}})
+
+		if !assert.NoError(t, err) {
+			return
+		}
+		content := document.Paths["/v1/widgets/events"]["get"].Responses["200"].Content
+		assert.Contains(t, content, "text/event-stream")
+		assert.NotContains(t, content, openapi.JSONContentType)
+	})
+
+	t.Run("should require the security schemes of the authenticated routes", func(t *testing.T) {
+		secured := []openapi.Route{
+			routes[0],
+			{
+				Method:    http.MethodGet,
+				Path:      "/widgets/export",
+				Security:  []string{"token"},
+				Responses: []openapi.RouteResponse{{Status: http.StatusOK}},
+			},
+		}
+		generator := openapi.NewGenerator(openapi.Info{}, []openapi.Mount{{Prefix: "/v1", Name: "v1", Routes: secured}})
+		generator.AddSecurityScheme("token", openapi.SecurityScheme{Type: "http", Scheme: "bearer"})
+		document, err := generator.Generate(gin.RoutesInfo{
+			{Method: http.MethodGet, Path: "/v1/widgets/:id"},
+			{Method: http.MethodGet, Path: "/v1/widgets/export"},
+		})
+
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "bearer", document.Components.SecuritySchemes["token"].Scheme)
+		assert.Equal(t, []openapi.SecurityRequirement{{"token": {}}}, document.Paths["/v1/widgets/export"]["get"].Security)
+		assert.Nil(t, document.Paths["/v1/widgets/{id}"]["get"].Security)
+	})
+
+	t.Run("should apply custom binding rules", func(t *testing.T) {
+		type item struct {
+			Code string `json:"code" binding:"required,code"`
+		}
+		generator := openapi.NewGenerator(openapi.Info{}, []openapi.Mount{{Routes: []openapi.Route{
+			{Method: http.MethodPost, Path: "/items", RequestBody: item{}},
+		}}})
+		generator.AddBindingRule("code", func(schema *openapi.Schema, _ string) {
+			schema.Pattern = "^[A-Z]+$"
+		})
+		document, err := generator.Generate(gin.RoutesInfo{{Method: http.MethodPost, Path: "/items"}})
+
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "^[A-Z]+$", document.Components.Schemas["item"].Properties["code"].Pattern)
+	})
+
+	t.Run("should apply the rules after dive to the items of lists", func(t *testing.T) {
+		type item struct {
+			Codes []string `json:"codes" binding:"omitempty,max=3,dive,code"`
+		}
+		generator := openapi.NewGenerator(openapi.Info{}, []openapi.Mount{{Routes: []openapi.Route{
+			{Method: http.MethodPost, Path: "/items", RequestBody: item{}},
+		}}})
+		generator.AddBindingRule("code", func(schema *openapi.Schema, _ string) {
+			schema.Pattern = "^[A-Z]+$"
+		})
+		document, err := generator.Generate(gin.RoutesInfo{{Method: http.MethodPost, Path: "/items"}})
+
+		if !assert.NoError(t, err) {
+			return
+		}
+		codes := document.Components.Schemas["item"].Properties["codes"]
+		assert.Empty(t, codes.Pattern)
+		assert.Equal(t, "^[A-Z]+$", codes.Items.Pattern)
+	})
+
+	t.Run("should fail when a registered route is not documented", func(t *testing.T) {
+		generator := openapi.NewGenerator(openapi.Info{}, []openapi.Mount{
+			{Prefix: "/v1", Name: "v1", Routes: routes},
+		})
+		_, err := generator.Generate(append(registered, gin.RouteInfo{Method: http.MethodDelete, Path: "/v1/widgets/:id"}))
+
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "DELETE /v1/widgets/:id")
+		}
+	})
+
+	t.Run("should fail when a documented route is not registered", func(t *testing.T) {
+		generator := openapi.NewGenerator(openapi.Info{}, []openapi.Mount{
+			{Prefix: "/v1", Name: "v1", Routes: routes},
+		})
+		_, err := generator.Generate(registered[:1])
+
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "POST /v1/widgets/")
+		}
+	})
+
+	t.Run("should skip the ignored routes", func(t *testing.T) {
+		generator := openapi.NewGenerator(openapi.Info{}, []openapi.Mount{
+			{Prefix: "/v1", Name: "v1", Routes: routes},
+		})
+		generator.Ignore(http.MethodGet, "/docs")
+		document, err := generator.Generate(append(registered, gin.RouteInfo{Method: http.MethodGet, Path: "/docs"}))
+
+		if assert.NoError(t, err) {
+			assert.NotContains(t, document.Paths, "/docs")
+		}
+	})
+}
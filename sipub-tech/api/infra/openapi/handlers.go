@@ -0,0 +1,55 @@
+package openapi
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	swaggerUIVersion = "5.17.14"
+)
+
+var (
+	swaggerUITemplate = template.Must(template.New("swagger-ui").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>{{.Title}}</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@{{.Version}}/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@{{.Version}}/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = () => {
+      window.ui = SwaggerUIBundle({url: "{{.SpecURL}}", dom_id: "#swagger-ui"});
+    };
+  </script>
+</body>
+</html>
+`))
+)
+
+func DocumentHandler(document *Document) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, document)
+	}
+}
+
+// Serves a Swagger UI page rendering the document served at specURL.
+func SwaggerUIHandler(title, specURL string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Header("Content-Type", "text/html; charset=utf-8")
+		ctx.Status(http.StatusOK)
+		if err := swaggerUITemplate.Execute(ctx.Writer, map[string]string{
+			"Title":   title,
+			"Version": swaggerUIVersion,
+			"SpecURL": specURL,
+		}); err != nil {
+			_ = ctx.Error(fmt.Errorf("failed rendering swagger ui: %w", err))
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	componentsPrefix = "#/components/schemas/"
+)
+
+// Applies the constraint of a gin binding tag (e.g. max=255) to the schema
+// of the field.
+type BindingRule func(schema *Schema, param string)
+
+var (
+	DefaultBindingRules = map[string]BindingRule{
+		"max": func(schema *Schema, param string) {
+			applyLimit(schema, param, &schema.MaxLength, &schema.Maximum)
+		},
+		"min": func(schema *Schema, param string) {
+			applyLimit(schema, param, &schema.MinLength, &schema.Minimum)
+		},
+		"gte": func(schema *Schema, param string) {
+			applyLimit(schema, param, &schema.MinLength, &schema.Minimum)
+		},
+		"lte": func(schema *Schema, param string) {
+			applyLimit(schema, param, &schema.MaxLength, &schema.Maximum)
+		},
+	}
+
+	timeType = reflect.TypeFor[time.Time]()
+)
+
+// Builds the schemas of Go types, registering named structs as components
+// and referencing them.
+type schemaRegistry struct {
+	rules   map[string]BindingRule
+	schemas map[string]*Schema
+}
+
+func newSchemaRegistry(rules map[string]BindingRule) *schemaRegistry {
+	return &schemaRegistry{
+		rules:   rules,
+		schemas: map[string]*Schema{},
+	}
+}
+
+func (registry *schemaRegistry) schemaOf(value any) *Schema {
+	return registry.schemaOfType(reflect.TypeOf(value))
+}
+
+func (registry *schemaRegistry) schemaOfType(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: registry.schemaOfType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: registry.schemaOfType(t.Elem())}
+	case reflect.Struct:
+		return registry.structSchema(t)
+	default:
+		return &Schema{}
+	}
+}
+
+func (registry *schemaRegistry) structSchema(t reflect.Type) *Schema {
+	name := t.Name()
+	if name != "" {
+		if _, exists := registry.schemas[name]; exists {
+			return &Schema{Ref: componentsPrefix + name}
+		}
+		// Registered before the fields so recursive types end on a reference.
+		registry.schemas[name] = &Schema{}
+	}
+
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for index := range t.NumField() {
+		field := t.Field(index)
+		if !field.IsExported() {
+			continue
+		}
+
+		jsonName, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if jsonName == "-" {
+			continue
+		}
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+
+		fieldSchema := registry.schemaOfType(field.Type)
+		required := !strings.Contains(options, "omitempty")
+		if binding, ok := field.Tag.Lookup("binding"); ok {
+			required = registry.applyBinding(fieldSchema, binding)
+		}
+
+		schema.Properties[jsonName] = fieldSchema
+		if required {
+			schema.Required = append(schema.Required, jsonName)
+		}
+	}
+
+	if name == "" {
+		return schema
+	}
+	registry.schemas[name] = schema
+	return &Schema{Ref: componentsPrefix + name}
+}
+
+// Applies the rules of the binding tag to the schema, returning if the
+// field is required. The rules after dive apply to the items of the list.
+func (registry *schemaRegistry) applyBinding(schema *Schema, binding string) (required bool) {
+	for rule := range strings.SplitSeq(binding, ",") {
+		tag, param, _ := strings.Cut(rule, "=")
+		if tag == "required" {
+			required = true
+			continue
+		}
+		if tag == "dive" && schema.Items != nil {
+			schema = schema.Items
+			continue
+		}
+		if apply, ok := registry.rules[tag]; ok {
+			apply(schema, param)
+		}
+	}
+	return
+}
+
+func applyLimit(schema *Schema, param string, length, value **int) {
+	limit, err := strconv.Atoi(param)
+	if err != nil {
+		return
+	}
+	switch schema.Type {
+	case "string":
+		*length = &limit
+	case "integer", "number":
+		*value = &limit
+	}
+}
@@ -0,0 +1,123 @@
+package operations
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+)
+
+const (
+	DefaultCapacity = 1000
+)
+
+type trackedOperation struct {
+	operation dtos.OperationResponseDTO
+	results   map[int]dtos.OperationItemResultDTO
+}
+
+// Keeps the last operations, up to the capacity, forgetting the oldest
+// ones. Every gateway receives the results of all the operations, so any
+// of them answers an operation started by another, as long as it was
+// running by then. A store shared by the gateways can be used by
+// implementing ports.OperationService.
+func NewMemoryStore(capacity int) *MemoryStore {
+	return &MemoryStore{
+		operations: map[dtos.OperationId]*trackedOperation{},
+		capacity:   capacity,
+	}
+}
+
+type MemoryStore struct {
+	ports.OperationService
+
+	mutex      sync.RWMutex
+	operations map[dtos.OperationId]*trackedOperation
+	order      []dtos.OperationId
+	capacity   int
+}
+
+func (store *MemoryStore) Start(ctx context.Context, operationType dtos.OperationType, total int) (dtos.OperationResponseDTO, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	tracked := store.track(dtos.OperationId(uuid.New().String()), operationType, total)
+	return tracked.operation, nil
+}
+
+func (store *MemoryStore) GetOne(ctx context.Context, id dtos.OperationId) (dtos.OperationResponseDTO, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+	tracked, ok := store.operations[id]
+	if !ok {
+		return dtos.OperationResponseDTO{}, ports.ErrOperationNotFound
+	}
+
+	operation := tracked.operation
+	operation.Results = make([]dtos.OperationItemResultDTO, 0, len(tracked.results))
+	for _, result := range tracked.results {
+		operation.Results = append(operation.Results, result)
+	}
+	slices.SortFunc(operation.Results, func(a, b dtos.OperationItemResultDTO) int {
+		return a.Index - b.Index
+	})
+	return operation, nil
+}
+
+// The operations started by other gateways are tracked from their first
+// results. Results of items outside the operation, or already recorded
+// because their batch was redelivered, are ignored.
+func (store *MemoryStore) Record(ctx context.Context, results dtos.OperationResultsDTO) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	tracked, ok := store.operations[results.OperationID]
+	if !ok {
+		tracked = store.track(results.OperationID, results.Type, results.Total)
+	}
+
+	operation := &tracked.operation
+	for _, result := range results.Results {
+		if _, recorded := tracked.results[result.Index]; recorded || result.Index < 0 || result.Index >= operation.Total {
+			continue
+		}
+		tracked.results[result.Index] = result
+		switch result.Status {
+		case dtos.OperationItemCreated, dtos.OperationItemDeleted:
+			operation.Succeeded++
+		default:
+			operation.Failed++
+		}
+	}
+	if operation.Status == dtos.OperationPending && len(tracked.results) == operation.Total {
+		operation.Status = dtos.OperationCompleted
+		operation.CompletedAt = time.Now().UnixMilli()
+	}
+	return nil
+}
+
+// Must be called with the lock held.
+func (store *MemoryStore) track(id dtos.OperationId, operationType dtos.OperationType, total int) *trackedOperation {
+	if len(store.order) > 0 && len(store.order) >= store.capacity {
+		delete(store.operations, store.order[0])
+		store.order = store.order[1:]
+	}
+
+	tracked := &trackedOperation{
+		operation: dtos.OperationResponseDTO{
+			ID:        id,
+			Type:      operationType,
+			Status:    dtos.OperationPending,
+			Total:     total,
+			Results:   []dtos.OperationItemResultDTO{},
+			CreatedAt: time.Now().UnixMilli(),
+		},
+		results: map[int]dtos.OperationItemResultDTO{},
+	}
+	store.operations[id] = tracked
+	store.order = append(store.order, id)
+	return tracked
+}
@@ -0,0 +1,93 @@
+package operations_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/operations"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should complete the operation once every item has a result", func(t *testing.T) {
+		store := operations.NewMemoryStore(10)
+		started, err := store.Start(ctx, dtos.BulkDeleteMovies, 3)
+		require.NoError(t, err)
+		assert.Equal(t, dtos.OperationPending, started.Status)
+
+		require.NoError(t, store.Record(ctx, dtos.OperationResultsDTO{
+			OperationID: started.ID,
+			Results: []dtos.OperationItemResultDTO{
+				{Index: 2, MovieID: 5, Status: dtos.OperationItemNotFound},
+				{Index: 0, MovieID: 3, Status: dtos.OperationItemDeleted},
+			},
+		}))
+		pending, err := store.GetOne(ctx, started.ID)
+		require.NoError(t, err)
+		assert.Equal(t, dtos.OperationPending, pending.Status)
+		assert.Equal(t, []int{0, 2}, []int{pending.Results[0].Index, pending.Results[1].Index})
+
+		require.NoError(t, store.Record(ctx, dtos.OperationResultsDTO{
+			OperationID: started.ID,
+			Results: []dtos.OperationItemResultDTO{{Index: 1, MovieID: 4, Status: dtos.OperationItemDeleted}},
+		}))
+		completed, err := store.GetOne(ctx, started.ID)
+		require.NoError(t, err)
+		assert.Equal(t, dtos.OperationCompleted, completed.Status)
+		assert.Equal(t, 2, completed.Succeeded)
+		assert.Equal(t, 1, completed.Failed)
+		assert.NotZero(t, completed.CompletedAt)
+	})
+
+	t.Run("should ignore redelivered results and items outside the operation", func(t *testing.T) {
+		store := operations.NewMemoryStore(10)
+		started, _ := store.Start(ctx, dtos.BulkCreateMovies, 2)
+		results := dtos.OperationResultsDTO{
+			OperationID: started.ID,
+			Results: []dtos.OperationItemResultDTO{
+				{Index: 0, MovieID: 1, Status: dtos.OperationItemCreated},
+				{Index: 2, MovieID: 2, Status: dtos.OperationItemCreated},
+			},
+		}
+		require.NoError(t, store.Record(ctx, results))
+		require.NoError(t, store.Record(ctx, results))
+
+		operation, _ := store.GetOne(ctx, started.ID)
+		assert.Equal(t, 1, operation.Succeeded)
+		assert.Len(t, operation.Results, 1)
+		assert.Equal(t, dtos.OperationPending, operation.Status)
+	})
+
+	t.Run("should track the operations started by other gateways from their results", func(t *testing.T) {
+		store := operations.NewMemoryStore(10)
+		require.NoError(t, store.Record(ctx, dtos.OperationResultsDTO{
+			OperationID: "elsewhere",
+			Type: dtos.BulkCreateMovies,
+			Total: 1,
+			Results: []dtos.OperationItemResultDTO{{Index: 0, Status: dtos.OperationItemFailed, Error: "invalid"}},
+		}))
+
+		operation, err := store.GetOne(ctx, "elsewhere")
+		require.NoError(t, err)
+		assert.Equal(t, dtos.BulkCreateMovies, operation.Type)
+		assert.Equal(t, dtos.OperationCompleted, operation.Status)
+	})
+
+	t.Run("should forget the oldest operations beyond the capacity", func(t *testing.T) {
+		store := operations.NewMemoryStore(2)
+		first, _ := store.Start(ctx, dtos.BulkCreateMovies, 1)
+		store.Start(ctx, dtos.BulkCreateMovies, 1)
+		last, _ := store.Start(ctx, dtos.BulkCreateMovies, 1)
+
+		_, err := store.GetOne(ctx, first.ID)
+		assert.ErrorIs(t, err, ports.ErrOperationNotFound)
+		_, err = store.GetOne(ctx, last.ID)
+		assert.NoError(t, err)
+	})
+}
@@ -3,14 +3,56 @@ package ports
 import (
 	"github.com/gin-gonic/gin"
 
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
 )
 
 type MovieController interface {
 	GetMovieHandler(usecase ports.GetMovieCase) gin.HandlerFunc
 	GetMoviesHandler(usecase ports.GetMoviesCase) gin.HandlerFunc
+	BatchGetMoviesHandler(usecase ports.BatchGetMoviesCase) gin.HandlerFunc
 	SaveMovieHandler(usecase ports.SaveMovieCase) gin.HandlerFunc
+	UpdateMovieHandler(usecase ports.UpdateMovieCase) gin.HandlerFunc
 	DeleteMovieHandler(usecase ports.DeleteMovieCase) gin.HandlerFunc
+	StreamMovieEventsHandler(usecase ports.StreamMovieEventsCase) gin.HandlerFunc
 }
 
+type ExportController interface {
+	ExportMoviesHandler(usecase ports.ExportMoviesCase) gin.HandlerFunc
+}
+
+type MovieStreamController interface {
+	StreamMoviesHandler(usecase ports.StreamMoviesCase) gin.HandlerFunc
+}
+
+type MoviePresenter interface {
+	PresentMovie(movie *dtos.MovieResponseDTO) any
+	PresentMovies(movies dtos.MoviesResponseDTO, query dtos.MoviesQueryDTO) any
+	PresentMovieBatch(movies dtos.MoviesBatchResponseDTO) any
+	PresentMovieEvent(event dtos.MovieEventDTO) any
+}
+
+type WebhookController interface {
+	CreateWebhookHandler(usecase ports.CreateWebhookCase) gin.HandlerFunc
+	GetWebhooksHandler(usecase ports.GetWebhooksCase) gin.HandlerFunc
+	GetWebhookHandler(usecase ports.GetWebhookCase) gin.HandlerFunc
+	UpdateWebhookHandler(usecase ports.UpdateWebhookCase) gin.HandlerFunc
+	DeleteWebhookHandler(usecase ports.DeleteWebhookCase) gin.HandlerFunc
+	GetWebhookDeliveriesHandler(usecase ports.GetWebhookDeliveriesCase) gin.HandlerFunc
+}
+
+type WebhookPresenter interface {
+	PresentWebhook(webhook *dtos.WebhookResponseDTO) any
+	PresentWebhooks(webhooks []dtos.WebhookResponseDTO) any
+	PresentWebhookDeliveries(deliveries dtos.WebhookDeliveriesResponseDTO, query dtos.WebhookDeliveriesQueryDTO) any
+}
 
+type OperationController interface {
+	BulkSaveMoviesHandler(usecase ports.BulkSaveMoviesCase) gin.HandlerFunc
+	BulkDeleteMoviesHandler(usecase ports.BulkDeleteMoviesCase) gin.HandlerFunc
+	GetOperationHandler(usecase ports.GetOperationCase) gin.HandlerFunc
+}
+
+type OperationPresenter interface {
+	PresentOperation(operation *dtos.OperationResponseDTO) any
+}
@@ -0,0 +1,67 @@
+package presenters
+
+import (
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	infraDtos "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/dtos"
+	infraPorts "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/ports"
+)
+
+func NewV1MoviePresenter() *V1MoviePresenter {
+	return &V1MoviePresenter{}
+}
+
+// Maps the movies to the v1 bodies: a JSONResponse for one movie, a
+// PaginatedJSONResponse for many and a BatchJSONResponse for the ones
+// fetched by their ids.
+// A new API version with different bodies should add its own presenter
+// and reuse the controller and the usecases.
+type V1MoviePresenter struct {
+	infraPorts.MoviePresenter
+}
+
+func (presenter *V1MoviePresenter) PresentMovie(movie *dtos.MovieResponseDTO) any {
+	return infraDtos.NewJSONResponse(movie)
+}
+
+func (presenter *V1MoviePresenter) PresentMovies(movies dtos.MoviesResponseDTO, query dtos.MoviesQueryDTO) any {
+	items := make([]infraDtos.JsonData, len(movies.Movies))
+	for index, movie := range movies.Movies {
+		items[index] = movie
+	}
+	return infraDtos.NewPaginatedResponse(items, query.Limit, movies.Cursor)
+}
+
+func (presenter *V1MoviePresenter) PresentMovieBatch(movies dtos.MoviesBatchResponseDTO) any {
+	items := make([]infraDtos.JsonData, len(movies.Movies))
+	for index, movie := range movies.Movies {
+		items[index] = movie
+	}
+	missing := movies.MissingIDs
+	if missing == nil {
+		missing = []dtos.MovieId{}
+	}
+	return infraDtos.NewBatchResponse(items, missing)
+}
+
+// The events are streamed as they are published by the movies service.
+func (presenter *V1MoviePresenter) PresentMovieEvent(event dtos.MovieEventDTO) any {
+	return event
+}
+
+// Documents the body returned by V1MoviePresenter.PresentMovie.
+type V1MovieBody struct {
+	Data dtos.MovieResponseDTO  `json:"data"`
+}
+
+// Documents the body returned by V1MoviePresenter.PresentMovies.
+type V1MoviesPage struct {
+	Data   []dtos.MovieResponseDTO  `json:"data"`
+	Limit  int                      `json:"limit"`
+	Cursor int                      `json:"cursor"`
+}
+
+// Documents the body returned by V1MoviePresenter.PresentMovieBatch.
+type V1MoviesBatch struct {
+	Data       []dtos.MovieResponseDTO  `json:"data"`
+	MissingIDs []dtos.MovieId           `json:"missing_ids"`
+}
@@ -0,0 +1,96 @@
+package presenters_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"testing/quick"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/presenters"
+)
+
+func TestV1MoviePresenter(t *testing.T) {
+	presenter := presenters.NewV1MoviePresenter()
+
+	t.Run("the movie body must match the documented V1MovieBody", func(t *testing.T) {
+		assertion := func(movie dtos.MovieResponseDTO) bool {
+			var body presenters.V1MovieBody
+			if err := strictRoundTrip(presenter.PresentMovie(&movie), &body); err != nil {
+				t.Logf("Body does not match the documentation: %v", err)
+				return false
+			}
+			// The change date is only sent as the Last-Modified header.
+			movie.UpdatedAt = 0
+			return body.Data == movie
+		}
+		if err := quick.Check(assertion, nil); err != nil {
+			t.Errorf("Failed checking assertion: %v", err)
+		}
+	})
+
+	t.Run("the movies body must match the documented V1MoviesPage", func(t *testing.T) {
+		assertion := func(movies []dtos.MovieResponseDTO, query dtos.MoviesQueryDTO, cursor int) bool {
+			pointers := make([]*dtos.MovieResponseDTO, len(movies))
+			for index := range movies {
+				pointers[index] = &movies[index]
+			}
+
+			var body presenters.V1MoviesPage
+			response := dtos.MoviesResponseDTO{Movies: pointers, Cursor: cursor}
+			if err := strictRoundTrip(presenter.PresentMovies(response, query), &body); err != nil {
+				t.Logf("Body does not match the documentation: %v", err)
+				return false
+			}
+			return len(body.Data) == len(movies) && body.Limit == query.Limit && body.Cursor == cursor
+		}
+		if err := quick.Check(assertion, nil); err != nil {
+			t.Errorf("Failed checking assertion: %v", err)
+		}
+	})
+
+	t.Run("the batch body must match the documented V1MoviesBatch", func(t *testing.T) {
+		assertion := func(movies []dtos.MovieResponseDTO, missing []dtos.MovieId) bool {
+			pointers := make([]*dtos.MovieResponseDTO, len(movies))
+			for index := range movies {
+				pointers[index] = &movies[index]
+			}
+
+			var body presenters.V1MoviesBatch
+			response := dtos.MoviesBatchResponseDTO{Movies: pointers, MissingIDs: missing}
+			if err := strictRoundTrip(presenter.PresentMovieBatch(response), &body); err != nil {
+				t.Logf("Body does not match the documentation: %v", err)
+				return false
+			}
+			return len(body.Data) == len(movies) && len(body.MissingIDs) == len(missing) && body.MissingIDs != nil
+		}
+		if err := quick.Check(assertion, nil); err != nil {
+			t.Errorf("Failed checking assertion: %v", err)
+		}
+	})
+
+	t.Run("the event body must match the documented MovieEventDTO", func(t *testing.T) {
+		assertion := func(event dtos.MovieEventDTO) bool {
+			var body dtos.MovieEventDTO
+			if err := strictRoundTrip(presenter.PresentMovieEvent(event), &body); err != nil {
+				t.Logf("Body does not match the documentation: %v", err)
+				return false
+			}
+			event.Movie.UpdatedAt = 0
+			return body == event
+		}
+		if err := quick.Check(assertion, nil); err != nil {
+			t.Errorf("Failed checking assertion: %v", err)
+		}
+	})
+}
+
+func strictRoundTrip(value any, target any) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(target)
+}
@@ -0,0 +1,25 @@
+package presenters
+
+import (
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	infraDtos "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/dtos"
+	infraPorts "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/ports"
+)
+
+func NewV1OperationPresenter() *V1OperationPresenter {
+	return &V1OperationPresenter{}
+}
+
+// Maps the operations to a v1 JSONResponse.
+type V1OperationPresenter struct {
+	infraPorts.OperationPresenter
+}
+
+func (presenter *V1OperationPresenter) PresentOperation(operation *dtos.OperationResponseDTO) any {
+	return infraDtos.NewJSONResponse(operation)
+}
+
+// Documents the body returned by V1OperationPresenter.PresentOperation.
+type V1OperationBody struct {
+	Data dtos.OperationResponseDTO  `json:"data"`
+}
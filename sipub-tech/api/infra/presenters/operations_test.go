@@ -0,0 +1,40 @@
+package presenters_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/presenters"
+)
+
+func TestV1OperationPresenter(t *testing.T) {
+	presenter := presenters.NewV1OperationPresenter()
+
+	t.Run("the operation body must match the documented V1OperationBody", func(t *testing.T) {
+		operations := []dtos.OperationResponseDTO{
+			{
+				ID: "9b2f0c36-8f0e-4a54-a1f5-3c8c5b0f6d21",
+				Type: dtos.BulkCreateMovies,
+				Status: dtos.OperationCompleted,
+				Total: 2,
+				Succeeded: 1,
+				Failed: 1,
+				Results: []dtos.OperationItemResultDTO{
+					{Index: 0, MovieID: 14, Status: dtos.OperationItemCreated},
+					{Index: 1, Status: dtos.OperationItemFailed, Error: "the movie could not be saved"},
+				},
+				CreatedAt: 1760868000000,
+				CompletedAt: 1760868000001,
+			},
+			{ID: "pending", Type: dtos.BulkDeleteMovies, Status: dtos.OperationPending, Total: 3, Results: []dtos.OperationItemResultDTO{}},
+		}
+		for _, operation := range operations {
+			var body presenters.V1OperationBody
+			if assert.NoError(t, strictRoundTrip(presenter.PresentOperation(&operation), &body)) {
+				assert.Equal(t, operation, body.Data)
+			}
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package presenters
+
+import (
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	infraDtos "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/dtos"
+	infraPorts "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/ports"
+)
+
+func NewV1WebhookPresenter() *V1WebhookPresenter {
+	return &V1WebhookPresenter{}
+}
+
+// Maps the webhooks to the v1 bodies: a JSONResponse for one webhook, a
+// ListJSONResponse for all of them and a PaginatedJSONResponse for the
+// deliveries.
+type V1WebhookPresenter struct {
+	infraPorts.WebhookPresenter
+}
+
+func (presenter *V1WebhookPresenter) PresentWebhook(webhook *dtos.WebhookResponseDTO) any {
+	return infraDtos.NewJSONResponse(webhook)
+}
+
+func (presenter *V1WebhookPresenter) PresentWebhooks(webhooks []dtos.WebhookResponseDTO) any {
+	items := make([]infraDtos.JsonData, len(webhooks))
+	for index := range webhooks {
+		items[index] = &webhooks[index]
+	}
+	return infraDtos.NewListResponse(items)
+}
+
+func (presenter *V1WebhookPresenter) PresentWebhookDeliveries(
+	deliveries dtos.WebhookDeliveriesResponseDTO, query dtos.WebhookDeliveriesQueryDTO,
+) any {
+	items := make([]infraDtos.JsonData, len(deliveries.Deliveries))
+	for index, delivery := range deliveries.Deliveries {
+		items[index] = delivery
+	}
+	return infraDtos.NewPaginatedResponse(items, query.Limit, deliveries.Cursor)
+}
+
+// Documents the body returned by V1WebhookPresenter.PresentWebhook.
+type V1WebhookBody struct {
+	Data dtos.WebhookResponseDTO  `json:"data"`
+}
+
+// Documents the body returned by V1WebhookPresenter.PresentWebhooks.
+type V1WebhooksList struct {
+	Data []dtos.WebhookResponseDTO  `json:"data"`
+}
+
+// Documents the body returned by V1WebhookPresenter.PresentWebhookDeliveries.
+type V1WebhookDeliveriesPage struct {
+	Data   []dtos.WebhookDeliveryDTO  `json:"data"`
+	Limit  int                        `json:"limit"`
+	Cursor int                        `json:"cursor"`
+}
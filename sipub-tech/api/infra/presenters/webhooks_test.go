@@ -0,0 +1,61 @@
+package presenters_test
+
+import (
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/presenters"
+)
+
+func TestV1WebhookPresenter(t *testing.T) {
+	presenter := presenters.NewV1WebhookPresenter()
+	webhook := dtos.WebhookResponseDTO{
+		ID: "4c0f5e0a-7a43-4d5e-9d0c-1f0a3e1d2b7c",
+		URL: "https://partner.example/hooks",
+		Events: []dtos.MovieEventType{dtos.MovieCreated},
+		Year: "1995",
+		DisabledReason: "disabled after 5 events failed in a row",
+		ConsecutiveFailures: 5,
+		Secret: "secret",
+		CreatedAt: 1760868000000,
+		UpdatedAt: 1760868000001,
+	}
+
+	t.Run("the webhook body must match the documented V1WebhookBody", func(t *testing.T) {
+		var body presenters.V1WebhookBody
+		if assert.NoError(t, strictRoundTrip(presenter.PresentWebhook(&webhook), &body)) {
+			assert.Equal(t, webhook, body.Data)
+		}
+	})
+
+	t.Run("the webhooks body must match the documented V1WebhooksList", func(t *testing.T) {
+		var body presenters.V1WebhooksList
+		webhooks := []dtos.WebhookResponseDTO{webhook, {ID: "other", Events: []dtos.MovieEventType{}}}
+		if assert.NoError(t, strictRoundTrip(presenter.PresentWebhooks(webhooks), &body)) {
+			assert.Equal(t, webhooks, body.Data)
+		}
+	})
+
+	t.Run("the deliveries body must match the documented V1WebhookDeliveriesPage", func(t *testing.T) {
+		assertion := func(deliveries []dtos.WebhookDeliveryDTO, query dtos.WebhookDeliveriesQueryDTO, cursor int) bool {
+			pointers := make([]*dtos.WebhookDeliveryDTO, len(deliveries))
+			for index := range deliveries {
+				pointers[index] = &deliveries[index]
+			}
+
+			var body presenters.V1WebhookDeliveriesPage
+			response := dtos.WebhookDeliveriesResponseDTO{Deliveries: pointers, Cursor: cursor}
+			if err := strictRoundTrip(presenter.PresentWebhookDeliveries(response, query), &body); err != nil {
+				t.Logf("Body does not match the documentation: %v", err)
+				return false
+			}
+			return len(body.Data) == len(deliveries) && body.Limit == query.Limit && body.Cursor == cursor
+		}
+		if err := quick.Check(assertion, nil); err != nil {
+			t.Errorf("Failed checking assertion: %v", err)
+		}
+	})
+}
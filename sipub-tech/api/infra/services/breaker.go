@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+)
+
+type BreakerState string
+
+const (
+	// The calls go through, counting the consecutive failures.
+	BreakerClosed BreakerState = "closed"
+	// The calls fail right away, until the open timeout passes.
+	BreakerOpen BreakerState = "open"
+	// A single call goes through, closing the breaker if it succeeds.
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+type BreakerConfig struct {
+	// How many consecutive calls fail before the breaker opens.
+	FailureThreshold int
+	// How long the breaker stays open before letting a call probe the
+	// service.
+	OpenTimeout time.Duration
+}
+
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		FailureThreshold: 5,
+		OpenTimeout: 10 * time.Second,
+	}
+}
+
+// Stops calling the movie service once it fails every call, answering
+// ports.UnavailableError right away instead of waiting for each call to
+// time out. Only the failures of the service count, as Unavailable and
+// DeadlineExceeded, while any answer, even NotFound, closes it again.
+func NewCircuitBreaker(config BreakerConfig) *CircuitBreaker {
+	return &CircuitBreaker{
+		config: config,
+		state: BreakerClosed,
+		now: time.Now,
+	}
+}
+
+type CircuitBreaker struct {
+	config BreakerConfig
+	now    func() time.Time
+
+	mutex    sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+	rejected int
+}
+
+type BreakerStats struct {
+	State    BreakerState `json:"state"`
+	Failures int          `json:"failures"`
+	Rejected int          `json:"rejected"`
+}
+
+func (breaker *CircuitBreaker) Stats() BreakerStats {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+	return BreakerStats{State: breaker.state, Failures: breaker.failures, Rejected: breaker.rejected}
+}
+
+func (breaker *CircuitBreaker) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context, method string, req, reply any, conn *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption,
+	) error {
+		if err := breaker.allow(); err != nil {
+			return err
+		}
+		err := invoker(ctx, method, req, reply, conn, opts...)
+		breaker.record(err)
+		return err
+	}
+}
+
+// Records the stream by its first failure, or once it ends. A stream
+// abandoned before it ends is recorded once its context is done, so an
+// abandoned probe does not keep the breaker half-open forever.
+func (breaker *CircuitBreaker) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context, desc *grpc.StreamDesc, conn *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		if err := breaker.allow(); err != nil {
+			return nil, err
+		}
+		stream, err := streamer(ctx, desc, conn, method, opts...)
+		if err != nil {
+			breaker.record(err)
+			return nil, err
+		}
+		wrapped := &breakerStream{ClientStream: stream, breaker: breaker}
+		wrapped.stop = context.AfterFunc(ctx, func() {
+			wrapped.recorded.Do(func() { breaker.abandon(ctx.Err()) })
+		})
+		return wrapped, nil
+	}
+}
+
+type breakerStream struct {
+	grpc.ClientStream
+
+	breaker  *CircuitBreaker
+	recorded sync.Once
+	stop     func() bool
+}
+
+func (stream *breakerStream) RecvMsg(message any) error {
+	err := stream.ClientStream.RecvMsg(message)
+	if errors.Is(err, io.EOF) {
+		stream.recorded.Do(func() { stream.breaker.record(nil) })
+		stream.stop()
+	} else if err != nil {
+		stream.recorded.Do(func() { stream.breaker.record(err) })
+		stream.stop()
+	}
+	return err
+}
+
+// Fails when the breaker is open, or half-open with a probe in flight.
+func (breaker *CircuitBreaker) allow() error {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	if breaker.state == BreakerOpen {
+		remaining := breaker.config.OpenTimeout - breaker.now().Sub(breaker.openedAt)
+		if remaining > 0 {
+			breaker.rejected++
+			return &ports.UnavailableError{RetryAfter: remaining, Reason: "the movies service is failing every call"}
+		}
+		breaker.state = BreakerHalfOpen
+	}
+	if breaker.state == BreakerHalfOpen {
+		if breaker.probing {
+			breaker.rejected++
+			return &ports.UnavailableError{RetryAfter: breaker.config.OpenTimeout, Reason: "the movies service is being probed"}
+		}
+		breaker.probing = true
+	}
+	return nil
+}
+
+// Records a stream whose context is done before it ended, as the call
+// would have ended with its context.
+func (breaker *CircuitBreaker) abandon(err error) {
+	breaker.record(status.FromContextError(err).Err())
+}
+
+// Running out of time is a failure of the service, while a call canceled
+// by its caller says nothing of it, only letting another call probe the
+// service.
+func (breaker *CircuitBreaker) record(err error) {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	if breaker.state == BreakerHalfOpen {
+		breaker.probing = false
+	}
+	failed := false
+	switch status.Code(err) {
+	case codes.Canceled:
+		return
+	case codes.Unavailable, codes.DeadlineExceeded:
+		failed = true
+	}
+	if !failed {
+		breaker.state = BreakerClosed
+		breaker.failures = 0
+		return
+	}
+	breaker.failures++
+	if breaker.state == BreakerHalfOpen || breaker.failures >= breaker.config.FailureThreshold {
+		breaker.state = BreakerOpen
+		breaker.openedAt = breaker.now()
+	}
+}
@@ -0,0 +1,133 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/services"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	const openTimeout = 50 * time.Millisecond
+	breaker := services.NewCircuitBreaker(services.BreakerConfig{FailureThreshold: 3, OpenTimeout: openTimeout})
+	intercept := breaker.UnaryClientInterceptor()
+
+	calls := 0
+	call := func(err error) error {
+		return intercept(
+			context.Background(), "/movies.MovieService/GetMovie", nil, nil, nil,
+			func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+				calls++
+				return err
+			},
+		)
+	}
+	unavailable := status.Error(codes.Unavailable, "down")
+
+	t.Run("should stay closed for the answers of the service", func(t *testing.T) {
+		call(unavailable)
+		call(unavailable)
+		call(status.Error(codes.NotFound, "no movie"))
+		call(unavailable)
+		call(unavailable)
+		assert.Equal(t, services.BreakerClosed, breaker.Stats().State)
+	})
+
+	t.Run("should open after the failures in a row", func(t *testing.T) {
+		call(status.Error(codes.DeadlineExceeded, "slow"))
+		assert.Equal(t, services.BreakerOpen, breaker.Stats().State)
+
+		calls = 0
+		err := call(nil)
+		var unavailableErr *ports.UnavailableError
+		assert.ErrorAs(t, err, &unavailableErr)
+		assert.ErrorIs(t, err, ports.ErrServiceUnavailable)
+		assert.LessOrEqual(t, unavailableErr.RetryAfter, openTimeout)
+		assert.Equal(t, 0, calls)
+		assert.Equal(t, 1, breaker.Stats().Rejected)
+	})
+
+	t.Run("should open again when the probe fails", func(t *testing.T) {
+		time.Sleep(openTimeout)
+		assert.ErrorIs(t, call(unavailable), unavailable)
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, services.BreakerOpen, breaker.Stats().State)
+	})
+
+	t.Run("should close when the probe succeeds", func(t *testing.T) {
+		time.Sleep(openTimeout)
+		assert.NoError(t, call(nil))
+		assert.Equal(t, services.BreakerClosed, breaker.Stats().State)
+		assert.Equal(t, 0, breaker.Stats().Failures)
+	})
+
+	t.Run("should let another call probe the service once the probe is canceled", func(t *testing.T) {
+		canceled := status.Error(codes.Canceled, "context canceled")
+		call(unavailable)
+		call(unavailable)
+		call(canceled)
+		assert.Equal(t, 2, breaker.Stats().Failures, "a canceled call should not forget the failures")
+		call(unavailable)
+		assert.Equal(t, services.BreakerOpen, breaker.Stats().State)
+
+		time.Sleep(openTimeout)
+		call(canceled)
+		assert.Equal(t, services.BreakerHalfOpen, breaker.Stats().State)
+
+		calls = 0
+		assert.NoError(t, call(nil))
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, services.BreakerClosed, breaker.Stats().State)
+	})
+}
+
+func TestCircuitBreakerStream(t *testing.T) {
+	const openTimeout = 50 * time.Millisecond
+	breaker := services.NewCircuitBreaker(services.BreakerConfig{FailureThreshold: 1, OpenTimeout: openTimeout})
+	intercept := breaker.StreamClientInterceptor()
+
+	open := func(ctx context.Context) (grpc.ClientStream, error) {
+		return intercept(
+			ctx, &grpc.StreamDesc{ServerStreams: true}, nil, "/movies.MovieService/StreamMovies",
+			func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+				return &FakeClientStream{}, nil
+			},
+		)
+	}
+
+	t.Run("should let another call probe the service once the probe stream is abandoned", func(t *testing.T) {
+		breaker.UnaryClientInterceptor()(
+			context.Background(), "/movies.MovieService/GetMovie", nil, nil, nil,
+			func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+				return status.Error(codes.Unavailable, "down")
+			},
+		)
+		assert.Equal(t, services.BreakerOpen, breaker.Stats().State)
+		time.Sleep(openTimeout)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		_, err := open(ctx)
+		assert.NoError(t, err)
+		_, err = open(context.Background())
+		assert.ErrorIs(t, err, ports.ErrServiceUnavailable, "a second probe went through")
+
+		cancel()
+
+		assert.Eventually(t, func() bool {
+			_, err := open(context.Background())
+			return err == nil
+		}, time.Second, 5*time.Millisecond)
+		assert.Equal(t, services.BreakerHalfOpen, breaker.Stats().State)
+	})
+}
+
+type FakeClientStream struct {
+	grpc.ClientStream
+}
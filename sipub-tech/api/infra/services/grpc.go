@@ -2,20 +2,82 @@ package services
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"encoding/json"
 	"fmt"
+	"io"
+	"time"
 
 	pb "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/grpc/movies"
-	pb_exceptions "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/grpc/exceptions"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
 )
 
 
+// How the gateway calls the movie service, the reads retried and both
+// reads and writes bounded, spread over the replicas resolved from the
+// address and guarded by a circuit breaker.
+type GRPCClientConfig struct {
+	// The policy spreading the calls, as round_robin over the addresses
+	// of a headless service, or pick_first.
+	LoadBalancing string
+	// The deadline of the reads and of the writes sent without a shorter
+	// one. The streams are only bounded by their request.
+	ReadTimeout time.Duration
+	WriteTimeout time.Duration
+	// How many times a read answered with Unavailable is attempted, the
+	// writes never retried as they are not idempotent.
+	RetryMaxAttempts int
+	RetryInitialBackoff time.Duration
+	RetryMaxBackoff time.Duration
+	Breaker BreakerConfig
+	// Calls over TLS, presenting the certificate of the client when the
+	// service verifies them, or in plaintext when nil.
+	TLS *tls.Config
+}
+
+func DefaultGRPCClientConfig() GRPCClientConfig {
+	return GRPCClientConfig{
+		LoadBalancing: "round_robin",
+		ReadTimeout: 5 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		RetryMaxAttempts: 3,
+		RetryInitialBackoff: 100 * time.Millisecond,
+		RetryMaxBackoff: time.Second,
+		Breaker: DefaultBreakerConfig(),
+	}
+}
+
 func NewMovieGRPCService(serverUrl string) *MovieGRPCService {
-	conn, err := grpc.NewClient(serverUrl, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	return NewMovieGRPCServiceWithConfig(serverUrl, DefaultGRPCClientConfig())
+}
+
+func NewMovieGRPCServiceWithConfig(serverUrl string, config GRPCClientConfig) *MovieGRPCService {
+	serviceConfig, err := config.serviceConfig()
+	if err != nil {
+		panic(fmt.Sprintf("invalid grpc service config: %v", err))
+	}
+	breaker := NewCircuitBreaker(config.Breaker)
+	transportCredentials := insecure.NewCredentials()
+	if config.TLS != nil {
+		transportCredentials = credentials.NewTLS(config.TLS)
+	}
+
+	conn, err := grpc.NewClient(
+		serverUrl,
+		grpc.WithTransportCredentials(transportCredentials),
+		grpc.WithDefaultServiceConfig(serviceConfig),
+		// The breaker sees each call once, after its retries.
+		grpc.WithChainUnaryInterceptor(breaker.UnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(breaker.StreamClientInterceptor()),
+	)
 	if err != nil {
 		panic("could not connect with grpc server.")
 	}
@@ -25,6 +87,7 @@ func NewMovieGRPCService(serverUrl string) *MovieGRPCService {
 	return &MovieGRPCService{
 		client: movieClient,
 		conn: conn,
+		breaker: breaker,
 	}
 }
 
@@ -33,6 +96,11 @@ type MovieGRPCService struct {
 
 	client pb.MovieServiceClient
 	conn *grpc.ClientConn
+	breaker *CircuitBreaker
+}
+
+func (service *MovieGRPCService) BreakerStats() BreakerStats {
+	return service.breaker.Stats()
 }
 
 func (service *MovieGRPCService) Close() {
@@ -44,15 +112,10 @@ func (service *MovieGRPCService) Close() {
 func (service *MovieGRPCService) GetOne(ctx context.Context, id dtos.MovieId) (dtos.MovieResponseDTO, error) {
 	response, err := service.client.GetMovie(
 		ctx,
-		&pb.GetMovieRequest{Id: int32(id)},
+		&pb.GetMovieRequest{Id: int64(id)},
 	)
 	if err != nil {
-		empty := dtos.MovieResponseDTO{} 
-		if err == pb_exceptions.ErrMovieNotFound {
-			return empty, ports.ErrMovieNotFound
-		}
-
-		return empty, fmt.Errorf("failed getting movie: %w", err)
+		return dtos.MovieResponseDTO{}, fmt.Errorf("failed getting movie: %w", translateError(err))
 	}
 	
 	return service.parseMovieResponse(response), nil
@@ -66,11 +129,11 @@ func (service *MovieGRPCService) GetAll(ctx context.Context, query dtos.MoviesQu
 		&pb.GetMoviesRequest{
 			Year:   query.Year,
 			Limit:  int32(query.Limit),
-			Cursor: int32(query.Cursor),
+			Cursor: int64(query.Cursor),
 		},
 	)
 	if err != nil {
-		return movies, fmt.Errorf("failed getting movies: %w", err)
+		return movies, fmt.Errorf("failed getting movies: %w", translateError(err))
 	}
 
 	movies = dtos.MoviesResponseDTO{
@@ -81,12 +144,122 @@ func (service *MovieGRPCService) GetAll(ctx context.Context, query dtos.MoviesQu
 	return movies, nil
 }
 
+func (service *MovieGRPCService) GetMany(ctx context.Context, ids []dtos.MovieId) (dtos.MoviesBatchResponseDTO, error) {
+	var movies dtos.MoviesBatchResponseDTO
+
+	request := &pb.BatchGetMoviesRequest{Ids: make([]int64, len(ids))}
+	for index, id := range ids {
+		request.Ids[index] = int64(id)
+	}
+	response, err := service.client.BatchGetMovies(ctx, request)
+	if err != nil {
+		return movies, fmt.Errorf("failed getting movies in batch: %w", translateError(err))
+	}
+
+	movies.Movies = service.parseMovieResponseArray(response.Movies)
+	movies.MissingIDs = make([]dtos.MovieId, len(response.MissingIds))
+	for index, id := range response.MissingIds {
+		movies.MissingIDs[index] = dtos.MovieId(id)
+	}
+	return movies, nil
+}
+
+func (service *MovieGRPCService) Export(ctx context.Context, emit func(movie dtos.MovieResponseDTO) error) error {
+	// The stream is canceled along with ctx when emit fails.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := service.client.ExportMovies(ctx, &pb.ExportMoviesRequest{})
+	if err != nil {
+		return fmt.Errorf("failed exporting movies: %w", translateError(err))
+	}
+	for {
+		movie, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed receiving exported movies: %w", translateError(err))
+		}
+		if err := emit(service.parseMovieResponse(movie)); err != nil {
+			return err
+		}
+	}
+}
+
+// The writes are applied by the movie service before it answers, the
+// synchronous alternative to MovieMessagingService, so their failures,
+// such as a version mismatch, are returned instead of only logged.
+
+func (service *MovieGRPCService) Save(ctx context.Context, movie dtos.CreateMovieDTO) error {
+	_, err := service.client.CreateMovie(ctx, &pb.CreateMovieRequest{Title: movie.Title, Year: movie.Year})
+	if err != nil {
+		return fmt.Errorf("failed saving movie %+v: %w", movie, translateError(err))
+	}
+	return nil
+}
+
+func (service *MovieGRPCService) Update(ctx context.Context, movie dtos.UpdateMovieDTO) error {
+	_, err := service.client.UpdateMovie(ctx, &pb.UpdateMovieRequest{
+		Id: int64(movie.ID),
+		Title: movie.Title,
+		Year: movie.Year,
+		ExpectedVersion: int64(movie.Version),
+	})
+	if err != nil {
+		return fmt.Errorf("failed updating movie %+v: %w", movie, translateError(err))
+	}
+	return nil
+}
+
+func (service *MovieGRPCService) Delete(ctx context.Context, id dtos.MovieId, expectedVersion int) error {
+	_, err := service.client.DeleteMovie(ctx, &pb.DeleteMovieRequest{Id: int64(id), ExpectedVersion: int64(expectedVersion)})
+	if err != nil {
+		return fmt.Errorf("failed deleting movie with id %d: %w", id, translateError(err))
+	}
+	return nil
+}
+
+// Streams the movies of the query, the movie service reading each page
+// once the previous one is received, so a slow emit holds it back.
+func (service *MovieGRPCService) Stream(
+	ctx context.Context, query dtos.MoviesQueryDTO, emit func(movie dtos.MovieResponseDTO) error,
+) error {
+	// The stream is canceled along with ctx when emit fails.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := service.client.StreamMovies(ctx, &pb.StreamMoviesRequest{
+		Year: query.Year,
+		Cursor: int64(query.Cursor),
+		Limit: int64(query.Limit),
+	})
+	if err != nil {
+		return fmt.Errorf("failed streaming movies: %w", translateError(err))
+	}
+	for {
+		movie, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed receiving streamed movies: %w", translateError(err))
+		}
+		if err := emit(service.parseMovieResponse(movie)); err != nil {
+			return err
+		}
+	}
+}
+
 func (service *MovieGRPCService) parseMovieResponse(movie *pb.Movie) dtos.MovieResponseDTO {
-	return dtos.MovieResponseDTO{
+	dto := dtos.MovieResponseDTO{
 		ID: int(movie.Id),
 		Title: movie.Title,
 		Year: movie.Year,
+		Version: int(movie.Version),
+	}
+	if movie.UpdatedAt != nil {
+		dto.UpdatedAt = movie.UpdatedAt.AsTime().UnixMilli()
 	}
+	return dto
 }
 
 func (service *MovieGRPCService) parseMovieResponseArray(movies []*pb.Movie) []*dtos.MovieResponseDTO {
@@ -98,3 +271,85 @@ func (service *MovieGRPCService) parseMovieResponseArray(movies []*pb.Movie) []*
 	return response
 }
 
+// Maps the status code returned by the movies service to the errors
+// declared in the ports, keeping the original status in the message.
+func translateError(err error) error {
+	var portError error
+	switch status.Code(err) {
+	case codes.NotFound:
+		portError = ports.ErrMovieNotFound
+	case codes.InvalidArgument:
+		portError = ports.ErrInvalidRequest
+	case codes.FailedPrecondition:
+		portError = ports.ErrPreconditionFailed
+	case codes.Unavailable:
+		portError = ports.ErrServiceUnavailable
+	case codes.DeadlineExceeded:
+		portError = ports.ErrServiceTimeout
+	default:
+		return err
+	}
+	return fmt.Errorf("%w: %v", portError, err)
+}
+
+// The gRPC service config of the movie service, as documented in
+// https://github.com/grpc/grpc/blob/master/doc/service_config.md.
+func (config GRPCClientConfig) serviceConfig() (string, error) {
+	type name struct {
+		Service string `json:"service"`
+		Method string  `json:"method"`
+	}
+	type retryPolicy struct {
+		MaxAttempts int              `json:"maxAttempts"`
+		InitialBackoff string        `json:"initialBackoff"`
+		MaxBackoff string            `json:"maxBackoff"`
+		BackoffMultiplier float64    `json:"backoffMultiplier"`
+		RetryableStatusCodes []string `json:"retryableStatusCodes"`
+	}
+	type methodConfig struct {
+		Name []name               `json:"name"`
+		Timeout string            `json:"timeout,omitempty"`
+		RetryPolicy *retryPolicy  `json:"retryPolicy,omitempty"`
+	}
+	methods := func(methods ...string) []name {
+		names := make([]name, len(methods))
+		for index, method := range methods {
+			names[index] = name{Service: pb.MovieService_ServiceDesc.ServiceName, Method: method}
+		}
+		return names
+	}
+
+	reads := methodConfig{
+		Name: methods("GetMovie", "GetMovies", "BatchGetMovies"),
+		Timeout: seconds(config.ReadTimeout),
+	}
+	// Retrying once is no retry, and the service config is refused for it.
+	if config.RetryMaxAttempts > 1 {
+		reads.RetryPolicy = &retryPolicy{
+			MaxAttempts: config.RetryMaxAttempts,
+			InitialBackoff: seconds(config.RetryInitialBackoff),
+			MaxBackoff: seconds(config.RetryMaxBackoff),
+			BackoffMultiplier: 2,
+			RetryableStatusCodes: []string{"UNAVAILABLE"},
+		}
+	}
+	writes := methodConfig{
+		Name: methods("CreateMovie", "UpdateMovie", "DeleteMovie"),
+		Timeout: seconds(config.WriteTimeout),
+	}
+
+	serviceConfig := map[string]any{"methodConfig": []methodConfig{reads, writes}}
+	if config.LoadBalancing != "" {
+		serviceConfig["loadBalancingConfig"] = []map[string]any{{config.LoadBalancing: map[string]any{}}}
+	}
+	encoded, err := json.Marshal(serviceConfig)
+	return string(encoded), err
+}
+
+// Formats duration as the service config does, left out when 0.
+func seconds(duration time.Duration) string {
+	if duration <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%gs", duration.Seconds())
+}
@@ -0,0 +1,231 @@
+package services_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/config/tlsconfig"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/config/tlsconfig/tlstest"
+	pb "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/grpc/movies"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/services"
+)
+
+func TestMovieGRPCServiceWrites(t *testing.T) {
+	server := &FakeMovieServer{}
+	service := serveMovies(t, server)
+
+	t.Run("should send the writes to the movie service", func(t *testing.T) {
+		require.NoError(t, service.Save(context.Background(), dtos.CreateMovieDTO{Title: "Fargo", Year: "1996"}))
+		assert.Equal(t, "Fargo", server.created.GetTitle())
+		assert.Equal(t, "1996", server.created.GetYear())
+
+		require.NoError(t, service.Update(context.Background(), dtos.UpdateMovieDTO{ID: 3, Title: "Heat", Year: "1995", Version: 2}))
+		assert.Equal(t, int64(3), server.updated.GetId())
+		assert.Equal(t, int64(2), server.updated.GetExpectedVersion())
+
+		require.NoError(t, service.Delete(context.Background(), 9, 4))
+		assert.Equal(t, int64(9), server.deleted.GetId())
+		assert.Equal(t, int64(4), server.deleted.GetExpectedVersion())
+	})
+
+	t.Run("should translate the statuses of the writes to the errors of the ports", func(t *testing.T) {
+		for code, expected := range map[codes.Code]error{
+			codes.NotFound:           ports.ErrMovieNotFound,
+			codes.FailedPrecondition: ports.ErrPreconditionFailed,
+			codes.InvalidArgument:    ports.ErrInvalidRequest,
+			codes.Unavailable:        ports.ErrServiceUnavailable,
+		} {
+			server.errorReturned = status.Error(code, "refused")
+
+			assert.ErrorIs(t, service.Save(context.Background(), dtos.CreateMovieDTO{}), expected, code.String())
+			assert.ErrorIs(t, service.Update(context.Background(), dtos.UpdateMovieDTO{ID: 3}), expected, code.String())
+			assert.ErrorIs(t, service.Delete(context.Background(), 3, 1), expected, code.String())
+		}
+	})
+}
+
+func TestMovieGRPCServiceResilience(t *testing.T) {
+	config := services.DefaultGRPCClientConfig()
+	config.ReadTimeout = 100 * time.Millisecond
+	config.RetryInitialBackoff = time.Millisecond
+	config.RetryMaxBackoff = time.Millisecond
+	config.Breaker = services.BreakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute}
+
+	t.Run("should retry the reads answered with Unavailable", func(t *testing.T) {
+		server := &FakeMovieServer{getErrors: []error{status.Error(codes.Unavailable, "restarting")}}
+		service := serveMoviesWithConfig(t, server, config)
+
+		movie, err := service.GetOne(context.Background(), 7)
+		require.NoError(t, err)
+		assert.Equal(t, 7, movie.ID)
+		assert.Equal(t, 2, server.getCalls)
+	})
+
+	t.Run("should not retry the writes", func(t *testing.T) {
+		server := &FakeMovieServer{errorReturned: status.Error(codes.Unavailable, "restarting")}
+		service := serveMoviesWithConfig(t, server, config)
+
+		assert.ErrorIs(t, service.Save(context.Background(), dtos.CreateMovieDTO{Title: "Fargo"}), ports.ErrServiceUnavailable)
+		assert.Equal(t, 1, server.createCalls)
+	})
+
+	t.Run("should time the reads out", func(t *testing.T) {
+		server := &FakeMovieServer{getBlocks: true}
+		service := serveMoviesWithConfig(t, server, config)
+
+		start := time.Now()
+		_, err := service.GetOne(context.Background(), 7)
+		assert.ErrorIs(t, err, ports.ErrServiceTimeout)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("should fail fast with a retry after once the breaker opens", func(t *testing.T) {
+		unavailable := status.Error(codes.Unavailable, "down")
+		server := &FakeMovieServer{getErrors: []error{unavailable, unavailable, unavailable, unavailable, unavailable, unavailable}}
+		service := serveMoviesWithConfig(t, server, config)
+
+		for range config.Breaker.FailureThreshold {
+			_, err := service.GetOne(context.Background(), 7)
+			assert.ErrorIs(t, err, ports.ErrServiceUnavailable)
+		}
+		calls := server.getCalls
+		assert.Equal(t, services.BreakerOpen, service.BreakerStats().State)
+
+		_, err := service.GetOne(context.Background(), 7)
+		var unavailableErr *ports.UnavailableError
+		require.ErrorAs(t, err, &unavailableErr)
+		assert.ErrorIs(t, err, ports.ErrServiceUnavailable)
+		assert.Greater(t, unavailableErr.RetryAfter, time.Duration(0))
+		assert.Equal(t, calls, server.getCalls, "the movie service was called with the breaker open")
+	})
+}
+
+func TestMovieGRPCServiceTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := tlstest.NewCA(t, "sipub")
+	caFile := ca.WriteCert(t, dir)
+	serverCert, serverKey := ca.Issue(t, dir, "movies", "movies-headless")
+	clientCert, clientKey := ca.Issue(t, dir, "api-gateway")
+
+	server, err := tlsconfig.NewReloader(tlsconfig.Files{CA: caFile, Cert: serverCert, Key: serverKey})
+	require.NoError(t, err)
+	serverTLS, err := server.ServerConfig()
+	require.NoError(t, err)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	grpcServer := grpc.NewServer(grpc.Creds(credentials.NewTLS(serverTLS)))
+	pb.RegisterMovieServiceServer(grpcServer, &FakeMovieServer{})
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	call := func(files tlsconfig.Files) error {
+		client, err := tlsconfig.NewReloader(files)
+		require.NoError(t, err)
+		config := services.DefaultGRPCClientConfig()
+		config.TLS = client.ClientConfig()
+		config.TLS.ServerName = "movies-headless"
+		service := services.NewMovieGRPCServiceWithConfig(listener.Addr().String(), config)
+		defer service.Close()
+		_, err = service.GetOne(context.Background(), 7)
+		return err
+	}
+
+	t.Run("should call the movie service over mTLS", func(t *testing.T) {
+		assert.NoError(t, call(tlsconfig.Files{CA: caFile, Cert: clientCert, Key: clientKey}))
+	})
+
+	t.Run("should be refused without a client certificate", func(t *testing.T) {
+		assert.ErrorIs(t, call(tlsconfig.Files{CA: caFile}), ports.ErrServiceUnavailable)
+	})
+
+	t.Run("should refuse a movie service of another CA", func(t *testing.T) {
+		other := tlstest.NewCA(t, "other")
+		assert.ErrorIs(t, call(tlsconfig.Files{CA: other.WriteCert(t, dir), Cert: clientCert, Key: clientKey}), ports.ErrServiceUnavailable)
+	})
+}
+
+// Serves server on a local port, answered by the service returned.
+func serveMovies(t *testing.T, server pb.MovieServiceServer) *services.MovieGRPCService {
+	return serveMoviesWithConfig(t, server, services.DefaultGRPCClientConfig())
+}
+
+func serveMoviesWithConfig(t *testing.T, server pb.MovieServiceServer, config services.GRPCClientConfig) *services.MovieGRPCService {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	grpcServer := grpc.NewServer()
+	pb.RegisterMovieServiceServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	service := services.NewMovieGRPCServiceWithConfig(listener.Addr().String(), config)
+	t.Cleanup(service.Close)
+	return service
+}
+
+// Records the writes, failing them with errorReturned when set, and
+// answers the reads with the movie of the id once getErrors are returned,
+// or only when canceled with getBlocks.
+type FakeMovieServer struct {
+	pb.UnimplementedMovieServiceServer
+
+	created       *pb.CreateMovieRequest
+	createCalls   int
+	updated       *pb.UpdateMovieRequest
+	deleted       *pb.DeleteMovieRequest
+	errorReturned error
+
+	getCalls  int
+	getErrors []error
+	getBlocks bool
+}
+
+func (server *FakeMovieServer) GetMovie(ctx context.Context, req *pb.GetMovieRequest) (*pb.Movie, error) {
+	server.getCalls++
+	if server.getBlocks {
+		<-ctx.Done()
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+	if len(server.getErrors) > 0 {
+		err := server.getErrors[0]
+		server.getErrors = server.getErrors[1:]
+		return nil, err
+	}
+	return &pb.Movie{Id: req.Id}, nil
+}
+
+func (server *FakeMovieServer) CreateMovie(ctx context.Context, req *pb.CreateMovieRequest) (*pb.Movie, error) {
+	server.created = req
+	server.createCalls++
+	if server.errorReturned != nil {
+		return nil, server.errorReturned
+	}
+	return &pb.Movie{Id: 1, Title: req.Title, Year: req.Year, Version: 1}, nil
+}
+
+func (server *FakeMovieServer) UpdateMovie(ctx context.Context, req *pb.UpdateMovieRequest) (*pb.Movie, error) {
+	server.updated = req
+	if server.errorReturned != nil {
+		return nil, server.errorReturned
+	}
+	return &pb.Movie{Id: req.Id, Title: req.Title, Year: req.Year, Version: req.ExpectedVersion + 1}, nil
+}
+
+func (server *FakeMovieServer) DeleteMovie(ctx context.Context, req *pb.DeleteMovieRequest) (*pb.DeleteMovieResponse, error) {
+	server.deleted = req
+	if server.errorReturned != nil {
+		return nil, server.errorReturned
+	}
+	return &pb.DeleteMovieResponse{Movie: &pb.Movie{Id: req.Id}}, nil
+}
@@ -2,9 +2,11 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	
 	"github.com/google/uuid"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/config/tlsconfig"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/messaging/constants"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/messaging/rabbitmq"
 
@@ -13,31 +15,69 @@ import (
 )
 
 func NewMovieMessagingService(connectionUrl string) *MovieMessagingService {
-	nodeId := uuid.New().String()
+	return newMovieMessagingService(rabbitmq.NewRabbitMqServer(connectionUrl, uuid.New().String()))
+}
+
+// Connects to RabbitMQ over amqps with files, instead of the CAs of the
+// system, failing when they cannot be loaded.
+func NewMovieMessagingServiceWithTLS(connectionUrl string, files tlsconfig.Files) (*MovieMessagingService, error) {
+	client := rabbitmq.NewRabbitMqServer(connectionUrl, uuid.New().String())
+	if err := client.UseTLS(files); err != nil {
+		return nil, err
+	}
+	return newMovieMessagingService(client), nil
+}
 
-	client := rabbitmq.NewRabbitMqServer(connectionUrl, nodeId)
+func newMovieMessagingService(client *rabbitmq.RabbitMqServer) *MovieMessagingService {
 	client.Open()
 
-	_, saver   := client.CreateProducer(constants.MovieCreatorQueueName, nil, nil)
-	_, deleter := client.CreateProducer(constants.MovieDeleterQueueName, nil, nil)
+	_, saver   := rabbitmq.CreateTypedProducer[dtos.CreateMovieDTO](client, constants.MovieCreatorQueueName, nil, nil)
+	_, updater := rabbitmq.CreateTypedProducer[dtos.UpdateMovieDTO](client, constants.MovieUpdaterQueueName, nil, nil)
+	_, deleter := rabbitmq.CreateTypedProducer[IdBody](client, constants.MovieDeleterQueueName, nil, nil)
+	_, bulkSaver   := rabbitmq.CreateTypedProducer[BulkCreateBody](client, constants.MovieBulkCreatorQueueName, nil, nil)
+	_, bulkDeleter := rabbitmq.CreateTypedProducer[BulkDeleteBody](client, constants.MovieBulkDeleterQueueName, nil, nil)
 	
 	return &MovieMessagingService{
 		client: client,
 		save:   saver,
+		update: updater,
 		delete: deleter,		
+		bulkSave:   bulkSaver,
+		bulkDelete: bulkDeleter,
 	}
 }
 
 type IdBody struct {
-	Id dtos.MovieId  `json:"id"`
+	Id      dtos.MovieId  `json:"id"`
+	Version int           `json:"version,omitempty"`
+}
+
+// A batch of the items of a bulk operation, the first being the item at
+// Offset of the operation.
+type BulkCreateBody struct {
+	OperationID dtos.OperationId       `json:"operation_id"`
+	Total       int                    `json:"total"`
+	Offset      int                    `json:"offset"`
+	Movies      []dtos.CreateMovieDTO  `json:"movies"`
+}
+
+type BulkDeleteBody struct {
+	OperationID dtos.OperationId  `json:"operation_id"`
+	Total       int               `json:"total"`
+	Offset      int               `json:"offset"`
+	IDs         []dtos.MovieId    `json:"ids"`
 }
 
 type MovieMessagingService struct {
 	ports.MovieExecutorService
+	ports.MovieBulkExecutorService
 
 	client *rabbitmq.RabbitMqServer
-	save   rabbitmq.ProducerFunction
-	delete rabbitmq.ProducerFunction
+	save   rabbitmq.TypedProducerFunction[dtos.CreateMovieDTO]
+	update rabbitmq.TypedProducerFunction[dtos.UpdateMovieDTO]
+	delete rabbitmq.TypedProducerFunction[IdBody]
+	bulkSave   rabbitmq.TypedProducerFunction[BulkCreateBody]
+	bulkDelete rabbitmq.TypedProducerFunction[BulkDeleteBody]
 }
 
 func (service *MovieMessagingService) Close() {
@@ -48,17 +88,124 @@ func (service *MovieMessagingService) GetClient() *rabbitmq.RabbitMqServer {
 	return service.client
 }
 
+type MovieEventHandler func(ctx context.Context, event dtos.MovieEventDTO) error
+
+// Calls the handlers with every event published by the movies service.
+// Each gateway gets all of them, through a queue of its own. The fields
+// the gateway does not know are ignored, so the movies service can add
+// them before the gateway is updated.
+func (service *MovieMessagingService) ListenMovieEvents(ctx context.Context, handlers ...MovieEventHandler) {
+	rabbitmq.RegisterTypedEventConsumer(
+		service.client,
+		constants.MovieEventsExchangeName,
+		rabbitmq.StandardExchangeConfig(rabbitmq.TopicExchange),
+		constants.AllMovieEventsBindingKey,
+		nil,
+		func(ctx context.Context, body movieEventBody) error {
+			event := body.toDTO()
+			var errs []error
+			for _, handler := range handlers {
+				errs = append(errs, handler(ctx, event))
+			}
+			return errors.Join(errs...)
+		},
+	)
+	service.client.Listen(ctx)
+}
+
+type OperationResultsHandler func(ctx context.Context, results dtos.OperationResultsDTO) error
+
+// Calls the handlers with the results of the items of every bulk
+// operation. Like the events, each gateway gets all of them, ignoring
+// the fields it does not know.
+func (service *MovieMessagingService) ListenOperationResults(ctx context.Context, handlers ...OperationResultsHandler) {
+	rabbitmq.RegisterTypedEventConsumer(
+		service.client,
+		constants.MovieOperationsExchangeName,
+		rabbitmq.StandardExchangeConfig(rabbitmq.TopicExchange),
+		constants.OperationResultsRoutingKey,
+		nil,
+		func(ctx context.Context, results dtos.OperationResultsDTO) error {
+			var errs []error
+			for _, handler := range handlers {
+				errs = append(errs, handler(ctx, results))
+			}
+			return errors.Join(errs...)
+		},
+	)
+	service.client.Listen(ctx)
+}
+
 func (service *MovieMessagingService) Save(ctx context.Context, movie dtos.CreateMovieDTO) error {
 	if err := service.save(ctx, movie); err != nil {
-		return fmt.Errorf("failed saving movie %+v: %w", movie, err)
+		return fmt.Errorf("failed saving movie %+v: %w: %v", movie, ports.ErrServiceUnavailable, err)
 	}
 	return nil
 }
 
-func (service *MovieMessagingService) Delete(ctx context.Context, id dtos.MovieId) error {
-	dto := IdBody{Id: id}
+func (service *MovieMessagingService) Update(ctx context.Context, movie dtos.UpdateMovieDTO) error {
+	if err := service.update(ctx, movie); err != nil {
+		return fmt.Errorf("failed updating movie %+v: %w: %v", movie, ports.ErrServiceUnavailable, err)
+	}
+	return nil
+}
+
+func (service *MovieMessagingService) Delete(ctx context.Context, id dtos.MovieId, expectedVersion int) error {
+	dto := IdBody{Id: id, Version: expectedVersion}
 	if err := service.delete(ctx, dto); err != nil {
-		return fmt.Errorf("failed deleting movie with id %d: %w", id, err)
+		return fmt.Errorf("failed deleting movie with id %d: %w: %v", id, ports.ErrServiceUnavailable, err)
+	}
+	return nil
+}
+
+func (service *MovieMessagingService) SaveBatch(
+	ctx context.Context, operation dtos.OperationId, total, offset int, movies []dtos.CreateMovieDTO,
+) error {
+	body := BulkCreateBody{OperationID: operation, Total: total, Offset: offset, Movies: movies}
+	if err := service.bulkSave(ctx, body); err != nil {
+		return fmt.Errorf(
+			"failed saving %d movies of operation %s: %w: %v", len(movies), operation, ports.ErrServiceUnavailable, err,
+		)
 	}
 	return nil
 }
+
+func (service *MovieMessagingService) DeleteBatch(
+	ctx context.Context, operation dtos.OperationId, total, offset int, ids []dtos.MovieId,
+) error {
+	body := BulkDeleteBody{OperationID: operation, Total: total, Offset: offset, IDs: ids}
+	if err := service.bulkDelete(ctx, body); err != nil {
+		return fmt.Errorf(
+			"failed deleting %d movies of operation %s: %w: %v", len(ids), operation, ports.ErrServiceUnavailable, err,
+		)
+	}
+	return nil
+}
+
+// An event as published by the movies service, whose movie carries the
+// time of the change that MovieResponseDTO does not encode.
+type movieEventBody struct {
+	EventID       string               `json:"event_id"`
+	Type          dtos.MovieEventType  `json:"type"`
+	OccurredAt    int64                `json:"occurred_at"`
+	CorrelationID string               `json:"correlation_id"`
+	CausationID   string               `json:"causation_id"`
+	Movie         struct {
+		ID        int     `json:"id"`
+		Title     string  `json:"title"`
+		Year      string  `json:"year"`
+		Version   int     `json:"version"`
+		UpdatedAt int64   `json:"updated_at"`
+	}                                  `json:"movie"`
+}
+
+func (body movieEventBody) toDTO() dtos.MovieEventDTO {
+	return dtos.MovieEventDTO{
+		EventID: body.EventID,
+		Type: body.Type,
+		OccurredAt: body.OccurredAt,
+		CorrelationID: body.CorrelationID,
+		CausationID: body.CausationID,
+		Movie: dtos.MovieResponseDTO(body.Movie),
+	}
+}
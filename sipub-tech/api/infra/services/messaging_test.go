@@ -17,6 +17,7 @@ import (
     "github.com/testcontainers/testcontainers-go"
     "github.com/testcontainers/testcontainers-go/wait"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/messaging/constants"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/messaging/rabbitmq"
 
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/services"
@@ -93,7 +94,7 @@ func TestMessagingEntrypoint(t *testing.T) {
 		client.Listen(ctx)
 
 		service.Save(ctx, createDto)
-		service.Delete(ctx, dtos.MovieId(id))
+		service.Delete(ctx, dtos.MovieId(id), 0)
 
 		messagesCount := 2
 
@@ -106,6 +107,90 @@ func TestMessagingEntrypoint(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("should pass the events published by the movies service to every handler.", func (t *testing.T) {
+		service := services.NewMovieMessagingService(connectionUrl)
+		defer service.Close()
+		received := make(chan dtos.MovieEventDTO, 2)
+		handler := func(ctx context.Context, event dtos.MovieEventDTO) error {
+			received <- event
+			return nil
+		}
+
+		service.ListenMovieEvents(ctx, handler, handler)
+
+		publisher := rabbitmq.NewRabbitMqServer(connectionUrl, "movies")
+		publisher.Open()
+		defer publisher.Close()
+		publish := publisher.CreateExchangeProducer(
+			constants.MovieEventsExchangeName, rabbitmq.StandardExchangeConfig(rabbitmq.TopicExchange), nil,
+		)
+
+		expected := dtos.MovieEventDTO{
+			EventID: faker.UUIDHyphenated(),
+			Type: dtos.MovieUpdated,
+			OccurredAt: time.Now().UnixMilli(),
+			Movie: dtos.MovieResponseDTO{ID: int(rand.Int32()), Title: faker.Sentence(), Year: faker.YearString(), Version: 2},
+		}
+		if err := publish(ctx, constants.MovieUpdatedRoutingKey, expected); err != nil {
+			t.Fatalf("Failed publishing event: %v", err)
+		}
+
+		for range 2 {
+			select {
+			case event := <- received:
+				assert.Equal(t, expected, event)
+			case <- time.After(1* time.Second):
+				t.Errorf("Did not reach handler")
+			}
+		}
+	})
+
+	t.Run("should pass the operation results to the handlers, along with the events.", func (t *testing.T) {
+		service := services.NewMovieMessagingService(connectionUrl)
+		defer service.Close()
+		events := make(chan dtos.MovieEventDTO, 2)
+		received := make(chan dtos.OperationResultsDTO, 2)
+
+		service.ListenMovieEvents(ctx, func(ctx context.Context, event dtos.MovieEventDTO) error {
+			events <- event
+			return nil
+		})
+		service.ListenOperationResults(ctx, func(ctx context.Context, results dtos.OperationResultsDTO) error {
+			received <- results
+			return nil
+		})
+
+		publisher := rabbitmq.NewRabbitMqServer(connectionUrl, "movies")
+		publisher.Open()
+		defer publisher.Close()
+		publish := publisher.CreateExchangeProducer(
+			constants.MovieOperationsExchangeName, rabbitmq.StandardExchangeConfig(rabbitmq.TopicExchange), nil,
+		)
+
+		expected := dtos.OperationResultsDTO{
+			OperationID: dtos.OperationId(faker.UUIDHyphenated()),
+			Type: dtos.BulkCreateMovies,
+			Total: 2,
+			Results: []dtos.OperationItemResultDTO{{Index: 1, MovieID: dtos.MovieId(rand.Int32()), Status: dtos.OperationItemCreated}},
+		}
+		if err := publish(ctx, constants.OperationResultsRoutingKey, expected); err != nil {
+			t.Fatalf("Failed publishing results: %v", err)
+		}
+
+		select {
+		case results := <- received:
+			assert.Equal(t, expected, results)
+		case <- time.After(1* time.Second):
+			t.Errorf("Did not reach handler")
+		}
+		select {
+		case <- received:
+			t.Errorf("The results were handled twice")
+		case <- time.After(100 * time.Millisecond):
+		}
+		assert.Empty(t, events)
+	})
 }
 
 func insertAuthInfo(endpoint, authInfo string) string {
@@ -0,0 +1,149 @@
+package streams
+
+import (
+	"context"
+	"sync"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+)
+
+const (
+	DefaultReplayCapacity   = 1_000
+	DefaultSubscriberBuffer = 64
+)
+
+// Fans the movie events out to the subscribers of the gateway, keeping
+// the last replayCapacity events so the subscribers can resume from the
+// last one they got.
+// Each subscriber may fall subscriberBuffer events behind before being
+// dropped.
+func NewMovieEventBroker(replayCapacity, subscriberBuffer int) *MovieEventBroker {
+	return &MovieEventBroker{
+		replayCapacity:   replayCapacity,
+		subscriberBuffer: subscriberBuffer,
+		buffered:         map[string]struct{}{},
+		subscribers:      map[*subscriber]struct{}{},
+	}
+}
+
+type MovieEventBroker struct {
+	ports.MovieEventSubscriberService
+
+	replayCapacity   int
+	subscriberBuffer int
+
+	mutex       sync.Mutex
+	replay      []dtos.MovieEventDTO
+	buffered    map[string]struct{}
+	subscribers map[*subscriber]struct{}
+}
+
+type subscriber struct {
+	year   string
+	events chan dtos.MovieEventDTO
+}
+
+func (sub *subscriber) wants(event dtos.MovieEventDTO) bool {
+	return sub.year == "" || sub.year == event.Movie.Year
+}
+
+// Sends the event to the subscribers and keeps it for the replays. Events
+// are delivered at least once, so the ones still buffered are ignored.
+// It is a services.MovieEventHandler.
+func (broker *MovieEventBroker) Publish(ctx context.Context, event dtos.MovieEventDTO) error {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	if _, seen := broker.buffered[event.EventID]; seen {
+		return nil
+	}
+	broker.buffer(event)
+
+	for sub := range broker.subscribers {
+		if !sub.wants(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			// Waiting would hold every other subscriber back.
+			broker.drop(sub)
+		}
+	}
+	return nil
+}
+
+// When the last event is no longer buffered, a MovieEventsReset event is
+// sent first instead of the replay.
+func (broker *MovieEventBroker) Subscribe(
+	ctx context.Context, query dtos.MovieEventsQueryDTO,
+) (<-chan dtos.MovieEventDTO, error) {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	sub := &subscriber{year: query.Year}
+	var replay []dtos.MovieEventDTO
+	if query.LastEventID != "" {
+		replay = broker.replayAfter(sub, query.LastEventID)
+	}
+
+	sub.events = make(chan dtos.MovieEventDTO, broker.subscriberBuffer + len(replay))
+	for _, event := range replay {
+		sub.events <- event
+	}
+	broker.subscribers[sub] = struct{}{}
+
+	go func() {
+		<-ctx.Done()
+		broker.mutex.Lock()
+		defer broker.mutex.Unlock()
+		broker.drop(sub)
+	}()
+
+	return sub.events, nil
+}
+
+// How many subscribers are listening.
+func (broker *MovieEventBroker) Subscribers() int {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	return len(broker.subscribers)
+}
+
+func (broker *MovieEventBroker) replayAfter(sub *subscriber, lastEventID string) []dtos.MovieEventDTO {
+	if _, ok := broker.buffered[lastEventID]; !ok {
+		return []dtos.MovieEventDTO{{Type: dtos.MovieEventsReset}}
+	}
+
+	var replay []dtos.MovieEventDTO
+	found := false
+	for _, event := range broker.replay {
+		if found && sub.wants(event) {
+			replay = append(replay, event)
+		}
+		found = found || event.EventID == lastEventID
+	}
+	return replay
+}
+
+func (broker *MovieEventBroker) buffer(event dtos.MovieEventDTO) {
+	if broker.replayCapacity <= 0 {
+		return
+	}
+	if len(broker.replay) == broker.replayCapacity {
+		delete(broker.buffered, broker.replay[0].EventID)
+		broker.replay = append(broker.replay[:0], broker.replay[1:]...)
+	}
+	broker.replay = append(broker.replay, event)
+	broker.buffered[event.EventID] = struct{}{}
+}
+
+// Closes the channel of the subscriber, if it is still subscribed.
+func (broker *MovieEventBroker) drop(sub *subscriber) {
+	if _, ok := broker.subscribers[sub]; !ok {
+		return
+	}
+	delete(broker.subscribers, sub)
+	close(sub.events)
+}
@@ -0,0 +1,130 @@
+package streams_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/streams"
+)
+
+func TestMovieEventBroker(t *testing.T) {
+	event := func(id int, year string) dtos.MovieEventDTO {
+		return dtos.MovieEventDTO{
+			EventID: fmt.Sprintf("event-%d", id),
+			Type:    dtos.MovieUpdated,
+			Movie:   dtos.MovieResponseDTO{ID: id, Year: year},
+		}
+	}
+
+	t.Run("should send the events to every subscriber of their year", func(t *testing.T) {
+		broker := streams.NewMovieEventBroker(10, 10)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		all, err := broker.Subscribe(ctx, dtos.MovieEventsQueryDTO{})
+		require.NoError(t, err)
+		of1995, err := broker.Subscribe(ctx, dtos.MovieEventsQueryDTO{Year: "1995"})
+		require.NoError(t, err)
+
+		broker.Publish(ctx, event(1, "1995"))
+		broker.Publish(ctx, event(2, "2006"))
+
+		assert.Equal(t, []dtos.MovieEventDTO{event(1, "1995"), event(2, "2006")}, drain(all))
+		assert.Equal(t, []dtos.MovieEventDTO{event(1, "1995")}, drain(of1995))
+	})
+
+	t.Run("should ignore the events delivered again", func(t *testing.T) {
+		broker := streams.NewMovieEventBroker(10, 10)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		events, _ := broker.Subscribe(ctx, dtos.MovieEventsQueryDTO{})
+		broker.Publish(ctx, event(1, "1995"))
+		broker.Publish(ctx, event(1, "1995"))
+
+		assert.Len(t, drain(events), 1)
+	})
+
+	t.Run("should replay the events after the last one, of the year only", func(t *testing.T) {
+		broker := streams.NewMovieEventBroker(10, 10)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		for id, year := range []string{"1995", "1995", "2006", "1995"} {
+			broker.Publish(ctx, event(id, year))
+		}
+
+		events, _ := broker.Subscribe(ctx, dtos.MovieEventsQueryDTO{Year: "1995", LastEventID: "event-0"})
+		broker.Publish(ctx, event(4, "1995"))
+
+		assert.Equal(t, []dtos.MovieEventDTO{event(1, "1995"), event(3, "1995"), event(4, "1995")}, drain(events))
+	})
+
+	t.Run("should send a reset when the last event is no longer buffered", func(t *testing.T) {
+		broker := streams.NewMovieEventBroker(2, 10)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		for id := range 3 {
+			broker.Publish(ctx, event(id, "1995"))
+		}
+
+		evicted, _ := broker.Subscribe(ctx, dtos.MovieEventsQueryDTO{LastEventID: "event-0"})
+		assert.Equal(t, []dtos.MovieEventDTO{{Type: dtos.MovieEventsReset}}, drain(evicted))
+
+		buffered, _ := broker.Subscribe(ctx, dtos.MovieEventsQueryDTO{LastEventID: "event-1"})
+		assert.Equal(t, []dtos.MovieEventDTO{event(2, "1995")}, drain(buffered))
+	})
+
+	t.Run("should drop the subscribers that fall behind", func(t *testing.T) {
+		broker := streams.NewMovieEventBroker(10, 1)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		slow, _ := broker.Subscribe(ctx, dtos.MovieEventsQueryDTO{})
+		broker.Publish(ctx, event(1, "1995"))
+		broker.Publish(ctx, event(2, "1995"))
+
+		assert.Equal(t, event(1, "1995"), <-slow)
+		_, open := <-slow
+		assert.False(t, open)
+		assert.Equal(t, 0, broker.Subscribers())
+	})
+
+	t.Run("should close the stream when the context is done", func(t *testing.T) {
+		broker := streams.NewMovieEventBroker(10, 10)
+		ctx, cancel := context.WithCancel(context.Background())
+
+		events, _ := broker.Subscribe(ctx, dtos.MovieEventsQueryDTO{})
+		assert.Equal(t, 1, broker.Subscribers())
+		cancel()
+
+		select {
+		case _, open := <-events:
+			assert.False(t, open)
+		case <-time.After(time.Second):
+			t.Error("Stream still open after the context was canceled")
+		}
+		assert.Equal(t, 0, broker.Subscribers())
+	})
+}
+
+// The events already sent to the channel.
+func drain(events <-chan dtos.MovieEventDTO) []dtos.MovieEventDTO {
+	var drained []dtos.MovieEventDTO
+	for {
+		select {
+		case event, open := <-events:
+			if !open {
+				return drained
+			}
+			drained = append(drained, event)
+		default:
+			return drained
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	infraDtos "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/webhooks"
+)
+
+const (
+	MovieYearTag = "movieyear"
+	MovieEventTypeTag = "movieeventtype"
+	PublicURLTag = "publicurl"
+)
+
+var (
+	registerOnce sync.Once
+
+	MovieEventTypes = []dtos.MovieEventType{dtos.MovieCreated, dtos.MovieUpdated, dtos.MovieDeleted}
+)
+
+// Registers the custom tags used by the DTOs in the validator gin uses
+// when binding requests, and makes the field errors report the json name
+// of the field instead of the struct one.
+// It is safe to call it more than once.
+func RegisterMovieValidators() {
+	registerOnce.Do(func() {
+		engine, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			panic("gin binding validator is not a go-playground validator")
+		}
+
+		engine.RegisterTagNameFunc(jsonFieldName)
+		if err := engine.RegisterValidation(MovieYearTag, validateMovieYear); err != nil {
+			panic(fmt.Sprintf("failed registering %q validation: %v", MovieYearTag, err))
+		}
+		if err := engine.RegisterValidation(MovieEventTypeTag, validateMovieEventType); err != nil {
+			panic(fmt.Sprintf("failed registering %q validation: %v", MovieEventTypeTag, err))
+		}
+		if err := engine.RegisterValidation(PublicURLTag, validatePublicURL); err != nil {
+			panic(fmt.Sprintf("failed registering %q validation: %v", PublicURLTag, err))
+		}
+	})
+}
+
+// Checks if the year is a 4-digit year between dtos.MinimumMovieYear and
+// the current year, the same range accepted by the year query.
+func IsValidMovieYear(year string) bool {
+	if len(year) != 4 {
+		return false
+	}
+	parsed, err := strconv.Atoi(year)
+	if err != nil {
+		return false
+	}
+	return parsed >= dtos.MinimumMovieYear && parsed <= time.Now().Year()
+}
+
+// Checks if the type is one of the events published by the movies
+// service, which webhooks can filter by.
+func IsValidMovieEventType(eventType dtos.MovieEventType) bool {
+	for _, valid := range MovieEventTypes {
+		if eventType == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// Checks if the url does not name the gateway or a host of its network,
+// either as localhost or as a loopback, link-local or private address.
+// The names are not resolved here, the addresses they resolve to being
+// refused when the deliveries connect.
+func IsPublicURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	if host == "" || host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return webhooks.IsPublicAddress(addr)
+	}
+	return true
+}
+
+// Converts the errors returned by the validator to field level errors,
+// named by their path in the body, such as movies[3].year.
+// It returns false if the error did not come from the validation.
+func FieldErrors(err error) ([]infraDtos.FieldError, bool) {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil, false
+	}
+
+	fields := make([]infraDtos.FieldError, len(validationErrors))
+	for index, fieldError := range validationErrors {
+		fields[index] = infraDtos.FieldError{
+			Field:   fieldPath(fieldError),
+			Message: describe(fieldError),
+		}
+	}
+	return fields, true
+}
+
+// The namespace of the error without the name of the validated struct.
+func fieldPath(fieldError validator.FieldError) string {
+	namespace := fieldError.Namespace()
+	if _, path, found := strings.Cut(namespace, "."); found {
+		return path
+	}
+	return fieldError.Field()
+}
+
+func lengthUnit(fieldError validator.FieldError) string {
+	switch fieldError.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "items"
+	default:
+		return "characters"
+	}
+}
+
+func validateMovieYear(field validator.FieldLevel) bool {
+	return IsValidMovieYear(field.Field().String())
+}
+
+func validateMovieEventType(field validator.FieldLevel) bool {
+	return IsValidMovieEventType(dtos.MovieEventType(field.Field().String()))
+}
+
+func validatePublicURL(field validator.FieldLevel) bool {
+	return IsPublicURL(field.Field().String())
+}
+
+func describe(fieldError validator.FieldError) string {
+	switch fieldError.Tag() {
+	case "required":
+		return "is required."
+	case "max":
+		return fmt.Sprintf("must have at most %s %s.", fieldError.Param(), lengthUnit(fieldError))
+	case "min":
+		return fmt.Sprintf("must have at least %s %s.", fieldError.Param(), lengthUnit(fieldError))
+	case "gt":
+		return fmt.Sprintf("must be greater than %s.", fieldError.Param())
+	case "http_url":
+		return "must be an http or https url."
+	case PublicURLTag:
+		return "must not point to a loopback, link-local or private address."
+	case MovieYearTag:
+		return fmt.Sprintf("must be a 4-digit year between %d and %d.", dtos.MinimumMovieYear, time.Now().Year())
+	case MovieEventTypeTag:
+		return fmt.Sprintf("must be one of %v.", MovieEventTypes)
+	default:
+		return fmt.Sprintf("failed on the %q validation.", fieldError.Tag())
+	}
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
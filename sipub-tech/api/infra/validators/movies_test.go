@@ -0,0 +1,58 @@
+package validators_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/validators"
+)
+
+func TestIsValidMovieYear(t *testing.T) {
+	currentYear := time.Now().Year()
+
+	t.Run("should accept 4-digit years between 1880 and the current year", func(t *testing.T) {
+		for year := 1880; year <= currentYear; year++ {
+			assert.True(t, validators.IsValidMovieYear(strconv.Itoa(year)), year)
+		}
+	})
+
+	t.Run("should refuse years out of range or malformed", func(t *testing.T) {
+		for _, year := range []string{"", "1879", strconv.Itoa(currentYear + 1), "01990", "199", "19a0", " 1990", "-990"} {
+			assert.False(t, validators.IsValidMovieYear(year), year)
+		}
+	})
+}
+
+func TestIsValidMovieEventType(t *testing.T) {
+	for _, eventType := range []dtos.MovieEventType{dtos.MovieCreated, dtos.MovieUpdated, dtos.MovieDeleted} {
+		assert.True(t, validators.IsValidMovieEventType(eventType), eventType)
+	}
+	for _, eventType := range []dtos.MovieEventType{"", dtos.MovieEventsReset, "moviecreated", "MovieRenamed"} {
+		assert.False(t, validators.IsValidMovieEventType(eventType), eventType)
+	}
+}
+
+func TestIsPublicURL(t *testing.T) {
+	for _, url := range []string{"https://partner.example/hooks", "http://8.8.8.8:8080/hooks", "https://[2001:4860:4860::8888]/"} {
+		assert.True(t, validators.IsPublicURL(url), url)
+	}
+	for _, url := range []string{
+		"http://localhost/hooks",
+		"http://api.LOCALHOST./hooks",
+		"http://127.0.0.1:8080/",
+		"http://10.0.0.5/",
+		"http://192.168.1.1/",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://[::1]/",
+		"http://[::ffff:127.0.0.1]/",
+		"http://[fd00::1]/",
+		"http://0.0.0.0/",
+		"http:///hooks",
+	} {
+		assert.False(t, validators.IsPublicURL(url), url)
+	}
+}
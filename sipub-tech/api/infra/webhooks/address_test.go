@@ -0,0 +1,35 @@
+package webhooks_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/webhooks"
+)
+
+func TestIsPublicAddress(t *testing.T) {
+	for _, addr := range []string{"8.8.8.8", "151.101.1.69", "2001:4860:4860::8888"} {
+		assert.True(t, webhooks.IsPublicAddress(netip.MustParseAddr(addr)), addr)
+	}
+	for _, addr := range []string{
+		"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.0.1", "169.254.169.254", "100.64.0.1",
+		"0.0.0.0", "0.1.2.3", "224.0.0.1", "::1", "::", "fe80::1", "fd00::1", "::ffff:10.0.0.1",
+	} {
+		assert.False(t, webhooks.IsPublicAddress(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	t.Run("should refuse to connect to a private address", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer server.Close()
+
+		_, err := webhooks.NewClient().Post(server.URL, "application/json", nil)
+
+		assert.ErrorIs(t, err, webhooks.ErrPrivateAddress)
+	})
+}
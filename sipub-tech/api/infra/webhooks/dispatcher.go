@@ -0,0 +1,289 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/config/backoff"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+)
+
+const (
+	UserAgent = "sipub-tech-webhooks/1.0"
+)
+
+var (
+	ErrDispatchQueueFull = fmt.Errorf("webhook dispatch queue is full")
+)
+
+type Config struct {
+	// How long the receiver has to answer each attempt.
+	Timeout time.Duration
+	// Attempts of each event, the first one included.
+	MaxAttempts int
+	// The wait after the first failed attempt, doubled on each retry up to
+	// MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff time.Duration
+	// Events failed in a row after which the webhook is disabled.
+	MaxConsecutiveFailures int
+	// Events waiting to be dispatched, and waiting for each webhook.
+	// Events beyond it are refused, or fail for that webhook.
+	QueueSize int
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Timeout: 10 * time.Second,
+		MaxAttempts: 5,
+		InitialBackoff: time.Second,
+		MaxBackoff: time.Minute,
+		MaxConsecutiveFailures: 5,
+		QueueSize: 1_000,
+	}
+}
+
+// How long to wait after the given number of failed attempts.
+func (config Config) Backoff(failures int) time.Duration {
+	return backoff.Exponential{Initial: config.InitialBackoff, Max: config.MaxBackoff}.Delay(failures)
+}
+
+// POSTs the movie events to the webhooks subscribed to them.
+// Each webhook has a worker of its own, sending its events in the order
+// they were dispatched, so a slow or failing receiver only delays its own
+// events. Every attempt is recorded in the store.
+func NewDispatcher(store Store, client *http.Client, config Config) *Dispatcher {
+	return &Dispatcher{
+		store: store,
+		client: client,
+		config: config,
+		events: make(chan dtos.MovieEventDTO, config.QueueSize),
+		queues: map[dtos.WebhookId]chan dtos.MovieEventDTO{},
+	}
+}
+
+type Dispatcher struct {
+	store  Store
+	client *http.Client
+	config Config
+
+	events chan dtos.MovieEventDTO
+	mutex  sync.Mutex
+	queues map[dtos.WebhookId]chan dtos.MovieEventDTO
+}
+
+// Queues the event to be sent by Run, without waiting for the receivers,
+// so it can be passed to ListenMovieEvents.
+func (dispatcher *Dispatcher) Dispatch(ctx context.Context, event dtos.MovieEventDTO) error {
+	select {
+	case dispatcher.events <- event:
+		return nil
+	default:
+		return fmt.Errorf("could not dispatch event %q: %w", event.EventID, ErrDispatchQueueFull)
+	}
+}
+
+// Sends the dispatched events until the context is done.
+func (dispatcher *Dispatcher) Run(ctx context.Context) {
+	var workers sync.WaitGroup
+	defer workers.Wait()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-dispatcher.events:
+			subscriptions, err := dispatcher.store.GetSubscriptions(ctx, event)
+			if err != nil {
+				log.Printf("Failed getting the webhooks of event %q: %v", event.EventID, err)
+				continue
+			}
+			for _, subscription := range subscriptions {
+				if dispatcher.claim(ctx, subscription.ID, event) {
+					dispatcher.enqueue(ctx, &workers, subscription.ID, event)
+				}
+			}
+		}
+	}
+}
+
+// Tells if this gateway sends the event to the webhook. When the claim
+// cannot be made, the event is sent anyway, as the receivers tell the
+// repeated events apart by their Webhook-Id.
+func (dispatcher *Dispatcher) claim(ctx context.Context, id dtos.WebhookId, event dtos.MovieEventDTO) bool {
+	claimed, err := dispatcher.store.Claim(ctx, id, event.EventID)
+	if err != nil {
+		log.Printf("Failed claiming event %q for webhook %q, sending it anyway: %v", event.EventID, id, err)
+		return true
+	}
+	return claimed
+}
+
+func (dispatcher *Dispatcher) enqueue(ctx context.Context, workers *sync.WaitGroup, id dtos.WebhookId, event dtos.MovieEventDTO) {
+	dispatcher.mutex.Lock()
+	queue, ok := dispatcher.queues[id]
+	if !ok {
+		queue = make(chan dtos.MovieEventDTO, dispatcher.config.QueueSize)
+		dispatcher.queues[id] = queue
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			dispatcher.work(ctx, id, queue)
+		}()
+	}
+	dispatcher.mutex.Unlock()
+
+	select {
+	case queue <- event:
+	default:
+		dispatcher.record(ctx, dtos.WebhookDeliveryDTO{
+			WebhookID: id,
+			EventID: event.EventID,
+			EventType: event.Type,
+			Status: dtos.WebhookDeliveryFailed,
+			Error: "dropped, as the webhook has too many events waiting",
+			DeliveredAt: time.Now().UnixMilli(),
+		})
+		dispatcher.fail(ctx, id)
+	}
+}
+
+// Sends the events of the webhook until the context is done, or the
+// webhook is deleted.
+func (dispatcher *Dispatcher) work(ctx context.Context, id dtos.WebhookId, queue chan dtos.MovieEventDTO) {
+	defer func() {
+		dispatcher.mutex.Lock()
+		delete(dispatcher.queues, id)
+		dispatcher.mutex.Unlock()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-queue:
+			if deleted := dispatcher.deliver(ctx, id, event); deleted {
+				return
+			}
+		}
+	}
+}
+
+// Attempts to send the event until the receiver accepts it or the
+// attempts run out. The subscription is read on every attempt, so the
+// changes to the webhook apply to the retries. Tells if the webhook was
+// deleted.
+func (dispatcher *Dispatcher) deliver(ctx context.Context, id dtos.WebhookId, event dtos.MovieEventDTO) bool {
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Failed encoding event %q for webhook %q: %v", event.EventID, id, err)
+		return false
+	}
+
+	for attempt := 1; ; attempt++ {
+		subscription, err := dispatcher.store.GetSubscription(ctx, id)
+		if errors.Is(err, ports.ErrWebhookNotFound) {
+			return true
+		}
+		if err != nil {
+			log.Printf("Failed getting webhook %q: %v", id, err)
+			return false
+		}
+		if !subscription.Enabled {
+			return false
+		}
+
+		delivery := dispatcher.post(ctx, subscription, event, body)
+		delivery.Attempt = attempt
+		dispatcher.record(ctx, delivery)
+
+		if delivery.Status == dtos.WebhookDeliverySucceeded {
+			if err := dispatcher.store.MarkDelivered(ctx, id); err != nil {
+				log.Printf("Failed marking event %q as delivered to webhook %q: %v", event.EventID, id, err)
+			}
+			return false
+		}
+		if attempt >= dispatcher.config.MaxAttempts {
+			dispatcher.fail(ctx, id)
+			return false
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(dispatcher.config.Backoff(attempt)):
+		}
+	}
+}
+
+func (dispatcher *Dispatcher) post(
+	ctx context.Context, subscription Subscription, event dtos.MovieEventDTO, body []byte,
+) dtos.WebhookDeliveryDTO {
+	start := time.Now()
+	delivery := dtos.WebhookDeliveryDTO{
+		WebhookID: subscription.ID,
+		EventID: event.EventID,
+		EventType: event.Type,
+		Status: dtos.WebhookDeliveryFailed,
+		DeliveredAt: start.UnixMilli(),
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, dispatcher.config.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		delivery.Error = fmt.Sprintf("could not build request: %v", err)
+		return delivery
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set(IDHeader, event.EventID)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(start.Unix(), 10))
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, event.EventID, start, body))
+
+	response, err := dispatcher.client.Do(req)
+	delivery.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64 << 10))
+
+	delivery.StatusCode = response.StatusCode
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		delivery.Error = fmt.Sprintf("receiver answered %s", response.Status)
+		return delivery
+	}
+	delivery.Status = dtos.WebhookDeliverySucceeded
+	return delivery
+}
+
+func (dispatcher *Dispatcher) record(ctx context.Context, delivery dtos.WebhookDeliveryDTO) {
+	if err := dispatcher.store.RecordDelivery(ctx, delivery); err != nil {
+		log.Printf("Failed recording delivery %+v: %v", delivery, err)
+	}
+}
+
+func (dispatcher *Dispatcher) fail(ctx context.Context, id dtos.WebhookId) {
+	maxFailures := dispatcher.config.MaxConsecutiveFailures
+	reason := fmt.Sprintf("disabled after %d events failed in a row", maxFailures)
+	disabled, err := dispatcher.store.MarkFailed(ctx, id, reason, maxFailures)
+	if err != nil {
+		log.Printf("Failed counting the failure of webhook %q: %v", id, err)
+		return
+	}
+	if disabled {
+		log.Printf("Webhook %q %s", id, reason)
+	}
+}
@@ -0,0 +1,294 @@
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/webhooks"
+)
+
+const (
+	secret = "0123456789abcdef0123456789abcdef"
+)
+
+func TestDispatcher(t *testing.T) {
+	event := dtos.MovieEventDTO{
+		EventID: "event-1",
+		Type: dtos.MovieCreated,
+		Movie: dtos.MovieResponseDTO{ID: 1, Title: "Toy Story", Year: "1995", Version: 1},
+	}
+
+	t.Run("should POST the event signed with the secret of the webhook", func(t *testing.T) {
+		receiver := NewFakeReceiver(t, http.StatusNoContent)
+		store, dispatcher := startDispatcher(t, testConfig())
+		webhook := createWebhook(t, store, dtos.CreateWebhookDTO{URL: receiver.URL()})
+
+		require.NoError(t, dispatcher.Dispatch(context.Background(), event))
+
+		request := receiver.Next(t)
+		assert.NoError(t, webhooks.Verify(secret, request.Header, request.Body, time.Minute, time.Now()))
+		assert.Equal(t, event.EventID, request.Header.Get(webhooks.IDHeader))
+		assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+
+		var received dtos.MovieEventDTO
+		require.NoError(t, json.Unmarshal(request.Body, &received))
+		assert.Equal(t, event, received)
+
+		deliveries := waitForDeliveries(t, store, webhook.ID, 1)
+		assert.Equal(t, dtos.WebhookDeliverySucceeded, deliveries[0].Status)
+		assert.Equal(t, http.StatusNoContent, deliveries[0].StatusCode)
+		assert.Equal(t, 1, deliveries[0].Attempt)
+	})
+
+	t.Run("should only POST the events matching the filters of the webhook", func(t *testing.T) {
+		receiver := NewFakeReceiver(t, http.StatusOK)
+		store, dispatcher := startDispatcher(t, testConfig())
+		createWebhook(t, store, dtos.CreateWebhookDTO{URL: receiver.URL(), Events: []dtos.MovieEventType{dtos.MovieDeleted}, Year: "1995"})
+
+		deletedOn2006 := dtos.MovieEventDTO{EventID: "event-2", Type: dtos.MovieDeleted, Movie: dtos.MovieResponseDTO{Year: "2006"}}
+		deletedOn1995 := dtos.MovieEventDTO{EventID: "event-3", Type: dtos.MovieDeleted, Movie: dtos.MovieResponseDTO{Year: "1995"}}
+		for _, dispatched := range []dtos.MovieEventDTO{event, deletedOn2006, deletedOn1995} {
+			require.NoError(t, dispatcher.Dispatch(context.Background(), dispatched))
+		}
+
+		assert.Equal(t, deletedOn1995.EventID, receiver.Next(t).Header.Get(webhooks.IDHeader))
+		receiver.AssertNothingReceived(t)
+	})
+
+	t.Run("should retry the failed attempts until the receiver accepts the event", func(t *testing.T) {
+		receiver := NewFakeReceiver(t, http.StatusInternalServerError, http.StatusBadGateway, http.StatusOK)
+		store, dispatcher := startDispatcher(t, testConfig())
+		webhook := createWebhook(t, store, dtos.CreateWebhookDTO{URL: receiver.URL()})
+
+		require.NoError(t, dispatcher.Dispatch(context.Background(), event))
+
+		for range 3 {
+			assert.Equal(t, event.EventID, receiver.Next(t).Header.Get(webhooks.IDHeader))
+		}
+		deliveries := waitForDeliveries(t, store, webhook.ID, 3)
+		assert.Equal(t, []int{3, 2, 1}, []int{deliveries[0].Attempt, deliveries[1].Attempt, deliveries[2].Attempt})
+		assert.Equal(t, dtos.WebhookDeliverySucceeded, deliveries[0].Status)
+		assert.Equal(t, dtos.WebhookDeliveryFailed, deliveries[1].Status)
+		assert.Equal(t, http.StatusBadGateway, deliveries[1].StatusCode)
+
+		stored, _ := store.GetOne(context.Background(), webhook.ID)
+		assert.Zero(t, stored.ConsecutiveFailures)
+	})
+
+	t.Run("should disable the webhook after too many events failed in a row", func(t *testing.T) {
+		receiver := NewFakeReceiver(t, http.StatusServiceUnavailable)
+		config := testConfig()
+		config.MaxAttempts = 2
+		config.MaxConsecutiveFailures = 2
+		store, dispatcher := startDispatcher(t, config)
+		webhook := createWebhook(t, store, dtos.CreateWebhookDTO{URL: receiver.URL()})
+
+		second := event
+		second.EventID = "event-2"
+		require.NoError(t, dispatcher.Dispatch(context.Background(), event))
+		require.NoError(t, dispatcher.Dispatch(context.Background(), second))
+		for range 4 {
+			receiver.Next(t)
+		}
+
+		assert.Eventually(t, func() bool {
+			stored, _ := store.GetOne(context.Background(), webhook.ID)
+			return !stored.Enabled
+		}, time.Second, time.Millisecond)
+		stored, _ := store.GetOne(context.Background(), webhook.ID)
+		assert.Equal(t, 2, stored.ConsecutiveFailures)
+		assert.NotEmpty(t, stored.DisabledReason)
+
+		third := event
+		third.EventID = "event-3"
+		require.NoError(t, dispatcher.Dispatch(context.Background(), third))
+		receiver.AssertNothingReceived(t)
+	})
+
+	t.Run("should record the attempts the receiver did not answer", func(t *testing.T) {
+		receiver := NewFakeReceiver(t, http.StatusOK)
+		url := receiver.URL()
+		receiver.Close()
+		config := testConfig()
+		config.MaxAttempts = 1
+		store, dispatcher := startDispatcher(t, config)
+		webhook := createWebhook(t, store, dtos.CreateWebhookDTO{URL: url})
+
+		require.NoError(t, dispatcher.Dispatch(context.Background(), event))
+
+		deliveries := waitForDeliveries(t, store, webhook.ID, 1)
+		assert.Equal(t, dtos.WebhookDeliveryFailed, deliveries[0].Status)
+		assert.Zero(t, deliveries[0].StatusCode)
+		assert.NotEmpty(t, deliveries[0].Error)
+	})
+
+	t.Run("should only POST the events claimed by its gateway", func(t *testing.T) {
+		receiver := NewFakeReceiver(t, http.StatusOK)
+		store := &ClaimingStore{MemoryStore: webhooks.NewMemoryStore(10), claims: map[string]bool{}}
+		createWebhook(t, store.MemoryStore, dtos.CreateWebhookDTO{URL: receiver.URL()})
+
+		// Each gateway receives the event.
+		for range 2 {
+			dispatcher := webhooks.NewDispatcher(store, http.DefaultClient, testConfig())
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			go dispatcher.Run(ctx)
+			require.NoError(t, dispatcher.Dispatch(context.Background(), event))
+		}
+
+		assert.Equal(t, event.EventID, receiver.Next(t).Header.Get(webhooks.IDHeader))
+		receiver.AssertNothingReceived(t)
+	})
+
+	t.Run("should refuse events beyond the queue", func(t *testing.T) {
+		config := testConfig()
+		config.QueueSize = 1
+		dispatcher := webhooks.NewDispatcher(webhooks.NewMemoryStore(10), http.DefaultClient, config)
+
+		require.NoError(t, dispatcher.Dispatch(context.Background(), event))
+		assert.ErrorIs(t, dispatcher.Dispatch(context.Background(), event), webhooks.ErrDispatchQueueFull)
+	})
+}
+
+func TestConfigBackoff(t *testing.T) {
+	config := webhooks.Config{InitialBackoff: time.Second, MaxBackoff: 10 * time.Second}
+
+	assert.Equal(t, time.Second, config.Backoff(1))
+	assert.Equal(t, 4*time.Second, config.Backoff(3))
+	assert.Equal(t, 10*time.Second, config.Backoff(5))
+	assert.Equal(t, 10*time.Second, config.Backoff(100))
+}
+
+
+func testConfig() webhooks.Config {
+	config := webhooks.DefaultConfig()
+	config.Timeout = time.Second
+	config.InitialBackoff = time.Millisecond
+	config.MaxBackoff = 5 * time.Millisecond
+	return config
+}
+
+func startDispatcher(t *testing.T, config webhooks.Config) (*webhooks.MemoryStore, *webhooks.Dispatcher) {
+	store := webhooks.NewMemoryStore(webhooks.DefaultDeliveryLogCapacity)
+	dispatcher := webhooks.NewDispatcher(store, http.DefaultClient, config)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		dispatcher.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return store, dispatcher
+}
+
+func createWebhook(t *testing.T, store webhooks.Store, webhook dtos.CreateWebhookDTO) dtos.WebhookResponseDTO {
+	webhook.Secret = secret
+	saved, err := store.Save(context.Background(), webhook)
+	require.NoError(t, err)
+	return saved
+}
+
+// Waits for the attempts to be recorded, answering them from the newest.
+func waitForDeliveries(t *testing.T, store webhooks.Store, id dtos.WebhookId, count int) []*dtos.WebhookDeliveryDTO {
+	var deliveries dtos.WebhookDeliveriesResponseDTO
+	assert.Eventually(t, func() bool {
+		deliveries, _ = store.GetDeliveries(context.Background(), dtos.WebhookDeliveriesQueryDTO{WebhookID: id})
+		return len(deliveries.Deliveries) == count
+	}, time.Second, time.Millisecond)
+	return deliveries.Deliveries
+}
+
+
+// Shares the webhooks of a MemoryStore as a DynamoDBStore, claiming
+// each event once.
+type ClaimingStore struct {
+	*webhooks.MemoryStore
+
+	mutex  sync.Mutex
+	claims map[string]bool
+}
+
+func (store *ClaimingStore) Claim(ctx context.Context, id dtos.WebhookId, eventID string) (bool, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	claim := string(id) + "/" + eventID
+	if store.claims[claim] {
+		return false, nil
+	}
+	store.claims[claim] = true
+	return true, nil
+}
+
+type ReceivedRequest struct {
+	Header http.Header
+	Body   []byte
+}
+
+// Answers the requests with the statuses in order, repeating the last one.
+type FakeReceiver struct {
+	server   *httptest.Server
+	mutex    sync.Mutex
+	statuses []int
+	received chan ReceivedRequest
+}
+
+func NewFakeReceiver(t *testing.T, statuses ...int) *FakeReceiver {
+	receiver := &FakeReceiver{statuses: statuses, received: make(chan ReceivedRequest, 100)}
+	receiver.server = httptest.NewServer(http.HandlerFunc(receiver.serve))
+	t.Cleanup(receiver.Close)
+	return receiver
+}
+
+func (receiver *FakeReceiver) serve(writer http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	receiver.mutex.Lock()
+	status := receiver.statuses[0]
+	if len(receiver.statuses) > 1 {
+		receiver.statuses = receiver.statuses[1:]
+	}
+	receiver.mutex.Unlock()
+
+	receiver.received <- ReceivedRequest{Header: req.Header.Clone(), Body: body}
+	writer.WriteHeader(status)
+}
+
+func (receiver *FakeReceiver) URL() string {
+	return receiver.server.URL + "/hooks/movies"
+}
+
+func (receiver *FakeReceiver) Close() {
+	receiver.server.Close()
+}
+
+func (receiver *FakeReceiver) Next(t *testing.T) ReceivedRequest {
+	select {
+	case request := <-receiver.received:
+		return request
+	case <-time.After(time.Second):
+		t.Fatal("the receiver got no request")
+		return ReceivedRequest{}
+	}
+}
+
+func (receiver *FakeReceiver) AssertNothingReceived(t *testing.T) {
+	select {
+	case request := <-receiver.received:
+		t.Errorf("the receiver got an unexpected request for event %q", request.Header.Get(webhooks.IDHeader))
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,643 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+)
+
+const (
+	webhookTableName = "webhooks"
+	deliveryTableName = "webhookDeliveries"
+	claimTableName = "webhookClaims"
+
+	// How long the claim of an event by a gateway is kept, long after
+	// every gateway received the event.
+	DefaultClaimTTL = 24 * time.Hour
+
+	// The limit of DynamoDB for each BatchWriteItem.
+	maxBatchWriteItems = 25
+	maxBatchAttempts = 5
+	batchRetryBackoff = 50 * time.Millisecond
+)
+
+// The deliveries are stored with the names of their json fields.
+var deliveryTag = func(options *attributevalue.EncoderOptions) { options.TagKey = "json" }
+
+// Loads the AWS configuration, the SDK defaults applying to the empty
+// region and endpoint.
+func NewDynamoDBClient(ctx context.Context, region, endpoint string) (*dynamodb.Client, error) {
+	options := []func(*config.LoadOptions) error{config.WithRegion(region)}
+	if endpoint != "" {
+		options = append(options, config.WithBaseEndpoint(endpoint))
+	}
+	awsConfig, err := config.LoadDefaultConfig(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load the AWS configs: %w", err)
+	}
+	return dynamodb.NewFromConfig(awsConfig), nil
+}
+
+type dbWebhook struct {
+	ID string                     `dynamodbav:"id"`
+	URL string                    `dynamodbav:"url"`
+	Events []dtos.MovieEventType  `dynamodbav:"events"`
+	Year string                   `dynamodbav:"year"`
+	Enabled bool                  `dynamodbav:"enabled"`
+	DisabledReason string         `dynamodbav:"disabled_reason"`
+	ConsecutiveFailures int       `dynamodbav:"consecutive_failures"`
+	Secret string                 `dynamodbav:"secret"`
+	CreatedAt int64               `dynamodbav:"created_at"`
+	UpdatedAt int64               `dynamodbav:"updated_at"`
+	// The id of the last delivery recorded, counting the deliveries of
+	// the webhook.
+	LastDeliveryID int            `dynamodbav:"last_delivery_id"`
+}
+
+func (webhook dbWebhook) toDTO() dtos.WebhookResponseDTO {
+	return dtos.WebhookResponseDTO{
+		ID: dtos.WebhookId(webhook.ID),
+		URL: webhook.URL,
+		Events: eventsOrEmpty(webhook.Events),
+		Year: webhook.Year,
+		Enabled: webhook.Enabled,
+		DisabledReason: webhook.DisabledReason,
+		ConsecutiveFailures: webhook.ConsecutiveFailures,
+		CreatedAt: webhook.CreatedAt,
+		UpdatedAt: webhook.UpdatedAt,
+	}
+}
+
+func (webhook dbWebhook) subscription() Subscription {
+	return Subscription{
+		ID: dtos.WebhookId(webhook.ID),
+		URL: webhook.URL,
+		Secret: webhook.Secret,
+		Enabled: webhook.Enabled,
+	}
+}
+
+type dbClaim struct {
+	Claim string     `dynamodbav:"claim"`
+	// Unix seconds, as read by the TTL of DynamoDB.
+	ExpiresAt int64  `dynamodbav:"expires_at"`
+}
+
+func webhookKey(id dtos.WebhookId) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: string(id)}}
+}
+
+func deliveryKey(id dtos.WebhookId, deliveryID int) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"webhook_id": &types.AttributeValueMemberS{Value: string(id)},
+		"id": &types.AttributeValueMemberN{Value: fmt.Sprint(deliveryID)},
+	}
+}
+
+// Keeps the webhooks and their deliveries in DynamoDB, shared by every
+// gateway, along with the claims of the events, so each event is sent to
+// a webhook by a single gateway. Keeps the last deliveries of each
+// webhook, up to the capacity, dropping the oldest ones.
+func NewDynamoDBStore(client *dynamodb.Client, deliveryLogCapacity int) *DynamoDBStore {
+	return &DynamoDBStore{
+		client: client,
+		capacity: deliveryLogCapacity,
+		claimTTL: DefaultClaimTTL,
+	}
+}
+
+type DynamoDBStore struct {
+	Store
+
+	client   *dynamodb.Client
+	capacity int
+	claimTTL time.Duration
+}
+
+// Creates the tables missing, waiting until they can be used.
+func (store *DynamoDBStore) CreateTables(ctx context.Context) error {
+	tables := []*dynamodb.CreateTableInput{
+		{
+			TableName: aws.String(webhookTableName),
+			AttributeDefinitions: []types.AttributeDefinition{
+				{AttributeName: aws.String("id"), AttributeType: types.ScalarAttributeTypeS},
+			},
+			KeySchema: []types.KeySchemaElement{
+				{AttributeName: aws.String("id"), KeyType: types.KeyTypeHash},
+			},
+		},
+		{
+			TableName: aws.String(deliveryTableName),
+			AttributeDefinitions: []types.AttributeDefinition{
+				{AttributeName: aws.String("webhook_id"), AttributeType: types.ScalarAttributeTypeS},
+				{AttributeName: aws.String("id"), AttributeType: types.ScalarAttributeTypeN},
+			},
+			KeySchema: []types.KeySchemaElement{
+				{AttributeName: aws.String("webhook_id"), KeyType: types.KeyTypeHash},
+				{AttributeName: aws.String("id"), KeyType: types.KeyTypeRange},
+			},
+		},
+		{
+			TableName: aws.String(claimTableName),
+			AttributeDefinitions: []types.AttributeDefinition{
+				{AttributeName: aws.String("claim"), AttributeType: types.ScalarAttributeTypeS},
+			},
+			KeySchema: []types.KeySchemaElement{
+				{AttributeName: aws.String("claim"), KeyType: types.KeyTypeHash},
+			},
+		},
+	}
+
+	waiter := dynamodb.NewTableExistsWaiter(store.client)
+	for _, table := range tables {
+		table.BillingMode = types.BillingModePayPerRequest
+		_, err := store.client.CreateTable(ctx, table)
+		var alreadyExists *types.ResourceInUseException
+		if errors.As(err, &alreadyExists) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("couldn't create table %s: %w", aws.ToString(table.TableName), err)
+		}
+		if err := waiter.Wait(ctx, &dynamodb.DescribeTableInput{TableName: table.TableName}, 5*time.Minute); err != nil {
+			return fmt.Errorf("couldn't wait for table %s: %w", aws.ToString(table.TableName), err)
+		}
+		if aws.ToString(table.TableName) == claimTableName {
+			store.expireClaims(ctx)
+		}
+	}
+	return nil
+}
+
+// The claims are only needed while the gateways receive the event, so
+// they are removed by the TTL of DynamoDB once expired.
+func (store *DynamoDBStore) expireClaims(ctx context.Context) {
+	if _, err := store.client.UpdateTimeToLive(ctx, &dynamodb.UpdateTimeToLiveInput{
+		TableName: aws.String(claimTableName),
+		TimeToLiveSpecification: &types.TimeToLiveSpecification{
+			AttributeName: aws.String("expires_at"),
+			Enabled: aws.Bool(true),
+		},
+	}); err != nil {
+		log.Printf("Failed enabling the TTL of table %s, so the claims are kept: %v", claimTableName, err)
+	}
+}
+
+func (store *DynamoDBStore) Save(ctx context.Context, webhook dtos.CreateWebhookDTO) (dtos.WebhookResponseDTO, error) {
+	now := time.Now().UnixMilli()
+	stored := dbWebhook{
+		ID: uuid.New().String(),
+		URL: webhook.URL,
+		Events: eventsOrEmpty(webhook.Events),
+		Year: webhook.Year,
+		Enabled: true,
+		Secret: webhook.Secret,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	item, err := attributevalue.MarshalMap(stored)
+	if err != nil {
+		return dtos.WebhookResponseDTO{}, fmt.Errorf("couldn't marshal webhook: %w", err)
+	}
+	expr, err := expression.NewBuilder().WithCondition(expression.AttributeNotExists(expression.Name("id"))).Build()
+	if err != nil {
+		return dtos.WebhookResponseDTO{}, fmt.Errorf("couldn't build condition to save webhook: %w", err)
+	}
+	if _, err := store.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(webhookTableName),
+		Item: item,
+		ConditionExpression: expr.Condition(),
+		ExpressionAttributeNames: expr.Names(),
+	}); err != nil {
+		return dtos.WebhookResponseDTO{}, fmt.Errorf("couldn't save webhook: %w", err)
+	}
+
+	saved := stored.toDTO()
+	saved.Secret = stored.Secret
+	return saved, nil
+}
+
+func (store *DynamoDBStore) GetOne(ctx context.Context, id dtos.WebhookId) (dtos.WebhookResponseDTO, error) {
+	stored, err := store.get(ctx, id)
+	if err != nil {
+		return dtos.WebhookResponseDTO{}, err
+	}
+	return stored.toDTO(), nil
+}
+
+// Answers the webhooks in the order they were created.
+func (store *DynamoDBStore) GetAll(ctx context.Context) ([]dtos.WebhookResponseDTO, error) {
+	stored, err := store.scan(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	webhooks := make([]dtos.WebhookResponseDTO, len(stored))
+	for index, webhook := range stored {
+		webhooks[index] = webhook.toDTO()
+	}
+	return webhooks, nil
+}
+
+func (store *DynamoDBStore) Update(ctx context.Context, webhook dtos.UpdateWebhookDTO) (dtos.WebhookResponseDTO, error) {
+	exists := expression.AttributeExists(expression.Name("id"))
+	if webhook.Enabled != nil && *webhook.Enabled {
+		// Only a disabled webhook forgets its failures.
+		forget := expression.Set(expression.Name("consecutive_failures"), expression.Value(0)).
+			Set(expression.Name("disabled_reason"), expression.Value(""))
+		disabled := exists.And(expression.Name("enabled").Equal(expression.Value(false)))
+		if _, err := store.update(ctx, webhook.ID, forget, disabled); err != nil && !errors.Is(err, ports.ErrWebhookNotFound) {
+			return dtos.WebhookResponseDTO{}, err
+		}
+	}
+
+	update := expression.Set(expression.Name("url"), expression.Value(webhook.URL)).
+		Set(expression.Name("events"), expression.Value(eventsOrEmpty(webhook.Events))).
+		Set(expression.Name("year"), expression.Value(webhook.Year)).
+		Set(expression.Name("updated_at"), expression.Value(time.Now().UnixMilli()))
+	if webhook.Secret != "" {
+		update = update.Set(expression.Name("secret"), expression.Value(webhook.Secret))
+	}
+	if webhook.Enabled != nil {
+		update = update.Set(expression.Name("enabled"), expression.Value(*webhook.Enabled))
+	}
+	updated, err := store.update(ctx, webhook.ID, update, exists)
+	if err != nil {
+		return dtos.WebhookResponseDTO{}, err
+	}
+	return updated.toDTO(), nil
+}
+
+// Deletes the webhook with its deliveries.
+func (store *DynamoDBStore) Delete(ctx context.Context, id dtos.WebhookId) error {
+	expr, err := expression.NewBuilder().WithCondition(expression.AttributeExists(expression.Name("id"))).Build()
+	if err != nil {
+		return fmt.Errorf("couldn't build condition to delete webhook %q: %w", id, err)
+	}
+	_, err = store.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(webhookTableName),
+		Key: webhookKey(id),
+		ConditionExpression: expr.Condition(),
+		ExpressionAttributeNames: expr.Names(),
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return ports.ErrWebhookNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't delete webhook %q: %w", id, err)
+	}
+
+	if err := store.deleteDeliveries(ctx, id); err != nil {
+		log.Printf("Failed deleting the deliveries of webhook %q: %v", id, err)
+	}
+	return nil
+}
+
+func (store *DynamoDBStore) GetDeliveries(
+	ctx context.Context, query dtos.WebhookDeliveriesQueryDTO,
+) (dtos.WebhookDeliveriesResponseDTO, error) {
+	limit := query.Limit
+	if limit == 0 {
+		limit = DefaultDeliveriesLimit
+	}
+	if _, err := store.get(ctx, query.WebhookID); err != nil {
+		return dtos.WebhookDeliveriesResponseDTO{}, err
+	}
+
+	keyCondition := expression.Key("webhook_id").Equal(expression.Value(string(query.WebhookID)))
+	if query.Cursor != 0 {
+		keyCondition = keyCondition.And(expression.Key("id").LessThan(expression.Value(query.Cursor)))
+	}
+	builder := expression.NewBuilder().WithKeyCondition(keyCondition)
+	if query.Status != "" {
+		builder = builder.WithFilter(expression.Name("status").Equal(expression.Value(string(query.Status))))
+	}
+	expr, err := builder.Build()
+	if err != nil {
+		return dtos.WebhookDeliveriesResponseDTO{}, fmt.Errorf("couldn't build query of deliveries: %w", err)
+	}
+
+	// One more than the limit is read, to tell if there is a next page.
+	deliveries := []*dtos.WebhookDeliveryDTO{}
+	paginator := dynamodb.NewQueryPaginator(store.client, &dynamodb.QueryInput{
+		TableName: aws.String(deliveryTableName),
+		KeyConditionExpression: expr.KeyCondition(),
+		FilterExpression: expr.Filter(),
+		ExpressionAttributeNames: expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ScanIndexForward: aws.Bool(false),
+	})
+	for paginator.HasMorePages() && len(deliveries) <= limit {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return dtos.WebhookDeliveriesResponseDTO{}, fmt.Errorf("couldn't query deliveries of webhook %q: %w", query.WebhookID, err)
+		}
+		for _, item := range page.Items {
+			var delivery dtos.WebhookDeliveryDTO
+			if err := attributevalue.UnmarshalMapWithOptions(item, &delivery, func(options *attributevalue.DecoderOptions) {
+				options.TagKey = "json"
+			}); err != nil {
+				return dtos.WebhookDeliveriesResponseDTO{}, fmt.Errorf("couldn't unmarshal delivery: %w", err)
+			}
+			deliveries = append(deliveries, &delivery)
+		}
+	}
+
+	response := dtos.WebhookDeliveriesResponseDTO{Deliveries: deliveries}
+	if len(deliveries) > limit {
+		response.Deliveries = deliveries[:limit]
+		response.Cursor = deliveries[limit-1].ID
+	}
+	return response, nil
+}
+
+func (store *DynamoDBStore) GetSubscriptions(ctx context.Context, event dtos.MovieEventDTO) ([]Subscription, error) {
+	enabled := expression.Name("enabled").Equal(expression.Value(true))
+	stored, err := store.scan(ctx, &enabled)
+	if err != nil {
+		return nil, err
+	}
+	var subscriptions []Subscription
+	for _, webhook := range stored {
+		if dto := webhook.toDTO(); dto.Matches(event) {
+			subscriptions = append(subscriptions, webhook.subscription())
+		}
+	}
+	return subscriptions, nil
+}
+
+func (store *DynamoDBStore) GetSubscription(ctx context.Context, id dtos.WebhookId) (Subscription, error) {
+	stored, err := store.get(ctx, id)
+	if err != nil {
+		return Subscription{}, err
+	}
+	return stored.subscription(), nil
+}
+
+// The deliveries of deleted webhooks are dropped. The ids count the
+// deliveries of each webhook, so the one recorded capacity deliveries
+// before is the oldest kept, and dropped.
+func (store *DynamoDBStore) RecordDelivery(ctx context.Context, delivery dtos.WebhookDeliveryDTO) error {
+	counted, err := store.update(
+		ctx,
+		delivery.WebhookID,
+		expression.Add(expression.Name("last_delivery_id"), expression.Value(1)),
+		expression.AttributeExists(expression.Name("id")),
+	)
+	if err != nil {
+		return fmt.Errorf("could not record delivery of event %q: %w", delivery.EventID, err)
+	}
+
+	delivery.ID = counted.LastDeliveryID
+	item, err := attributevalue.MarshalMapWithOptions(delivery, deliveryTag)
+	if err != nil {
+		return fmt.Errorf("couldn't marshal delivery of event %q: %w", delivery.EventID, err)
+	}
+	if _, err := store.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(deliveryTableName),
+		Item: item,
+	}); err != nil {
+		return fmt.Errorf("could not record delivery of event %q: %w", delivery.EventID, err)
+	}
+
+	if dropped := delivery.ID - store.capacity; dropped > 0 {
+		if _, err := store.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+			TableName: aws.String(deliveryTableName),
+			Key: deliveryKey(delivery.WebhookID, dropped),
+		}); err != nil {
+			log.Printf("Failed dropping delivery %d of webhook %q: %v", dropped, delivery.WebhookID, err)
+		}
+	}
+	return nil
+}
+
+func (store *DynamoDBStore) MarkDelivered(ctx context.Context, id dtos.WebhookId) error {
+	_, err := store.update(
+		ctx,
+		id,
+		expression.Set(expression.Name("consecutive_failures"), expression.Value(0)),
+		expression.AttributeExists(expression.Name("id")),
+	)
+	return err
+}
+
+func (store *DynamoDBStore) MarkFailed(ctx context.Context, id dtos.WebhookId, reason string, maxFailures int) (bool, error) {
+	exists := expression.AttributeExists(expression.Name("id"))
+	counted, err := store.update(
+		ctx, id, expression.Add(expression.Name("consecutive_failures"), expression.Value(1)), exists,
+	)
+	if err != nil {
+		return false, err
+	}
+	if !counted.Enabled || counted.ConsecutiveFailures < maxFailures {
+		return false, nil
+	}
+
+	// Another gateway may have disabled it first.
+	disable := expression.Set(expression.Name("enabled"), expression.Value(false)).
+		Set(expression.Name("disabled_reason"), expression.Value(reason)).
+		Set(expression.Name("updated_at"), expression.Value(time.Now().UnixMilli()))
+	_, err = store.update(ctx, id, disable, exists.And(expression.Name("enabled").Equal(expression.Value(true))))
+	if errors.Is(err, ports.ErrWebhookNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Claims the event for the webhook with a conditional write, which only
+// the first gateway succeeds.
+func (store *DynamoDBStore) Claim(ctx context.Context, id dtos.WebhookId, eventID string) (bool, error) {
+	item, err := attributevalue.MarshalMap(dbClaim{
+		Claim: string(id) + "/" + eventID,
+		ExpiresAt: time.Now().Add(store.claimTTL).Unix(),
+	})
+	if err != nil {
+		return false, fmt.Errorf("couldn't marshal claim: %w", err)
+	}
+	expr, err := expression.NewBuilder().WithCondition(expression.AttributeNotExists(expression.Name("claim"))).Build()
+	if err != nil {
+		return false, fmt.Errorf("couldn't build condition to claim event %q: %w", eventID, err)
+	}
+	_, err = store.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(claimTableName),
+		Item: item,
+		ConditionExpression: expr.Condition(),
+		ExpressionAttributeNames: expr.Names(),
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("couldn't claim event %q for webhook %q: %w", eventID, id, err)
+	}
+	return true, nil
+}
+
+func (store *DynamoDBStore) get(ctx context.Context, id dtos.WebhookId) (dbWebhook, error) {
+	response, err := store.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(webhookTableName),
+		Key: webhookKey(id),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return dbWebhook{}, fmt.Errorf("couldn't get webhook %q: %w", id, err)
+	}
+	if response.Item == nil {
+		return dbWebhook{}, ports.ErrWebhookNotFound
+	}
+	var stored dbWebhook
+	if err := attributevalue.UnmarshalMap(response.Item, &stored); err != nil {
+		return dbWebhook{}, fmt.Errorf("couldn't unmarshal webhook %q: %w", id, err)
+	}
+	return stored, nil
+}
+
+// Scans the webhooks passing the filter, when not nil, in the order they
+// were created.
+func (store *DynamoDBStore) scan(ctx context.Context, filter *expression.ConditionBuilder) ([]dbWebhook, error) {
+	input := &dynamodb.ScanInput{
+		TableName: aws.String(webhookTableName),
+		ConsistentRead: aws.Bool(true),
+	}
+	if filter != nil {
+		expr, err := expression.NewBuilder().WithFilter(*filter).Build()
+		if err != nil {
+			return nil, fmt.Errorf("couldn't build filter of webhooks: %w", err)
+		}
+		input.FilterExpression = expr.Filter()
+		input.ExpressionAttributeNames = expr.Names()
+		input.ExpressionAttributeValues = expr.Values()
+	}
+
+	var webhooks []dbWebhook
+	paginator := dynamodb.NewScanPaginator(store.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't scan webhooks: %w", err)
+		}
+		var stored []dbWebhook
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &stored); err != nil {
+			return nil, fmt.Errorf("couldn't unmarshal webhooks: %w", err)
+		}
+		webhooks = append(webhooks, stored...)
+	}
+	sort.SliceStable(webhooks, func(i, j int) bool {
+		if webhooks[i].CreatedAt != webhooks[j].CreatedAt {
+			return webhooks[i].CreatedAt < webhooks[j].CreatedAt
+		}
+		return webhooks[i].ID < webhooks[j].ID
+	})
+	return webhooks, nil
+}
+
+// Updates the webhook if the condition holds, answering it updated, and
+// failing with ports.ErrWebhookNotFound otherwise.
+func (store *DynamoDBStore) update(
+	ctx context.Context, id dtos.WebhookId, update expression.UpdateBuilder, condition expression.ConditionBuilder,
+) (dbWebhook, error) {
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
+	if err != nil {
+		return dbWebhook{}, fmt.Errorf("couldn't build expression to update webhook %q: %w", id, err)
+	}
+	response, err := store.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(webhookTableName),
+		Key: webhookKey(id),
+		UpdateExpression: expr.Update(),
+		ConditionExpression: expr.Condition(),
+		ExpressionAttributeNames: expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ReturnValues: types.ReturnValueAllNew,
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return dbWebhook{}, ports.ErrWebhookNotFound
+	}
+	if err != nil {
+		return dbWebhook{}, fmt.Errorf("couldn't update webhook %q: %w", id, err)
+	}
+	var updated dbWebhook
+	if err := attributevalue.UnmarshalMap(response.Attributes, &updated); err != nil {
+		return dbWebhook{}, fmt.Errorf("couldn't unmarshal webhook %q: %w", id, err)
+	}
+	return updated, nil
+}
+
+// Deletes the deliveries of the webhook, in batches, retrying the ones
+// DynamoDB leaves unprocessed.
+func (store *DynamoDBStore) deleteDeliveries(ctx context.Context, id dtos.WebhookId) error {
+	expr, err := expression.NewBuilder().
+		WithKeyCondition(expression.Key("webhook_id").Equal(expression.Value(string(id)))).
+		WithProjection(expression.NamesList(expression.Name("webhook_id"), expression.Name("id"))).
+		Build()
+	if err != nil {
+		return fmt.Errorf("couldn't build query of deliveries: %w", err)
+	}
+
+	paginator := dynamodb.NewQueryPaginator(store.client, &dynamodb.QueryInput{
+		TableName: aws.String(deliveryTableName),
+		KeyConditionExpression: expr.KeyCondition(),
+		ProjectionExpression: expr.Projection(),
+		ExpressionAttributeNames: expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("couldn't query deliveries: %w", err)
+		}
+		for start := 0; start < len(page.Items); start += maxBatchWriteItems {
+			batch := page.Items[start:min(start + maxBatchWriteItems, len(page.Items))]
+			requests := make([]types.WriteRequest, len(batch))
+			for index, key := range batch {
+				requests[index] = types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: key}}
+			}
+			if err := store.batchWrite(ctx, map[string][]types.WriteRequest{deliveryTableName: requests}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (store *DynamoDBStore) batchWrite(ctx context.Context, requests map[string][]types.WriteRequest) error {
+	backoff := batchRetryBackoff
+	for attempt := 1; len(requests) > 0; attempt++ {
+		response, err := store.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{RequestItems: requests})
+		if err != nil {
+			return fmt.Errorf("couldn't write a batch of items: %w", err)
+		}
+		requests = response.UnprocessedItems
+		if len(requests) == 0 {
+			return nil
+		}
+		if attempt == maxBatchAttempts {
+			return fmt.Errorf("couldn't write %d items after %d attempts", len(requests[deliveryTableName]), attempt)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return nil
+}
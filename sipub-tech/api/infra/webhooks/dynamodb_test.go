@@ -0,0 +1,177 @@
+//go:build integration
+
+package webhooks_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/webhooks"
+)
+
+const (
+	localStackImage = "localstack/localstack"
+	localStackPort = "4566"
+	awsRegion = "us-east-1"
+)
+
+func TestDynamoDBStore(t *testing.T) {
+	ctx := context.Background()
+
+	// The SDK needs credentials, whatever they are for localstack.
+	for _, name := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"} {
+		if os.Getenv(name) == "" {
+			t.Setenv(name, "test")
+		}
+	}
+	localStackC, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image: localStackImage,
+			ExposedPorts: []string{"4566/tcp"},
+			WaitingFor: wait.ForLog("Ready."),
+			Env: map[string]string{
+				"SERVICES": "dynamodb",
+				"LOCALSTACK_AUTH_TOKEN": os.Getenv("LOCALSTACK_AUTH_TOKEN"),
+			},
+		},
+		Started: true,
+	})
+	testcontainers.CleanupContainer(t, localStackC)
+	require.NoError(t, err)
+	endpoint, err := localStackC.PortEndpoint(ctx, localStackPort, "http")
+	require.NoError(t, err)
+
+	client, err := webhooks.NewDynamoDBClient(ctx, awsRegion, endpoint)
+	require.NoError(t, err)
+	store := webhooks.NewDynamoDBStore(client, 4)
+	require.NoError(t, store.CreateTables(ctx))
+	require.NoError(t, store.CreateTables(ctx), "the existing tables should be kept")
+
+	t.Run("should only answer the secret on creation, in the order the webhooks were created", func(t *testing.T) {
+		first := createWebhook(t, store, dtos.CreateWebhookDTO{URL: "http://localhost/first"})
+		second := createWebhook(t, store, dtos.CreateWebhookDTO{URL: "http://localhost/second", Year: "1995"})
+		t.Cleanup(func() {
+			store.Delete(ctx, first.ID)
+			store.Delete(ctx, second.ID)
+		})
+
+		assert.Equal(t, secret, first.Secret)
+		assert.True(t, first.Enabled)
+		assert.Equal(t, []dtos.MovieEventType{}, first.Events)
+
+		stored, err := store.GetOne(ctx, second.ID)
+		require.NoError(t, err)
+		assert.Empty(t, stored.Secret)
+		assert.Equal(t, "1995", stored.Year)
+
+		all, err := store.GetAll(ctx)
+		require.NoError(t, err)
+		require.Len(t, all, 2)
+		assert.Equal(t, []dtos.WebhookId{first.ID, second.ID}, []dtos.WebhookId{all[0].ID, all[1].ID})
+	})
+
+	t.Run("should fail with ports.ErrWebhookNotFound for unknown webhooks", func(t *testing.T) {
+		_, err := store.GetOne(ctx, "missing")
+		assert.ErrorIs(t, err, ports.ErrWebhookNotFound)
+		_, err = store.Update(ctx, dtos.UpdateWebhookDTO{ID: "missing"})
+		assert.ErrorIs(t, err, ports.ErrWebhookNotFound)
+		assert.ErrorIs(t, store.Delete(ctx, "missing"), ports.ErrWebhookNotFound)
+		_, err = store.GetDeliveries(ctx, dtos.WebhookDeliveriesQueryDTO{WebhookID: "missing"})
+		assert.ErrorIs(t, err, ports.ErrWebhookNotFound)
+		assert.ErrorIs(t, store.RecordDelivery(ctx, dtos.WebhookDeliveryDTO{WebhookID: "missing"}), ports.ErrWebhookNotFound)
+	})
+
+	t.Run("should keep the secret unless a new one is sent, and forget the failures when enabled again", func(t *testing.T) {
+		saved := createWebhook(t, store, dtos.CreateWebhookDTO{URL: "http://localhost/hooks"})
+		t.Cleanup(func() { store.Delete(ctx, saved.ID) })
+		disabled, err := store.MarkFailed(ctx, saved.ID, "failing", 2)
+		require.NoError(t, err)
+		assert.False(t, disabled)
+		disabled, err = store.MarkFailed(ctx, saved.ID, "failing", 2)
+		require.NoError(t, err)
+		require.True(t, disabled)
+		disabled, _ = store.MarkFailed(ctx, saved.ID, "failing", 2)
+		assert.False(t, disabled, "it should only be disabled once")
+
+		enabled := true
+		updated, err := store.Update(ctx, dtos.UpdateWebhookDTO{ID: saved.ID, URL: "http://localhost/other", Enabled: &enabled})
+		require.NoError(t, err)
+		assert.Equal(t, "http://localhost/other", updated.URL)
+		assert.True(t, updated.Enabled)
+		assert.Zero(t, updated.ConsecutiveFailures)
+		assert.Empty(t, updated.DisabledReason)
+
+		subscription, _ := store.GetSubscription(ctx, saved.ID)
+		assert.Equal(t, secret, subscription.Secret)
+
+		store.Update(ctx, dtos.UpdateWebhookDTO{ID: saved.ID, URL: updated.URL, Secret: "a new secret to be used"})
+		subscription, _ = store.GetSubscription(ctx, saved.ID)
+		assert.Equal(t, "a new secret to be used", subscription.Secret)
+	})
+
+	t.Run("should answer the deliveries from the newest, paginated by the cursor", func(t *testing.T) {
+		saved := createWebhook(t, store, dtos.CreateWebhookDTO{URL: "http://localhost/hooks"})
+		for index := range 6 {
+			status := dtos.WebhookDeliverySucceeded
+			if index%2 == 0 {
+				status = dtos.WebhookDeliveryFailed
+			}
+			require.NoError(t, store.RecordDelivery(ctx, dtos.WebhookDeliveryDTO{
+				WebhookID: saved.ID, EventID: fmt.Sprintf("event-%d", index), Status: status,
+			}))
+		}
+
+		page, err := store.GetDeliveries(ctx, dtos.WebhookDeliveriesQueryDTO{WebhookID: saved.ID, Limit: 3})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"event-5", "event-4", "event-3"}, eventIDs(page.Deliveries))
+
+		page, _ = store.GetDeliveries(ctx, dtos.WebhookDeliveriesQueryDTO{WebhookID: saved.ID, Limit: 3, Cursor: page.Cursor})
+		assert.Equal(t, []string{"event-2"}, eventIDs(page.Deliveries), "the oldest deliveries should have been dropped")
+		assert.Zero(t, page.Cursor)
+
+		page, _ = store.GetDeliveries(ctx, dtos.WebhookDeliveriesQueryDTO{WebhookID: saved.ID, Status: dtos.WebhookDeliveryFailed})
+		assert.Equal(t, []string{"event-4", "event-2"}, eventIDs(page.Deliveries))
+
+		require.NoError(t, store.Delete(ctx, saved.ID))
+		_, err = store.GetDeliveries(ctx, dtos.WebhookDeliveriesQueryDTO{WebhookID: saved.ID})
+		assert.ErrorIs(t, err, ports.ErrWebhookNotFound)
+	})
+
+	t.Run("should only subscribe the enabled webhooks matching the event", func(t *testing.T) {
+		all := createWebhook(t, store, dtos.CreateWebhookDTO{URL: "http://localhost/all"})
+		on2006 := createWebhook(t, store, dtos.CreateWebhookDTO{URL: "http://localhost/2006", Year: "2006"})
+		disabled := createWebhook(t, store, dtos.CreateWebhookDTO{URL: "http://localhost/disabled"})
+		t.Cleanup(func() {
+			for _, id := range []dtos.WebhookId{all.ID, on2006.ID, disabled.ID} {
+				store.Delete(ctx, id)
+			}
+		})
+		store.MarkFailed(ctx, disabled.ID, "failing", 1)
+
+		subscriptions, err := store.GetSubscriptions(ctx, dtos.MovieEventDTO{Type: dtos.MovieCreated, Movie: dtos.MovieResponseDTO{Year: "1995"}})
+		require.NoError(t, err)
+		assert.Equal(t, []webhooks.Subscription{{ID: all.ID, URL: all.URL, Secret: secret, Enabled: true}}, subscriptions)
+	})
+
+	t.Run("should claim each event for a webhook once", func(t *testing.T) {
+		claimed, err := store.Claim(ctx, "webhook", "event-1")
+		require.NoError(t, err)
+		assert.True(t, claimed)
+
+		claimed, err = store.Claim(ctx, "webhook", "event-1")
+		require.NoError(t, err)
+		assert.False(t, claimed, "another gateway should have claimed it")
+
+		claimed, _ = store.Claim(ctx, "other", "event-1")
+		assert.True(t, claimed)
+	})
+}
@@ -0,0 +1,52 @@
+package webhooks_test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/webhooks"
+)
+
+func TestSignature(t *testing.T) {
+	now := time.Unix(1760868000, 0)
+	signedHeader := func(secret, id string, timestamp time.Time, body []byte) http.Header {
+		header := http.Header{}
+		header.Set(webhooks.IDHeader, id)
+		header.Set(webhooks.TimestampHeader, strconv.FormatInt(timestamp.Unix(), 10))
+		header.Set(webhooks.SignatureHeader, webhooks.Sign(secret, id, timestamp, body))
+		return header
+	}
+
+	t.Run("should verify the requests it signed", func(t *testing.T) {
+		assertion := func(secret, id string, body []byte) bool {
+			header := signedHeader(secret, id, now, body)
+			return assert.NoError(t, webhooks.Verify(secret, header, body, time.Minute, now))
+		}
+		if err := quick.Check(assertion, nil); err != nil {
+			t.Errorf("assertion failed: %v", err)
+		}
+	})
+
+	t.Run("should refuse tampered requests", func(t *testing.T) {
+		body := []byte(`{"event_id":"event-1"}`)
+		header := signedHeader(secret, "event-1", now, body)
+
+		assert.ErrorIs(t, webhooks.Verify("another secret", header, body, time.Minute, now), webhooks.ErrInvalidSignature)
+		assert.ErrorIs(t, webhooks.Verify(secret, header, []byte(`{"event_id":"event-2"}`), time.Minute, now), webhooks.ErrInvalidSignature)
+
+		header.Set(webhooks.IDHeader, "event-2")
+		assert.ErrorIs(t, webhooks.Verify(secret, header, body, time.Minute, now), webhooks.ErrInvalidSignature)
+	})
+
+	t.Run("should refuse requests signed out of the tolerance", func(t *testing.T) {
+		body := []byte(`{}`)
+		header := signedHeader(secret, "event-1", now.Add(-10*time.Minute), body)
+
+		assert.ErrorIs(t, webhooks.Verify(secret, header, body, 5*time.Minute, now), webhooks.ErrSignatureExpired)
+	})
+}
@@ -0,0 +1,281 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+)
+
+const (
+	DefaultDeliveryLogCapacity = 100
+	DefaultDeliveriesLimit     = 50
+)
+
+// What the dispatcher needs to send the events to a webhook.
+type Subscription struct {
+	ID      dtos.WebhookId
+	URL     string
+	Secret  string
+	Enabled bool
+}
+
+// Keeps the webhooks and the log of their deliveries. The MemoryStore
+// keeps them in the memory of a single gateway, and the DynamoDBStore
+// shares them between the gateways.
+type Store interface {
+	ports.WebhookService
+
+	// The enabled webhooks whose filters match the event.
+	GetSubscriptions(ctx context.Context, event dtos.MovieEventDTO) ([]Subscription, error)
+	// Fails with ports.ErrWebhookNotFound when the webhook was deleted.
+	GetSubscription(ctx context.Context, id dtos.WebhookId) (Subscription, error)
+	// Appends the delivery to the log of its webhook, setting its id.
+	RecordDelivery(ctx context.Context, delivery dtos.WebhookDeliveryDTO) error
+	// Forgets the failures of the webhook, after an event was delivered.
+	MarkDelivered(ctx context.Context, id dtos.WebhookId) error
+	// Counts an event whose delivery failed, disabling the webhook with the
+	// reason once maxFailures are counted in a row. Tells if it did.
+	MarkFailed(ctx context.Context, id dtos.WebhookId, reason string, maxFailures int) (bool, error)
+	// Claims the sending of the event to the webhook, as every gateway
+	// receives the events. Tells if it was not claimed before.
+	Claim(ctx context.Context, id dtos.WebhookId, eventID string) (bool, error)
+}
+
+type storedWebhook struct {
+	webhook    dtos.WebhookResponseDTO
+	secret     string
+	deliveries []dtos.WebhookDeliveryDTO
+}
+
+// Keeps the last deliveries of each webhook, up to the capacity, dropping
+// the oldest ones.
+func NewMemoryStore(deliveryLogCapacity int) *MemoryStore {
+	return &MemoryStore{
+		webhooks: map[dtos.WebhookId]*storedWebhook{},
+		capacity: deliveryLogCapacity,
+	}
+}
+
+type MemoryStore struct {
+	Store
+
+	mutex          sync.RWMutex
+	webhooks       map[dtos.WebhookId]*storedWebhook
+	order          []dtos.WebhookId
+	capacity       int
+	lastDeliveryID int
+}
+
+func (store *MemoryStore) Save(ctx context.Context, webhook dtos.CreateWebhookDTO) (dtos.WebhookResponseDTO, error) {
+	now := time.Now().UnixMilli()
+	stored := &storedWebhook{
+		webhook: dtos.WebhookResponseDTO{
+			ID:        dtos.WebhookId(uuid.New().String()),
+			URL:       webhook.URL,
+			Events:    eventsOrEmpty(webhook.Events),
+			Year:      webhook.Year,
+			Enabled:   true,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		secret: webhook.Secret,
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	store.webhooks[stored.webhook.ID] = stored
+	store.order = append(store.order, stored.webhook.ID)
+
+	saved := stored.webhook
+	saved.Secret = stored.secret
+	return saved, nil
+}
+
+func (store *MemoryStore) GetOne(ctx context.Context, id dtos.WebhookId) (dtos.WebhookResponseDTO, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+	stored, ok := store.webhooks[id]
+	if !ok {
+		return dtos.WebhookResponseDTO{}, ports.ErrWebhookNotFound
+	}
+	return stored.webhook, nil
+}
+
+// Answers the webhooks in the order they were created.
+func (store *MemoryStore) GetAll(ctx context.Context) ([]dtos.WebhookResponseDTO, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+	webhooks := make([]dtos.WebhookResponseDTO, len(store.order))
+	for index, id := range store.order {
+		webhooks[index] = store.webhooks[id].webhook
+	}
+	return webhooks, nil
+}
+
+func (store *MemoryStore) Update(ctx context.Context, webhook dtos.UpdateWebhookDTO) (dtos.WebhookResponseDTO, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	stored, ok := store.webhooks[webhook.ID]
+	if !ok {
+		return dtos.WebhookResponseDTO{}, ports.ErrWebhookNotFound
+	}
+
+	stored.webhook.URL = webhook.URL
+	stored.webhook.Events = eventsOrEmpty(webhook.Events)
+	stored.webhook.Year = webhook.Year
+	if webhook.Secret != "" {
+		stored.secret = webhook.Secret
+	}
+	if webhook.Enabled != nil {
+		if *webhook.Enabled && !stored.webhook.Enabled {
+			stored.webhook.ConsecutiveFailures = 0
+			stored.webhook.DisabledReason = ""
+		}
+		stored.webhook.Enabled = *webhook.Enabled
+	}
+	stored.webhook.UpdatedAt = time.Now().UnixMilli()
+	return stored.webhook, nil
+}
+
+func (store *MemoryStore) Delete(ctx context.Context, id dtos.WebhookId) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	if _, ok := store.webhooks[id]; !ok {
+		return ports.ErrWebhookNotFound
+	}
+	delete(store.webhooks, id)
+	store.order = slices.DeleteFunc(store.order, func(stored dtos.WebhookId) bool { return stored == id })
+	return nil
+}
+
+func (store *MemoryStore) GetDeliveries(
+	ctx context.Context, query dtos.WebhookDeliveriesQueryDTO,
+) (dtos.WebhookDeliveriesResponseDTO, error) {
+	limit := query.Limit
+	if limit == 0 {
+		limit = DefaultDeliveriesLimit
+	}
+
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+	stored, ok := store.webhooks[query.WebhookID]
+	if !ok {
+		return dtos.WebhookDeliveriesResponseDTO{}, ports.ErrWebhookNotFound
+	}
+
+	response := dtos.WebhookDeliveriesResponseDTO{Deliveries: []*dtos.WebhookDeliveryDTO{}}
+	for index := len(stored.deliveries) - 1; index >= 0; index-- {
+		delivery := stored.deliveries[index]
+		if query.Cursor != 0 && delivery.ID >= query.Cursor {
+			continue
+		}
+		if query.Status != "" && delivery.Status != query.Status {
+			continue
+		}
+		if len(response.Deliveries) == limit {
+			response.Cursor = response.Deliveries[limit-1].ID
+			break
+		}
+		response.Deliveries = append(response.Deliveries, &delivery)
+	}
+	return response, nil
+}
+
+func (store *MemoryStore) GetSubscriptions(ctx context.Context, event dtos.MovieEventDTO) ([]Subscription, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+	var subscriptions []Subscription
+	for _, id := range store.order {
+		stored := store.webhooks[id]
+		if stored.webhook.Enabled && stored.webhook.Matches(event) {
+			subscriptions = append(subscriptions, stored.subscription())
+		}
+	}
+	return subscriptions, nil
+}
+
+func (store *MemoryStore) GetSubscription(ctx context.Context, id dtos.WebhookId) (Subscription, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+	stored, ok := store.webhooks[id]
+	if !ok {
+		return Subscription{}, ports.ErrWebhookNotFound
+	}
+	return stored.subscription(), nil
+}
+
+// The deliveries of deleted webhooks are dropped.
+func (store *MemoryStore) RecordDelivery(ctx context.Context, delivery dtos.WebhookDeliveryDTO) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	stored, ok := store.webhooks[delivery.WebhookID]
+	if !ok {
+		return fmt.Errorf("could not record delivery of event %q: %w", delivery.EventID, ports.ErrWebhookNotFound)
+	}
+
+	store.lastDeliveryID++
+	delivery.ID = store.lastDeliveryID
+	stored.deliveries = append(stored.deliveries, delivery)
+	if overflow := len(stored.deliveries) - store.capacity; overflow > 0 {
+		stored.deliveries = slices.Delete(stored.deliveries, 0, overflow)
+	}
+	return nil
+}
+
+func (store *MemoryStore) MarkDelivered(ctx context.Context, id dtos.WebhookId) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	stored, ok := store.webhooks[id]
+	if !ok {
+		return ports.ErrWebhookNotFound
+	}
+	stored.webhook.ConsecutiveFailures = 0
+	return nil
+}
+
+func (store *MemoryStore) MarkFailed(ctx context.Context, id dtos.WebhookId, reason string, maxFailures int) (bool, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	stored, ok := store.webhooks[id]
+	if !ok {
+		return false, ports.ErrWebhookNotFound
+	}
+
+	stored.webhook.ConsecutiveFailures++
+	if !stored.webhook.Enabled || stored.webhook.ConsecutiveFailures < maxFailures {
+		return false, nil
+	}
+	stored.webhook.Enabled = false
+	stored.webhook.DisabledReason = reason
+	stored.webhook.UpdatedAt = time.Now().UnixMilli()
+	return true, nil
+}
+
+// A single gateway receives the events it keeps the webhooks of.
+func (store *MemoryStore) Claim(ctx context.Context, id dtos.WebhookId, eventID string) (bool, error) {
+	return true, nil
+}
+
+func (stored *storedWebhook) subscription() Subscription {
+	return Subscription{
+		ID:      stored.webhook.ID,
+		URL:     stored.webhook.URL,
+		Secret:  stored.secret,
+		Enabled: stored.webhook.Enabled,
+	}
+}
+
+// The filters are answered as an empty list instead of null.
+func eventsOrEmpty(events []dtos.MovieEventType) []dtos.MovieEventType {
+	if events == nil {
+		return []dtos.MovieEventType{}
+	}
+	return slices.Clone(events)
+}
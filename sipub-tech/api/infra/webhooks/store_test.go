@@ -0,0 +1,115 @@
+package webhooks_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/webhooks"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should only answer the secret on creation", func(t *testing.T) {
+		store := webhooks.NewMemoryStore(10)
+		saved := createWebhook(t, store, dtos.CreateWebhookDTO{URL: "http://localhost/hooks"})
+
+		assert.Equal(t, secret, saved.Secret)
+		assert.True(t, saved.Enabled)
+		assert.Equal(t, []dtos.MovieEventType{}, saved.Events)
+
+		stored, err := store.GetOne(ctx, saved.ID)
+		require.NoError(t, err)
+		assert.Empty(t, stored.Secret)
+
+		all, err := store.GetAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []dtos.WebhookResponseDTO{stored}, all)
+	})
+
+	t.Run("should fail with ports.ErrWebhookNotFound for unknown webhooks", func(t *testing.T) {
+		store := webhooks.NewMemoryStore(10)
+
+		_, err := store.GetOne(ctx, "missing")
+		assert.ErrorIs(t, err, ports.ErrWebhookNotFound)
+		_, err = store.Update(ctx, dtos.UpdateWebhookDTO{ID: "missing"})
+		assert.ErrorIs(t, err, ports.ErrWebhookNotFound)
+		assert.ErrorIs(t, store.Delete(ctx, "missing"), ports.ErrWebhookNotFound)
+		_, err = store.GetDeliveries(ctx, dtos.WebhookDeliveriesQueryDTO{WebhookID: "missing"})
+		assert.ErrorIs(t, err, ports.ErrWebhookNotFound)
+	})
+
+	t.Run("should keep the secret unless a new one is sent, and forget the failures when enabled again", func(t *testing.T) {
+		store := webhooks.NewMemoryStore(10)
+		saved := createWebhook(t, store, dtos.CreateWebhookDTO{URL: "http://localhost/hooks"})
+		disabled, err := store.MarkFailed(ctx, saved.ID, "failing", 1)
+		require.NoError(t, err)
+		require.True(t, disabled)
+
+		enabled := true
+		updated, err := store.Update(ctx, dtos.UpdateWebhookDTO{ID: saved.ID, URL: "http://localhost/other", Enabled: &enabled})
+		require.NoError(t, err)
+		assert.Equal(t, "http://localhost/other", updated.URL)
+		assert.True(t, updated.Enabled)
+		assert.Zero(t, updated.ConsecutiveFailures)
+		assert.Empty(t, updated.DisabledReason)
+
+		subscription, _ := store.GetSubscription(ctx, saved.ID)
+		assert.Equal(t, secret, subscription.Secret)
+
+		store.Update(ctx, dtos.UpdateWebhookDTO{ID: saved.ID, URL: updated.URL, Secret: "a new secret to be used"})
+		subscription, _ = store.GetSubscription(ctx, saved.ID)
+		assert.Equal(t, "a new secret to be used", subscription.Secret)
+	})
+
+	t.Run("should answer the deliveries from the newest, paginated by the cursor", func(t *testing.T) {
+		store := webhooks.NewMemoryStore(4)
+		saved := createWebhook(t, store, dtos.CreateWebhookDTO{URL: "http://localhost/hooks"})
+		for index := range 6 {
+			status := dtos.WebhookDeliverySucceeded
+			if index%2 == 0 {
+				status = dtos.WebhookDeliveryFailed
+			}
+			require.NoError(t, store.RecordDelivery(ctx, dtos.WebhookDeliveryDTO{
+				WebhookID: saved.ID, EventID: fmt.Sprintf("event-%d", index), Status: status,
+			}))
+		}
+
+		page, err := store.GetDeliveries(ctx, dtos.WebhookDeliveriesQueryDTO{WebhookID: saved.ID, Limit: 3})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"event-5", "event-4", "event-3"}, eventIDs(page.Deliveries))
+
+		page, _ = store.GetDeliveries(ctx, dtos.WebhookDeliveriesQueryDTO{WebhookID: saved.ID, Limit: 3, Cursor: page.Cursor})
+		assert.Equal(t, []string{"event-2"}, eventIDs(page.Deliveries), "the oldest deliveries should have been dropped")
+		assert.Zero(t, page.Cursor)
+
+		page, _ = store.GetDeliveries(ctx, dtos.WebhookDeliveriesQueryDTO{WebhookID: saved.ID, Status: dtos.WebhookDeliveryFailed})
+		assert.Equal(t, []string{"event-4", "event-2"}, eventIDs(page.Deliveries))
+	})
+
+	t.Run("should only subscribe the enabled webhooks matching the event", func(t *testing.T) {
+		store := webhooks.NewMemoryStore(10)
+		all := createWebhook(t, store, dtos.CreateWebhookDTO{URL: "http://localhost/all"})
+		createWebhook(t, store, dtos.CreateWebhookDTO{URL: "http://localhost/2006", Year: "2006"})
+		disabled := createWebhook(t, store, dtos.CreateWebhookDTO{URL: "http://localhost/disabled"})
+		store.MarkFailed(ctx, disabled.ID, "failing", 1)
+
+		subscriptions, err := store.GetSubscriptions(ctx, dtos.MovieEventDTO{Type: dtos.MovieCreated, Movie: dtos.MovieResponseDTO{Year: "1995"}})
+		require.NoError(t, err)
+		assert.Equal(t, []webhooks.Subscription{{ID: all.ID, URL: all.URL, Secret: secret, Enabled: true}}, subscriptions)
+	})
+}
+
+func eventIDs(deliveries []*dtos.WebhookDeliveryDTO) []string {
+	ids := make([]string, len(deliveries))
+	for index, delivery := range deliveries {
+		ids[index] = delivery.EventID
+	}
+	return ids
+}
@@ -1,30 +1,129 @@
 package main
 
 import (
+	"context"
+	"expvar"
+	"log"
 	"os"
 
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/config"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/cache"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/entrypoints"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/operations"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/services"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/streams"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/webhooks"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/api/infra/controllers"
 )
 
 func main() {
-	rabbitmqConnectionURL := os.Getenv("API_GATEWAY_RABBITMQ_CONNECTION_URL")
-	executorService := services.NewMovieMessagingService(rabbitmqConnectionURL)
+	settings := DefaultConfig()
+	config.NewLoader("api_gateway", EnvPrefix).MustLoad(&settings, os.Args[1:])
+	log.Printf("Configuration:")
+	if err := config.Print(log.Writer(), &settings); err != nil {
+		log.Printf("Failed printing the configuration: %v", err)
+	}
+
+	var executorService *services.MovieMessagingService
+	if settings.RabbitMQTLS.IsSet() {
+		var err error
+		if executorService, err = services.NewMovieMessagingServiceWithTLS(settings.RabbitMQURL, settings.RabbitMQTLS.Files()); err != nil {
+			log.Fatalf("Invalid RabbitMQ TLS configuration: %v", err)
+		}
+	} else {
+		executorService = services.NewMovieMessagingService(settings.RabbitMQURL)
+	}
 	defer executorService.Close()
+	// The events and results that cannot be decoded are logged and
+	// dropped, as the queues of the gateways have no dead letter queue.
+	expvar.Publish("rejected_messages", expvar.Func(func() any { return executorService.GetClient().Rejected() }))
 
-	gRPCConnectionUrl := os.Getenv("API_GATEWAY_GRPC_CONNECTION_URL")
-	queryService := services.NewMovieGRPCService(gRPCConnectionUrl)
+	clientConfig, err := settings.GRPC.ClientConfig()
+	if err != nil {
+		log.Fatalf("Invalid gRPC client configuration: %v", err)
+	}
+	queryService := services.NewMovieGRPCServiceWithConfig(settings.GRPCURL, clientConfig)
 	defer queryService.Close()
+	expvar.Publish("movie_service_breaker", expvar.Func(func() any { return queryService.BreakerStats() }))
+
+	cachedQueryService := cache.NewCachedMovieService(
+		queryService,
+		cache.NewLRUBackend(settings.Cache.Capacity),
+		cache.DefaultConfig(),
+	)
+	expvar.Publish("movie_cache", expvar.Func(func() any { return cachedQueryService.Stats() }))
+
+	eventBroker := streams.NewMovieEventBroker(settings.Events.ReplayCapacity, streams.DefaultSubscriberBuffer)
+	expvar.Publish("movie_event_subscribers", expvar.Func(func() any { return eventBroker.Subscribers() }))
+
+	webhookStore := newWebhookStore(settings)
+	webhookDispatcher := webhooks.NewDispatcher(webhookStore, webhooks.NewClient(), webhooks.DefaultConfig())
+	go webhookDispatcher.Run(context.Background())
+
+	// The cache is invalidated first, so the subscribers fetching the
+	// changed movies get them fresh.
+	executorService.ListenMovieEvents(
+		context.Background(),
+		cachedQueryService.Invalidate,
+		eventBroker.Publish,
+		webhookDispatcher.Dispatch,
+	)
+
+	operationStore := operations.NewMemoryStore(operations.DefaultCapacity)
+	executorService.ListenOperationResults(context.Background(), operationStore.Record)
 	
+	// The bulk operations are queued whatever the executor, as they are
+	// answered by the operation results.
+	var movieExecutor ports.MovieExecutorService = executorService
+	if settings.Executor == GRPCExecutor {
+		movieExecutor = queryService
+	}
+
 	movieController := controllers.NewMovieController()
 
 	server := entrypoints.NewGinEntrypoint(
-		executorService,
-		queryService,
+		movieExecutor,
+		cachedQueryService,
+		eventBroker,
 		movieController,
 	)
+	// As the export, the webhooks are only served with tokens.
+	if len(settings.Webhooks.Tokens) > 0 {
+		server.RegisterWebhooks(webhookStore, settings.Webhooks.Tokens, controllers.NewWebhookController())
+	}
+	server.RegisterBulkOperations(executorService, operationStore, controllers.NewOperationController())
+	// Streamed past the cache, as the listings streamed are too large to
+	// be held in it.
+	server.RegisterMovieStream(queryService, controllers.NewMovieStreamController())
+	// The export is only served when there are tokens to authenticate it.
+	if len(settings.Export.Tokens) > 0 {
+		server.RegisterExport(queryService, settings.Export.Tokens, controllers.NewExportController())
+	}
+	// As the export, the metrics are only served with tokens.
+	if len(settings.Metrics.Tokens) > 0 {
+		server.RegisterMetrics(settings.Metrics.Tokens)
+	}
 	server.Setup()
 
-	server.Serve()
+	server.Serve(settings.Port)
+}
+
+// The webhooks are only kept in DynamoDB when they are served, the
+// gateway otherwise having none to send the events to.
+func newWebhookStore(settings Config) webhooks.Store {
+	if len(settings.Webhooks.Tokens) == 0 || settings.Webhooks.Store == MemoryWebhookStore {
+		return webhooks.NewMemoryStore(webhooks.DefaultDeliveryLogCapacity)
+	}
+
+	ctx := context.Background()
+	client, err := webhooks.NewDynamoDBClient(ctx, settings.DynamoDB.Region, settings.DynamoDB.Endpoint)
+	if err != nil {
+		log.Fatalf("Invalid DynamoDB configuration: %v", err)
+	}
+	store := webhooks.NewDynamoDBStore(client, webhooks.DefaultDeliveryLogCapacity)
+	if err := store.CreateTables(ctx); err != nil {
+		log.Fatalf("Failed creating the webhook tables: %v", err)
+	}
+	return store
 }
@@ -0,0 +1,23 @@
+// Package backoff computes the waits between the retries of the services,
+// doubling from an initial wait up to a maximum.
+package backoff
+
+import "time"
+
+type Exponential struct {
+	// The wait after the first failure.
+	Initial time.Duration
+	Max time.Duration
+}
+
+// How long to wait after the given number of consecutive failures.
+func (backoff Exponential) Delay(failures int) time.Duration {
+	delay := backoff.Initial
+	for range failures - 1 {
+		if delay >= backoff.Max / 2 {
+			return backoff.Max
+		}
+		delay *= 2
+	}
+	return min(delay, backoff.Max)
+}
@@ -0,0 +1,24 @@
+package backoff_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/config/backoff"
+)
+
+func TestExponentialDelay(t *testing.T) {
+	exponential := backoff.Exponential{Initial: time.Second, Max: 10 * time.Second}
+	for failures, expected := range map[int]time.Duration{
+		0: time.Second,
+		1: time.Second,
+		2: 2 * time.Second,
+		4: 8 * time.Second,
+		5: 10 * time.Second,
+		1000: 10 * time.Second,
+	} {
+		if delay := exponential.Delay(failures); delay != expected {
+			t.Errorf("Expected %s after %d failures, found %s", expected, failures, delay)
+		}
+	}
+}
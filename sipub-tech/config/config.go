@@ -0,0 +1,335 @@
+// Package config loads the typed configuration of the services. The
+// struct given holds the defaults, which are overridden, in this order, by
+// an optional YAML file, the environment and the command line flags, and
+// is validated once loaded.
+//
+// The fields are described by their tags:
+//
+//	Port int `yaml:"port" env:"PORT" validate:"min=1,max=65535" usage:"the port listened"`
+//
+// The yaml tag names the key of the field in the file and, joined with the
+// keys of the structs holding it, its flag, as -server.port. The env tag
+// names its variable after the prefix of the loader, and a variable with
+// _FILE appended names a file with the value, as the k8s secrets mounted.
+// The secret tag hides the value when printed, fully with "true" or only
+// the password of an url with "url". The validate tag takes the rules of
+// github.com/go-playground/validator, and a struct implementing Validator
+// is checked after them.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Appended to the variable of a field to name a file with its value.
+	FileEnvSuffix = "_FILE"
+	// Appended to the prefix of the loader to name the variable of the
+	// config file.
+	ConfigFileEnv = "CONFIG_FILE"
+)
+
+// Checks what the validate tags cannot, such as fields required by others.
+type Validator interface {
+	Validate() error
+}
+
+// Every problem of the configuration loaded, so they are fixed at once.
+type Error struct {
+	Problems []string
+}
+
+func (err *Error) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(err.Problems, "\n  - ")
+}
+
+// A leaf field of the configuration.
+type field struct {
+	path string
+	env string
+	usage string
+	secret string
+	value reflect.Value
+}
+
+// Names the field by everything that sets it.
+func (field field) describe() string {
+	if field.env == "" {
+		return fmt.Sprintf("%s (-%s)", field.path, field.path)
+	}
+	return fmt.Sprintf("%s (%s, -%s)", field.path, field.env, field.path)
+}
+
+type Loader struct {
+	name string
+	prefix string
+
+	// Where the environment is read from, os.LookupEnv by default.
+	LookupEnv func(string) (string, bool)
+	// Where the usage of the flags is printed, the standard error by default.
+	Output io.Writer
+}
+
+// Loads the configuration of the command name, with the variables named
+// after prefix, as API_GATEWAY_.
+func NewLoader(name, prefix string) *Loader {
+	return &Loader{
+		name: name,
+		prefix: prefix,
+		LookupEnv: os.LookupEnv,
+		Output: os.Stderr,
+	}
+}
+
+// Loads the configuration into target, a pointer to a struct holding the
+// defaults, from the file, the environment and the flags in args. Besides
+// the flags of the fields, args take -config, the file, and -print-config,
+// which makes Load return ErrPrintConfig once the configuration is valid.
+func (loader *Loader) Load(target any, args []string) error {
+	pointer := reflect.ValueOf(target)
+	if pointer.Kind() != reflect.Pointer || pointer.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("the configuration must be a pointer to a struct, got %T", target)
+	}
+	fields, err := fieldsOf(pointer.Elem(), "", loader.prefix)
+	if err != nil {
+		return err
+	}
+
+	flags := flag.NewFlagSet(loader.name, flag.ContinueOnError)
+	flags.SetOutput(loader.Output)
+	configFile := flags.String("config", "", fmt.Sprintf("the YAML file with the configuration ($%s%s)", loader.prefix, ConfigFileEnv))
+	printConfig := flags.Bool("print-config", false, "print the configuration, with the secrets hidden, and exit")
+	flagValues := make(map[string]*string, len(fields))
+	for _, field := range fields {
+		usage := field.usage
+		if field.env != "" {
+			usage = fmt.Sprintf("%s ($%s)", usage, field.env)
+		}
+		flagValues[field.path] = flags.String(field.path, redact(field.secret, formatValue(field.value)), usage)
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *configFile == "" {
+		*configFile, _ = loader.LookupEnv(loader.prefix + ConfigFileEnv)
+	}
+	if *configFile != "" {
+		if err := loadFile(*configFile, target); err != nil {
+			return err
+		}
+	}
+
+	var problems []string
+	for _, field := range fields {
+		raw, ok, err := loader.lookup(field.env)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", field.describe(), err))
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if err := setValue(field.value, raw); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", field.describe(), err))
+		}
+	}
+	flags.Visit(func(set *flag.Flag) {
+		for _, field := range fields {
+			if field.path != set.Name {
+				continue
+			}
+			if err := setValue(field.value, *flagValues[field.path]); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", field.describe(), err))
+			}
+		}
+	})
+	if len(problems) == 0 {
+		problems = validate(target, fields)
+	}
+	if len(problems) > 0 {
+		return &Error{Problems: problems}
+	}
+	if *printConfig {
+		return ErrPrintConfig
+	}
+	return nil
+}
+
+// Returned by Load when -print-config is passed.
+var ErrPrintConfig = errors.New("configuration print requested")
+
+// Loads the configuration, exiting when it is invalid, or once it is
+// printed when -print-config is passed.
+func (loader *Loader) MustLoad(target any, args []string) {
+	err := loader.Load(target, args)
+	switch {
+	case err == nil:
+		return
+	case errors.Is(err, flag.ErrHelp):
+		os.Exit(0)
+	case errors.Is(err, ErrPrintConfig):
+		if err := Print(os.Stdout, target); err != nil {
+			fmt.Fprintf(loader.Output, "%s: %v\n", loader.name, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	default:
+		fmt.Fprintf(loader.Output, "%s: %v\n", loader.name, err)
+		os.Exit(2)
+	}
+}
+
+// The value of the variable, or of the file named by the variable with
+// FileEnvSuffix appended, without the trailing line break.
+func (loader *Loader) lookup(env string) (string, bool, error) {
+	if env == "" {
+		return "", false, nil
+	}
+	if value, ok := loader.LookupEnv(env); ok {
+		return value, true, nil
+	}
+	path, ok := loader.LookupEnv(env + FileEnvSuffix)
+	if !ok || path == "" {
+		return "", false, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("failed reading %s: %w", env + FileEnvSuffix, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), true, nil
+}
+
+// Lists the leaf fields of the struct, keyed by their yaml names joined
+// after path.
+func fieldsOf(value reflect.Value, path, prefix string) ([]field, error) {
+	var fields []field
+	for index := 0; index < value.NumField(); index++ {
+		structField := value.Type().Field(index)
+		if !structField.IsExported() {
+			continue
+		}
+		name := strings.Split(structField.Tag.Get("yaml"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(structField.Name)
+		}
+		if path != "" {
+			name = path + "." + name
+		}
+
+		fieldValue := value.Field(index)
+		if fieldValue.Kind() == reflect.Struct && fieldValue.Type() != reflect.TypeOf(time.Time{}) {
+			nested, err := fieldsOf(fieldValue, name, prefix)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, nested...)
+			continue
+		}
+		if !settable(fieldValue) {
+			return nil, fmt.Errorf("unsupported type %s of configuration %s", fieldValue.Type(), name)
+		}
+		env := structField.Tag.Get("env")
+		if env != "" {
+			env = prefix + env
+		}
+		fields = append(fields, field{
+			path: name,
+			env: env,
+			usage: structField.Tag.Get("usage"),
+			secret: structField.Tag.Get("secret"),
+			value: fieldValue,
+		})
+	}
+	return fields, nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func settable(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.String, reflect.Bool, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	case reflect.Slice:
+		return value.Type().Elem().Kind() == reflect.String
+	default:
+		return false
+	}
+}
+
+// Parses raw into the value, splitting lists on commas.
+func setValue(value reflect.Value, raw string) error {
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("malformed boolean %q", raw)
+		}
+		value.SetBool(parsed)
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("malformed number %q", raw)
+		}
+		value.SetFloat(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value.Type() == durationType {
+			parsed, err := time.ParseDuration(raw)
+			if err != nil {
+				return fmt.Errorf("malformed duration %q, expected one as 1m30s", raw)
+			}
+			value.SetInt(int64(parsed))
+			return nil
+		}
+		parsed, err := strconv.ParseInt(raw, 10, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("malformed integer %q", raw)
+		}
+		value.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(raw, 10, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("malformed positive integer %q", raw)
+		}
+		value.SetUint(parsed)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items).Convert(value.Type()))
+	}
+	return nil
+}
+
+func formatValue(value reflect.Value) string {
+	switch {
+	case value.Type() == durationType:
+		return time.Duration(value.Int()).String()
+	case value.Kind() == reflect.Slice:
+		items := make([]string, value.Len())
+		for index := range items {
+			items[index] = value.Index(index).String()
+		}
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(value.Interface())
+	}
+}
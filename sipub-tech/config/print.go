@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "<redacted>"
+
+// Prints the configuration as YAML, in the order of the fields, with the
+// secrets hidden.
+func Print(writer io.Writer, target any) error {
+	pointer := reflect.ValueOf(target)
+	if pointer.Kind() != reflect.Pointer || pointer.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("the configuration must be a pointer to a struct, got %T", target)
+	}
+	fields, err := fieldsOf(pointer.Elem(), "", "")
+	if err != nil {
+		return err
+	}
+
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	for _, field := range fields {
+		mapping := root
+		keys := strings.Split(field.path, ".")
+		for _, key := range keys[:len(keys) - 1] {
+			mapping = childMapping(mapping, key)
+		}
+		mapping.Content = append(
+			mapping.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: keys[len(keys) - 1]},
+			valueNode(field),
+		)
+	}
+
+	encoder := yaml.NewEncoder(writer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return fmt.Errorf("failed printing the configuration: %w", err)
+	}
+	return encoder.Close()
+}
+
+func childMapping(mapping *yaml.Node, key string) *yaml.Node {
+	for index := 0; index < len(mapping.Content); index += 2 {
+		if mapping.Content[index].Value == key {
+			return mapping.Content[index + 1]
+		}
+	}
+	child := &yaml.Node{Kind: yaml.MappingNode}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, child)
+	return child
+}
+
+func valueNode(field field) *yaml.Node {
+	if field.value.Kind() == reflect.Slice {
+		sequence := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for index := 0; index < field.value.Len(); index++ {
+			sequence.Content = append(sequence.Content, scalarNode(redact(field.secret, field.value.Index(index).String())))
+		}
+		return sequence
+	}
+	if field.value.Kind() != reflect.String {
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: formatValue(field.value)}
+	}
+	return scalarNode(redact(field.secret, field.value.String()))
+}
+
+// A string, quoted when it would be read as another type.
+func scalarNode(value string) *yaml.Node {
+	node := &yaml.Node{}
+	node.SetString(value)
+	return node
+}
+
+// Hides the value of a secret, or only the password of an url with the
+// secret "url". Empty values are kept, showing the secret is missing.
+func redact(secret, value string) string {
+	switch {
+	case value == "" || secret == "":
+		return value
+	case secret == "url":
+		parsed, err := url.Parse(value)
+		if err != nil {
+			return redacted
+		}
+		return parsed.Redacted()
+	default:
+		return redacted
+	}
+}
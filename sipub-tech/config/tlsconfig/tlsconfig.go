@@ -0,0 +1,199 @@
+// Package tlsconfig builds the TLS configurations of the servers and
+// clients of the services from PEM files, reloading the files once they
+// change, so the certificates are rotated without restarting. The files
+// are checked at most once per reload interval, on the handshakes, so
+// only the connections made after a rotation use the new certificates.
+package tlsconfig
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+const DefaultReloadInterval = 30 * time.Second
+
+var (
+	ErrNoCertificate = errors.New("no certificate configured")
+	ErrNoServerName  = errors.New("no server name to verify the certificate of the server")
+)
+
+// The PEM files of a server or a client.
+type Files struct {
+	// The CAs verifying the peers. The clients fall back to the CAs of the
+	// system when it is empty, and the servers only verify the
+	// certificates of the clients, as mTLS, when it is set.
+	CA string
+	// The certificate and its key, required by the servers, and presented
+	// by the clients of a server verifying them.
+	Cert string
+	Key  string
+	// The name the clients verify in the certificate of the server, the
+	// ServerName of the connection when empty. As no name is sent to a
+	// server dialed by its IP address, it is then required, and may be the
+	// address itself, verified against the IP SANs.
+	ServerName string
+	// How often the files are checked for changes, never when 0.
+	ReloadInterval time.Duration
+}
+
+// Reloads the files when they change, keeping the certificates loaded
+// last when the new ones fail to load, as a rotation half written.
+type Reloader struct {
+	files Files
+
+	mutex       sync.Mutex
+	checkedAt   time.Time
+	contents    [3][]byte
+	certificate *tls.Certificate
+	pool        *x509.CertPool
+}
+
+// Loads the files, failing when they cannot be.
+func NewReloader(files Files) (*Reloader, error) {
+	reloader := &Reloader{files: files}
+	if err := reloader.reload(); err != nil {
+		return nil, err
+	}
+	reloader.checkedAt = time.Now()
+	return reloader, nil
+}
+
+// The configuration of a server presenting the certificate, and verifying
+// the certificates of the clients with the CAs, when set.
+func (reloader *Reloader) ServerConfig() (*tls.Config, error) {
+	if reloader.files.Cert == "" {
+		return nil, fmt.Errorf("tls server: %w", ErrNoCertificate)
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			certificate, pool := reloader.current()
+			config := &tls.Config{
+				MinVersion: tls.VersionTLS12,
+				Certificates: []tls.Certificate{*certificate},
+			}
+			if pool != nil {
+				config.ClientCAs = pool
+				config.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+			return config, nil
+		},
+	}, nil
+}
+
+// The configuration of a client verifying the server with the CAs, and
+// presenting the certificate when set.
+func (reloader *Reloader) ClientConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// The certificate of the server is verified by VerifyConnection,
+		// with the CAs loaded last, as RootCAs cannot be reloaded.
+		InsecureSkipVerify: true,
+		VerifyConnection: func(state tls.ConnectionState) error {
+			_, pool := reloader.current()
+			return verifyServer(state, pool, reloader.files.ServerName)
+		},
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			certificate, _ := reloader.current()
+			if certificate == nil {
+				return &tls.Certificate{}, nil
+			}
+			return certificate, nil
+		},
+	}
+}
+
+func verifyServer(state tls.ConnectionState, pool *x509.CertPool, serverName string) error {
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("tls: the server sent no certificate")
+	}
+	if serverName == "" {
+		serverName = state.ServerName
+	}
+	// Verify skips the name when it is empty, accepting any certificate
+	// of the CAs.
+	if serverName == "" {
+		return fmt.Errorf("tls: %w", ErrNoServerName)
+	}
+	options := x509.VerifyOptions{
+		DNSName: serverName,
+		Roots: pool,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, intermediate := range state.PeerCertificates[1:] {
+		options.Intermediates.AddCert(intermediate)
+	}
+	_, err := state.PeerCertificates[0].Verify(options)
+	return err
+}
+
+func (reloader *Reloader) unchanged(contents [3][]byte) bool {
+	for index := range contents {
+		if !bytes.Equal(contents[index], reloader.contents[index]) {
+			return false
+		}
+	}
+	return true
+}
+
+// The certificate and the CAs, reloaded first when the interval passed.
+func (reloader *Reloader) current() (*tls.Certificate, *x509.CertPool) {
+	reloader.mutex.Lock()
+	defer reloader.mutex.Unlock()
+
+	interval := reloader.files.ReloadInterval
+	if interval > 0 && time.Now().Sub(reloader.checkedAt) >= interval {
+		reloader.checkedAt = time.Now()
+		if err := reloader.reload(); err != nil {
+			log.Printf("Failed reloading the TLS files, keeping the ones loaded: %v", err)
+		}
+	}
+	return reloader.certificate, reloader.pool
+}
+
+// Loads the files that changed since they were loaded last. Must be
+// called with the mutex held, but for NewReloader.
+func (reloader *Reloader) reload() error {
+	var contents [3][]byte
+	for index, path := range []string{reloader.files.CA, reloader.files.Cert, reloader.files.Key} {
+		if path == "" {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed reading %s: %w", path, err)
+		}
+		contents[index] = content
+	}
+	if reloader.unchanged(contents) {
+		return nil
+	}
+
+	var pool *x509.CertPool
+	if reloader.files.CA != "" {
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(contents[0]) {
+			return fmt.Errorf("no certificates found in %s", reloader.files.CA)
+		}
+	}
+	var certificate *tls.Certificate
+	if reloader.files.Cert != "" || reloader.files.Key != "" {
+		loaded, err := tls.X509KeyPair(contents[1], contents[2])
+		if err != nil {
+			return fmt.Errorf("failed loading %s and %s: %w", reloader.files.Cert, reloader.files.Key, err)
+		}
+		certificate = &loaded
+	}
+
+	reloader.contents = contents
+	reloader.pool = pool
+	reloader.certificate = certificate
+	return nil
+}
@@ -0,0 +1,180 @@
+package tlsconfig_test
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/config/tlsconfig"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/config/tlsconfig/tlstest"
+)
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	ca := tlstest.NewCA(t, "sipub")
+	caFile := ca.WriteCert(t, dir)
+	serverCert, serverKey := ca.Issue(t, dir, "server", "localhost", "127.0.0.1")
+	clientCert, clientKey := ca.Issue(t, dir, "client")
+
+	// Checked on every handshake.
+	server := newReloader(t, tlsconfig.Files{CA: caFile, Cert: serverCert, Key: serverKey, ReloadInterval: time.Nanosecond})
+	address := listen(t, server)
+
+	t.Run("should accept the clients with a certificate of the CA", func(t *testing.T) {
+		client := newReloader(t, tlsconfig.Files{CA: caFile, Cert: clientCert, Key: clientKey})
+		if err := handshake(address, client); err != nil {
+			t.Errorf("Expected the handshake to succeed, found %v", err)
+		}
+	})
+
+	t.Run("should refuse the clients without a certificate", func(t *testing.T) {
+		client := newReloader(t, tlsconfig.Files{CA: caFile})
+		if err := handshake(address, client); err == nil {
+			t.Errorf("Expected the client without a certificate to be refused")
+		}
+	})
+
+	t.Run("should refuse the clients and the servers of another CA", func(t *testing.T) {
+		other := tlstest.NewCA(t, "other")
+		otherCert, otherKey := other.Issue(t, dir, "intruder")
+		client := newReloader(t, tlsconfig.Files{CA: caFile, Cert: otherCert, Key: otherKey})
+		if err := handshake(address, client); err == nil {
+			t.Errorf("Expected the client of another CA to be refused")
+		}
+
+		client = newReloader(t, tlsconfig.Files{CA: other.WriteCert(t, dir), Cert: clientCert, Key: clientKey})
+		if err := handshake(address, client); err == nil {
+			t.Errorf("Expected the server of another CA to be refused")
+		}
+	})
+
+	t.Run("should present the certificate rotated, keeping the last one when the new fails to load", func(t *testing.T) {
+		rotated := tlstest.NewCA(t, "rotated")
+		rotatedCert, rotatedKey := rotated.Issue(t, dir, "server-rotated", "localhost", "127.0.0.1")
+		copyFile(t, rotatedCert, serverCert)
+		copyFile(t, rotatedKey, serverKey)
+
+		client := newReloader(t, tlsconfig.Files{CA: rotated.WriteCert(t, dir), Cert: clientCert, Key: clientKey})
+		if err := handshake(address, client); err != nil {
+			t.Errorf("Expected the rotated certificate to be presented, found %v", err)
+		}
+
+		if err := os.WriteFile(serverKey, []byte("half written"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := handshake(address, client); err != nil {
+			t.Errorf("Expected the certificate loaded last to be kept, found %v", err)
+		}
+	})
+}
+
+func TestReloaderServerName(t *testing.T) {
+	dir := t.TempDir()
+	ca := tlstest.NewCA(t, "sipub")
+	caFile := ca.WriteCert(t, dir)
+	serverCert, serverKey := ca.Issue(t, dir, "server", "localhost", "127.0.0.1")
+	address := listen(t, newReloader(t, tlsconfig.Files{Cert: serverCert, Key: serverKey}))
+
+	t.Run("should refuse a server dialed by its address without a server name", func(t *testing.T) {
+		client := newReloader(t, tlsconfig.Files{CA: caFile})
+		if err := dial(address, client.ClientConfig()); !errors.Is(err, tlsconfig.ErrNoServerName) {
+			t.Errorf("Expected %v, found %v", tlsconfig.ErrNoServerName, err)
+		}
+	})
+
+	t.Run("should verify the server name configured against the certificate", func(t *testing.T) {
+		client := newReloader(t, tlsconfig.Files{CA: caFile, ServerName: "127.0.0.1"})
+		if err := dial(address, client.ClientConfig()); err != nil {
+			t.Errorf("Expected the address in the IP SANs to be accepted, found %v", err)
+		}
+
+		client = newReloader(t, tlsconfig.Files{CA: caFile, ServerName: "movies.example"})
+		if err := dial(address, client.ClientConfig()); err == nil {
+			t.Errorf("Expected a name missing from the certificate to be refused")
+		}
+	})
+}
+
+func TestNewReloader(t *testing.T) {
+	t.Run("should fail for the files that cannot be loaded", func(t *testing.T) {
+		if _, err := tlsconfig.NewReloader(tlsconfig.Files{CA: "missing.pem"}); err == nil {
+			t.Errorf("Expected an error for a missing file")
+		}
+	})
+
+	t.Run("should not build a server config without a certificate", func(t *testing.T) {
+		reloader := newReloader(t, tlsconfig.Files{})
+		if _, err := reloader.ServerConfig(); !errors.Is(err, tlsconfig.ErrNoCertificate) {
+			t.Errorf("Expected ErrNoCertificate, found %v", err)
+		}
+	})
+}
+
+func newReloader(t *testing.T, files tlsconfig.Files) *tlsconfig.Reloader {
+	reloader, err := tlsconfig.NewReloader(files)
+	if err != nil {
+		t.Fatalf("Failed loading %+v: %v", files, err)
+	}
+	return reloader
+}
+
+// Serves TLS with the configuration of reloader, answering a byte to each
+// connection once the handshake succeeds.
+func listen(t *testing.T, reloader *tlsconfig.Reloader) string {
+	config, err := reloader.ServerConfig()
+	if err != nil {
+		t.Fatalf("Failed building the server config: %v", err)
+	}
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", config)
+	if err != nil {
+		t.Fatalf("Failed listening: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				if conn.(*tls.Conn).Handshake() == nil {
+					conn.Write([]byte{1})
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// Connects to address as localhost, reading the byte of the server, as
+// the client only learns of a refused certificate once it reads.
+func handshake(address string, reloader *tlsconfig.Reloader) error {
+	config := reloader.ClientConfig()
+	config.ServerName = "localhost"
+	return dial(address, config)
+}
+
+func dial(address string, config *tls.Config) error {
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: time.Second}, "tcp", address, config)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = conn.Read(make([]byte, 1))
+	return err
+}
+
+func copyFile(t *testing.T, from, to string) {
+	content, err := os.ReadFile(from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(to, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Checks the validate tags of the fields and then the Validator of target,
+// describing each problem with the variable and flag that fix it.
+func validate(target any, fields []field) []string {
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	validate.RegisterTagNameFunc(func(structField reflect.StructField) string {
+		name := strings.Split(structField.Tag.Get("yaml"), ",")[0]
+		if name == "" {
+			return strings.ToLower(structField.Name)
+		}
+		return name
+	})
+
+	var problems []string
+	var validationErrors validator.ValidationErrors
+	if err := validate.Struct(target); errors.As(err, &validationErrors) {
+		for _, fieldError := range validationErrors {
+			problems = append(problems, describeProblem(fieldError, fields))
+		}
+	} else if err != nil {
+		problems = append(problems, err.Error())
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+
+	if validator, ok := target.(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	return problems
+}
+
+func describeProblem(fieldError validator.FieldError, fields []field) string {
+	path := fieldError.Namespace()
+	if _, after, found := strings.Cut(path, "."); found {
+		path = after
+	}
+	described := field{path: path}
+	for _, field := range fields {
+		if field.path == path {
+			described = field
+		}
+	}
+
+	problem := fmt.Sprintf("%s %s", described.describe(), ruleMessage(fieldError))
+	if value := fmt.Sprint(fieldError.Value()); described.secret == "" && value != "" && fieldError.Tag() != "required" {
+		problem += fmt.Sprintf(", got %q", value)
+	}
+	return problem
+}
+
+func ruleMessage(fieldError validator.FieldError) string {
+	param := fieldError.Param()
+	counted := ""
+	switch fieldError.Kind() {
+	case reflect.String:
+		counted = " characters"
+	case reflect.Slice, reflect.Map:
+		counted = " items"
+	}
+	switch fieldError.Tag() {
+	case "required":
+		return "is required"
+	case "required_if":
+		return fmt.Sprintf("is required when %s", strings.Replace(param, " ", " is ", 1))
+	case "min", "gte":
+		return fmt.Sprintf("must be at least %s%s", param, counted)
+	case "max", "lte":
+		return fmt.Sprintf("must be at most %s%s", param, counted)
+	case "gt":
+		return fmt.Sprintf("must be greater than %s%s", param, counted)
+	case "lt":
+		return fmt.Sprintf("must be less than %s%s", param, counted)
+	case "oneof":
+		return fmt.Sprintf("must be one of %s", strings.ReplaceAll(param, " ", ", "))
+	case "url", "http_url":
+		return "must be an url"
+	case "hostname_port":
+		return "must be a host:port address"
+	case "dive":
+		return "has an invalid item"
+	default:
+		return fmt.Sprintf("fails the %s rule", fieldError.Tag())
+	}
+}
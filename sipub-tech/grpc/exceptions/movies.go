@@ -1,5 +1,20 @@
 package exceptions
 
-import "fmt"
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
 
-var ErrMovieNotFound = fmt.Errorf("movie not found")
+// Errors are gRPC statuses so that clients can tell them apart by their
+// code with status.Code, as the message does not survive the wire as a
+// comparable value.
+var ErrMovieNotFound = status.Error(codes.NotFound, "movie not found")
+
+var ErrTooManyMovieIds = status.Error(codes.InvalidArgument, "too many movie ids")
+
+var ErrVersionMismatch = status.Error(codes.FailedPrecondition, "movie is not on the expected version")
+
+// The movie of a write is invalid, as described by err.
+func NewInvalidMovieError(err error) error {
+	return status.Error(codes.InvalidArgument, err.Error())
+}
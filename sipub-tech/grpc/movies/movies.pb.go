@@ -1,16 +1,19 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.25.0-devel
-// 	protoc        v3.14.0
+// 	protoc-gen-go v1.36.7
+// 	protoc        v3.21.12
 // source: movies.proto
 
 package movies
 
 import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
+	unsafe "unsafe"
 )
 
 const (
@@ -21,22 +24,19 @@ const (
 )
 
 type GetMoviesRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Year          string                 `protobuf:"bytes,1,opt,name=year,proto3" json:"year,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Cursor        int64                  `protobuf:"varint,3,opt,name=cursor,proto3" json:"cursor,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Year   string `protobuf:"bytes,1,opt,name=year,proto3" json:"year,omitempty"`
-	Limit  int32  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
-	Cursor int32  `protobuf:"varint,3,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetMoviesRequest) Reset() {
 	*x = GetMoviesRequest{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_movies_proto_msgTypes[0]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_movies_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *GetMoviesRequest) String() string {
@@ -47,7 +47,7 @@ func (*GetMoviesRequest) ProtoMessage() {}
 
 func (x *GetMoviesRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_movies_proto_msgTypes[0]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -76,7 +76,7 @@ func (x *GetMoviesRequest) GetLimit() int32 {
 	return 0
 }
 
-func (x *GetMoviesRequest) GetCursor() int32 {
+func (x *GetMoviesRequest) GetCursor() int64 {
 	if x != nil {
 		return x.Cursor
 	}
@@ -84,20 +84,17 @@ func (x *GetMoviesRequest) GetCursor() int32 {
 }
 
 type GetMovieRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Id int32 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetMovieRequest) Reset() {
 	*x = GetMovieRequest{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_movies_proto_msgTypes[1]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_movies_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *GetMovieRequest) String() string {
@@ -108,7 +105,7 @@ func (*GetMovieRequest) ProtoMessage() {}
 
 func (x *GetMovieRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_movies_proto_msgTypes[1]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -123,30 +120,403 @@ func (*GetMovieRequest) Descriptor() ([]byte, []int) {
 	return file_movies_proto_rawDescGZIP(), []int{1}
 }
 
-func (x *GetMovieRequest) GetId() int32 {
+func (x *GetMovieRequest) GetId() int64 {
 	if x != nil {
 		return x.Id
 	}
 	return 0
 }
 
-type Movie struct {
-	state         protoimpl.MessageState
+type BatchGetMoviesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []int64                `protobuf:"varint,1,rep,packed,name=ids,proto3" json:"ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetMoviesRequest) Reset() {
+	*x = BatchGetMoviesRequest{}
+	mi := &file_movies_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetMoviesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetMoviesRequest) ProtoMessage() {}
+
+func (x *BatchGetMoviesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_movies_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetMoviesRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetMoviesRequest) Descriptor() ([]byte, []int) {
+	return file_movies_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BatchGetMoviesRequest) GetIds() []int64 {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+type ExportMoviesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// How many segments of the table are scanned at a time, a default when
+	// 0.
+	Segments      int32 `protobuf:"varint,1,opt,name=segments,proto3" json:"segments,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportMoviesRequest) Reset() {
+	*x = ExportMoviesRequest{}
+	mi := &file_movies_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportMoviesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportMoviesRequest) ProtoMessage() {}
+
+func (x *ExportMoviesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_movies_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportMoviesRequest.ProtoReflect.Descriptor instead.
+func (*ExportMoviesRequest) Descriptor() ([]byte, []int) {
+	return file_movies_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ExportMoviesRequest) GetSegments() int32 {
+	if x != nil {
+		return x.Segments
+	}
+	return 0
+}
+
+type StreamMoviesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only the movies of this year, when set.
+	Year string `protobuf:"bytes,1,opt,name=year,proto3" json:"year,omitempty"`
+	// Starts after the movie with this id, as the cursor of GetMovies.
+	Cursor int64 `protobuf:"varint,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	// The most movies streamed, every movie when 0.
+	Limit         int64 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamMoviesRequest) Reset() {
+	*x = StreamMoviesRequest{}
+	mi := &file_movies_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamMoviesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamMoviesRequest) ProtoMessage() {}
+
+func (x *StreamMoviesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_movies_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamMoviesRequest.ProtoReflect.Descriptor instead.
+func (*StreamMoviesRequest) Descriptor() ([]byte, []int) {
+	return file_movies_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *StreamMoviesRequest) GetYear() string {
+	if x != nil {
+		return x.Year
+	}
+	return ""
+}
+
+func (x *StreamMoviesRequest) GetCursor() int64 {
+	if x != nil {
+		return x.Cursor
+	}
+	return 0
+}
+
+func (x *StreamMoviesRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type CreateMovieRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	Year          string                 `protobuf:"bytes,2,opt,name=year,proto3" json:"year,omitempty"`
+	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateMovieRequest) Reset() {
+	*x = CreateMovieRequest{}
+	mi := &file_movies_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateMovieRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateMovieRequest) ProtoMessage() {}
+
+func (x *CreateMovieRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_movies_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateMovieRequest.ProtoReflect.Descriptor instead.
+func (*CreateMovieRequest) Descriptor() ([]byte, []int) {
+	return file_movies_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CreateMovieRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *CreateMovieRequest) GetYear() string {
+	if x != nil {
+		return x.Year
+	}
+	return ""
+}
+
+type UpdateMovieRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Year  string                 `protobuf:"bytes,3,opt,name=year,proto3" json:"year,omitempty"`
+	// Only updates the movie if it is still on this version, when not 0.
+	ExpectedVersion int64 `protobuf:"varint,4,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *UpdateMovieRequest) Reset() {
+	*x = UpdateMovieRequest{}
+	mi := &file_movies_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateMovieRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateMovieRequest) ProtoMessage() {}
+
+func (x *UpdateMovieRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_movies_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateMovieRequest.ProtoReflect.Descriptor instead.
+func (*UpdateMovieRequest) Descriptor() ([]byte, []int) {
+	return file_movies_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UpdateMovieRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateMovieRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *UpdateMovieRequest) GetYear() string {
+	if x != nil {
+		return x.Year
+	}
+	return ""
+}
+
+func (x *UpdateMovieRequest) GetExpectedVersion() int64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+type DeleteMovieRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Only deletes the movie if it is still on this version, when not 0.
+	ExpectedVersion int64 `protobuf:"varint,2,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *DeleteMovieRequest) Reset() {
+	*x = DeleteMovieRequest{}
+	mi := &file_movies_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteMovieRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteMovieRequest) ProtoMessage() {}
+
+func (x *DeleteMovieRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_movies_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteMovieRequest.ProtoReflect.Descriptor instead.
+func (*DeleteMovieRequest) Descriptor() ([]byte, []int) {
+	return file_movies_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DeleteMovieRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DeleteMovieRequest) GetExpectedVersion() int64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+type DeleteMovieResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The movie deleted, unset when there was none.
+	Movie         *Movie `protobuf:"bytes,1,opt,name=movie,proto3" json:"movie,omitempty"`
 	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteMovieResponse) Reset() {
+	*x = DeleteMovieResponse{}
+	mi := &file_movies_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteMovieResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteMovieResponse) ProtoMessage() {}
+
+func (x *DeleteMovieResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_movies_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteMovieResponse.ProtoReflect.Descriptor instead.
+func (*DeleteMovieResponse) Descriptor() ([]byte, []int) {
+	return file_movies_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DeleteMovieResponse) GetMovie() *Movie {
+	if x != nil {
+		return x.Movie
+	}
+	return nil
+}
 
-	Id    int32  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+type Movie struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Ids may exceed 32 bits, but always fit in 53 bits.
+	Id    int64  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
 	Title string `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
 	Year  string `protobuf:"bytes,3,opt,name=year,proto3" json:"year,omitempty"`
+	// Starts at 1 and is incremented on every update of the movie.
+	Version       int64                  `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Movie) Reset() {
 	*x = Movie{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_movies_proto_msgTypes[2]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_movies_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *Movie) String() string {
@@ -156,8 +526,8 @@ func (x *Movie) String() string {
 func (*Movie) ProtoMessage() {}
 
 func (x *Movie) ProtoReflect() protoreflect.Message {
-	mi := &file_movies_proto_msgTypes[2]
-	if protoimpl.UnsafeEnabled && x != nil {
+	mi := &file_movies_proto_msgTypes[9]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -169,10 +539,10 @@ func (x *Movie) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Movie.ProtoReflect.Descriptor instead.
 func (*Movie) Descriptor() ([]byte, []int) {
-	return file_movies_proto_rawDescGZIP(), []int{2}
+	return file_movies_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *Movie) GetId() int32 {
+func (x *Movie) GetId() int64 {
 	if x != nil {
 		return x.Id
 	}
@@ -193,22 +563,33 @@ func (x *Movie) GetYear() string {
 	return ""
 }
 
+func (x *Movie) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Movie) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
 type Movies struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Movies        []*Movie               `protobuf:"bytes,1,rep,name=movies,proto3" json:"movies,omitempty"`
+	Cursor        int64                  `protobuf:"varint,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Movies []*Movie `protobuf:"bytes,1,rep,name=movies,proto3" json:"movies,omitempty"`
-	Cursor int32    `protobuf:"varint,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Movies) Reset() {
 	*x = Movies{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_movies_proto_msgTypes[3]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_movies_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *Movies) String() string {
@@ -218,8 +599,8 @@ func (x *Movies) String() string {
 func (*Movies) ProtoMessage() {}
 
 func (x *Movies) ProtoReflect() protoreflect.Message {
-	mi := &file_movies_proto_msgTypes[3]
-	if protoimpl.UnsafeEnabled && x != nil {
+	mi := &file_movies_proto_msgTypes[10]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -231,7 +612,7 @@ func (x *Movies) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Movies.ProtoReflect.Descriptor instead.
 func (*Movies) Descriptor() ([]byte, []int) {
-	return file_movies_proto_rawDescGZIP(), []int{3}
+	return file_movies_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *Movies) GetMovies() []*Movie {
@@ -241,75 +622,178 @@ func (x *Movies) GetMovies() []*Movie {
 	return nil
 }
 
-func (x *Movies) GetCursor() int32 {
+func (x *Movies) GetCursor() int64 {
 	if x != nil {
 		return x.Cursor
 	}
 	return 0
 }
 
-var File_movies_proto protoreflect.FileDescriptor
+// The movies found, in the order of their ids in the request, and the ids
+// of the movies that do not exist.
+type MoviesBatch struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Movies        []*Movie               `protobuf:"bytes,1,rep,name=movies,proto3" json:"movies,omitempty"`
+	MissingIds    []int64                `protobuf:"varint,2,rep,packed,name=missing_ids,json=missingIds,proto3" json:"missing_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MoviesBatch) Reset() {
+	*x = MoviesBatch{}
+	mi := &file_movies_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MoviesBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MoviesBatch) ProtoMessage() {}
+
+func (x *MoviesBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_movies_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
 
-var file_movies_proto_rawDesc = []byte{
-	0x0a, 0x0c, 0x6d, 0x6f, 0x76, 0x69, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06,
-	0x6d, 0x6f, 0x76, 0x69, 0x65, 0x73, 0x22, 0x54, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x4d, 0x6f, 0x76,
-	0x69, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x79, 0x65,
-	0x61, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x79, 0x65, 0x61, 0x72, 0x12, 0x14,
-	0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6c,
-	0x69, 0x6d, 0x69, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x18, 0x03,
-	0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x22, 0x21, 0x0a, 0x0f,
-	0x47, 0x65, 0x74, 0x4d, 0x6f, 0x76, 0x69, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
-	0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x22,
-	0x41, 0x0a, 0x05, 0x4d, 0x6f, 0x76, 0x69, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c,
-	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x12,
-	0x0a, 0x04, 0x79, 0x65, 0x61, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x79, 0x65,
-	0x61, 0x72, 0x22, 0x47, 0x0a, 0x06, 0x4d, 0x6f, 0x76, 0x69, 0x65, 0x73, 0x12, 0x25, 0x0a, 0x06,
-	0x6d, 0x6f, 0x76, 0x69, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x6d,
-	0x6f, 0x76, 0x69, 0x65, 0x73, 0x2e, 0x4d, 0x6f, 0x76, 0x69, 0x65, 0x52, 0x06, 0x6d, 0x6f, 0x76,
-	0x69, 0x65, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x05, 0x52, 0x06, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x32, 0x79, 0x0a, 0x0c, 0x4d,
-	0x6f, 0x76, 0x69, 0x65, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x35, 0x0a, 0x09, 0x47,
-	0x65, 0x74, 0x4d, 0x6f, 0x76, 0x69, 0x65, 0x73, 0x12, 0x18, 0x2e, 0x6d, 0x6f, 0x76, 0x69, 0x65,
-	0x73, 0x2e, 0x47, 0x65, 0x74, 0x4d, 0x6f, 0x76, 0x69, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x1a, 0x0e, 0x2e, 0x6d, 0x6f, 0x76, 0x69, 0x65, 0x73, 0x2e, 0x4d, 0x6f, 0x76, 0x69,
-	0x65, 0x73, 0x12, 0x32, 0x0a, 0x08, 0x47, 0x65, 0x74, 0x4d, 0x6f, 0x76, 0x69, 0x65, 0x12, 0x17,
-	0x2e, 0x6d, 0x6f, 0x76, 0x69, 0x65, 0x73, 0x2e, 0x47, 0x65, 0x74, 0x4d, 0x6f, 0x76, 0x69, 0x65,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0d, 0x2e, 0x6d, 0x6f, 0x76, 0x69, 0x65, 0x73,
-	0x2e, 0x4d, 0x6f, 0x76, 0x69, 0x65, 0x42, 0x0a, 0x5a, 0x08, 0x2e, 0x2f, 0x6d, 0x6f, 0x76, 0x69,
-	0x65, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+// Deprecated: Use MoviesBatch.ProtoReflect.Descriptor instead.
+func (*MoviesBatch) Descriptor() ([]byte, []int) {
+	return file_movies_proto_rawDescGZIP(), []int{11}
 }
 
+func (x *MoviesBatch) GetMovies() []*Movie {
+	if x != nil {
+		return x.Movies
+	}
+	return nil
+}
+
+func (x *MoviesBatch) GetMissingIds() []int64 {
+	if x != nil {
+		return x.MissingIds
+	}
+	return nil
+}
+
+var File_movies_proto protoreflect.FileDescriptor
+
+const file_movies_proto_rawDesc = "" +
+	"\n" +
+	"\fmovies.proto\x12\x06movies\x1a\x1cgoogle/api/annotations.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"T\n" +
+	"\x10GetMoviesRequest\x12\x12\n" +
+	"\x04year\x18\x01 \x01(\tR\x04year\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06cursor\x18\x03 \x01(\x03R\x06cursor\"!\n" +
+	"\x0fGetMovieRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\")\n" +
+	"\x15BatchGetMoviesRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\x03R\x03ids\"1\n" +
+	"\x13ExportMoviesRequest\x12\x1a\n" +
+	"\bsegments\x18\x01 \x01(\x05R\bsegments\"W\n" +
+	"\x13StreamMoviesRequest\x12\x12\n" +
+	"\x04year\x18\x01 \x01(\tR\x04year\x12\x16\n" +
+	"\x06cursor\x18\x02 \x01(\x03R\x06cursor\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x03R\x05limit\">\n" +
+	"\x12CreateMovieRequest\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12\x12\n" +
+	"\x04year\x18\x02 \x01(\tR\x04year\"y\n" +
+	"\x12UpdateMovieRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x12\n" +
+	"\x04year\x18\x03 \x01(\tR\x04year\x12)\n" +
+	"\x10expected_version\x18\x04 \x01(\x03R\x0fexpectedVersion\"O\n" +
+	"\x12DeleteMovieRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12)\n" +
+	"\x10expected_version\x18\x02 \x01(\x03R\x0fexpectedVersion\":\n" +
+	"\x13DeleteMovieResponse\x12#\n" +
+	"\x05movie\x18\x01 \x01(\v2\r.movies.MovieR\x05movie\"\x96\x01\n" +
+	"\x05Movie\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x12\n" +
+	"\x04year\x18\x03 \x01(\tR\x04year\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\x03R\aversion\x129\n" +
+	"\n" +
+	"updated_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"G\n" +
+	"\x06Movies\x12%\n" +
+	"\x06movies\x18\x01 \x03(\v2\r.movies.MovieR\x06movies\x12\x16\n" +
+	"\x06cursor\x18\x02 \x01(\x03R\x06cursor\"U\n" +
+	"\vMoviesBatch\x12%\n" +
+	"\x06movies\x18\x01 \x03(\v2\r.movies.MovieR\x06movies\x12\x1f\n" +
+	"\vmissing_ids\x18\x02 \x03(\x03R\n" +
+	"missingIds2\x8e\x06\n" +
+	"\fMovieService\x12R\n" +
+	"\tGetMovies\x12\x18.movies.GetMoviesRequest\x1a\x0e.movies.Movies\"\x1b\x82\xd3\xe4\x93\x02\x15\x12\x13/internal/v1/movies\x12T\n" +
+	"\bGetMovie\x12\x17.movies.GetMovieRequest\x1a\r.movies.Movie\" \x82\xd3\xe4\x93\x02\x1a\x12\x18/internal/v1/movies/{id}\x12m\n" +
+	"\x0eBatchGetMovies\x12\x1d.movies.BatchGetMoviesRequest\x1a\x13.movies.MoviesBatch\"'\x82\xd3\xe4\x93\x02!:\x01*\"\x1c/internal/v1/movies:batchGet\x12`\n" +
+	"\fExportMovies\x12\x1b.movies.ExportMoviesRequest\x1a\r.movies.Movie\"\"\x82\xd3\xe4\x93\x02\x1c\x12\x1a/internal/v1/movies:export0\x01\x12`\n" +
+	"\fStreamMovies\x12\x1b.movies.StreamMoviesRequest\x1a\r.movies.Movie\"\"\x82\xd3\xe4\x93\x02\x1c\x12\x1a/internal/v1/movies:stream0\x01\x12X\n" +
+	"\vCreateMovie\x12\x1a.movies.CreateMovieRequest\x1a\r.movies.Movie\"\x1e\x82\xd3\xe4\x93\x02\x18:\x01*\"\x13/internal/v1/movies\x12]\n" +
+	"\vUpdateMovie\x12\x1a.movies.UpdateMovieRequest\x1a\r.movies.Movie\"#\x82\xd3\xe4\x93\x02\x1d:\x01*\x1a\x18/internal/v1/movies/{id}\x12h\n" +
+	"\vDeleteMovie\x12\x1a.movies.DeleteMovieRequest\x1a\x1b.movies.DeleteMovieResponse\" \x82\xd3\xe4\x93\x02\x1a*\x18/internal/v1/movies/{id}B\n" +
+	"Z\b./moviesb\x06proto3"
+
 var (
 	file_movies_proto_rawDescOnce sync.Once
-	file_movies_proto_rawDescData = file_movies_proto_rawDesc
+	file_movies_proto_rawDescData []byte
 )
 
 func file_movies_proto_rawDescGZIP() []byte {
 	file_movies_proto_rawDescOnce.Do(func() {
-		file_movies_proto_rawDescData = protoimpl.X.CompressGZIP(file_movies_proto_rawDescData)
+		file_movies_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_movies_proto_rawDesc), len(file_movies_proto_rawDesc)))
 	})
 	return file_movies_proto_rawDescData
 }
 
-var file_movies_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
-var file_movies_proto_goTypes = []interface{}{
-	(*GetMoviesRequest)(nil), // 0: movies.GetMoviesRequest
-	(*GetMovieRequest)(nil),  // 1: movies.GetMovieRequest
-	(*Movie)(nil),            // 2: movies.Movie
-	(*Movies)(nil),           // 3: movies.Movies
+var file_movies_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_movies_proto_goTypes = []any{
+	(*GetMoviesRequest)(nil),      // 0: movies.GetMoviesRequest
+	(*GetMovieRequest)(nil),       // 1: movies.GetMovieRequest
+	(*BatchGetMoviesRequest)(nil), // 2: movies.BatchGetMoviesRequest
+	(*ExportMoviesRequest)(nil),   // 3: movies.ExportMoviesRequest
+	(*StreamMoviesRequest)(nil),   // 4: movies.StreamMoviesRequest
+	(*CreateMovieRequest)(nil),    // 5: movies.CreateMovieRequest
+	(*UpdateMovieRequest)(nil),    // 6: movies.UpdateMovieRequest
+	(*DeleteMovieRequest)(nil),    // 7: movies.DeleteMovieRequest
+	(*DeleteMovieResponse)(nil),   // 8: movies.DeleteMovieResponse
+	(*Movie)(nil),                 // 9: movies.Movie
+	(*Movies)(nil),                // 10: movies.Movies
+	(*MoviesBatch)(nil),           // 11: movies.MoviesBatch
+	(*timestamppb.Timestamp)(nil), // 12: google.protobuf.Timestamp
 }
 var file_movies_proto_depIdxs = []int32{
-	2, // 0: movies.Movies.movies:type_name -> movies.Movie
-	0, // 1: movies.MovieService.GetMovies:input_type -> movies.GetMoviesRequest
-	1, // 2: movies.MovieService.GetMovie:input_type -> movies.GetMovieRequest
-	3, // 3: movies.MovieService.GetMovies:output_type -> movies.Movies
-	2, // 4: movies.MovieService.GetMovie:output_type -> movies.Movie
-	3, // [3:5] is the sub-list for method output_type
-	1, // [1:3] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	9,  // 0: movies.DeleteMovieResponse.movie:type_name -> movies.Movie
+	12, // 1: movies.Movie.updated_at:type_name -> google.protobuf.Timestamp
+	9,  // 2: movies.Movies.movies:type_name -> movies.Movie
+	9,  // 3: movies.MoviesBatch.movies:type_name -> movies.Movie
+	0,  // 4: movies.MovieService.GetMovies:input_type -> movies.GetMoviesRequest
+	1,  // 5: movies.MovieService.GetMovie:input_type -> movies.GetMovieRequest
+	2,  // 6: movies.MovieService.BatchGetMovies:input_type -> movies.BatchGetMoviesRequest
+	3,  // 7: movies.MovieService.ExportMovies:input_type -> movies.ExportMoviesRequest
+	4,  // 8: movies.MovieService.StreamMovies:input_type -> movies.StreamMoviesRequest
+	5,  // 9: movies.MovieService.CreateMovie:input_type -> movies.CreateMovieRequest
+	6,  // 10: movies.MovieService.UpdateMovie:input_type -> movies.UpdateMovieRequest
+	7,  // 11: movies.MovieService.DeleteMovie:input_type -> movies.DeleteMovieRequest
+	10, // 12: movies.MovieService.GetMovies:output_type -> movies.Movies
+	9,  // 13: movies.MovieService.GetMovie:output_type -> movies.Movie
+	11, // 14: movies.MovieService.BatchGetMovies:output_type -> movies.MoviesBatch
+	9,  // 15: movies.MovieService.ExportMovies:output_type -> movies.Movie
+	9,  // 16: movies.MovieService.StreamMovies:output_type -> movies.Movie
+	9,  // 17: movies.MovieService.CreateMovie:output_type -> movies.Movie
+	9,  // 18: movies.MovieService.UpdateMovie:output_type -> movies.Movie
+	8,  // 19: movies.MovieService.DeleteMovie:output_type -> movies.DeleteMovieResponse
+	12, // [12:20] is the sub-list for method output_type
+	4,  // [4:12] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_movies_proto_init() }
@@ -317,63 +801,13 @@ func file_movies_proto_init() {
 	if File_movies_proto != nil {
 		return
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_movies_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetMoviesRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_movies_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetMovieRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_movies_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Movie); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_movies_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Movies); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_movies_proto_rawDesc,
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_movies_proto_rawDesc), len(file_movies_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   12,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -382,7 +816,6 @@ func file_movies_proto_init() {
 		MessageInfos:      file_movies_proto_msgTypes,
 	}.Build()
 	File_movies_proto = out.File
-	file_movies_proto_rawDesc = nil
 	file_movies_proto_goTypes = nil
 	file_movies_proto_depIdxs = nil
 }
@@ -1,4 +1,8 @@
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v3.21.12
+// source: movies.proto
 
 package movies
 
@@ -11,15 +15,47 @@ import (
 
 // This is a compile-time assertion to ensure that this generated file
 // is compatible with the grpc package it is being compiled against.
-// Requires gRPC-Go v1.32.0 or later.
-const _ = grpc.SupportPackageIsVersion7
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	MovieService_GetMovies_FullMethodName      = "/movies.MovieService/GetMovies"
+	MovieService_GetMovie_FullMethodName       = "/movies.MovieService/GetMovie"
+	MovieService_BatchGetMovies_FullMethodName = "/movies.MovieService/BatchGetMovies"
+	MovieService_ExportMovies_FullMethodName   = "/movies.MovieService/ExportMovies"
+	MovieService_StreamMovies_FullMethodName   = "/movies.MovieService/StreamMovies"
+	MovieService_CreateMovie_FullMethodName    = "/movies.MovieService/CreateMovie"
+	MovieService_UpdateMovie_FullMethodName    = "/movies.MovieService/UpdateMovie"
+	MovieService_DeleteMovie_FullMethodName    = "/movies.MovieService/DeleteMovie"
+)
 
 // MovieServiceClient is the client API for MovieService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Also served as JSON over HTTP, under /internal/v1, by the transcoding
+// of the movie service, as annotated on each method.
 type MovieServiceClient interface {
 	GetMovies(ctx context.Context, in *GetMoviesRequest, opts ...grpc.CallOption) (*Movies, error)
 	GetMovie(ctx context.Context, in *GetMovieRequest, opts ...grpc.CallOption) (*Movie, error)
+	// Fails with InvalidArgument for more than 100 ids.
+	BatchGetMovies(ctx context.Context, in *BatchGetMoviesRequest, opts ...grpc.CallOption) (*MoviesBatch, error)
+	// Streams every movie, in no order, scanning the table in parallel
+	// segments.
+	ExportMovies(ctx context.Context, in *ExportMoviesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Movie], error)
+	// Streams the movies of the filter page by page, as the listing of
+	// GetMovies following every cursor, until the limit or the last page.
+	StreamMovies(ctx context.Context, in *StreamMoviesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Movie], error)
+	// The writes are applied before answering, the synchronous alternative
+	// to the queues of the movie service. They fail with InvalidArgument
+	// for an invalid movie, and, when an expected version is given, with
+	// FailedPrecondition if the movie is on another one.
+	CreateMovie(ctx context.Context, in *CreateMovieRequest, opts ...grpc.CallOption) (*Movie, error)
+	// Fails with NotFound when the movie does not exist.
+	UpdateMovie(ctx context.Context, in *UpdateMovieRequest, opts ...grpc.CallOption) (*Movie, error)
+	// Deleting a movie that does not exist does nothing, unless a version
+	// is expected, which fails with NotFound.
+	DeleteMovie(ctx context.Context, in *DeleteMovieRequest, opts ...grpc.CallOption) (*DeleteMovieResponse, error)
 }
 
 type movieServiceClient struct {
@@ -31,8 +67,9 @@ func NewMovieServiceClient(cc grpc.ClientConnInterface) MovieServiceClient {
 }
 
 func (c *movieServiceClient) GetMovies(ctx context.Context, in *GetMoviesRequest, opts ...grpc.CallOption) (*Movies, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(Movies)
-	err := c.cc.Invoke(ctx, "/movies.MovieService/GetMovies", in, out, opts...)
+	err := c.cc.Invoke(ctx, MovieService_GetMovies_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -40,8 +77,87 @@ func (c *movieServiceClient) GetMovies(ctx context.Context, in *GetMoviesRequest
 }
 
 func (c *movieServiceClient) GetMovie(ctx context.Context, in *GetMovieRequest, opts ...grpc.CallOption) (*Movie, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Movie)
+	err := c.cc.Invoke(ctx, MovieService_GetMovie_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *movieServiceClient) BatchGetMovies(ctx context.Context, in *BatchGetMoviesRequest, opts ...grpc.CallOption) (*MoviesBatch, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MoviesBatch)
+	err := c.cc.Invoke(ctx, MovieService_BatchGetMovies_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *movieServiceClient) ExportMovies(ctx context.Context, in *ExportMoviesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Movie], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MovieService_ServiceDesc.Streams[0], MovieService_ExportMovies_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportMoviesRequest, Movie]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MovieService_ExportMoviesClient = grpc.ServerStreamingClient[Movie]
+
+func (c *movieServiceClient) StreamMovies(ctx context.Context, in *StreamMoviesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Movie], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MovieService_ServiceDesc.Streams[1], MovieService_StreamMovies_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamMoviesRequest, Movie]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MovieService_StreamMoviesClient = grpc.ServerStreamingClient[Movie]
+
+func (c *movieServiceClient) CreateMovie(ctx context.Context, in *CreateMovieRequest, opts ...grpc.CallOption) (*Movie, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(Movie)
-	err := c.cc.Invoke(ctx, "/movies.MovieService/GetMovie", in, out, opts...)
+	err := c.cc.Invoke(ctx, MovieService_CreateMovie_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *movieServiceClient) UpdateMovie(ctx context.Context, in *UpdateMovieRequest, opts ...grpc.CallOption) (*Movie, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Movie)
+	err := c.cc.Invoke(ctx, MovieService_UpdateMovie_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *movieServiceClient) DeleteMovie(ctx context.Context, in *DeleteMovieRequest, opts ...grpc.CallOption) (*DeleteMovieResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteMovieResponse)
+	err := c.cc.Invoke(ctx, MovieService_DeleteMovie_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -50,16 +166,40 @@ func (c *movieServiceClient) GetMovie(ctx context.Context, in *GetMovieRequest,
 
 // MovieServiceServer is the server API for MovieService service.
 // All implementations must embed UnimplementedMovieServiceServer
-// for forward compatibility
+// for forward compatibility.
+//
+// Also served as JSON over HTTP, under /internal/v1, by the transcoding
+// of the movie service, as annotated on each method.
 type MovieServiceServer interface {
 	GetMovies(context.Context, *GetMoviesRequest) (*Movies, error)
 	GetMovie(context.Context, *GetMovieRequest) (*Movie, error)
+	// Fails with InvalidArgument for more than 100 ids.
+	BatchGetMovies(context.Context, *BatchGetMoviesRequest) (*MoviesBatch, error)
+	// Streams every movie, in no order, scanning the table in parallel
+	// segments.
+	ExportMovies(*ExportMoviesRequest, grpc.ServerStreamingServer[Movie]) error
+	// Streams the movies of the filter page by page, as the listing of
+	// GetMovies following every cursor, until the limit or the last page.
+	StreamMovies(*StreamMoviesRequest, grpc.ServerStreamingServer[Movie]) error
+	// The writes are applied before answering, the synchronous alternative
+	// to the queues of the movie service. They fail with InvalidArgument
+	// for an invalid movie, and, when an expected version is given, with
+	// FailedPrecondition if the movie is on another one.
+	CreateMovie(context.Context, *CreateMovieRequest) (*Movie, error)
+	// Fails with NotFound when the movie does not exist.
+	UpdateMovie(context.Context, *UpdateMovieRequest) (*Movie, error)
+	// Deleting a movie that does not exist does nothing, unless a version
+	// is expected, which fails with NotFound.
+	DeleteMovie(context.Context, *DeleteMovieRequest) (*DeleteMovieResponse, error)
 	mustEmbedUnimplementedMovieServiceServer()
 }
 
-// UnimplementedMovieServiceServer must be embedded to have forward compatible implementations.
-type UnimplementedMovieServiceServer struct {
-}
+// UnimplementedMovieServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMovieServiceServer struct{}
 
 func (UnimplementedMovieServiceServer) GetMovies(context.Context, *GetMoviesRequest) (*Movies, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetMovies not implemented")
@@ -67,7 +207,26 @@ func (UnimplementedMovieServiceServer) GetMovies(context.Context, *GetMoviesRequ
 func (UnimplementedMovieServiceServer) GetMovie(context.Context, *GetMovieRequest) (*Movie, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetMovie not implemented")
 }
+func (UnimplementedMovieServiceServer) BatchGetMovies(context.Context, *BatchGetMoviesRequest) (*MoviesBatch, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetMovies not implemented")
+}
+func (UnimplementedMovieServiceServer) ExportMovies(*ExportMoviesRequest, grpc.ServerStreamingServer[Movie]) error {
+	return status.Errorf(codes.Unimplemented, "method ExportMovies not implemented")
+}
+func (UnimplementedMovieServiceServer) StreamMovies(*StreamMoviesRequest, grpc.ServerStreamingServer[Movie]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamMovies not implemented")
+}
+func (UnimplementedMovieServiceServer) CreateMovie(context.Context, *CreateMovieRequest) (*Movie, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateMovie not implemented")
+}
+func (UnimplementedMovieServiceServer) UpdateMovie(context.Context, *UpdateMovieRequest) (*Movie, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateMovie not implemented")
+}
+func (UnimplementedMovieServiceServer) DeleteMovie(context.Context, *DeleteMovieRequest) (*DeleteMovieResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteMovie not implemented")
+}
 func (UnimplementedMovieServiceServer) mustEmbedUnimplementedMovieServiceServer() {}
+func (UnimplementedMovieServiceServer) testEmbeddedByValue()                      {}
 
 // UnsafeMovieServiceServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to MovieServiceServer will
@@ -77,6 +236,13 @@ type UnsafeMovieServiceServer interface {
 }
 
 func RegisterMovieServiceServer(s grpc.ServiceRegistrar, srv MovieServiceServer) {
+	// If the following call pancis, it indicates UnimplementedMovieServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
 	s.RegisterService(&MovieService_ServiceDesc, srv)
 }
 
@@ -90,7 +256,7 @@ func _MovieService_GetMovies_Handler(srv interface{}, ctx context.Context, dec f
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/movies.MovieService/GetMovies",
+		FullMethod: MovieService_GetMovies_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
 		return srv.(MovieServiceServer).GetMovies(ctx, req.(*GetMoviesRequest))
@@ -108,7 +274,7 @@ func _MovieService_GetMovie_Handler(srv interface{}, ctx context.Context, dec fu
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/movies.MovieService/GetMovie",
+		FullMethod: MovieService_GetMovie_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
 		return srv.(MovieServiceServer).GetMovie(ctx, req.(*GetMovieRequest))
@@ -116,6 +282,100 @@ func _MovieService_GetMovie_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MovieService_BatchGetMovies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetMoviesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MovieServiceServer).BatchGetMovies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MovieService_BatchGetMovies_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MovieServiceServer).BatchGetMovies(ctx, req.(*BatchGetMoviesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MovieService_ExportMovies_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportMoviesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MovieServiceServer).ExportMovies(m, &grpc.GenericServerStream[ExportMoviesRequest, Movie]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MovieService_ExportMoviesServer = grpc.ServerStreamingServer[Movie]
+
+func _MovieService_StreamMovies_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamMoviesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MovieServiceServer).StreamMovies(m, &grpc.GenericServerStream[StreamMoviesRequest, Movie]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MovieService_StreamMoviesServer = grpc.ServerStreamingServer[Movie]
+
+func _MovieService_CreateMovie_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateMovieRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MovieServiceServer).CreateMovie(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MovieService_CreateMovie_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MovieServiceServer).CreateMovie(ctx, req.(*CreateMovieRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MovieService_UpdateMovie_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateMovieRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MovieServiceServer).UpdateMovie(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MovieService_UpdateMovie_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MovieServiceServer).UpdateMovie(ctx, req.(*UpdateMovieRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MovieService_DeleteMovie_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteMovieRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MovieServiceServer).DeleteMovie(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MovieService_DeleteMovie_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MovieServiceServer).DeleteMovie(ctx, req.(*DeleteMovieRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // MovieService_ServiceDesc is the grpc.ServiceDesc for MovieService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -131,7 +391,34 @@ var MovieService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetMovie",
 			Handler:    _MovieService_GetMovie_Handler,
 		},
+		{
+			MethodName: "BatchGetMovies",
+			Handler:    _MovieService_BatchGetMovies_Handler,
+		},
+		{
+			MethodName: "CreateMovie",
+			Handler:    _MovieService_CreateMovie_Handler,
+		},
+		{
+			MethodName: "UpdateMovie",
+			Handler:    _MovieService_UpdateMovie_Handler,
+		},
+		{
+			MethodName: "DeleteMovie",
+			Handler:    _MovieService_DeleteMovie_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportMovies",
+			Handler:       _MovieService_ExportMovies_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamMovies",
+			Handler:       _MovieService_StreamMovies_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "movies.proto",
 }
@@ -0,0 +1,98 @@
+package movies
+
+// Not generated: the checks of the requests that need no repository, run
+// by the validation interceptor of the movie service before the handlers.
+// The rules of the movies themselves, such as the range of the years, are
+// still checked by the movie service.
+
+import (
+	"errors"
+	"fmt"
+)
+
+func (req *GetMovieRequest) Validate() error {
+	return validID("id", req.GetId())
+}
+
+func (req *GetMoviesRequest) Validate() error {
+	return errors.Join(
+		validYearFilter(req.GetYear()),
+		notNegative("limit", int64(req.GetLimit())),
+		notNegative("cursor", req.GetCursor()),
+	)
+}
+
+func (req *BatchGetMoviesRequest) Validate() error {
+	for index, id := range req.GetIds() {
+		if err := validID(fmt.Sprintf("ids[%d]", index), id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (req *ExportMoviesRequest) Validate() error {
+	return notNegative("segments", int64(req.GetSegments()))
+}
+
+func (req *StreamMoviesRequest) Validate() error {
+	return errors.Join(
+		validYearFilter(req.GetYear()),
+		notNegative("cursor", req.GetCursor()),
+		notNegative("limit", req.GetLimit()),
+	)
+}
+
+func (req *CreateMovieRequest) Validate() error {
+	return errors.Join(required("title", req.GetTitle()), required("year", req.GetYear()))
+}
+
+func (req *UpdateMovieRequest) Validate() error {
+	return errors.Join(
+		validID("id", req.GetId()),
+		required("title", req.GetTitle()),
+		required("year", req.GetYear()),
+		notNegative("expected_version", req.GetExpectedVersion()),
+	)
+}
+
+func (req *DeleteMovieRequest) Validate() error {
+	return errors.Join(validID("id", req.GetId()), notNegative("expected_version", req.GetExpectedVersion()))
+}
+
+func validID(field string, id int64) error {
+	if id <= 0 {
+		return fmt.Errorf("%s must be positive, got %d", field, id)
+	}
+	return nil
+}
+
+func notNegative(field string, value int64) error {
+	if value < 0 {
+		return fmt.Errorf("%s cannot be negative, got %d", field, value)
+	}
+	return nil
+}
+
+func required(field, value string) error {
+	if value == "" {
+		return fmt.Errorf("%s is required", field)
+	}
+	return nil
+}
+
+// The year filters are empty, for every year, or have 4 digits.
+func validYearFilter(year string) error {
+	if year == "" {
+		return nil
+	}
+	if len(year) != 4 {
+		return fmt.Errorf("year must have 4 digits, got %q", year)
+	}
+	for _, digit := range year {
+		if digit < '0' || digit > '9' {
+			return fmt.Errorf("year must have 4 digits, got %q", year)
+		}
+	}
+	return nil
+}
@@ -3,5 +3,24 @@ package constants
 const (
 	MovieCreatorQueueName = "movie_service.movie_creator"
 	MovieDeleterQueueName = "movie_service.movie_deleter"
-)
+	MovieUpdaterQueueName = "movie_service.movie_updater"
+
+	// Queues of the batches of the bulk operations, each message carrying
+	// a slice of the items of one operation.
+	MovieBulkCreatorQueueName = "movie_service.movie_bulk_creator"
+	MovieBulkDeleterQueueName = "movie_service.movie_bulk_deleter"
 
+	// Topic exchange of the domain events of the movie service, routed
+	// with the keys below.
+	MovieEventsExchangeName = "movies.events"
+	MovieCreatedRoutingKey  = "movie.created"
+	MovieUpdatedRoutingKey  = "movie.updated"
+	MovieDeletedRoutingKey  = "movie.deleted"
+	// Binds to every movie event.
+	AllMovieEventsBindingKey = "movie.*"
+
+	// Topic exchange of the results of the items of the bulk operations,
+	// published by the movie service once each batch is written.
+	MovieOperationsExchangeName = "movies.operations"
+	OperationResultsRoutingKey  = "operation.results"
+)
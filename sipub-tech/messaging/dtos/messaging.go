@@ -7,4 +7,7 @@ type Message struct {
 
 type MessageMetadata struct {
 	CorrelationId string
+	// Unique to each published message, so messages published while
+	// consuming it can name it as their cause.
+	MessageId string
 }
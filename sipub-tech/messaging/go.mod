@@ -3,6 +3,7 @@ module github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/messaging
 go 1.24.4
 
 require (
+	github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/config v0.0.0-00010101000000-000000000000
 	github.com/google/uuid v1.6.0
 	github.com/rabbitmq/amqp091-go v1.10.0
 	github.com/stretchr/testify v1.10.0
@@ -59,8 +60,10 @@ require (
 	go.opentelemetry.io/otel/sdk v1.37.0 // indirect
 	go.opentelemetry.io/otel/trace v1.37.0 // indirect
 	go.opentelemetry.io/proto/otlp v1.7.1 // indirect
-	golang.org/x/crypto v0.37.0 // indirect
+	golang.org/x/crypto v0.40.0 // indirect
 	golang.org/x/sys v0.34.0 // indirect
 	google.golang.org/protobuf v1.36.6 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/config => ../config
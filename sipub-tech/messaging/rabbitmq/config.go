@@ -4,6 +4,12 @@ import (
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+// The suffix of the dead letter queue of each named queue.
+const DeadLetterSuffix = ".dlq"
+
+// Named queues are durable, and dead letter the messages their consumers
+// reject, the poison ones, to the queue of the same name with the
+// DeadLetterSuffix, declared along with them.
 func StandardQueueConfig() *QueueConfig {
 	return &QueueConfig{
 		durable: true,
@@ -11,16 +17,17 @@ func StandardQueueConfig() *QueueConfig {
 		exclusive: false,
 		noWait: false,
 		arguments: nil,
+		deadLetter: true,
 	}
 }
 
 func NewQueueConfig(durable, deleteWhenUnused, exclusive, noWait bool, arguments amqp.Table) *QueueConfig {
 	return &QueueConfig{
-		durable,
-		deleteWhenUnused,
-		exclusive,
-		noWait,
-		arguments,
+		durable: durable,
+		deleteWhenUnused: deleteWhenUnused,
+		exclusive: exclusive,
+		noWait: noWait,
+		arguments: arguments,
 	}
 }
 
@@ -30,6 +37,9 @@ type QueueConfig struct {
 	exclusive bool
 	noWait bool
 	arguments amqp.Table
+	// Whether the rejected messages are dead lettered to a queue declared
+	// along with the named queue.
+	deadLetter bool
 }
 
 func StandardConsumerConfig() *ConsumerConfig {
@@ -86,3 +96,55 @@ type ProducerConfig struct {
 }
 
 
+const (
+	// Delivers every message to every bound queue.
+	FanoutExchange = amqp.ExchangeFanout
+	// Delivers the messages to the queues bound with a matching pattern,
+	// such as "movie.*" for "movie.created".
+	TopicExchange  = amqp.ExchangeTopic
+)
+
+// Exchanges are durable and kept when unused by default, so messages
+// published while no consumer is bound are routed once one binds again.
+func StandardExchangeConfig(kind string) *ExchangeConfig {
+	return &ExchangeConfig{
+		kind: kind,
+		durable: true,
+		autoDelete: false,
+		internal: false,
+		noWait: false,
+		arguments: nil,
+	}
+}
+
+func NewExchangeConfig(kind string, durable, autoDelete, internal, noWait bool, arguments amqp.Table) *ExchangeConfig {
+	return &ExchangeConfig{
+		kind,
+		durable,
+		autoDelete,
+		internal,
+		noWait,
+		arguments,
+	}
+}
+
+type ExchangeConfig struct {
+	kind string
+	durable bool
+	autoDelete bool
+	internal bool
+	noWait bool
+	arguments amqp.Table
+}
+
+// Queues bound by RegisterExchangeConsumer are private to the server and
+// removed with its connection, so each server gets every message.
+func subscriptionQueueConfig() *QueueConfig {
+	return &QueueConfig{
+		durable: false,
+		deleteWhenUnused: true,
+		exclusive: true,
+		noWait: false,
+		arguments: nil,
+	}
+}
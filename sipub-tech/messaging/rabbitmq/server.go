@@ -3,18 +3,23 @@ package rabbitmq
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"sync/atomic"
+	"time"
 	
 	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/google/uuid"
 
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/config/tlsconfig"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/messaging/dtos"
 )
 
 
 type ConsumerFunction func(ctx context.Context, body any) error
 type ProducerFunction func(ctx context.Context, body any) error
+type RoutedProducerFunction func(ctx context.Context, routingKey string, body any) error
 type ContextKey string
 
 const CorrelationIdKey ContextKey = "correlationId"
@@ -30,11 +35,37 @@ func NewRabbitMqServer(connectionUrl, nodeId string) *RabbitMqServer {
 type RabbitMqServer struct {
 	connectionUrl string
 	nodeId string
+	tls *tlsconfig.Reloader
 	
 	conn *amqp.Connection
 	ch   *amqp.Channel
 
 	consumers []*consumerData
+	rejected atomic.Int64
+}
+
+// How many messages were rejected as poison since the server was created.
+func (rmqServer *RabbitMqServer) Rejected() int64 {
+	return rmqServer.rejected.Load()
+}
+
+// Connects over amqps with the CAs and the client certificate of files,
+// instead of the CAs of the system, reloaded for each new connection.
+// Must be called before Open, with an amqps url.
+func (rmqServer *RabbitMqServer) UseTLS(files tlsconfig.Files) error {
+	uri, err := amqp.ParseURI(rmqServer.connectionUrl)
+	if err != nil {
+		return fmt.Errorf("invalid RabbitMQ url: %w", err)
+	}
+	if uri.Scheme != "amqps" {
+		return fmt.Errorf("the RabbitMQ url must be amqps:// to use TLS, got %s://", uri.Scheme)
+	}
+	reloader, err := tlsconfig.NewReloader(files)
+	if err != nil {
+		return fmt.Errorf("failed loading the RabbitMQ TLS files: %w", err)
+	}
+	rmqServer.tls = reloader
+	return nil
 }
 
 func (rmqServer *RabbitMqServer) Open() {
@@ -56,8 +87,14 @@ func (rmqServer *RabbitMqServer) Close() {
 	}
 }
 
+// Starts the consumers registered since the last call, so consumers can
+// be added after the server started listening.
 func (rmqServer *RabbitMqServer) Listen(ctx context.Context) {
 	for _, consumer := range rmqServer.consumers {
+		if consumer.listening {
+			continue
+		}
+		consumer.listening = true
 		go rmqServer.consumeForever(ctx, consumer)
 	}
 }
@@ -68,85 +105,170 @@ func (rmqServer *RabbitMqServer) RegisterConsumer(
 	consumerConfig *ConsumerConfig,
 	consumerFunction ConsumerFunction,
 ) {
+	rmqServer.registerQueueConsumer(queueName, queueConfig, consumerConfig, untypedHandler(consumerFunction))
+}
+
+func (rmqServer *RabbitMqServer) CreateProducer(
+	queueName string,
+	queueConfig *QueueConfig,
+	producerConfig *ProducerConfig,
+) (amqp.Queue, ProducerFunction) {
 	if queueConfig == nil {
 		queueConfig = StandardQueueConfig()
 	}
-	if consumerConfig == nil {
-		consumerConfig = StandardConsumerConfig()
+	if producerConfig == nil {
+		producerConfig = StandardProducerConfig()
 	}
-
+	
 	queue := rmqServer.declareQueue(queueName, queueConfig)
-	consumer := rmqServer.registerConsumer(queue, consumerConfig)
 
-	consumerData := &consumerData{
-		queue: queue,
-		consumer: consumer,
-		consumerFunction: consumerFunction,
+	return queue, func(ctx context.Context, body any) error {
+		return rmqServer.publish(ctx, producerConfig.exchange, queue.Name, producerConfig, body)
 	}
+}
 
-	rmqServer.consumers = append(rmqServer.consumers, consumerData)
+// Declares the exchange and returns a function publishing to it with a
+// routing key. The exchange of the producerConfig is ignored.
+func (rmqServer *RabbitMqServer) CreateExchangeProducer(
+	exchangeName string,
+	exchangeConfig *ExchangeConfig,
+	producerConfig *ProducerConfig,
+) RoutedProducerFunction {
+	if producerConfig == nil {
+		producerConfig = StandardProducerConfig()
+	}
+
+	rmqServer.declareExchange(exchangeName, exchangeConfig)
+
+	return func(ctx context.Context, routingKey string, body any) error {
+		return rmqServer.publish(ctx, exchangeName, routingKey, producerConfig, body)
+	}
 }
 
-func (rmqServer *RabbitMqServer) CreateProducer(
+// Declares the exchange and consumes the messages routed to it with the
+// bindingKey through a queue private to this server.
+func (rmqServer *RabbitMqServer) RegisterExchangeConsumer(
+	exchangeName string,
+	exchangeConfig *ExchangeConfig,
+	bindingKey string,
+	consumerConfig *ConsumerConfig,
+	consumerFunction ConsumerFunction,
+) {
+	rmqServer.registerExchangeConsumer(
+		exchangeName, exchangeConfig, bindingKey, consumerConfig, untypedHandler(consumerFunction),
+	)
+}
+
+func (rmqServer *RabbitMqServer) registerQueueConsumer(
 	queueName string,
 	queueConfig *QueueConfig,
-	producerConfig *ProducerConfig,
-) (amqp.Queue, ProducerFunction) {
+	consumerConfig *ConsumerConfig,
+	handler messageHandler,
+) {
 	if queueConfig == nil {
 		queueConfig = StandardQueueConfig()
 	}
-	if producerConfig == nil {
-		producerConfig = StandardProducerConfig()
+	if consumerConfig == nil {
+		consumerConfig = StandardConsumerConfig()
 	}
-	
+
 	queue := rmqServer.declareQueue(queueName, queueConfig)
+	consumer := rmqServer.registerConsumer(queue, consumerConfig)
 
-	return queue, func(ctx context.Context, body any) error {
-		correlationId, ok := ctx.Value(CorrelationIdKey).(string)
-		if ok {
-			correlationId = correlationId + "-"
-		} else {
-			correlationId = ""
-		}
-		newCorrelationId := fmt.Sprintf(
-			"%s%s[%s-%s]",
-			correlationId,
-			rmqServer.nodeId,
-			queue.Name,
-			uuid.New().String(),
-		)
-		messageBody := dtos.Message{
-			Metadata: dtos.MessageMetadata{CorrelationId: newCorrelationId},
-			Data: body,
-		}
-		bytes, err := json.Marshal(messageBody)
-		if err != nil {
-			return fmt.Errorf("error marshalling body: %w", err)
-		}
+	rmqServer.consumers = append(rmqServer.consumers, &consumerData{
+		queue: queue,
+		consumer: consumer,
+		handler: handler,
+	})
+}
 
-		err = rmqServer.ch.PublishWithContext(
-			ctx,
-			producerConfig.exchange,
-			queue.Name,
-			producerConfig.mandatory,
-			producerConfig.immediate,
-			amqp.Publishing{
-				DeliveryMode: producerConfig.deliveryMode,
-				ContentType: "application/json",
-				Body: bytes,
-			},
-		)
-		if err != nil {
-			return fmt.Errorf("error publishing message: %w", err)
-		}
+func (rmqServer *RabbitMqServer) registerExchangeConsumer(
+	exchangeName string,
+	exchangeConfig *ExchangeConfig,
+	bindingKey string,
+	consumerConfig *ConsumerConfig,
+	handler messageHandler,
+) {
+	if consumerConfig == nil {
+		consumerConfig = StandardConsumerConfig()
+	}
+
+	rmqServer.declareExchange(exchangeName, exchangeConfig)
+	queue := rmqServer.declareQueue("", subscriptionQueueConfig())
+	rmqServer.bindQueue(queue, exchangeName, bindingKey)
+	consumer := rmqServer.registerConsumer(queue, consumerConfig)
+
+	rmqServer.consumers = append(rmqServer.consumers, &consumerData{
+		queue: queue,
+		consumer: consumer,
+		handler: handler,
+	})
+}
 
-		return nil
+func (rmqServer *RabbitMqServer) publish(
+	ctx context.Context,
+	exchange string,
+	routingKey string,
+	producerConfig *ProducerConfig,
+	body any,
+) error {
+	correlationId, ok := ctx.Value(CorrelationIdKey).(string)
+	if ok {
+		correlationId = correlationId + "-"
+	} else {
+		correlationId = ""
+	}
+	destination := routingKey
+	if exchange != "" {
+		destination = exchange + ":" + routingKey
+	}
+	newCorrelationId := fmt.Sprintf(
+		"%s%s[%s-%s]",
+		correlationId,
+		rmqServer.nodeId,
+		destination,
+		uuid.New().String(),
+	)
+	messageId := uuid.New().String()
+	messageBody := dtos.Message{
+		Metadata: dtos.MessageMetadata{CorrelationId: newCorrelationId, MessageId: messageId},
+		Data: body,
+	}
+	bytes, err := json.Marshal(messageBody)
+	if err != nil {
+		return fmt.Errorf("error marshalling body: %w", err)
 	}
+
+	err = rmqServer.ch.PublishWithContext(
+		ctx,
+		exchange,
+		routingKey,
+		producerConfig.mandatory,
+		producerConfig.immediate,
+		amqp.Publishing{
+			DeliveryMode: producerConfig.deliveryMode,
+			ContentType: "application/json",
+			MessageId: messageId,
+			Timestamp: time.Now(),
+			Body: bytes,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("error publishing message: %w", err)
+	}
+
+	return nil
 }
 
 
 func (rmqServer *RabbitMqServer) connect() {
-	conn, err := amqp.Dial(rmqServer.connectionUrl)
+	var conn *amqp.Connection
+	var err error
+	if rmqServer.tls != nil {
+		conn, err = amqp.DialTLS(rmqServer.connectionUrl, rmqServer.tls.ClientConfig())
+	} else {
+		conn, err = amqp.Dial(rmqServer.connectionUrl)
+	}
 	rmqServer.failOnError(err, "Failed to connect to RabbitMQ")
 	rmqServer.conn = conn
 }
@@ -158,18 +280,60 @@ func (rmqServer *RabbitMqServer) openChannel() {
 }
 
 func (rmqServer *RabbitMqServer) declareQueue(queueName string, queueConfig *QueueConfig) amqp.Queue {
+	arguments := queueConfig.arguments
+	if queueConfig.deadLetter && queueName != "" {
+		arguments = rmqServer.declareDeadLetterQueue(queueName, arguments)
+	}
 	q, err := rmqServer.ch.QueueDeclare(
 		queueName, 
 		queueConfig.durable,   
 		queueConfig.deleteWhenUnused,   
 		queueConfig.exclusive,   
 		queueConfig.noWait,   
-		queueConfig.arguments,     
+		arguments,     
 	)
 	rmqServer.failOnError(err, fmt.Sprintf("Failed to declare %q queue", queueName))
 	return q
 }
 
+// Declares the durable queue the messages rejected from queueName are
+// dead lettered to, returning the arguments of queueName routing them
+// there through the default exchange.
+func (rmqServer *RabbitMqServer) declareDeadLetterQueue(queueName string, arguments amqp.Table) amqp.Table {
+	deadLetterQueue := queueName + DeadLetterSuffix
+	_, err := rmqServer.ch.QueueDeclare(deadLetterQueue, true, false, false, false, nil)
+	rmqServer.failOnError(err, fmt.Sprintf("Failed to declare %q queue", deadLetterQueue))
+
+	deadLettered := amqp.Table{}
+	for key, value := range arguments {
+		deadLettered[key] = value
+	}
+	deadLettered["x-dead-letter-exchange"] = ""
+	deadLettered["x-dead-letter-routing-key"] = deadLetterQueue
+	return deadLettered
+}
+
+func (rmqServer *RabbitMqServer) declareExchange(exchangeName string, exchangeConfig *ExchangeConfig) {
+	if exchangeConfig == nil {
+		exchangeConfig = StandardExchangeConfig(FanoutExchange)
+	}
+	err := rmqServer.ch.ExchangeDeclare(
+		exchangeName,
+		exchangeConfig.kind,
+		exchangeConfig.durable,
+		exchangeConfig.autoDelete,
+		exchangeConfig.internal,
+		exchangeConfig.noWait,
+		exchangeConfig.arguments,
+	)
+	rmqServer.failOnError(err, fmt.Sprintf("Failed to declare %q exchange", exchangeName))
+}
+
+func (rmqServer *RabbitMqServer) bindQueue(queue amqp.Queue, exchangeName, bindingKey string) {
+	err := rmqServer.ch.QueueBind(queue.Name, bindingKey, exchangeName, false, nil)
+	rmqServer.failOnError(err, fmt.Sprintf("Failed to bind %q queue to %q exchange", queue.Name, exchangeName))
+}
+
 func (rmqServer *RabbitMqServer) setQoS() {
 	err := rmqServer.ch.Qos(
 		4,
@@ -193,13 +357,16 @@ func (rmqServer *RabbitMqServer) registerConsumer(queue amqp.Queue, consumerConf
 	return msgs
 }
 
+// Acknowledges every message once handled, even when the handler fails,
+// but the poison ones, which are rejected without requeue, to the dead
+// letter queue of the named queues.
 func (rmqServer *RabbitMqServer) consumeForever(ctx context.Context, consumer *consumerData) {
 	for delivery := range consumer.consumer {
-		var message dtos.Message
+		var message envelope
 		
 		if err := json.Unmarshal(delivery.Body, &message); err != nil {
-			log.Printf("Error unmarshalling body: %+v", delivery.Body)
-			return
+			rmqServer.reject(consumer, delivery, fmt.Errorf("%w: malformed message %s: %v", ErrPoisonMessage, delivery.Body, err))
+			continue
 		}
 
 		newCorrelationId := fmt.Sprintf(
@@ -213,15 +380,27 @@ func (rmqServer *RabbitMqServer) consumeForever(ctx context.Context, consumer *c
 		internalContext := context.WithValue(ctx, CorrelationIdKey, newCorrelationId)
 		internalContext = context.WithValue(internalContext, MetadataKey, message.Metadata)
 
-		if err := consumer.consumerFunction(internalContext, message.Data); err != nil {
+		if err := consumer.handler(internalContext, message.Data); err != nil {
+			if errors.Is(err, ErrPoisonMessage) {
+				rmqServer.reject(consumer, delivery, err)
+				continue
+			}
 			log.Printf("Error ocurred on consumerFunction for queue %q: %v", consumer.queue.Name, err)
 		}
 		if err := delivery.Ack(false); err != nil {
-			log.Printf("Error deliveryng acknowledgemennt for message %+v", message)
+			log.Printf("Error deliveryng acknowledgemennt for message %+v", message.Metadata)
 		}
 	}
 }
 
+func (rmqServer *RabbitMqServer) reject(consumer *consumerData, delivery amqp.Delivery, err error) {
+	rmqServer.rejected.Add(1)
+	log.Printf("Rejecting message %s of queue %q: %v", delivery.MessageId, consumer.queue.Name, err)
+	if err := delivery.Nack(false, false); err != nil {
+		log.Printf("Error rejecting message %s: %v", delivery.MessageId, err)
+	}
+}
+
 func (rmqServer *RabbitMqServer) failOnError(err error, msg string) {
 	if err != nil {
 		rmqServer.Close()
@@ -230,9 +409,32 @@ func (rmqServer *RabbitMqServer) failOnError(err error, msg string) {
 }
 
 
+// Handles the data of a message, still encoded.
+type messageHandler func(ctx context.Context, data json.RawMessage) error
+
+// Calls consumerFunction with the data decoded into any, the numbers
+// being float64.
+func untypedHandler(consumerFunction ConsumerFunction) messageHandler {
+	return func(ctx context.Context, data json.RawMessage) error {
+		var body any
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &body); err != nil {
+				return fmt.Errorf("%w: couldn't decode %s: %v", ErrPoisonMessage, data, err)
+			}
+		}
+		return consumerFunction(ctx, body)
+	}
+}
+
+// A dtos.Message whose data is decoded by the handler of the consumer.
+type envelope struct {
+	Metadata dtos.MessageMetadata
+	Data json.RawMessage
+}
+
 type consumerData struct {
 	queue amqp.Queue
 	consumer <- chan amqp.Delivery
-	consumerFunction ConsumerFunction
+	handler messageHandler
+	listening bool
 }
-
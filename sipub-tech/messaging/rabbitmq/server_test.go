@@ -7,6 +7,7 @@ import (
     "testing"
 	"time"
 
+    amqp "github.com/rabbitmq/amqp091-go"
     "github.com/stretchr/testify/require"
     "github.com/testcontainers/testcontainers-go"
     "github.com/testcontainers/testcontainers-go/wait"
@@ -100,6 +101,150 @@ func TestRabbitMqServer(t *testing.T) {
 		}
 		
 	})
+
+	t.Run("should deliver the messages published to an exchange to every bound server", func(t *testing.T) {
+		const exchangeName = "testExchange"
+		publisher := rabbitmq.NewRabbitMqServer(connectionUrl, nodeId)
+		publisher.Open()
+		defer publisher.Close()
+		producerFunction := publisher.CreateExchangeProducer(exchangeName, nil, nil)
+
+		receivedChans := make([]chan any, 2)
+		for index := range receivedChans {
+			receivedChan := make(chan any, 10)
+			receivedChans[index] = receivedChan
+
+			subscriber := rabbitmq.NewRabbitMqServer(connectionUrl, fmt.Sprintf("%s-%d", nodeId, index))
+			subscriber.Open()
+			defer subscriber.Close()
+			subscriber.RegisterExchangeConsumer(exchangeName, nil, "", nil, func(ctx context.Context, body any) error {
+				receivedChan <- body
+				return nil
+			})
+			subscriber.Listen(context.Background())
+		}
+
+		if err := producerFunction(ctx, "", "changed"); err != nil {
+			t.Errorf("Error found when running producer function: %v", err)
+		}
+
+		for index, receivedChan := range receivedChans {
+			select {
+			case received := <- receivedChan:
+				if received != "changed" {
+					t.Errorf("Subscriber %d expected %q, got %+v", index, "changed", received)
+				}
+			case <- time.After(1 * time.Second):
+				t.Errorf("Subscriber %d was not called after 1 second.", index)
+			}
+		}
+	})
+
+	t.Run("should route the messages of a topic exchange by their routing keys", func(t *testing.T) {
+		const exchangeName = "testTopicExchange"
+		exchangeConfig := rabbitmq.StandardExchangeConfig(rabbitmq.TopicExchange)
+
+		publisher := rabbitmq.NewRabbitMqServer(connectionUrl, nodeId)
+		publisher.Open()
+		defer publisher.Close()
+		producerFunction := publisher.CreateExchangeProducer(exchangeName, exchangeConfig, nil)
+
+		received := make(chan any, 10)
+		subscriber := rabbitmq.NewRabbitMqServer(connectionUrl, nodeId + "-topic")
+		subscriber.Open()
+		defer subscriber.Close()
+		subscriber.RegisterExchangeConsumer(exchangeName, exchangeConfig, "movie.created", nil, func(ctx context.Context, body any) error {
+			metadata, _ := ctx.Value(rabbitmq.MetadataKey).(dtos.MessageMetadata)
+			if metadata.MessageId == "" {
+				t.Error("message id is not set.")
+			}
+			received <- body
+			return nil
+		})
+		subscriber.Listen(context.Background())
+
+		for _, routingKey := range []string{"movie.deleted", "movie.created"} {
+			if err := producerFunction(ctx, routingKey, routingKey); err != nil {
+				t.Errorf("Error found when running producer function: %v", err)
+			}
+		}
+
+		select {
+		case body := <- received:
+			if body != "movie.created" {
+				t.Errorf("Expected only %q to be routed, got %+v", "movie.created", body)
+			}
+		case <- time.After(1 * time.Second):
+			t.Errorf("Consumer Function was not called after 1 second.")
+		}
+	})
+
+	t.Run("should decode the typed messages, rejecting the poison ones without stopping", func(t *testing.T) {
+		const queueName = "testTypedQueue"
+		rabbitmqServer := rabbitmq.NewRabbitMqServer(connectionUrl, nodeId)
+		rabbitmqServer.Open()
+		defer rabbitmqServer.Close()
+		_, untypedProducer := rabbitmqServer.CreateProducer(queueName, nil, nil)
+		_, typedProducer := rabbitmq.CreateTypedProducer[movieBody](rabbitmqServer, queueName, nil, nil)
+
+		received := make(chan movieBody, 10)
+		rabbitmq.RegisterTypedConsumer(rabbitmqServer, queueName, nil, nil, func(ctx context.Context, body movieBody) error {
+			received <- body
+			return nil
+		})
+		rabbitmqServer.Listen(context.Background())
+
+		require.NoError(t, untypedProducer(ctx, map[string]any{"id": 1, "unknown": true}))
+		require.NoError(t, typedProducer(ctx, movieBody{ID: 2, Title: "Heat"}))
+
+		select {
+		case body := <- received:
+			if body.ID != 2 {
+				t.Errorf("Expected only the movie 2 to be handled, got %+v", body)
+			}
+			if rejected := rabbitmqServer.Rejected(); rejected != 1 {
+				t.Errorf("Expected the poison message to be counted as rejected, got %d", rejected)
+			}
+		case <- time.After(1 * time.Second):
+			t.Errorf("Consumer Function was not called after 1 second.")
+		}
+	})
+
+	t.Run("should dead letter the rejected messages to the queue with the dead letter suffix", func(t *testing.T) {
+		const queueName = "testDeadLetteredQueue"
+		rabbitmqServer := rabbitmq.NewRabbitMqServer(connectionUrl, nodeId)
+		rabbitmqServer.Open()
+		defer rabbitmqServer.Close()
+		_, untypedProducer := rabbitmqServer.CreateProducer(queueName, nil, nil)
+		rabbitmq.RegisterTypedConsumer(rabbitmqServer, queueName, nil, nil, func(ctx context.Context, body movieBody) error {
+			return nil
+		})
+		rabbitmqServer.Listen(context.Background())
+
+		require.NoError(t, untypedProducer(ctx, map[string]any{"id": 1, "unknown": true}))
+
+		conn, err := amqp.Dial(connectionUrl)
+		require.NoError(t, err)
+		defer conn.Close()
+		ch, err := conn.Channel()
+		require.NoError(t, err)
+
+		deadline := time.Now().Add(time.Second)
+		for {
+			message, ok, err := ch.Get(queueName + rabbitmq.DeadLetterSuffix, true)
+			require.NoError(t, err)
+			if ok {
+				if !strings.Contains(string(message.Body), `"unknown":true`) {
+					t.Errorf("Expected the poison message in the dead letter queue, got %s", message.Body)
+				}
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("The rejected message did not arrive in %q after 1 second.", queueName + rabbitmq.DeadLetterSuffix)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
 }
 
 func insertAuthInfo(endpoint, authInfo string) string {
@@ -0,0 +1,143 @@
+package rabbitmq
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Returned, wrapped, for the messages that can never be handled, as the
+// ones whose data cannot be decoded. They are rejected without requeue,
+// instead of being acknowledged, so the broker dead letters the ones of
+// named queues to the <queue>.dlq declared with them, and drops the ones
+// of the server-named queues of the exchange consumers. Consumers may
+// wrap it as well to refuse a message.
+var ErrPoisonMessage = errors.New("poison message")
+
+type TypedConsumerFunction[T any] func(ctx context.Context, body T) error
+type TypedProducerFunction[T any] func(ctx context.Context, body T) error
+type TypedRoutedProducerFunction[T any] func(ctx context.Context, routingKey string, body T) error
+
+// Consumes the queue as RegisterConsumer, calling consumerFunction with
+// the data of each message decoded into T, as Decode. Messages that
+// cannot be decoded are poison and never reach it.
+func RegisterTypedConsumer[T any](
+	rmqServer *RabbitMqServer,
+	queueName string,
+	queueConfig *QueueConfig,
+	consumerConfig *ConsumerConfig,
+	consumerFunction TypedConsumerFunction[T],
+) {
+	rmqServer.registerQueueConsumer(queueName, queueConfig, consumerConfig, typedHandler(consumerFunction))
+}
+
+// Consumes the exchange as RegisterExchangeConsumer, decoding the data of
+// each message into T, as RegisterTypedConsumer.
+func RegisterTypedExchangeConsumer[T any](
+	rmqServer *RabbitMqServer,
+	exchangeName string,
+	exchangeConfig *ExchangeConfig,
+	bindingKey string,
+	consumerConfig *ConsumerConfig,
+	consumerFunction TypedConsumerFunction[T],
+) {
+	rmqServer.registerExchangeConsumer(
+		exchangeName, exchangeConfig, bindingKey, consumerConfig, typedHandler(consumerFunction),
+	)
+}
+
+// Consumes the events of the exchange as RegisterTypedExchangeConsumer,
+// decoding their data as DecodeEvent.
+func RegisterTypedEventConsumer[T any](
+	rmqServer *RabbitMqServer,
+	exchangeName string,
+	exchangeConfig *ExchangeConfig,
+	bindingKey string,
+	consumerConfig *ConsumerConfig,
+	consumerFunction TypedConsumerFunction[T],
+) {
+	rmqServer.registerExchangeConsumer(
+		exchangeName, exchangeConfig, bindingKey, consumerConfig, decodingHandler(DecodeEvent[T], consumerFunction),
+	)
+}
+
+// Declares the queue as CreateProducer, returning a function that only
+// publishes T.
+func CreateTypedProducer[T any](
+	rmqServer *RabbitMqServer,
+	queueName string,
+	queueConfig *QueueConfig,
+	producerConfig *ProducerConfig,
+) (amqp.Queue, TypedProducerFunction[T]) {
+	queue, produce := rmqServer.CreateProducer(queueName, queueConfig, producerConfig)
+	return queue, func(ctx context.Context, body T) error {
+		return produce(ctx, body)
+	}
+}
+
+// Declares the exchange as CreateExchangeProducer, returning a function
+// that only publishes T.
+func CreateTypedExchangeProducer[T any](
+	rmqServer *RabbitMqServer,
+	exchangeName string,
+	exchangeConfig *ExchangeConfig,
+	producerConfig *ProducerConfig,
+) TypedRoutedProducerFunction[T] {
+	produce := rmqServer.CreateExchangeProducer(exchangeName, exchangeConfig, producerConfig)
+	return func(ctx context.Context, routingKey string, body T) error {
+		return produce(ctx, routingKey, body)
+	}
+}
+
+// Decodes the data of a message into T, refusing the fields T does not
+// have, the numbers that do not fit the fields, trailing data and
+// missing data. The numbers decoded into any are kept as json.Number, so
+// large ids do not lose precision. The errors wrap ErrPoisonMessage.
+func Decode[T any](data []byte) (T, error) {
+	return decode[T](data, true)
+}
+
+// Decodes the data of a message into T as Decode, but ignoring the fields
+// T does not have. The events published by other services are decoded
+// with it, so the fields they add do not make every event poison.
+func DecodeEvent[T any](data []byte) (T, error) {
+	return decode[T](data, false)
+}
+
+func decode[T any](data []byte, strict bool) (T, error) {
+	var body T
+	if len(bytes.TrimSpace(data)) == 0 || bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return body, fmt.Errorf("%w: the message has no data to decode to %T", ErrPoisonMessage, body)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	decoder.UseNumber()
+	if err := decoder.Decode(&body); err != nil {
+		return body, fmt.Errorf("%w: couldn't decode %s to %T: %v", ErrPoisonMessage, data, body, err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return body, fmt.Errorf("%w: trailing data after %T in %s", ErrPoisonMessage, body, data)
+	}
+	return body, nil
+}
+
+func typedHandler[T any](consumerFunction TypedConsumerFunction[T]) messageHandler {
+	return decodingHandler(Decode[T], consumerFunction)
+}
+
+func decodingHandler[T any](decode func([]byte) (T, error), consumerFunction TypedConsumerFunction[T]) messageHandler {
+	return func(ctx context.Context, data json.RawMessage) error {
+		body, err := decode(data)
+		if err != nil {
+			return err
+		}
+		return consumerFunction(ctx, body)
+	}
+}
@@ -0,0 +1,67 @@
+package rabbitmq_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/messaging/rabbitmq"
+)
+
+type movieBody struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+	Extra any    `json:"extra,omitempty"`
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("should decode the data into the struct", func(t *testing.T) {
+		body, err := rabbitmq.Decode[movieBody]([]byte(`{"id": 9007199254740993, "title": "Heat"}`))
+		require.NoError(t, err)
+		assert.Equal(t, movieBody{ID: 9007199254740993, Title: "Heat"}, body)
+	})
+
+	t.Run("should keep the numbers decoded into any as json.Number", func(t *testing.T) {
+		body, err := rabbitmq.Decode[movieBody]([]byte(`{"id": 1, "title": "Heat", "extra": 9007199254740993}`))
+		require.NoError(t, err)
+		assert.Equal(t, json.Number("9007199254740993"), body.Extra)
+	})
+
+	for name, data := range map[string]string{
+		"unknown fields":       `{"id": 1, "title": "Heat", "year": "1995"}`,
+		"fractional numbers":   `{"id": 1.5, "title": "Heat"}`,
+		"numbers as strings":   `{"id": "1", "title": "Heat"}`,
+		"numbers out of range": `{"id": 1e40, "title": "Heat"}`,
+		"trailing data":        `{"id": 1, "title": "Heat"} {}`,
+		"missing data":         ``,
+		"null data":            `null`,
+		"other types":          `["Heat"]`,
+	} {
+		t.Run("should refuse as poison the "+name, func(t *testing.T) {
+			_, err := rabbitmq.Decode[movieBody]([]byte(data))
+			assert.ErrorIs(t, err, rabbitmq.ErrPoisonMessage)
+		})
+	}
+}
+
+func TestDecodeEvent(t *testing.T) {
+	t.Run("should ignore the fields the struct does not have", func(t *testing.T) {
+		body, err := rabbitmq.DecodeEvent[movieBody]([]byte(`{"id": 1, "title": "Heat", "year": "1995"}`))
+		require.NoError(t, err)
+		assert.Equal(t, movieBody{ID: 1, Title: "Heat"}, body)
+	})
+
+	for name, data := range map[string]string{
+		"fractional numbers": `{"id": 1.5, "title": "Heat"}`,
+		"trailing data":      `{"id": 1, "title": "Heat"} {}`,
+		"missing data":       ``,
+		"other types":        `["Heat"]`,
+	} {
+		t.Run("should refuse as poison the "+name, func(t *testing.T) {
+			_, err := rabbitmq.DecodeEvent[movieBody]([]byte(data))
+			assert.ErrorIs(t, err, rabbitmq.ErrPoisonMessage)
+		})
+	}
+}
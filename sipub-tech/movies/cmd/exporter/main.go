@@ -0,0 +1,76 @@
+// Exports the movies to a file the importer reads back, scanning the table
+// in parallel segments. The file is a JSON array, NDJSON or CSV with a
+// header, of the id, title, year, version and update time of each movie,
+// in no order.
+//
+// Usage:
+//
+//	exporter [-file movies.json] [-format json|ndjson|csv] [-columns id=movie_id,year=released]
+//	         [-segments 4] [-region us-east-1] [-endpoint http://localhost:4566]
+//
+// The movies are written to the standard output when no file is given.
+// The region and endpoint default to the AWS_REGION and DYNAMO_DB_ENDPOINT
+// environment variables, and the format to the one of the file extension.
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/usecases"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/infra/catalogue"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/infra/repositories"
+)
+
+func main() {
+	path := flag.String("file", "", "the file to write the movies to, the standard output by default")
+	format := flag.String("format", "", "json, ndjson or csv, guessed from the file extension by default")
+	columnMapping := flag.String("columns", "", "the CSV columns of the movie fields, as id=movie_id,title=name,year=released")
+	segments := flag.Int("segments", usecases.DefaultExportSegments, "how many segments of the table are scanned at a time")
+	awsRegion := flag.String("region", os.Getenv("AWS_REGION"), "the AWS region of DynamoDB")
+	dynamoDBEndpoint := flag.String("endpoint", os.Getenv("DYNAMO_DB_ENDPOINT"), "the DynamoDB endpoint")
+	flag.Parse()
+	if *format == "" {
+		*format = string(catalogue.FormatFromPath(*path))
+	}
+	columns, err := catalogue.ParseCSVColumns(*columnMapping)
+	if err != nil {
+		log.Fatalf("Invalid column mapping: %v", err)
+	}
+
+	var output io.Writer = os.Stdout
+	if *path != "" {
+		file, err := os.Create(*path)
+		if err != nil {
+			log.Fatalf("Error creating file: %v", err)
+		}
+		defer file.Close()
+		output = file
+	}
+	writer, err := catalogue.NewWriter(catalogue.Format(*format), output, columns)
+	if err != nil {
+		log.Fatalf("Invalid format: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	repo := repositories.NewMovieRepository(repositories.NewRepositoryConfig(*awsRegion, *dynamoDBEndpoint))
+	repo.Open()
+
+	exported, err := usecases.NewExportMoviesCase(repo, *segments).ExportMovies(ctx, func(movie dtos.MovieResponseDTO) error {
+		return writer.Write(movie)
+	})
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		log.Fatalf("Export failed after %d movies: %v", exported, err)
+	}
+	log.Printf("%d movies exported.", exported)
+}
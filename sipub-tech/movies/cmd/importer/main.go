@@ -0,0 +1,135 @@
+// Imports the movies of a file, keeping their ids. The file is a JSON
+// array, NDJSON or CSV with a header, of the id, title and year of each
+// movie, and is read as it is imported.
+//
+// Usage:
+//
+//	importer -file movies.json [-format json|ndjson|csv] [-columns id=movie_id,year=released]
+//	         [-mode upsert|skip-existing] [-dry-run] [-resume] [-checkpoint movies.json.checkpoint]
+//	         [-region us-east-1] [-endpoint http://localhost:4566]
+//
+// The file, region and endpoint default to the JSON_PATH, AWS_REGION and
+// DYNAMO_DB_ENDPOINT environment variables, and the format to the one of
+// the file extension. The rows done are saved in the checkpoint file as
+// they are imported, for -resume to continue an interrupted import after
+// them. It exits with 1 when any row failed.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/usecases"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/infra/catalogue"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/infra/repositories"
+)
+
+func main() {
+	config := usecases.DefaultImportConfig()
+	jsonPath := flag.String("file", os.Getenv("JSON_PATH"), "the file with the movies")
+	format := flag.String("format", "", "json, ndjson or csv, guessed from the file extension by default")
+	columnMapping := flag.String("columns", "", "the CSV columns of the movie fields, as id=movie_id,title=name,year=released")
+	mode := flag.String("mode", string(config.Mode), "upsert to overwrite the existing movies, or skip-existing to keep them")
+	flag.BoolVar(&config.DryRun, "dry-run", false, "only validate the rows, without writing them")
+	resume := flag.Bool("resume", false, "continue an interrupted import after the rows done in the checkpoint")
+	checkpointPath := flag.String("checkpoint", "", "the checkpoint file, the file with .checkpoint appended by default")
+	awsRegion := flag.String("region", os.Getenv("AWS_REGION"), "the AWS region of DynamoDB")
+	dynamoDBEndpoint := flag.String("endpoint", os.Getenv("DYNAMO_DB_ENDPOINT"), "the DynamoDB endpoint")
+	flag.IntVar(&config.BatchSize, "batch-size", config.BatchSize, "how many movies each write carries")
+	flag.IntVar(&config.Concurrency, "concurrency", config.Concurrency, "how many writes run at a time")
+	flag.Parse()
+	if *jsonPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *format == "" {
+		*format = string(catalogue.FormatFromPath(*jsonPath))
+	}
+	if *checkpointPath == "" {
+		*checkpointPath = *jsonPath + ".checkpoint"
+	}
+	config.Mode = dtos.ImportMode(*mode)
+	if config.Mode != dtos.ImportUpsert && config.Mode != dtos.ImportSkipExisting {
+		log.Fatalf("Unknown import mode %q", *mode)
+	}
+	columns, err := catalogue.ParseCSVColumns(*columnMapping)
+	if err != nil {
+		log.Fatalf("Invalid column mapping: %v", err)
+	}
+
+	if *resume {
+		checkpoint, err := catalogue.LoadCheckpoint(*checkpointPath)
+		if err != nil {
+			log.Fatalf("Failed to load the checkpoint: %v", err)
+		}
+		if checkpoint.File != "" && checkpoint.File != *jsonPath {
+			log.Fatalf("The checkpoint %s is of the file %s", *checkpointPath, checkpoint.File)
+		}
+		config.ResumeFrom = checkpoint.Done
+		log.Printf("Resuming after row %d", config.ResumeFrom)
+	}
+
+	file, err := os.Open(*jsonPath)
+	if err != nil {
+		log.Fatalf("Error opening file: %v", err)
+	}
+	defer file.Close()
+	reader, err := catalogue.NewReader(catalogue.Format(*format), file, columns)
+	if err != nil {
+		log.Fatalf("Invalid format: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var repo *repositories.MovieRepository
+	if !config.DryRun {
+		repo = repositories.NewMovieRepository(repositories.NewRepositoryConfig(*awsRegion, *dynamoDBEndpoint))
+		repo.Open()
+		if err := repo.CreateTables(ctx); err != nil {
+			log.Fatalf("Failed to create tables: %v", err)
+		}
+	}
+
+	config.Progress = func(progress dtos.ImportSummaryDTO) {
+		log.Printf(
+			"Read %d movies: %d imported, %d skipped, %d failed",
+			progress.Total, progress.Imported, progress.Skipped, progress.Failed,
+		)
+		if config.DryRun {
+			return
+		}
+		if err := catalogue.SaveCheckpoint(*checkpointPath, catalogue.Checkpoint{File: *jsonPath, Done: progress.Done}); err != nil {
+			log.Printf("Failed to save the checkpoint: %v", err)
+		}
+	}
+	summary, err := usecases.NewImportMoviesCase(repo, config).ImportMovies(ctx, reader)
+
+	for _, rowError := range summary.Errors {
+		fmt.Printf("row %d (id %d): %s\n", rowError.Row, rowError.ID, rowError.Error)
+	}
+	if config.DryRun {
+		fmt.Printf("%d movies read, %d valid, %d failed.\n", summary.Total, summary.Imported, summary.Failed)
+	} else {
+		fmt.Printf(
+			"%d movies read, %d imported, %d skipped, %d failed. The next movie created gets an id past %d.\n",
+			summary.Total, summary.Imported, summary.Skipped, summary.Failed, summary.LastID,
+		)
+	}
+	if err != nil {
+		log.Fatalf("Import failed after row %d, run again with -resume to continue: %v", summary.Done, err)
+	}
+	if !config.DryRun {
+		if err := os.Remove(*checkpointPath); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to remove the checkpoint: %v", err)
+		}
+	}
+	if summary.Failed > 0 {
+		os.Exit(1)
+	}
+}
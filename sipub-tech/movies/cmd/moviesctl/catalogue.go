@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	pb "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/grpc/movies"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/infra/catalogue"
+)
+
+const (
+	// The most movies a bulk creation of the gateway takes.
+	maxBulkMovies = 1000
+	operationPollInterval = time.Second
+)
+
+type bulkMovie struct {
+	Title string  `json:"title"`
+	Year string   `json:"year"`
+}
+
+type bulkCreateRequest struct {
+	Movies []bulkMovie  `json:"movies"`
+}
+
+// A bulk operation of the gateway, as answered by POST /v1/movies/bulk
+// and GET /v1/operations/:id.
+type OperationView struct {
+	ID string         `json:"id" yaml:"id"`
+	Status string     `json:"status" yaml:"status"`
+	Total int         `json:"total" yaml:"total"`
+	Succeeded int     `json:"succeeded" yaml:"succeeded"`
+	Failed int        `json:"failed" yaml:"failed"`
+}
+
+type operationResponse struct {
+	Data OperationView  `json:"data"`
+}
+
+func operationsTable(operations ...OperationView) Table {
+	table := Table{Headers: []string{"OPERATION", "STATUS", "TOTAL", "SUCCEEDED", "FAILED"}}
+	for _, operation := range operations {
+		table.Rows = append(table.Rows, []string{
+			operation.ID, operation.Status, strconv.Itoa(operation.Total),
+			strconv.Itoa(operation.Succeeded), strconv.Itoa(operation.Failed),
+		})
+	}
+	return table
+}
+
+// Imports through the bulk creations of the gateway, so the movies get new
+// ids. The importer command keeps the ids, writing straight to DynamoDB.
+func runImport(ctx context.Context, args []string) error {
+	flags, globals := newFlags("import", "import -file movies.json [-format json|ndjson|csv] [-columns title=name] [-wait] [flags]")
+	path := flags.String("file", "", "the file with the movies")
+	format := flags.String("format", "", "json, ndjson or csv, guessed from the file extension by default")
+	columnMapping := flags.String("columns", "", "the CSV columns of the movie fields, as title=name,year=released")
+	batchSize := flags.Int("batch-size", maxBulkMovies, "how many movies each bulk creation carries")
+	wait := flags.Bool("wait", false, "wait for the operations to complete")
+	parseFlags(flags, args)
+	if *path == "" || *batchSize <= 0 || *batchSize > maxBulkMovies {
+		flags.Usage()
+		return errUsage
+	}
+	if *format == "" {
+		*format = string(catalogue.FormatFromPath(*path))
+	}
+	columns, err := catalogue.ParseCSVColumns(*columnMapping)
+	if err != nil {
+		return fmt.Errorf("invalid column mapping: %w", err)
+	}
+	file, err := os.Open(*path)
+	if err != nil {
+		return fmt.Errorf("failed opening %s: %w", *path, err)
+	}
+	defer file.Close()
+	reader, err := catalogue.NewReader(catalogue.Format(*format), file, columns)
+	if err != nil {
+		return err
+	}
+	session, err := globals.session()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	var operations []OperationView
+	send := func(batch []bulkMovie) error {
+		var response operationResponse
+		if _, err := session.Request(ctx, http.MethodPost, "/v1/movies/bulk", nil, bulkCreateRequest{Movies: batch}, &response); err != nil {
+			return fmt.Errorf("failed creating movies %d to %d: %w", len(operations) * *batchSize + 1, len(operations) * *batchSize + len(batch), err)
+		}
+		operations = append(operations, response.Data)
+		return nil
+	}
+	batch := make([]bulkMovie, 0, *batchSize)
+	for row := 0; ; row++ {
+		movie, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed reading row %d: %w", row, err)
+		}
+		batch = append(batch, bulkMovie{Title: movie.Title, Year: movie.Year})
+		if len(batch) == *batchSize {
+			if err := send(batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if len(batch) > 0 {
+		if err := send(batch); err != nil {
+			return err
+		}
+	}
+
+	if *wait {
+		for index := range operations {
+			if operations[index], err = waitOperation(ctx, session, operations[index]); err != nil {
+				return err
+			}
+		}
+	}
+	return session.Print(operations, operationsTable(operations...))
+}
+
+func waitOperation(ctx context.Context, session *Session, operation OperationView) (OperationView, error) {
+	ticker := time.NewTicker(operationPollInterval)
+	defer ticker.Stop()
+	for operation.Status != "completed" {
+		select {
+		case <-ctx.Done():
+			return operation, ctx.Err()
+		case <-ticker.C:
+		}
+		var response operationResponse
+		if _, err := session.Request(ctx, http.MethodGet, "/v1/operations/" + operation.ID, nil, nil, &response); err != nil {
+			return operation, fmt.Errorf("failed getting operation %s: %w", operation.ID, err)
+		}
+		operation = response.Data
+	}
+	return operation, nil
+}
+
+func runExport(ctx context.Context, args []string) error {
+	flags, globals := newFlags("export", "export [-file movies.json] [-format json|ndjson|csv] [-segments 4] [flags]")
+	path := flags.String("file", "", "the file written, the standard output by default")
+	format := flags.String("format", "", "json, ndjson or csv, guessed from the file extension by default")
+	columnMapping := flags.String("columns", "", "the CSV columns of the movie fields, as title=name,year=released")
+	segments := flags.Int("segments", 0, "how many segments the table is scanned in, the server default by default")
+	parseFlags(flags, args)
+	if *format == "" {
+		*format = string(catalogue.FormatFromPath(*path))
+	}
+	columns, err := catalogue.ParseCSVColumns(*columnMapping)
+	if err != nil {
+		return fmt.Errorf("invalid column mapping: %w", err)
+	}
+	session, err := globals.session()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	client, err := session.Movies()
+	if err != nil {
+		return err
+	}
+
+	out := io.Writer(os.Stdout)
+	if *path != "" {
+		file, err := os.Create(*path)
+		if err != nil {
+			return fmt.Errorf("failed creating %s: %w", *path, err)
+		}
+		defer file.Close()
+		out = file
+	}
+	writer, err := catalogue.NewWriter(catalogue.Format(*format), out, columns)
+	if err != nil {
+		return err
+	}
+
+	stream, err := client.ExportMovies(ctx, &pb.ExportMoviesRequest{Segments: int32(*segments)})
+	if err != nil {
+		return fmt.Errorf("failed exporting movies: %w", err)
+	}
+	exported := 0
+	for {
+		movie, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("export failed after %d movies: %w", exported, err)
+		}
+		if err := writer.Write(dtos.MovieResponseDTO{
+			ID: dtos.MovieID(movie.Id),
+			Title: movie.Title,
+			Year: movie.Year,
+			Version: int(movie.Version),
+			UpdatedAt: movie.UpdatedAt.AsTime().UnixMilli(),
+		}); err != nil {
+			return fmt.Errorf("failed writing movie %d: %w", movie.Id, err)
+		}
+		exported++
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed writing the export: %w", err)
+	}
+	if *path != "" {
+		fmt.Fprintf(os.Stderr, "%d movies exported to %s.\n", exported, *path)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "moviesctl", "config.yaml")
+
+	t.Run("should use the compose endpoints without a config file", func(t *testing.T) {
+		config, err := LoadConfig(path)
+		if err != nil {
+			t.Fatalf("Error loading the missing config: %v", err)
+		}
+		context, err := config.Context("")
+		if err != nil || context.GRPC != "localhost:5000" {
+			t.Errorf("Unexpected default context %+v: %v", context, err)
+		}
+	})
+
+	t.Run("should save and load the contexts", func(t *testing.T) {
+		config := DefaultConfig()
+		config.Contexts["staging"] = Context{Gateway: "https://movies.staging", GRPC: "movies.staging:5000"}
+		config.CurrentContext = "staging"
+		if err := SaveConfig(path, config); err != nil {
+			t.Fatalf("Error saving the config: %v", err)
+		}
+
+		loaded, err := LoadConfig(path)
+		if err != nil {
+			t.Fatalf("Error loading the config: %v", err)
+		}
+		if !reflect.DeepEqual(config, loaded) {
+			t.Errorf("Config loaded: %+v different from Expected: %+v", loaded, config)
+		}
+		if names := loaded.ContextNames(); !reflect.DeepEqual([]string{"compose", "staging"}, names) {
+			t.Errorf("Context names: %v different from Expected: [compose staging]", names)
+		}
+	})
+
+	t.Run("should fail on unknown contexts", func(t *testing.T) {
+		if _, err := DefaultConfig().Context("production"); err == nil {
+			t.Errorf("No error returned for an unknown context")
+		}
+	})
+}
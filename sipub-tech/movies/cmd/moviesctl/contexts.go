@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+type ContextView struct {
+	Name string       `json:"name" yaml:"name"`
+	Current bool      `json:"current" yaml:"current"`
+	Context `yaml:",inline"`
+}
+
+func contextsTable(contexts ...ContextView) Table {
+	table := Table{Headers: []string{"CURRENT", "NAME", "GATEWAY", "GRPC", "RABBITMQ"}}
+	for _, view := range contexts {
+		current := ""
+		if view.Current {
+			current = "*"
+		}
+		table.Rows = append(table.Rows, []string{current, view.Name, view.Gateway, view.GRPC, redactURL(view.RabbitMQ)})
+	}
+	return table
+}
+
+func runConfig(ctx context.Context, args []string) error {
+	subcommands := map[string]command{
+		"contexts": runConfigContexts,
+		"use": runConfigUse,
+		"set": runConfigSet,
+	}
+	if len(args) == 0 || subcommands[args[0]] == nil {
+		fmt.Println("Usage: moviesctl config contexts|use|set [flags]")
+		return errUsage
+	}
+	return subcommands[args[0]](ctx, args[1:])
+}
+
+func runConfigContexts(ctx context.Context, args []string) error {
+	flags, globals := newFlags("config contexts", "config contexts [flags]")
+	parseFlags(flags, args)
+	output, err := ParseOutputFormat(globals.output)
+	if err != nil {
+		return err
+	}
+	config, err := LoadConfig(globals.config)
+	if err != nil {
+		return err
+	}
+
+	views := []ContextView{}
+	for _, name := range config.ContextNames() {
+		views = append(views, ContextView{Name: name, Current: name == config.CurrentContext, Context: config.Contexts[name]})
+	}
+	return (&Session{Output: output, Out: os.Stdout}).Print(views, contextsTable(views...))
+}
+
+func runConfigUse(ctx context.Context, args []string) error {
+	flags, globals := newFlags("config use", "config use <name> [flags]")
+	args = parseFlags(flags, args)
+	if len(args) != 1 {
+		flags.Usage()
+		return errUsage
+	}
+	config, err := LoadConfig(globals.config)
+	if err != nil {
+		return err
+	}
+	if _, err := config.Context(args[0]); err != nil {
+		return err
+	}
+	config.CurrentContext = args[0]
+	return SaveConfig(globals.config, config)
+}
+
+// Creates the context, or changes the endpoints given of an existing one.
+func runConfigSet(ctx context.Context, args []string) error {
+	flags, globals := newFlags("config set", "config set <name> [-gateway url] [-grpc address] [-rabbitmq url] [flags]")
+	gateway := flags.String("gateway", "", "the base url of the API gateway")
+	grpcAddress := flags.String("grpc", "", "the address of the gRPC server of the movies service")
+	rabbitMQ := flags.String("rabbitmq", "", "the url of RabbitMQ")
+	args = parseFlags(flags, args)
+	if len(args) != 1 {
+		flags.Usage()
+		return errUsage
+	}
+	config, err := LoadConfig(globals.config)
+	if err != nil {
+		return err
+	}
+	if config.Contexts == nil {
+		config.Contexts = map[string]Context{}
+	}
+
+	context := config.Contexts[args[0]]
+	if *gateway != "" {
+		context.Gateway = *gateway
+	}
+	if *grpcAddress != "" {
+		context.GRPC = *grpcAddress
+	}
+	if *rabbitMQ != "" {
+		context.RabbitMQ = *rabbitMQ
+	}
+	config.Contexts[args[0]] = context
+	if config.CurrentContext == "" {
+		config.CurrentContext = args[0]
+	}
+	return SaveConfig(globals.config, config)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	pb "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/grpc/movies"
+)
+
+const healthTimeout = 5 * time.Second
+
+type HealthView struct {
+	Component string          `json:"component" yaml:"component"`
+	Endpoint string           `json:"endpoint" yaml:"endpoint"`
+	Healthy bool              `json:"healthy" yaml:"healthy"`
+	Latency time.Duration     `json:"latency_ns" yaml:"latency_ns"`
+	Error string              `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+func healthTable(checks ...HealthView) Table {
+	table := Table{Headers: []string{"COMPONENT", "ENDPOINT", "STATUS", "LATENCY", "ERROR"}}
+	for _, check := range checks {
+		status := "up"
+		if !check.Healthy {
+			status = "down"
+		}
+		table.Rows = append(table.Rows, []string{
+			check.Component, check.Endpoint, status, check.Latency.Round(time.Millisecond).String(), check.Error,
+		})
+	}
+	return table
+}
+
+// Times check, bounding it by the health timeout.
+func checkHealth(ctx context.Context, component, endpoint string, check func(context.Context) error) HealthView {
+	ctx, cancel := context.WithTimeout(ctx, healthTimeout)
+	defer cancel()
+	view := HealthView{Component: component, Endpoint: endpoint}
+	start := time.Now()
+	err := check(ctx)
+	view.Latency = time.Since(start)
+	view.Healthy = err == nil
+	if err != nil {
+		view.Error = err.Error()
+	}
+	return view
+}
+
+// Checks the gateway, the movie service and the broker of the context,
+// failing when any of them is down.
+func runHealth(ctx context.Context, args []string) error {
+	flags, globals := newFlags("health", "health [flags]")
+	parseFlags(flags, args)
+	session, err := globals.session()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	checks := []HealthView{
+		checkHealth(ctx, "gateway", session.Context.Gateway, func(ctx context.Context) error {
+			_, err := session.Request(ctx, http.MethodGet, "/openapi.json", nil, nil, nil)
+			return err
+		}),
+		checkHealth(ctx, "movies", session.Context.GRPC, func(ctx context.Context) error {
+			client, err := session.Movies()
+			if err != nil {
+				return err
+			}
+			_, err = client.GetMovies(ctx, &pb.GetMoviesRequest{Limit: 1})
+			return err
+		}),
+		checkHealth(ctx, "rabbitmq", redactURL(session.Context.RabbitMQ), func(ctx context.Context) error {
+			conn, err := dialRabbitMQ(session)
+			if err != nil {
+				return err
+			}
+			return conn.Close()
+		}),
+	}
+	if err := session.Print(checks, healthTable(checks...)); err != nil {
+		return err
+	}
+	var down []string
+	for _, check := range checks {
+		if !check.Healthy {
+			down = append(down, check.Component)
+		}
+	}
+	if len(down) > 0 {
+		return fmt.Errorf("%s down", strings.Join(down, ", "))
+	}
+	return nil
+}
+
+// Hides the password of the url, printed along the checks.
+func redactURL(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	return parsed.Redacted()
+}
@@ -0,0 +1,77 @@
+// Operates the movies deployments from the command line, through the gRPC
+// server of the movies service, the API gateway and RabbitMQ.
+//
+// Usage:
+//
+//	moviesctl get <id>
+//	moviesctl list [-year 1995] [-max 50]
+//	moviesctl search <title> [-year 1995] [-max 50]
+//	moviesctl create -title <title> -year <year>
+//	moviesctl delete <id> [-version 3]
+//	moviesctl import -file movies.json [-format json|ndjson|csv] [-wait]
+//	moviesctl export [-file movies.json] [-format json|ndjson|csv] [-segments 4]
+//	moviesctl queues list|purge <queue> -yes|replay <queue> [-from <queue>.dlq]
+//	moviesctl health
+//	moviesctl config contexts|use <name>|set <name> [-gateway url] [-grpc address] [-rabbitmq url]
+//
+// Every command takes -context, the context of the config file used, -config,
+// the config file, $MOVIESCTL_CONFIG or moviesctl/config.yaml in the user
+// config directory by default, and -o, the output, table, json or yaml.
+// Without a config file, the endpoints of the docker compose deployment are
+// used.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+)
+
+type command func(ctx context.Context, args []string) error
+
+// Returned when the arguments are wrong, once the usage is printed.
+var errUsage = errors.New("invalid arguments")
+
+var commands = map[string]command{
+	"get": runGet,
+	"list": runList,
+	"search": runSearch,
+	"create": runCreate,
+	"delete": runDelete,
+	"import": runImport,
+	"export": runExport,
+	"queues": runQueues,
+	"health": runHealth,
+	"config": runConfig,
+}
+
+func usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(os.Stderr, "Usage: moviesctl <command> [flags]\n\nCommands: %s\n", strings.Join(names, ", "))
+}
+
+func main() {
+	if len(os.Args) < 2 || commands[os.Args[1]] == nil {
+		usage()
+		os.Exit(2)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := commands[os.Args[1]](ctx, os.Args[2:]); err != nil {
+		stop()
+		if errors.Is(err, errUsage) {
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "moviesctl %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
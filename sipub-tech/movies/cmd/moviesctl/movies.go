@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/grpc/movies"
+)
+
+const (
+	defaultPageSize = 100
+)
+
+type MovieView struct {
+	ID int64              `json:"id" yaml:"id"`
+	Title string          `json:"title" yaml:"title"`
+	Year string           `json:"year" yaml:"year"`
+	Version int64         `json:"version" yaml:"version"`
+	UpdatedAt time.Time   `json:"updated_at" yaml:"updated_at"`
+}
+
+func newMovieView(movie *pb.Movie) MovieView {
+	view := MovieView{ID: movie.Id, Title: movie.Title, Year: movie.Year, Version: movie.Version}
+	if movie.UpdatedAt != nil {
+		view.UpdatedAt = movie.UpdatedAt.AsTime()
+	}
+	return view
+}
+
+func moviesTable(movies ...MovieView) Table {
+	table := Table{Headers: []string{"ID", "TITLE", "YEAR", "VERSION", "UPDATED"}}
+	for _, movie := range movies {
+		updated := "-"
+		if !movie.UpdatedAt.IsZero() && movie.UpdatedAt.Unix() != 0 {
+			updated = movie.UpdatedAt.Local().Format(time.DateTime)
+		}
+		table.Rows = append(table.Rows, []string{
+			strconv.FormatInt(movie.ID, 10), movie.Title, movie.Year, strconv.FormatInt(movie.Version, 10), updated,
+		})
+	}
+	return table
+}
+
+// What a command that only queues a change answers.
+type StatusView struct {
+	Status string   `json:"status" yaml:"status"`
+	Detail string   `json:"detail" yaml:"detail"`
+}
+
+func (view StatusView) table() Table {
+	return Table{Headers: []string{"STATUS", "DETAIL"}, Rows: [][]string{{view.Status, view.Detail}}}
+}
+
+func parseMovieID(raw string) (int64, error) {
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("malformed movie id %q", raw)
+	}
+	return id, nil
+}
+
+func runGet(ctx context.Context, args []string) error {
+	flags, globals := newFlags("get", "get <id> [flags]")
+	args = parseFlags(flags, args)
+	if len(args) != 1 {
+		flags.Usage()
+		return errUsage
+	}
+	id, err := parseMovieID(args[0])
+	if err != nil {
+		return err
+	}
+	session, err := globals.session()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	client, err := session.Movies()
+	if err != nil {
+		return err
+	}
+
+	movie, err := client.GetMovie(ctx, &pb.GetMovieRequest{Id: id})
+	if err != nil {
+		return fmt.Errorf("failed getting movie %d: %w", id, err)
+	}
+	view := newMovieView(movie)
+	return session.Print(view, moviesTable(view))
+}
+
+// Calls visit with the pages of the movies of the year, or of every
+// movie, following the cursors until the last page or until visit
+// returns false.
+func pageMovies(ctx context.Context, client pb.MovieServiceClient, year string, pageSize int, visit func([]*pb.Movie) bool) error {
+	var cursor int64
+	for {
+		page, err := client.GetMovies(ctx, &pb.GetMoviesRequest{Year: year, Limit: int32(pageSize), Cursor: cursor})
+		if err != nil {
+			return fmt.Errorf("failed listing movies after %d: %w", cursor, err)
+		}
+		if !visit(page.Movies) || page.Cursor == 0 {
+			return nil
+		}
+		cursor = page.Cursor
+	}
+}
+
+func runList(ctx context.Context, args []string) error {
+	flags, globals := newFlags("list", "list [-year 1995] [-max 50] [flags]")
+	year := flags.String("year", "", "only list the movies of this year")
+	pageSize := flags.Int("page-size", defaultPageSize, "how many movies each request fetches")
+	maximum := flags.Int("max", 0, "stop after this many movies, 0 for every movie")
+	parseFlags(flags, args)
+	return listMovies(ctx, globals, *year, *pageSize, *maximum, func(*pb.Movie) bool { return true })
+}
+
+func runSearch(ctx context.Context, args []string) error {
+	flags, globals := newFlags("search", "search <title> [-year 1995] [-max 50] [flags]")
+	year := flags.String("year", "", "only search the movies of this year")
+	pageSize := flags.Int("page-size", defaultPageSize, "how many movies each request fetches")
+	maximum := flags.Int("max", 0, "stop after this many matches, 0 for every match")
+	args = parseFlags(flags, args)
+	if len(args) == 0 {
+		flags.Usage()
+		return errUsage
+	}
+	title := strings.ToLower(strings.Join(args, " "))
+	return listMovies(ctx, globals, *year, *pageSize, *maximum, func(movie *pb.Movie) bool {
+		return strings.Contains(strings.ToLower(movie.Title), title)
+	})
+}
+
+// Pages through the movies, keeping the ones matched up to maximum. The
+// title is matched here, as the gateway only filters by year.
+func listMovies(
+	ctx context.Context, globals *globalFlags, year string, pageSize, maximum int, match func(*pb.Movie) bool,
+) error {
+	session, err := globals.session()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	client, err := session.Movies()
+	if err != nil {
+		return err
+	}
+
+	views := []MovieView{}
+	err = pageMovies(ctx, client, year, pageSize, func(movies []*pb.Movie) bool {
+		for _, movie := range movies {
+			if !match(movie) {
+				continue
+			}
+			views = append(views, newMovieView(movie))
+			if maximum > 0 && len(views) >= maximum {
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return session.Print(views, moviesTable(views...))
+}
+
+func runCreate(ctx context.Context, args []string) error {
+	flags, globals := newFlags("create", "create -title <title> -year <year> [flags]")
+	title := flags.String("title", "", "the title of the movie")
+	year := flags.String("year", "", "the year of the movie, with 4 digits")
+	parseFlags(flags, args)
+	if *title == "" || *year == "" {
+		flags.Usage()
+		return errUsage
+	}
+	session, err := globals.session()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	movie := map[string]string{"title": *title, "year": *year}
+	if _, err := session.Request(ctx, http.MethodPost, "/v1/movies/", nil, movie, nil); err != nil {
+		return fmt.Errorf("failed creating movie: %w", err)
+	}
+	view := StatusView{Status: "queued", Detail: fmt.Sprintf("The creation of %q (%s) was queued.", *title, *year)}
+	return session.Print(view, view.table())
+}
+
+func runDelete(ctx context.Context, args []string) error {
+	flags, globals := newFlags("delete", "delete <id> [-version 3] [flags]")
+	version := flags.Int("version", 0, "only delete the movie if it is still on this version")
+	args = parseFlags(flags, args)
+	if len(args) != 1 {
+		flags.Usage()
+		return errUsage
+	}
+	id, err := parseMovieID(args[0])
+	if err != nil {
+		return err
+	}
+	session, err := globals.session()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	headers := map[string]string{}
+	if *version > 0 {
+		headers["If-Match"] = fmt.Sprintf(`"%d-%d"`, id, *version)
+	}
+	if _, err := session.Request(ctx, http.MethodDelete, fmt.Sprintf("/v1/movies/%d", id), headers, nil, nil); err != nil {
+		return fmt.Errorf("failed deleting movie %d: %w", id, err)
+	}
+	view := StatusView{Status: "queued", Detail: fmt.Sprintf("The deletion of movie %d was queued.", id)}
+	return session.Print(view, view.table())
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+type OutputFormat string
+
+const (
+	OutputTable OutputFormat = "table"
+	OutputJSON  OutputFormat = "json"
+	OutputYAML  OutputFormat = "yaml"
+)
+
+func ParseOutputFormat(raw string) (OutputFormat, error) {
+	switch format := OutputFormat(strings.ToLower(raw)); format {
+	case OutputTable, OutputJSON, OutputYAML:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown output %q, expected table, json or yaml", raw)
+	}
+}
+
+// The rows of a value printed as a table.
+type Table struct {
+	Headers []string
+	Rows [][]string
+}
+
+// Prints value as JSON or YAML, or the table as aligned columns.
+func Print(writer io.Writer, format OutputFormat, value any, table Table) error {
+	switch format {
+	case OutputJSON:
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	case OutputYAML:
+		encoder := yaml.NewEncoder(writer)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(value); err != nil {
+			return err
+		}
+		return encoder.Close()
+	default:
+		tabs := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tabs, strings.Join(table.Headers, "\t"))
+		for _, row := range table.Rows {
+			fmt.Fprintln(tabs, strings.Join(row, "\t"))
+		}
+		return tabs.Flush()
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPrint(t *testing.T) {
+	view := StatusView{Status: "queued", Detail: "done"}
+	expected := map[OutputFormat]string{
+		OutputTable: "STATUS  DETAIL\nqueued  done\n",
+		OutputJSON: "{\n  \"status\": \"queued\",\n  \"detail\": \"done\"\n}\n",
+		OutputYAML: "status: queued\ndetail: done\n",
+	}
+
+	for format, output := range expected {
+		t.Run("should print "+string(format), func(t *testing.T) {
+			var buffer bytes.Buffer
+			if err := Print(&buffer, format, view, view.table()); err != nil {
+				t.Fatalf("Error printing: %v", err)
+			}
+			if buffer.String() != output {
+				t.Errorf("Output: %q different from Expected: %q", buffer.String(), output)
+			}
+		})
+	}
+
+	t.Run("should refuse unknown outputs", func(t *testing.T) {
+		if _, err := ParseOutputFormat("xml"); err == nil {
+			t.Errorf("No error returned for an unknown output")
+		}
+	})
+}
+
+func TestParseFlags(t *testing.T) {
+	flags, globals := newFlags("get", "get <id>")
+	args := parseFlags(flags, []string{"42", "-o", "json", "-context", "staging"})
+
+	if len(args) != 1 || args[0] != "42" || globals.output != "json" || globals.context != "staging" {
+		t.Errorf("Unexpected arguments %v and flags %+v", args, globals)
+	}
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/messaging/constants"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/messaging/rabbitmq"
+)
+
+// The suffix of the dead letter queue of each queue. The consumers of the
+// movie service reject the poison messages, the ones that cannot be
+// decoded or are invalid, and their queues are declared dead lettering
+// the rejected messages to the queues with this suffix.
+const DeadLetterSuffix = rabbitmq.DeadLetterSuffix
+
+const dialTimeout = 5 * time.Second
+
+// The queues consumed by the movie service.
+var knownQueues = []string{
+	constants.MovieCreatorQueueName,
+	constants.MovieUpdaterQueueName,
+	constants.MovieDeleterQueueName,
+	constants.MovieBulkCreatorQueueName,
+	constants.MovieBulkDeleterQueueName,
+}
+
+type QueueView struct {
+	Name string        `json:"name" yaml:"name"`
+	Messages int       `json:"messages" yaml:"messages"`
+	Consumers int      `json:"consumers" yaml:"consumers"`
+	Exists bool        `json:"exists" yaml:"exists"`
+}
+
+func queuesTable(queues ...QueueView) Table {
+	table := Table{Headers: []string{"QUEUE", "MESSAGES", "CONSUMERS"}}
+	for _, queue := range queues {
+		messages, consumers := strconv.Itoa(queue.Messages), strconv.Itoa(queue.Consumers)
+		if !queue.Exists {
+			messages, consumers = "-", "-"
+		}
+		table.Rows = append(table.Rows, []string{queue.Name, messages, consumers})
+	}
+	return table
+}
+
+func dialRabbitMQ(session *Session) (*amqp.Connection, error) {
+	if session.Context.RabbitMQ == "" {
+		return nil, fmt.Errorf("the context has no rabbitmq url")
+	}
+	conn, err := amqp.DialConfig(session.Context.RabbitMQ, amqp.Config{Dial: amqp.DefaultDial(dialTimeout)})
+	if err != nil {
+		return nil, fmt.Errorf("failed connecting to rabbitmq: %w", err)
+	}
+	return conn, nil
+}
+
+// Inspects the queue without declaring it. A channel is closed by the
+// broker when the queue is missing, so each inspection opens its own.
+func inspectQueue(conn *amqp.Connection, name string) (QueueView, error) {
+	channel, err := conn.Channel()
+	if err != nil {
+		return QueueView{}, fmt.Errorf("failed opening a channel: %w", err)
+	}
+	defer channel.Close()
+	queue, err := channel.QueueDeclarePassive(name, true, false, false, false, nil)
+	var amqpErr *amqp.Error
+	if errors.As(err, &amqpErr) && amqpErr.Code == amqp.NotFound {
+		return QueueView{Name: name}, nil
+	}
+	if err != nil {
+		return QueueView{}, fmt.Errorf("failed inspecting queue %s: %w", name, err)
+	}
+	return QueueView{Name: name, Messages: queue.Messages, Consumers: queue.Consumers, Exists: true}, nil
+}
+
+func runQueues(ctx context.Context, args []string) error {
+	subcommands := map[string]command{
+		"list": runQueuesList,
+		"purge": runQueuesPurge,
+		"replay": runQueuesReplay,
+	}
+	if len(args) == 0 || subcommands[args[0]] == nil {
+		fmt.Println("Usage: moviesctl queues list|purge|replay [flags]")
+		return errUsage
+	}
+	return subcommands[args[0]](ctx, args[1:])
+}
+
+func runQueuesList(ctx context.Context, args []string) error {
+	flags, globals := newFlags("queues list", "queues list [flags]")
+	parseFlags(flags, args)
+	session, err := globals.session()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	conn, err := dialRabbitMQ(session)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	views := []QueueView{}
+	for _, name := range knownQueues {
+		for _, queue := range []string{name, name + DeadLetterSuffix} {
+			view, err := inspectQueue(conn, queue)
+			if err != nil {
+				return err
+			}
+			views = append(views, view)
+		}
+	}
+	return session.Print(views, queuesTable(views...))
+}
+
+func runQueuesPurge(ctx context.Context, args []string) error {
+	flags, globals := newFlags("queues purge", "queues purge <queue> -yes [flags]")
+	confirmed := flags.Bool("yes", false, "confirm that the messages of the queue are dropped")
+	args = parseFlags(flags, args)
+	if len(args) != 1 {
+		flags.Usage()
+		return errUsage
+	}
+	if !*confirmed {
+		return fmt.Errorf("purging drops every message of %s, run again with -yes to confirm", args[0])
+	}
+	session, err := globals.session()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	conn, err := dialRabbitMQ(session)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	channel, err := conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed opening a channel: %w", err)
+	}
+	defer channel.Close()
+
+	purged, err := channel.QueuePurge(args[0], false)
+	if err != nil {
+		return fmt.Errorf("failed purging queue %s: %w", args[0], err)
+	}
+	view := StatusView{Status: "purged", Detail: fmt.Sprintf("%d messages dropped from %s.", purged, args[0])}
+	return session.Print(view, view.table())
+}
+
+// Moves the messages of the dead letter queue of a queue back to it, one
+// at a time, acking each only once the broker confirmed its publishing.
+// Only the messages in the dead letter queue at the start are replayed,
+// so the ones failing again and dead-lettered back are not replayed
+// forever.
+func runQueuesReplay(ctx context.Context, args []string) error {
+	flags, globals := newFlags("queues replay", "queues replay <queue> [-from <queue>.dlq] [-max 100] [flags]")
+	from := flags.String("from", "", "the queue replayed, the dead letter queue of the queue by default")
+	maximum := flags.Int("max", 0, "stop after this many messages, 0 for every message")
+	args = parseFlags(flags, args)
+	if len(args) != 1 {
+		flags.Usage()
+		return errUsage
+	}
+	target := args[0]
+	if *from == "" {
+		*from = target + DeadLetterSuffix
+	}
+	session, err := globals.session()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	conn, err := dialRabbitMQ(session)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	channel, err := conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed opening a channel: %w", err)
+	}
+	defer channel.Close()
+	if _, err := channel.QueueDeclarePassive(target, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed finding queue %s: %w", target, err)
+	}
+	deadLetters, err := channel.QueueDeclarePassive(*from, true, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed finding queue %s: %w", *from, err)
+	}
+	limit := deadLetters.Messages
+	if *maximum > 0 && *maximum < limit {
+		limit = *maximum
+	}
+	if err := channel.Confirm(false); err != nil {
+		return fmt.Errorf("failed enabling publisher confirms: %w", err)
+	}
+	// The broker returns the messages it could not route before
+	// confirming them, so a returned message is received by the time its
+	// confirmation is.
+	returns := channel.NotifyReturn(make(chan amqp.Return, 1))
+
+	replayed := 0
+	for replayed < limit {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		message, ok, err := channel.Get(*from, false)
+		if err != nil {
+			return fmt.Errorf("failed getting a message from %s after %d replayed: %w", *from, replayed, err)
+		}
+		if !ok {
+			break
+		}
+		confirmation, err := channel.PublishWithDeferredConfirmWithContext(ctx, "", target, true, false, amqp.Publishing{
+			Headers: message.Headers,
+			ContentType: message.ContentType,
+			ContentEncoding: message.ContentEncoding,
+			DeliveryMode: amqp.Persistent,
+			CorrelationId: message.CorrelationId,
+			MessageId: message.MessageId,
+			Timestamp: message.Timestamp,
+			Type: message.Type,
+			Body: message.Body,
+		})
+		if err == nil {
+			var confirmed bool
+			if confirmed, err = confirmation.WaitContext(ctx); err == nil && !confirmed {
+				err = fmt.Errorf("the broker refused the message")
+			}
+		}
+		if err == nil {
+			select {
+			case returned := <-returns:
+				err = fmt.Errorf("the broker returned the message: %s", returned.ReplyText)
+			default:
+			}
+		}
+		if err != nil {
+			message.Nack(false, true)
+			return fmt.Errorf("failed replaying to %s after %d replayed: %w", target, replayed, err)
+		}
+		if err := message.Ack(false); err != nil {
+			return fmt.Errorf("failed acking a message of %s after %d replayed: %w", *from, replayed, err)
+		}
+		replayed++
+	}
+	view := StatusView{Status: "replayed", Detail: fmt.Sprintf("%d messages moved from %s to %s.", replayed, *from, target)}
+	return session.Print(view, view.table())
+}
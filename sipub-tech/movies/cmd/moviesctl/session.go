@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	pb "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/grpc/movies"
+)
+
+const (
+	requestTimeout = 30 * time.Second
+)
+
+// The flags every command takes, choosing the context and the output.
+type globalFlags struct {
+	config string
+	context string
+	output string
+}
+
+func newFlags(name, usage string) (*flag.FlagSet, *globalFlags) {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: moviesctl %s\n\nFlags:\n", usage)
+		flags.PrintDefaults()
+	}
+	globals := &globalFlags{}
+	flags.StringVar(&globals.config, "config", DefaultConfigPath(), "the config file with the contexts")
+	flags.StringVar(&globals.context, "context", "", "the context to use, the current one by default")
+	flags.StringVar(&globals.output, "o", string(OutputTable), "the output, table, json or yaml")
+	return flags, globals
+}
+
+// Parses the flags wherever they are among the arguments, returning the
+// others.
+func parseFlags(flags *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		flags.Parse(args)
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func (globals *globalFlags) session() (*Session, error) {
+	output, err := ParseOutputFormat(globals.output)
+	if err != nil {
+		return nil, err
+	}
+	config, err := LoadConfig(globals.config)
+	if err != nil {
+		return nil, err
+	}
+	context, err := config.Context(globals.context)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{
+		Context: context,
+		Output: output,
+		Out: os.Stdout,
+		http: &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+// The clients of the endpoints of a context, connected when first used.
+type Session struct {
+	Context Context
+	Output OutputFormat
+	Out io.Writer
+
+	conn *grpc.ClientConn
+	http *http.Client
+}
+
+func (session *Session) Print(value any, table Table) error {
+	return Print(session.Out, session.Output, value, table)
+}
+
+func (session *Session) Movies() (pb.MovieServiceClient, error) {
+	if session.conn == nil {
+		if session.Context.GRPC == "" {
+			return nil, fmt.Errorf("the context has no grpc address")
+		}
+		conn, err := grpc.NewClient(session.Context.GRPC, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, fmt.Errorf("failed connecting to %s: %w", session.Context.GRPC, err)
+		}
+		session.conn = conn
+	}
+	return pb.NewMovieServiceClient(session.conn), nil
+}
+
+func (session *Session) Close() {
+	if session.conn != nil {
+		session.conn.Close()
+	}
+}
+
+// A problem answered by the gateway.
+type Problem struct {
+	Title string    `json:"title"`
+	Status int      `json:"status"`
+	Detail string   `json:"detail"`
+	Code string     `json:"code"`
+}
+
+func (problem *Problem) Error() string {
+	return fmt.Sprintf("%d %s (%s): %s", problem.Status, problem.Title, problem.Code, problem.Detail)
+}
+
+// Sends body as JSON to the path of the gateway, decoding the answer into
+// response when it is not nil. Answers other than 2xx are returned as a
+// *Problem.
+func (session *Session) Request(
+	ctx context.Context, method, path string, headers map[string]string, body, response any,
+) (*http.Response, error) {
+	if session.Context.Gateway == "" {
+		return nil, fmt.Errorf("the context has no gateway url")
+	}
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed encoding the request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(session.Context.Gateway, "/") + path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("malformed request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	res, err := session.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed requesting %s %s: %w", method, path, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		problem := &Problem{Status: res.StatusCode, Title: http.StatusText(res.StatusCode)}
+		json.NewDecoder(res.Body).Decode(problem)
+		return res, problem
+	}
+	if response != nil {
+		if err := json.NewDecoder(res.Body).Decode(response); err != nil {
+			return res, fmt.Errorf("malformed answer to %s %s: %w", method, path, err)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/config/tlsconfig"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/infra/entrypoints"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/infra/idgen"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/infra/interceptors"
+)
+
+const EnvPrefix = "MOVIE_SERVICE_"
+
+// The configuration of the movie service, loaded from the MOVIE_SERVICE_
+// variables.
+type Config struct {
+	GRPCPort int               `yaml:"grpc_port" env:"GRPC_LISTENING_PORT" validate:"min=1,max=65535" usage:"the port the gRPC server listens on"`
+	TranscodingPort int        `yaml:"transcoding_port" env:"TRANSCODING_PORT" validate:"min=0,max=65535" usage:"the port of the gRPC methods as JSON over HTTP, under /internal/v1, off when 0"`
+	RabbitMQURL string         `yaml:"rabbitmq_url" env:"RABBITMQ_CONNECTION_URL" validate:"required,url" secret:"url" usage:"the url of RabbitMQ"`
+	DynamoDB DynamoDBConfig    `yaml:"dynamodb"`
+	IDs IDsConfig              `yaml:"ids"`
+	GRPC GRPCConfig            `yaml:"grpc"`
+	RabbitMQTLS TLSFilesConfig `yaml:"rabbitmq_tls"`
+}
+
+type DynamoDBConfig struct {
+	// The AWS SDK defaults apply when they are empty.
+	Region string    `yaml:"region" env:"AWS_REGION" usage:"the AWS region of DynamoDB"`
+	Endpoint string  `yaml:"endpoint" env:"DYNAMO_DB_ENDPOINT" validate:"omitempty,url" usage:"the DynamoDB endpoint, as the one of localstack"`
+}
+
+type IDsConfig struct {
+	Generator string  `yaml:"generator" env:"ID_GENERATOR" validate:"oneof=blocks snowflake" usage:"blocks, leased from DynamoDB, or snowflake"`
+	BlockSize int     `yaml:"block_size" env:"ID_BLOCK_SIZE" validate:"min=1" usage:"how many ids each block leased holds"`
+	// Unset, as -1, by default, as each replica needs its own.
+	Node int          `yaml:"node" env:"ID_NODE" usage:"the node of the replica in the snowflake ids, unique to each replica"`
+}
+
+type GRPCConfig struct {
+	AccessLog string                      `yaml:"access_log" env:"GRPC_ACCESS_LOG" validate:"oneof=json text off" usage:"the format of the access logs, json, text or off"`
+	DefaultDeadline time.Duration         `yaml:"default_deadline" env:"GRPC_DEFAULT_DEADLINE" validate:"min=0" usage:"the deadline of the unary calls sent without one, none when 0"`
+	MaxRecvMsgSize int                    `yaml:"max_recv_msg_size" env:"GRPC_MAX_RECV_MSG_SIZE" validate:"min=1" usage:"the largest message received, in bytes"`
+	MaxSendMsgSize int                    `yaml:"max_send_msg_size" env:"GRPC_MAX_SEND_MSG_SIZE" validate:"min=1" usage:"the largest message sent, in bytes"`
+	MaxConnectionAge time.Duration        `yaml:"max_connection_age" env:"GRPC_MAX_CONNECTION_AGE" validate:"min=0" usage:"the age the connections are closed at, for the clients to spread over the replicas, never when 0"`
+	MaxConnectionAgeGrace time.Duration   `yaml:"max_connection_age_grace" env:"GRPC_MAX_CONNECTION_AGE_GRACE" validate:"min=0" usage:"how long the calls in flight, as the streams of the exports, have once a connection is too old"`
+	KeepaliveTime time.Duration           `yaml:"keepalive_time" env:"GRPC_KEEPALIVE_TIME" validate:"min=1s" usage:"how long a connection is idle before it is pinged"`
+	KeepaliveTimeout time.Duration        `yaml:"keepalive_timeout" env:"GRPC_KEEPALIVE_TIMEOUT" validate:"min=1s" usage:"how long a ping has to be answered before the connection is closed"`
+	KeepaliveMinClientTime time.Duration  `yaml:"keepalive_min_client_time" env:"GRPC_KEEPALIVE_MIN_CLIENT_TIME" validate:"min=0" usage:"the clients pinging more often than this are disconnected"`
+	// Served in plaintext without a certificate.
+	TLSCertFile string                    `yaml:"tls_cert_file" env:"GRPC_TLS_CERT_FILE" usage:"the PEM certificate of the gRPC server, served over TLS when set"`
+	TLSKeyFile string                     `yaml:"tls_key_file" env:"GRPC_TLS_KEY_FILE" usage:"the PEM key of the certificate of the gRPC server"`
+	TLSClientCAFile string                `yaml:"tls_client_ca_file" env:"GRPC_TLS_CLIENT_CA_FILE" usage:"the PEM CAs of the client certificates, required from the clients, as mTLS, when set"`
+	Reflection bool                       `yaml:"reflection" env:"GRPC_REFLECTION" usage:"whether the services are listed to the clients, as grpcurl, by the gRPC reflection"`
+}
+
+// The files of the amqps connections, the CAs of the system verifying
+// RabbitMQ when they are empty.
+type TLSFilesConfig struct {
+	CAFile string    `yaml:"ca_file" env:"RABBITMQ_TLS_CA_FILE" usage:"the PEM CAs verifying RabbitMQ, with an amqps url"`
+	CertFile string  `yaml:"cert_file" env:"RABBITMQ_TLS_CERT_FILE" usage:"the PEM client certificate presented to RabbitMQ"`
+	KeyFile string   `yaml:"key_file" env:"RABBITMQ_TLS_KEY_FILE" usage:"the PEM key of the client certificate"`
+	ServerName string  `yaml:"server_name" env:"RABBITMQ_TLS_SERVER_NAME" usage:"the name verified in the certificate of RabbitMQ, the host of rabbitmq_url when empty, required when it is an IP address"`
+}
+
+// Whether any file or the server name is set, the connection then made
+// with them.
+func (config TLSFilesConfig) IsSet() bool {
+	return config.CAFile != "" || config.CertFile != "" || config.KeyFile != "" || config.ServerName != ""
+}
+
+func (config TLSFilesConfig) Files() tlsconfig.Files {
+	return tlsconfig.Files{
+		CA: config.CAFile,
+		Cert: config.CertFile,
+		Key: config.KeyFile,
+		ServerName: config.ServerName,
+		ReloadInterval: tlsconfig.DefaultReloadInterval,
+	}
+}
+
+func DefaultConfig() Config {
+	server := entrypoints.DefaultGRPCServerConfig()
+	return Config{
+		GRPCPort: 5000,
+		IDs: IDsConfig{Generator: "blocks", BlockSize: idgen.DefaultBlockSize, Node: -1},
+		GRPC: GRPCConfig{
+			AccessLog: "json",
+			DefaultDeadline: server.Interceptors.DefaultDeadline,
+			MaxRecvMsgSize: server.MaxRecvMsgSize,
+			MaxSendMsgSize: server.MaxSendMsgSize,
+			MaxConnectionAge: server.MaxConnectionAge,
+			MaxConnectionAgeGrace: server.MaxConnectionAgeGrace,
+			KeepaliveTime: server.KeepaliveTime,
+			KeepaliveTimeout: server.KeepaliveTimeout,
+			KeepaliveMinClientTime: server.KeepaliveMinClientTime,
+		},
+	}
+}
+
+// The configuration of the gRPC server, with the access logs written to
+// the standard error in the format chosen, failing when the TLS files
+// cannot be loaded.
+func (config GRPCConfig) ServerConfig() (entrypoints.GRPCServerConfig, error) {
+	var accessLog *slog.Logger
+	switch config.AccessLog {
+	case "json":
+		accessLog = slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	case "text":
+		accessLog = slog.New(slog.NewTextHandler(os.Stderr, nil))
+	}
+	var tlsConfig *tls.Config
+	if config.TLSCertFile != "" {
+		reloader, err := tlsconfig.NewReloader(tlsconfig.Files{
+			CA: config.TLSClientCAFile,
+			Cert: config.TLSCertFile,
+			Key: config.TLSKeyFile,
+			ReloadInterval: tlsconfig.DefaultReloadInterval,
+		})
+		if err != nil {
+			return entrypoints.GRPCServerConfig{}, fmt.Errorf("failed loading the gRPC TLS files: %w", err)
+		}
+		if tlsConfig, err = reloader.ServerConfig(); err != nil {
+			return entrypoints.GRPCServerConfig{}, err
+		}
+	}
+	return entrypoints.GRPCServerConfig{
+		Interceptors: interceptors.Config{AccessLog: accessLog, DefaultDeadline: config.DefaultDeadline},
+		MaxRecvMsgSize: config.MaxRecvMsgSize,
+		MaxSendMsgSize: config.MaxSendMsgSize,
+		MaxConnectionAge: config.MaxConnectionAge,
+		MaxConnectionAgeGrace: config.MaxConnectionAgeGrace,
+		KeepaliveTime: config.KeepaliveTime,
+		KeepaliveTimeout: config.KeepaliveTimeout,
+		KeepaliveMinClientTime: config.KeepaliveMinClientTime,
+		TLS: tlsConfig,
+		Reflection: config.Reflection,
+	}, nil
+}
+
+func (config *Config) Validate() error {
+	if (config.GRPC.TLSCertFile == "") != (config.GRPC.TLSKeyFile == "") {
+		return fmt.Errorf("grpc.tls_cert_file and grpc.tls_key_file (%sGRPC_TLS_CERT_FILE, %sGRPC_TLS_KEY_FILE) must be set together", EnvPrefix, EnvPrefix)
+	}
+	if config.GRPC.TLSClientCAFile != "" && config.GRPC.TLSCertFile == "" {
+		return fmt.Errorf("grpc.tls_client_ca_file (%sGRPC_TLS_CLIENT_CA_FILE) requires grpc.tls_cert_file, as the clients are only verified over TLS", EnvPrefix)
+	}
+	if (config.RabbitMQTLS.CertFile == "") != (config.RabbitMQTLS.KeyFile == "") {
+		return fmt.Errorf("rabbitmq_tls.cert_file and rabbitmq_tls.key_file (%sRABBITMQ_TLS_CERT_FILE, %sRABBITMQ_TLS_KEY_FILE) must be set together", EnvPrefix, EnvPrefix)
+	}
+	if config.IDs.Generator != "snowflake" {
+		return nil
+	}
+	if config.IDs.Node < 0 || config.IDs.Node > idgen.MaxSnowflakeNode {
+		return fmt.Errorf(
+			"ids.node (%sID_NODE, -ids.node) must be between 0 and %d, unique to each replica, with the snowflake generator",
+			EnvPrefix, idgen.MaxSnowflakeNode,
+		)
+	}
+	return nil
+}
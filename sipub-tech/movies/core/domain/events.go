@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"context"
+)
+
+type MovieEventType string
+
+const (
+	MovieCreated MovieEventType = "MovieCreated"
+	MovieUpdated MovieEventType = "MovieUpdated"
+	MovieDeleted MovieEventType = "MovieDeleted"
+)
+
+// Something that happened to a movie, carrying the movie as it was left:
+// the new movie for creations and updates, and the removed one for
+// deletions.
+type MovieEvent struct {
+	ID string `json:"event_id"`
+	Type MovieEventType `json:"type"`
+	// Unix milliseconds of when the movie was changed.
+	OccurredAt int64 `json:"occurred_at"`
+	// The request the event belongs to, shared by every message it caused.
+	CorrelationID string `json:"correlation_id"`
+	// The message that caused the event.
+	CausationID string `json:"causation_id"`
+	Movie Movie `json:"movie"`
+}
+
+// What caused the events raised while handling a message or a write RPC.
+type EventCause struct {
+	CorrelationID string
+	CausationID string
+}
+
+type contextKey string
+
+const eventCauseKey contextKey = "eventCause"
+
+func WithEventCause(ctx context.Context, cause EventCause) context.Context {
+	return context.WithValue(ctx, eventCauseKey, cause)
+}
+
+// The cause set by WithEventCause, empty when there is none.
+func EventCauseFrom(ctx context.Context) EventCause {
+	cause, _ := ctx.Value(eventCauseKey).(EventCause)
+	return cause
+}
@@ -5,4 +5,8 @@ type Movie struct {
 	ID int `json:"id"`
 	Title string `json:"title"`
 	Year string `json:"year"`
+	// Starts at 1 and is incremented on every update of the movie.
+	Version int `json:"version"`
+	// Unix milliseconds of the last change of the movie.
+	UpdatedAt int64 `json:"updated_at"`
 }
@@ -0,0 +1,53 @@
+package dtos
+
+import (
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/domain"
+)
+
+type ImportMode string
+
+const (
+	// Overwrites the existing movies with the imported ones.
+	ImportUpsert ImportMode = "upsert"
+	// Keeps the existing movies, skipping the imported ones with their ids.
+	ImportSkipExisting ImportMode = "skip-existing"
+)
+
+// A movie of an import, which keeps its id.
+type ImportMovieDTO struct {
+	ID MovieID     `json:"id" validate:"required,gt=0"`
+	Title string   `json:"title" validate:"required,max=255"`
+	Year string    `json:"year" validate:"required,movieyear"`
+}
+
+// Why a row of an import failed, by its position in the file, from 1.
+type ImportRowErrorDTO struct {
+	Row int          `json:"row"`
+	ID MovieID       `json:"id,omitempty"`
+	Error string     `json:"error"`
+}
+
+// How an import is going, or how it ended. The rows resumed from are
+// counted in Total and Done only.
+type ImportSummaryDTO struct {
+	// The rows read so far.
+	Total int                    `json:"total"`
+	Imported int                 `json:"imported"`
+	Skipped int                  `json:"skipped"`
+	Failed int                   `json:"failed"`
+	// The rows from the start of the file that are all imported, skipped
+	// or failed, for an interrupted import to resume after them.
+	Done int                     `json:"done"`
+	// The greatest id imported. The id counter is advanced at least to it,
+	// for the movies created later to get greater ids.
+	LastID MovieID               `json:"last_id"`
+	Errors []ImportRowErrorDTO   `json:"errors,omitempty"`
+}
+
+func (dto *ImportMovieDTO) ToDomain() domain.Movie {
+	return domain.Movie{
+		ID: int(dto.ID),
+		Title: dto.Title,
+		Year: dto.Year,
+	}
+}
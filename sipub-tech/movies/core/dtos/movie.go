@@ -11,8 +11,17 @@ func NewMovieID(id int) MovieID {
 type MovieID int
 
 type CreateMovieDTO struct {
-	Title string  `json:"title"`
-	Year string   `json:"year"`
+	Title string  `json:"title" validate:"required,max=255"`
+	Year string   `json:"year" validate:"required,movieyear"`
+}
+
+// Replaces the title and year of the movie. When Version is set, the
+// update only happens if the movie is still on that version.
+type UpdateMovieDTO struct {
+	ID MovieID    `json:"id" validate:"required,gt=0"`
+	Title string  `json:"title" validate:"required,max=255"`
+	Year string   `json:"year" validate:"required,movieyear"`
+	Version int   `json:"version" validate:"gte=0"`
 }
 
 type GetMoviesDTO struct {
@@ -21,6 +30,14 @@ type GetMoviesDTO struct {
 	Cursor int   `json:"cursor"`
 }
 
+// The movies of a StreamMoviesCase, as the ones of GetMoviesDTO following
+// every cursor, up to Limit movies, or all of them when it is 0.
+type StreamMoviesDTO struct {
+	Year string  `json:"year"`
+	Limit int    `json:"limit"`
+	Cursor int   `json:"cursor"`
+}
+
 func (dto *CreateMovieDTO) ToDomain() domain.Movie {
 	return domain.Movie{
 		Title: dto.Title,
@@ -28,6 +45,14 @@ func (dto *CreateMovieDTO) ToDomain() domain.Movie {
 	}
 }
 
+func (dto *UpdateMovieDTO) ToDomain() domain.Movie {
+	return domain.Movie{
+		ID: int(dto.ID),
+		Title: dto.Title,
+		Year: dto.Year,
+	}
+}
+
 func NewMovieResponseDTOFromDomain(movie domain.Movie) *MovieResponseDTO {
 	dto := mapDomainToResponse(movie)
 	return &dto
@@ -42,9 +67,11 @@ func MoviesToResponseDTOs(movies []domain.Movie) *[]MovieResponseDTO {
 }
 
 type MovieResponseDTO struct {
-	ID MovieID    `json:"id"`
-	Title string  `json:"title"`
-	Year string   `json:"year"`
+	ID MovieID        `json:"id"`
+	Title string      `json:"title"`
+	Year string       `json:"year"`
+	Version int       `json:"version"`
+	UpdatedAt int64   `json:"updated_at"`
 }
 
 func mapDomainToResponse(movie domain.Movie) MovieResponseDTO {
@@ -52,6 +79,7 @@ func mapDomainToResponse(movie domain.Movie) MovieResponseDTO {
 		ID: MovieID(movie.ID),
 		Title: movie.Title,
 		Year: movie.Year,
+		Version: movie.Version,
+		UpdatedAt: movie.UpdatedAt,
 	}
 }
-
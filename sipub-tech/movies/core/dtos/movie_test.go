@@ -2,8 +2,11 @@ package dtos_test
 
 import (
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 	"testing/quick"
+	"time"
 
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/domain"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/dtos"
@@ -33,3 +36,75 @@ func TestMoviesToResponseDTOs(t *testing.T) {
 		}
 	})
 }
+
+func TestCreateMovieDTOValidate(t *testing.T) {
+	currentYear := time.Now().Year()
+
+	t.Run("should accept a titled movie with a year between 1880 and the current year", func(t *testing.T) {
+		assertion := func(title string, yearOffset uint16) bool {
+			dto := dtos.CreateMovieDTO{
+				Title: "movie " + title,
+				Year: strconv.Itoa(dtos.MinimumMovieYear + int(yearOffset) % (currentYear - dtos.MinimumMovieYear + 1)),
+			}
+			if len(dto.Title) > 255 {
+				dto.Title = dto.Title[:255]
+			}
+			if err := dto.Validate(); err != nil {
+				t.Logf("Valid dto %+v refused: %v", dto, err)
+				return false
+			}
+			return true
+		}
+		if err := quick.Check(assertion, nil); err != nil {
+			t.Errorf("Error found testing assertion: %v", err)
+		}
+	})
+
+	t.Run("should refuse malformed movies", func(t *testing.T) {
+		invalid := []dtos.CreateMovieDTO{
+			{},
+			{Title: "a movie"},
+			{Year: "1990"},
+			{Title: strings.Repeat("a", 256), Year: "1990"},
+			{Title: "a movie", Year: "1879"},
+			{Title: "a movie", Year: strconv.Itoa(currentYear + 1)},
+			{Title: "a movie", Year: "01990"},
+			{Title: "a movie", Year: "abcd"},
+		}
+		for _, dto := range invalid {
+			if err := dto.Validate(); err == nil {
+				t.Errorf("Invalid dto %+v accepted", dto)
+			}
+		}
+	})
+}
+
+func TestUpdateMovieDTOValidate(t *testing.T) {
+	t.Run("should accept a movie with an id, with or without the expected version", func(t *testing.T) {
+		valid := []dtos.UpdateMovieDTO{
+			{ID: 1, Title: "a movie", Year: "1990"},
+			{ID: 7, Title: "a movie", Year: "1990", Version: 3},
+		}
+		for _, dto := range valid {
+			if err := dto.Validate(); err != nil {
+				t.Errorf("Valid dto %+v refused: %v", dto, err)
+			}
+		}
+	})
+
+	t.Run("should refuse malformed movies", func(t *testing.T) {
+		invalid := []dtos.UpdateMovieDTO{
+			{},
+			{Title: "a movie", Year: "1990"},
+			{ID: -1, Title: "a movie", Year: "1990"},
+			{ID: 1, Title: "a movie", Year: "1879"},
+			{ID: 1, Year: "1990"},
+			{ID: 1, Title: "a movie", Year: "1990", Version: -1},
+		}
+		for _, dto := range invalid {
+			if err := dto.Validate(); err == nil {
+				t.Errorf("Invalid dto %+v accepted", dto)
+			}
+		}
+	})
+}
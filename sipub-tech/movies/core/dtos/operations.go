@@ -0,0 +1,53 @@
+package dtos
+
+type OperationType string
+
+const (
+	BulkCreateMovies OperationType = "BulkCreateMovies"
+	BulkDeleteMovies OperationType = "BulkDeleteMovies"
+)
+
+type OperationItemStatus string
+
+const (
+	OperationItemCreated  OperationItemStatus = "created"
+	OperationItemDeleted  OperationItemStatus = "deleted"
+	OperationItemNotFound OperationItemStatus = "not_found"
+	OperationItemFailed   OperationItemStatus = "failed"
+)
+
+// A batch of the movies of a bulk creation, the first being the item at
+// Offset of the operation. The movies are validated one by one, so the
+// invalid ones fail alone.
+type BulkCreateMoviesDTO struct {
+	OperationID string          `json:"operation_id" validate:"required"`
+	Total int                   `json:"total" validate:"gt=0"`
+	Offset int                  `json:"offset" validate:"gte=0"`
+	Movies []CreateMovieDTO     `json:"movies" validate:"required,min=1"`
+}
+
+// A batch of the ids of a bulk deletion, the first being the item at
+// Offset of the operation.
+type BulkDeleteMoviesDTO struct {
+	OperationID string   `json:"operation_id" validate:"required"`
+	Total int            `json:"total" validate:"gt=0"`
+	Offset int           `json:"offset" validate:"gte=0"`
+	IDs []MovieID        `json:"ids" validate:"required,min=1"`
+}
+
+// What happened to an item of an operation, by its index in the request.
+type OperationItemResultDTO struct {
+	Index int                    `json:"index"`
+	MovieID MovieID              `json:"movie_id,omitempty"`
+	Status OperationItemStatus   `json:"status"`
+	Error string                 `json:"error,omitempty"`
+}
+
+// The results of a batch of an operation. They carry the operation type
+// and size, so any gateway can track the operation.
+type OperationResultsDTO struct {
+	OperationID string                    `json:"operation_id"`
+	Type OperationType                    `json:"type"`
+	Total int                             `json:"total"`
+	Results []OperationItemResultDTO      `json:"results"`
+}
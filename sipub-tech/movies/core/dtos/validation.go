@@ -0,0 +1,90 @@
+package dtos
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	MinimumMovieYear = 1880
+	movieYearTag     = "movieyear"
+)
+
+var (
+	validate = newValidator()
+)
+
+// Validates the DTO against the rules declared on its tags, the same
+// enforced by the api gateway. The error lists every invalid field.
+func (dto *CreateMovieDTO) Validate() error {
+	if err := validate.Struct(dto); err != nil {
+		return describeValidationError(err)
+	}
+	return nil
+}
+
+func (dto *UpdateMovieDTO) Validate() error {
+	if err := validate.Struct(dto); err != nil {
+		return describeValidationError(err)
+	}
+	return nil
+}
+
+func (dto *BulkCreateMoviesDTO) Validate() error {
+	if err := validate.Struct(dto); err != nil {
+		return describeValidationError(err)
+	}
+	return nil
+}
+
+func (dto *BulkDeleteMoviesDTO) Validate() error {
+	if err := validate.Struct(dto); err != nil {
+		return describeValidationError(err)
+	}
+	return nil
+}
+
+func (dto *ImportMovieDTO) Validate() error {
+	if err := validate.Struct(dto); err != nil {
+		return describeValidationError(err)
+	}
+	return nil
+}
+
+func IsValidMovieYear(year string) bool {
+	if len(year) != 4 {
+		return false
+	}
+	parsed, err := strconv.Atoi(year)
+	if err != nil {
+		return false
+	}
+	return parsed >= MinimumMovieYear && parsed <= time.Now().Year()
+}
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	if err := v.RegisterValidation(movieYearTag, func(field validator.FieldLevel) bool {
+		return IsValidMovieYear(field.Field().String())
+	}); err != nil {
+		panic(fmt.Sprintf("failed registering %q validation: %v", movieYearTag, err))
+	}
+	return v
+}
+
+func describeValidationError(err error) error {
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return fmt.Errorf("failed validating dto: %w", err)
+	}
+
+	fields := make([]string, len(validationErrors))
+	for index, fieldError := range validationErrors {
+		fields[index] = fmt.Sprintf("%s failed on %q", fieldError.Field(), fieldError.Tag())
+	}
+	return fmt.Errorf("invalid dto: %s", strings.Join(fields, ", "))
+}
@@ -0,0 +1,19 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/domain"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/dtos"
+)
+
+// Announces what happened to the movies, so other services can react.
+type MovieEventPublisher interface {
+	PublishMovieEvent(ctx context.Context, event domain.MovieEvent) error
+}
+
+// Reports the results of the items of the bulk operations to whoever
+// tracks them.
+type OperationResultsPublisher interface {
+	PublishOperationResults(ctx context.Context, results dtos.OperationResultsDTO) error
+}
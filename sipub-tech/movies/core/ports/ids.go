@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"context"
+)
+
+// Allocates the ids of the new movies. The ids are positive, fit in 53
+// bits and are never handed out twice, even across restarts and replicas,
+// but they are not consecutive.
+type IdGenerator interface {
+	NextIds(ctx context.Context, count int) ([]int, error)
+}
+
+// Reserves the next count ids of the shared counter for the caller alone,
+// returning the last of them.
+type IdBlockLeaser interface {
+	LeaseIds(ctx context.Context, count int) (last int, err error)
+}
@@ -0,0 +1,26 @@
+package ports
+
+import (
+	"fmt"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/dtos"
+)
+
+var (
+	ErrMalformedImportRow = fmt.Errorf("malformed import row")
+)
+
+// Reads the movies of an import one at a time, in the order of the file,
+// failing with io.EOF after the last. The errors wrapping
+// ErrMalformedImportRow only fail their row, the others stop the import.
+type MovieImportReader interface {
+	Read() (dtos.ImportMovieDTO, error)
+}
+
+// Writes the movies of an export one at a time, in a format a
+// MovieImportReader of it reads back. Close ends the file, without closing
+// what it is written to.
+type MovieExportWriter interface {
+	Write(movie dtos.MovieResponseDTO) error
+	Close() error
+}
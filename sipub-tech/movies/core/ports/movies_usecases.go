@@ -13,12 +13,48 @@ type MoviesGetter interface {
 	GetMovies(ctx context.Context, query dtos.GetMoviesDTO) (movies *[]dtos.MovieResponseDTO, newCursor int, err error)
 }
 
+// Returns the movies found and the ids missing, failing with
+// ErrTooManyMovieIds for too many ids.
+type MoviesBatchGetter interface {
+	BatchGetMovies(ctx context.Context, ids []dtos.MovieID) (movies *[]dtos.MovieResponseDTO, missing []dtos.MovieID, err error)
+}
+
+// Returns the saved movie, with its id and version.
 type MovieSaver interface {
-	SaveMovie(ctx context.Context, movie dtos.CreateMovieDTO) error
+	SaveMovie(ctx context.Context, movie dtos.CreateMovieDTO) (*dtos.MovieResponseDTO, error)
 }
 
+// Returns the movie as the update left it.
+type MovieUpdater interface {
+	UpdateMovie(ctx context.Context, movie dtos.UpdateMovieDTO) (*dtos.MovieResponseDTO, error)
+}
+
+// Deletes the movie, returning it, or nil when there was none. When
+// expectedVersion is not 0, the movie is only deleted if it is still on
+// that version.
 type MovieDeleter interface {
-	DeleteMovie(ctx context.Context, id dtos.MovieID) error
+	DeleteMovie(ctx context.Context, id dtos.MovieID, expectedVersion int) (*dtos.MovieResponseDTO, error)
+}
+
+// Writes a batch of a bulk operation, publishing the result of each item.
+type MovieBulkSaver interface {
+	SaveMovies(ctx context.Context, batch dtos.BulkCreateMoviesDTO) error
 }
 
+type MovieBulkDeleter interface {
+	DeleteMovies(ctx context.Context, batch dtos.BulkDeleteMoviesDTO) error
+}
 
+// Imports the movies read with their ids, reporting the rows that failed
+// in the summary. It fails when the file could not be read, the id counter
+// could not be advanced or the context is done, in which case the import
+// resumes from the Done rows of the summary.
+type MoviesImporter interface {
+	ImportMovies(ctx context.Context, reader MovieImportReader) (dtos.ImportSummaryDTO, error)
+}
+
+// Calls emit with every movie, one at a time, in no order, returning how
+// many were exported.
+type MoviesExporter interface {
+	ExportMovies(ctx context.Context, emit func(movie dtos.MovieResponseDTO) error) (int, error)
+}
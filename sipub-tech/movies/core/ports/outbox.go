@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/domain"
+)
+
+// The events recorded with the writes of the movies, waiting to be
+// published.
+type MovieEventOutbox interface {
+	// The oldest pending events, in the order they were recorded.
+	GetPendingEvents(ctx context.Context, limit int) ([]domain.MovieEvent, error)
+	MarkEventSent(ctx context.Context, eventID string) error
+	// Records a failed publication, keeping the event pending.
+	MarkEventFailed(ctx context.Context, eventID string, cause error) error
+}
@@ -11,20 +11,29 @@ type MovieRepository interface {
 	TableCreatorRepository
 	MovieQueryRepository
 	MovieExecuteRepository
+	MovieEventOutbox
 }
 
 type MovieQueryRepository interface {
 	MovieOneGetterRepository
+	MovieManyGetterRepository
 	MovieAllGetterRepository
+	MovieStreamerRepository
+	MovieSegmentScannerRepository
 }
 
 type MovieExecuteRepository interface {
 	MovieSaverRepository
+	MovieUpdaterRepository
 	MovieDeleterRepository
+	MovieBulkSaverRepository
+	MovieBulkDeleterRepository
 }
 
 var (
 	ErrMovieNotFound = fmt.Errorf("movie not found in the repository")
+	ErrVersionMismatch = fmt.Errorf("movie is not on the expected version")
+	ErrTooManyMovieIds = fmt.Errorf("too many movie ids")
 )
 
 type TableCreatorRepository interface {
@@ -35,14 +44,74 @@ type MovieOneGetterRepository interface {
 	GetOne(ctx context.Context, id int) (movie domain.Movie, err error)
 }
 
+// Returns the movies found, in the order of the ids, each once.
+type MovieManyGetterRepository interface {
+	GetMany(ctx context.Context, ids []int) ([]domain.Movie, error)
+}
+
 type MovieAllGetterRepository interface {
 	GetAll(ctx context.Context, year string, limit int, lastMovieId int) (movies []domain.Movie, cursor int, err error)
 }
 
+// Calls emit with each page of the movies of the year, or of every movie
+// when it is empty, after the movie lastMovieId when it is not 0, as
+// GetAll following every cursor. A page is only fetched once emit returns
+// for the previous one, and the first error of emit stops the pages.
+type MovieStreamerRepository interface {
+	StreamAll(ctx context.Context, year string, lastMovieId int, emit func(movies []domain.Movie) error) error
+}
+
+// Scans a segment of the movies, one of totalSegments that together cover
+// them all, calling emit with each page of movies found, in no order. The
+// segments can be scanned in parallel.
+type MovieSegmentScannerRepository interface {
+	ScanSegment(ctx context.Context, segment, totalSegments int, emit func(movies []domain.Movie) error) error
+}
+
+// The writes also record their event in the outbox, carrying the movie as
+// the write left it, so the event is stored if and only if the write is.
+
+// Returns the saved movie, with its id and version.
 type MovieSaverRepository interface {
-	Save(ctx context.Context, movie domain.Movie) error
+	Save(ctx context.Context, movie domain.Movie, event domain.MovieEvent) (domain.Movie, error)
+}
+
+// Replaces the title and year of the movie, incrementing its version, and
+// returns the updated movie.
+// When expectedVersion is not 0, it fails with ErrVersionMismatch if the
+// movie is on another version.
+type MovieUpdaterRepository interface {
+	Update(ctx context.Context, movie domain.Movie, expectedVersion int, event domain.MovieEvent) (domain.Movie, error)
 }
 
+// Returns the deleted movie, or a zero movie if there was none, in which
+// case no event is recorded.
+// When expectedVersion is not 0, it fails with ErrVersionMismatch if the
+// movie is on another version, and with ErrMovieNotFound if there is none.
 type MovieDeleterRepository interface {
-	Delete(ctx context.Context, id int) error
+	Delete(ctx context.Context, id int, expectedVersion int, event domain.MovieEvent) (domain.Movie, error)
+}
+
+// The bulk writes are applied movie by movie, returning the movies and
+// the error of each, in the order they were passed. Each event is still
+// recorded if and only if its write is.
+
+// Saves the movies with ids allocated at once.
+type MovieBulkSaverRepository interface {
+	SaveBatch(ctx context.Context, movies []domain.Movie, events []domain.MovieEvent) ([]domain.Movie, []error)
+}
+
+// Deletes the movies whatever their version, failing with ErrMovieNotFound
+// for the missing ones.
+type MovieBulkDeleterRepository interface {
+	DeleteBatch(ctx context.Context, ids []int, events []domain.MovieEvent) ([]domain.Movie, []error)
+}
+
+// Writes the movies with the ids they carry, returning the error of each,
+// and advances the id counter past them, for the movies created later not
+// to take their ids. The movies found are the existing ones, to skip.
+type MovieImporterRepository interface {
+	MovieManyGetterRepository
+	ImportBatch(ctx context.Context, movies []domain.Movie) []error
+	AdvanceIds(ctx context.Context, lastId int) error
 }
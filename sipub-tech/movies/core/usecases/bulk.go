@@ -0,0 +1,136 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/domain"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/ports"
+)
+
+const (
+	// Answered for the items failed by the repository, whose errors are
+	// only logged.
+	bulkSaveFailedMessage = "the movie could not be saved"
+	bulkDeleteFailedMessage = "the movie could not be deleted"
+)
+
+func NewSaveMoviesCase(repo ports.MovieBulkSaverRepository, publisher ports.OperationResultsPublisher) *SaveMoviesCase {
+	return &SaveMoviesCase{
+		repo: repo,
+		publisher: publisher,
+	}
+}
+
+type SaveMoviesCase struct {
+	repo ports.MovieBulkSaverRepository
+	publisher ports.OperationResultsPublisher
+}
+
+// The invalid movies fail alone, the others are saved together. The
+// results are published even when every item failed, so the operation
+// completes.
+func (ucase *SaveMoviesCase) SaveMovies(ctx context.Context, batch dtos.BulkCreateMoviesDTO) error {
+	results := make([]dtos.OperationItemResultDTO, len(batch.Movies))
+	var movies []domain.Movie
+	var events []domain.MovieEvent
+	var positions []int
+	for index, movie := range batch.Movies {
+		results[index].Index = batch.Offset + index
+		if err := movie.Validate(); err != nil {
+			results[index].Status = dtos.OperationItemFailed
+			results[index].Error = err.Error()
+			continue
+		}
+		movies = append(movies, movie.ToDomain())
+		events = append(events, newMovieEvent(ctx, domain.MovieCreated))
+		positions = append(positions, index)
+	}
+
+	if len(movies) > 0 {
+		saved, errs := ucase.repo.SaveBatch(ctx, movies, events)
+		for position, index := range positions {
+			if errs[position] != nil {
+				log.Printf("Failed saving item %d of operation %s: %v", results[index].Index, batch.OperationID, errs[position])
+				results[index].Status = dtos.OperationItemFailed
+				results[index].Error = bulkSaveFailedMessage
+				continue
+			}
+			results[index].Status = dtos.OperationItemCreated
+			results[index].MovieID = dtos.MovieID(saved[position].ID)
+		}
+	}
+
+	return publishResults(ctx, ucase.publisher, dtos.OperationResultsDTO{
+		OperationID: batch.OperationID,
+		Type: dtos.BulkCreateMovies,
+		Total: batch.Total,
+		Results: results,
+	})
+}
+
+func NewDeleteMoviesCase(repo ports.MovieBulkDeleterRepository, publisher ports.OperationResultsPublisher) *DeleteMoviesCase {
+	return &DeleteMoviesCase{
+		repo: repo,
+		publisher: publisher,
+	}
+}
+
+type DeleteMoviesCase struct {
+	repo ports.MovieBulkDeleterRepository
+	publisher ports.OperationResultsPublisher
+}
+
+// The missing movies are reported as not found, and the ids that are not
+// positive fail alone.
+func (ucase *DeleteMoviesCase) DeleteMovies(ctx context.Context, batch dtos.BulkDeleteMoviesDTO) error {
+	results := make([]dtos.OperationItemResultDTO, len(batch.IDs))
+	var ids []int
+	var events []domain.MovieEvent
+	var positions []int
+	for index, id := range batch.IDs {
+		results[index].Index = batch.Offset + index
+		results[index].MovieID = id
+		if id <= 0 {
+			results[index].Status = dtos.OperationItemFailed
+			results[index].Error = fmt.Sprintf("invalid movie id %d", id)
+			continue
+		}
+		ids = append(ids, int(id))
+		events = append(events, newMovieEvent(ctx, domain.MovieDeleted))
+		positions = append(positions, index)
+	}
+
+	if len(ids) > 0 {
+		_, errs := ucase.repo.DeleteBatch(ctx, ids, events)
+		for position, index := range positions {
+			switch err := errs[position]; {
+			case err == nil:
+				results[index].Status = dtos.OperationItemDeleted
+			case errors.Is(err, ports.ErrMovieNotFound):
+				results[index].Status = dtos.OperationItemNotFound
+			default:
+				log.Printf("Failed deleting item %d of operation %s: %v", results[index].Index, batch.OperationID, err)
+				results[index].Status = dtos.OperationItemFailed
+				results[index].Error = bulkDeleteFailedMessage
+			}
+		}
+	}
+
+	return publishResults(ctx, ucase.publisher, dtos.OperationResultsDTO{
+		OperationID: batch.OperationID,
+		Type: dtos.BulkDeleteMovies,
+		Total: batch.Total,
+		Results: results,
+	})
+}
+
+func publishResults(ctx context.Context, publisher ports.OperationResultsPublisher, results dtos.OperationResultsDTO) error {
+	if err := publisher.PublishOperationResults(ctx, results); err != nil {
+		return fmt.Errorf("error publishing the results of operation %s: %w", results.OperationID, err)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package usecases_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"testing/quick"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/domain"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/usecases"
+)
+
+func TestSaveMoviesCase(t *testing.T) {
+	t.Run("should save the valid movies together, failing the invalid ones alone", func (t *testing.T) {
+		repo := &MockMovieBulkRepository{}
+		publisher := &MockOperationResultsPublisher{}
+		batch := dtos.BulkCreateMoviesDTO{
+			OperationID: "operation",
+			Total: 10,
+			Offset: 7,
+			Movies: []dtos.CreateMovieDTO{
+				{Title: "first", Year: "1990"},
+				{Title: "invalid", Year: "1879"},
+				{Title: "second", Year: "2001"},
+			},
+		}
+
+		if err := usecases.NewSaveMoviesCase(repo, publisher).SaveMovies(context.Background(), batch); err != nil {
+			t.Fatalf("Error saving the movies: %v", err)
+		}
+
+		expectedMovies := []domain.Movie{{Title: "first", Year: "1990"}, {Title: "second", Year: "2001"}}
+		if !reflect.DeepEqual(expectedMovies, repo.moviesPassed) {
+			t.Errorf("Movies passed: %+v different from Expected: %+v", repo.moviesPassed, expectedMovies)
+		}
+		for _, event := range repo.eventsPassed {
+			if event.Type != domain.MovieCreated || event.ID == "" {
+				t.Errorf("Event %+v should be a creation with an id", event)
+			}
+		}
+
+		results := publisher.published.Results
+		if publisher.published.OperationID != "operation" || publisher.published.Total != 10 ||
+			publisher.published.Type != dtos.BulkCreateMovies || len(results) != 3 {
+			t.Fatalf("Unexpected results published: %+v", publisher.published)
+		}
+		expectedStatuses := []dtos.OperationItemStatus{
+			dtos.OperationItemCreated, dtos.OperationItemFailed, dtos.OperationItemCreated,
+		}
+		for index, result := range results {
+			if result.Index != 7 + index || result.Status != expectedStatuses[index] {
+				t.Errorf("Result %+v should be item %d with status %s", result, 7 + index, expectedStatuses[index])
+			}
+		}
+		if results[0].MovieID != 1 || results[2].MovieID != 2 || results[1].Error == "" {
+			t.Errorf("Created items should carry their ids and the failed one its error: %+v", results)
+		}
+	})
+
+	t.Run("should report the movies failed by the repository without its errors", func (t *testing.T) {
+		assertion := func(errorMessage string) bool {
+			repo := &MockMovieBulkRepository{errorReturned: fmt.Errorf("random error: %s", errorMessage)}
+			publisher := &MockOperationResultsPublisher{}
+			batch := dtos.BulkCreateMoviesDTO{
+				OperationID: "operation", Total: 1, Movies: []dtos.CreateMovieDTO{{Title: "a movie", Year: "1990"}},
+			}
+
+			if err := usecases.NewSaveMoviesCase(repo, publisher).SaveMovies(context.Background(), batch); err != nil {
+				t.Logf("Error saving the movies: %v", err)
+				return false
+			}
+			result := publisher.published.Results[0]
+			return result.Status == dtos.OperationItemFailed && result.Error != repo.errorReturned.Error()
+		}
+		if err := quick.Check(assertion, nil); err != nil {
+			t.Errorf("Failed assertion: %v", err)
+		}
+	})
+
+	t.Run("should return an error when the results could not be published", func (t *testing.T) {
+		publisher := &MockOperationResultsPublisher{errorReturned: fmt.Errorf("broker unavailable")}
+		batch := dtos.BulkCreateMoviesDTO{OperationID: "operation", Total: 1, Movies: []dtos.CreateMovieDTO{{}}}
+
+		if err := usecases.NewSaveMoviesCase(&MockMovieBulkRepository{}, publisher).SaveMovies(context.Background(), batch); err == nil {
+			t.Errorf("No error returned when the results were not published")
+		}
+	})
+}
+
+func TestDeleteMoviesCase(t *testing.T) {
+	t.Run("should delete the movies, reporting the missing ones as not found", func (t *testing.T) {
+		repo := &MockMovieBulkRepository{missing: map[int]bool{3: true}}
+		publisher := &MockOperationResultsPublisher{}
+		batch := dtos.BulkDeleteMoviesDTO{OperationID: "operation", Total: 3, IDs: []dtos.MovieID{1, 0, 3}}
+
+		if err := usecases.NewDeleteMoviesCase(repo, publisher).DeleteMovies(context.Background(), batch); err != nil {
+			t.Fatalf("Error deleting the movies: %v", err)
+		}
+
+		if !reflect.DeepEqual([]int{1, 3}, repo.idsPassed) {
+			t.Errorf("Ids passed: %+v different from Expected: [1 3]", repo.idsPassed)
+		}
+		expected := []dtos.OperationItemResultDTO{
+			{Index: 0, MovieID: 1, Status: dtos.OperationItemDeleted},
+			{Index: 1, MovieID: 0, Status: dtos.OperationItemFailed, Error: "invalid movie id 0"},
+			{Index: 2, MovieID: 3, Status: dtos.OperationItemNotFound},
+		}
+		if !reflect.DeepEqual(expected, publisher.published.Results) {
+			t.Errorf("Results published: %+v different from Expected: %+v", publisher.published.Results, expected)
+		}
+		if publisher.published.Type != dtos.BulkDeleteMovies {
+			t.Errorf("Results published with type %s", publisher.published.Type)
+		}
+	})
+}
+
+// Saves the movies with consecutive ids from 1, failing all of them with
+// errorReturned, and deletes every movie but the missing ones.
+type MockMovieBulkRepository struct {
+	moviesPassed []domain.Movie
+	eventsPassed []domain.MovieEvent
+	idsPassed []int
+	missing map[int]bool
+	errorReturned error
+}
+
+func (repo *MockMovieBulkRepository) SaveBatch(
+	ctx context.Context, movies []domain.Movie, events []domain.MovieEvent,
+) ([]domain.Movie, []error) {
+	repo.moviesPassed = movies
+	repo.eventsPassed = events
+	saved := make([]domain.Movie, len(movies))
+	errs := make([]error, len(movies))
+	for index, movie := range movies {
+		movie.ID = index + 1
+		saved[index] = movie
+		errs[index] = repo.errorReturned
+	}
+	return saved, errs
+}
+
+func (repo *MockMovieBulkRepository) DeleteBatch(
+	ctx context.Context, ids []int, events []domain.MovieEvent,
+) ([]domain.Movie, []error) {
+	repo.idsPassed = ids
+	repo.eventsPassed = events
+	deleted := make([]domain.Movie, len(ids))
+	errs := make([]error, len(ids))
+	for index, id := range ids {
+		if repo.missing[id] {
+			errs[index] = ports.ErrMovieNotFound
+			continue
+		}
+		deleted[index] = domain.Movie{ID: id}
+		errs[index] = repo.errorReturned
+	}
+	return deleted, errs
+}
+
+type MockOperationResultsPublisher struct {
+	published dtos.OperationResultsDTO
+	errorReturned error
+}
+
+func (publisher *MockOperationResultsPublisher) PublishOperationResults(
+	ctx context.Context, results dtos.OperationResultsDTO,
+) error {
+	publisher.published = results
+	return publisher.errorReturned
+}
@@ -0,0 +1,80 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/domain"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/ports"
+)
+
+const (
+	DefaultExportSegments = 4
+	// More segments than this only add requests to a table this size.
+	MaxExportSegments = 64
+)
+
+// Scans the movies in segments, clamped from 1 to MaxExportSegments.
+func NewExportMoviesCase(repo ports.MovieSegmentScannerRepository, segments int) *ExportMoviesCase {
+	return &ExportMoviesCase{
+		repo: repo,
+		segments: min(max(segments, 1), MaxExportSegments),
+	}
+}
+
+type ExportMoviesCase struct {
+	repo ports.MovieSegmentScannerRepository
+	segments int
+}
+
+// The segments are scanned in parallel, but emit is called with one movie
+// at a time, in no order. The first error, of a scan or of emit, stops the
+// export.
+func (ucase *ExportMoviesCase) ExportMovies(
+	ctx context.Context, emit func(movie dtos.MovieResponseDTO) error,
+) (exported int, err error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	pages := make(chan []domain.Movie)
+	var scanners sync.WaitGroup
+	for segment := range ucase.segments {
+		scanners.Add(1)
+		go func() {
+			defer scanners.Done()
+			err := ucase.repo.ScanSegment(ctx, segment, ucase.segments, func(movies []domain.Movie) error {
+				select {
+				case pages <- movies:
+					return nil
+				case <-ctx.Done():
+					return context.Cause(ctx)
+				}
+			})
+			if err != nil {
+				cancel(fmt.Errorf("error scanning segment %d of the movies: %w", segment, err))
+			}
+		}()
+	}
+	go func() {
+		scanners.Wait()
+		close(pages)
+	}()
+
+	// The pages are drained even after a failure, for the scanners to see
+	// the cancellation.
+	for page := range pages {
+		for _, movie := range page {
+			if ctx.Err() != nil {
+				break
+			}
+			if err := emit(*dtos.NewMovieResponseDTOFromDomain(movie)); err != nil {
+				cancel(fmt.Errorf("error exporting movie %d: %w", movie.ID, err))
+				break
+			}
+			exported++
+		}
+	}
+	return exported, context.Cause(ctx)
+}
@@ -0,0 +1,102 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/domain"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/usecases"
+)
+
+func TestExportMoviesCase(t *testing.T) {
+	ctx := context.Background()
+	var movies []domain.Movie
+	for id := 1; id <= 25; id++ {
+		movies = append(movies, domain.Movie{ID: id, Title: fmt.Sprintf("movie %d", id), Year: "2001"})
+	}
+
+	t.Run("should emit every movie of every segment once", func(t *testing.T) {
+		repo := &StubMovieSegmentScannerRepository{movies: movies, pageSize: 3}
+		var ids []int
+
+		exported, err := usecases.NewExportMoviesCase(repo, 4).ExportMovies(ctx, func(movie dtos.MovieResponseDTO) error {
+			ids = append(ids, int(movie.ID))
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Error exporting the movies: %v", err)
+		}
+
+		slices.Sort(ids)
+		expected := make([]int, len(movies))
+		for index, movie := range movies {
+			expected[index] = movie.ID
+		}
+		if exported != 25 || !reflect.DeepEqual(expected, ids) {
+			t.Errorf("Exported %d movies %v instead of %v", exported, ids, expected)
+		}
+	})
+
+	t.Run("should stop on the first error of emit", func(t *testing.T) {
+		repo := &StubMovieSegmentScannerRepository{movies: movies, pageSize: 3}
+		diskFull := fmt.Errorf("disk full")
+
+		exported, err := usecases.NewExportMoviesCase(repo, 4).ExportMovies(ctx, func(movie dtos.MovieResponseDTO) error {
+			return diskFull
+		})
+		if !errors.Is(err, diskFull) || exported != 0 {
+			t.Errorf("Exported %d movies with error %v instead of %v", exported, err, diskFull)
+		}
+	})
+
+	t.Run("should stop on the first error of a scan", func(t *testing.T) {
+		throttled := fmt.Errorf("throttled")
+		repo := &StubMovieSegmentScannerRepository{movies: movies, pageSize: 3, failingSegment: 2, errorReturned: throttled}
+
+		_, err := usecases.NewExportMoviesCase(repo, 4).ExportMovies(ctx, func(movie dtos.MovieResponseDTO) error {
+			return nil
+		})
+		if !errors.Is(err, throttled) {
+			t.Errorf("Error %v different from Expected: %v", err, throttled)
+		}
+	})
+}
+
+// Splits the movies among the segments by id, emitting pages of pageSize,
+// and fails the failingSegment, when errorReturned is set.
+type StubMovieSegmentScannerRepository struct {
+	movies []domain.Movie
+	pageSize int
+	failingSegment int
+	errorReturned error
+}
+
+func (repo *StubMovieSegmentScannerRepository) ScanSegment(
+	ctx context.Context, segment, totalSegments int, emit func(movies []domain.Movie) error,
+) error {
+	if repo.errorReturned != nil && segment == repo.failingSegment {
+		return repo.errorReturned
+	}
+	var page []domain.Movie
+	for _, movie := range repo.movies {
+		if movie.ID % totalSegments != segment {
+			continue
+		}
+		page = append(page, movie)
+		if len(page) == repo.pageSize {
+			if err := emit(page); err != nil {
+				return err
+			}
+			page = nil
+		}
+	}
+	if len(page) > 0 {
+		return emit(page)
+	}
+	return nil
+}
@@ -0,0 +1,270 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/domain"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/ports"
+)
+
+type ImportConfig struct {
+	// How many movies each write of the repository carries.
+	BatchSize int
+	// How many writes run at a time.
+	Concurrency int
+	Mode dtos.ImportMode
+	// Only validates the rows, without touching the repository. The valid
+	// rows are counted as imported, as the existing movies are not looked
+	// up.
+	DryRun bool
+	// How many rows from the start of the file to skip, as they are done.
+	ResumeFrom int
+	// Called after each write with the summary so far, without its errors.
+	Progress func(progress dtos.ImportSummaryDTO)
+}
+
+func DefaultImportConfig() ImportConfig {
+	return ImportConfig{
+		BatchSize: 100,
+		Concurrency: 4,
+		Mode: dtos.ImportUpsert,
+	}
+}
+
+func NewImportMoviesCase(repo ports.MovieImporterRepository, config ImportConfig) *ImportMoviesCase {
+	return &ImportMoviesCase{
+		repo: repo,
+		config: config,
+	}
+}
+
+type ImportMoviesCase struct {
+	repo ports.MovieImporterRepository
+	config ImportConfig
+}
+
+// The rows covered by a write, the failed ones included, and the movies
+// of the valid ones with their rows.
+type importBatch struct {
+	sequence int
+	endRow int
+	movies []domain.Movie
+	rows []int
+	lastID dtos.MovieID
+}
+
+// The rows are read as they are imported, so the file is never held in
+// memory. The invalid rows, and those repeating the id of an earlier row,
+// fail alone. The id counter is advanced past the ids of each batch before
+// it is written, so the movies created meanwhile never take an imported id.
+func (ucase *ImportMoviesCase) ImportMovies(ctx context.Context, reader ports.MovieImportReader) (dtos.ImportSummaryDTO, error) {
+	tracker := newImportTracker(ucase.config)
+	batches := make(chan importBatch)
+	var workers sync.WaitGroup
+	for range max(ucase.config.Concurrency, 1) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for batch := range batches {
+				ucase.importBatch(ctx, tracker, batch)
+			}
+		}()
+	}
+
+	err := ucase.readBatches(ctx, reader, tracker, batches)
+	close(batches)
+	workers.Wait()
+	return tracker.result(), err
+}
+
+func (ucase *ImportMoviesCase) readBatches(
+	ctx context.Context, reader ports.MovieImportReader, tracker *importTracker, batches chan<- importBatch,
+) error {
+	batchSize := max(ucase.config.BatchSize, 1)
+	rowsById := make(map[dtos.MovieID]int)
+	var advanced dtos.MovieID
+	var batch importBatch
+
+	send := func(endRow int) error {
+		batch.endRow = endRow
+		if !ucase.config.DryRun && batch.lastID > advanced {
+			if err := ucase.repo.AdvanceIds(ctx, int(batch.lastID)); err != nil {
+				return fmt.Errorf("error advancing the ids to %d: %w", batch.lastID, err)
+			}
+			advanced = batch.lastID
+		}
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("import stopped at row %d: %w", batch.endRow, err)
+		}
+		select {
+		case batches <- batch:
+		case <-ctx.Done():
+			return fmt.Errorf("import stopped at row %d: %w", batch.endRow, ctx.Err())
+		}
+		batch = importBatch{sequence: batch.sequence + 1}
+		return nil
+	}
+
+	for index := 0; ; index++ {
+		row, err := reader.Read()
+		switch {
+		case errors.Is(err, io.EOF):
+			return send(index)
+		case err != nil && !errors.Is(err, ports.ErrMalformedImportRow):
+			return fmt.Errorf("error reading row %d: %w", index + 1, err)
+		}
+		tracker.read()
+		if index < ucase.config.ResumeFrom {
+			continue
+		}
+
+		if err == nil {
+			err = row.Validate()
+		}
+		if first, ok := rowsById[row.ID]; err == nil && ok {
+			err = fmt.Errorf("movie id %d repeated from row %d", row.ID, first)
+		}
+		if err != nil {
+			tracker.fail(index, row.ID, err)
+			continue
+		}
+
+		rowsById[row.ID] = index + 1
+		batch.movies = append(batch.movies, row.ToDomain())
+		batch.rows = append(batch.rows, index)
+		batch.lastID = max(batch.lastID, row.ID)
+		if len(batch.movies) == batchSize {
+			if err := send(index + 1); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (ucase *ImportMoviesCase) importBatch(ctx context.Context, tracker *importTracker, batch importBatch) {
+	if len(batch.movies) == 0 || ucase.config.DryRun {
+		tracker.finish(batch, make([]error, len(batch.movies)), nil)
+		return
+	}
+
+	var existing map[int]bool
+	if ucase.config.Mode == dtos.ImportSkipExisting {
+		ids := make([]int, len(batch.movies))
+		for index, movie := range batch.movies {
+			ids[index] = movie.ID
+		}
+		found, err := ucase.repo.GetMany(ctx, ids)
+		if err != nil {
+			tracker.finish(batch, repeatImportError(len(ids), fmt.Errorf("error looking up the existing movies: %w", err)), nil)
+			return
+		}
+		existing = make(map[int]bool, len(found))
+		for _, movie := range found {
+			existing[movie.ID] = true
+		}
+	}
+
+	var movies []domain.Movie
+	var positions []int
+	for index, movie := range batch.movies {
+		if !existing[movie.ID] {
+			movies = append(movies, movie)
+			positions = append(positions, index)
+		}
+	}
+	errs := make([]error, len(batch.movies))
+	if len(movies) > 0 {
+		for position, err := range ucase.repo.ImportBatch(ctx, movies) {
+			errs[positions[position]] = err
+		}
+	}
+	tracker.finish(batch, errs, existing)
+}
+
+func repeatImportError(count int, err error) []error {
+	errs := make([]error, count)
+	for index := range errs {
+		errs[index] = err
+	}
+	return errs
+}
+
+// Gathers the results of the batches, which finish in any order, moving
+// Done past the batches finished in a row from the start.
+func newImportTracker(config ImportConfig) *importTracker {
+	return &importTracker{
+		summary: dtos.ImportSummaryDTO{Done: config.ResumeFrom},
+		finished: make(map[int]int),
+		progress: config.Progress,
+	}
+}
+
+type importTracker struct {
+	mutex sync.Mutex
+	summary dtos.ImportSummaryDTO
+	// The end rows of the batches finished after one still running.
+	finished map[int]int
+	nextSequence int
+	progress func(progress dtos.ImportSummaryDTO)
+}
+
+func (tracker *importTracker) read() {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	tracker.summary.Total++
+}
+
+func (tracker *importTracker) fail(index int, id dtos.MovieID, err error) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	tracker.failLocked(index, id, err)
+}
+
+func (tracker *importTracker) failLocked(index int, id dtos.MovieID, err error) {
+	tracker.summary.Failed++
+	tracker.summary.Errors = append(tracker.summary.Errors, dtos.ImportRowErrorDTO{Row: index + 1, ID: id, Error: err.Error()})
+}
+
+func (tracker *importTracker) finish(batch importBatch, errs []error, skipped map[int]bool) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	for index, movie := range batch.movies {
+		switch {
+		case skipped[movie.ID]:
+			tracker.summary.Skipped++
+		case errs[index] != nil:
+			tracker.failLocked(batch.rows[index], dtos.MovieID(movie.ID), errs[index])
+		default:
+			tracker.summary.Imported++
+			tracker.summary.LastID = max(tracker.summary.LastID, dtos.MovieID(movie.ID))
+		}
+	}
+
+	tracker.finished[batch.sequence] = batch.endRow
+	for endRow, ok := tracker.finished[tracker.nextSequence]; ok; endRow, ok = tracker.finished[tracker.nextSequence] {
+		delete(tracker.finished, tracker.nextSequence)
+		tracker.summary.Done = max(tracker.summary.Done, endRow)
+		tracker.nextSequence++
+	}
+
+	if tracker.progress != nil {
+		progress := tracker.summary
+		progress.Errors = nil
+		tracker.progress(progress)
+	}
+}
+
+func (tracker *importTracker) result() dtos.ImportSummaryDTO {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	summary := tracker.summary
+	sort.Slice(summary.Errors, func(i, j int) bool { return summary.Errors[i].Row < summary.Errors[j].Row })
+	return summary
+}
@@ -0,0 +1,223 @@
+package usecases_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/domain"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/usecases"
+)
+
+func TestImportMoviesCase(t *testing.T) {
+	ctx := context.Background()
+	rows := []dtos.ImportMovieDTO{
+		{ID: 5, Title: "first", Year: "1990"},
+		{ID: 0, Title: "no id", Year: "1990"},
+		{ID: 9, Title: "second", Year: "2001"},
+		{ID: 5, Title: "repeated", Year: "2001"},
+		{ID: 7, Title: "failed", Year: "2001"},
+		{ID: 3, Title: "old", Year: "1879"},
+		{ID: 2, Title: "third", Year: "1999"},
+	}
+
+	t.Run("should import the valid rows in batches, reporting the others by row", func(t *testing.T) {
+		repo := &MockMovieImporterRepository{failing: map[int]bool{7: true}}
+		var progress []dtos.ImportSummaryDTO
+		config := usecases.ImportConfig{
+			BatchSize: 2,
+			Concurrency: 3,
+			Mode: dtos.ImportUpsert,
+			Progress: func(summary dtos.ImportSummaryDTO) { progress = append(progress, summary) },
+		}
+
+		summary, err := usecases.NewImportMoviesCase(repo, config).ImportMovies(ctx, &StubMovieImportReader{rows: rows})
+		if err != nil {
+			t.Fatalf("Error importing the movies: %v", err)
+		}
+
+		if summary.Total != 7 || summary.Imported != 3 || summary.Failed != 4 || summary.Done != 7 || summary.LastID != 9 {
+			t.Errorf("Unexpected summary: %+v", summary)
+		}
+		if failedRows := rowsOf(summary.Errors); !reflect.DeepEqual([]int{2, 4, 5, 6}, failedRows) {
+			t.Errorf("Failed rows: %v different from Expected: [2 4 5 6]", failedRows)
+		}
+		if !reflect.DeepEqual([]int{9}, repo.advances) {
+			t.Errorf("Ids advanced to %v instead of only forward to [9]", repo.advances)
+		}
+		if len(repo.imported) != 3 || len(repo.batchSizes) != 2 {
+			t.Errorf("Movies imported %+v in batches of %v", repo.imported, repo.batchSizes)
+		}
+		if last := progress[len(progress) - 1]; len(progress) != 3 || last.Done != 7 || last.Errors != nil {
+			t.Errorf("Unexpected progress reported: %+v", progress)
+		}
+	})
+
+	t.Run("should skip the existing movies in skip-existing mode", func(t *testing.T) {
+		repo := &MockMovieImporterRepository{existing: map[int]bool{9: true}}
+		config := usecases.DefaultImportConfig()
+		config.Mode = dtos.ImportSkipExisting
+
+		summary, err := usecases.NewImportMoviesCase(repo, config).ImportMovies(ctx, &StubMovieImportReader{rows: rows})
+		if err != nil {
+			t.Fatalf("Error importing the movies: %v", err)
+		}
+		if summary.Imported != 3 || summary.Skipped != 1 {
+			t.Errorf("Unexpected summary: %+v", summary)
+		}
+		for _, movie := range repo.imported {
+			if movie.ID == 9 {
+				t.Errorf("Existing movie %+v overwritten", movie)
+			}
+		}
+	})
+
+	t.Run("should only validate the rows on a dry run", func(t *testing.T) {
+		repo := &MockMovieImporterRepository{}
+		config := usecases.DefaultImportConfig()
+		config.DryRun = true
+
+		summary, err := usecases.NewImportMoviesCase(repo, config).ImportMovies(ctx, &StubMovieImportReader{rows: rows})
+		if err != nil {
+			t.Fatalf("Error validating the movies: %v", err)
+		}
+		if summary.Imported != 4 || summary.Failed != 3 || len(repo.imported) != 0 || repo.advances != nil {
+			t.Errorf("Dry run touched the repository or miscounted: %+v", summary)
+		}
+	})
+
+	t.Run("should resume after the rows done, failing the malformed rows alone", func(t *testing.T) {
+		repo := &MockMovieImporterRepository{}
+		config := usecases.DefaultImportConfig()
+		config.ResumeFrom = 4
+		reader := &StubMovieImportReader{rows: rows, malformed: map[int]bool{5: true}}
+
+		summary, err := usecases.NewImportMoviesCase(repo, config).ImportMovies(ctx, reader)
+		if err != nil {
+			t.Fatalf("Error importing the movies: %v", err)
+		}
+		if summary.Total != 7 || summary.Imported != 2 || summary.Failed != 1 || summary.Done != 7 {
+			t.Errorf("Unexpected summary: %+v", summary)
+		}
+		if failedRows := rowsOf(summary.Errors); !reflect.DeepEqual([]int{6}, failedRows) {
+			t.Errorf("Failed rows: %v different from Expected: [6]", failedRows)
+		}
+	})
+
+	t.Run("should stop on read errors, keeping the rows done", func(t *testing.T) {
+		repo := &MockMovieImporterRepository{}
+		config := usecases.DefaultImportConfig()
+		config.BatchSize = 1
+		config.Concurrency = 1
+		reader := &StubMovieImportReader{rows: rows, errorAt: 3}
+
+		summary, err := usecases.NewImportMoviesCase(repo, config).ImportMovies(ctx, reader)
+		if err == nil {
+			t.Fatalf("No error returned when the file could not be read")
+		}
+		if summary.Done != 3 {
+			t.Errorf("Rows done %d instead of 3", summary.Done)
+		}
+	})
+
+	t.Run("should write nothing when the ids could not be advanced", func(t *testing.T) {
+		repo := &MockMovieImporterRepository{errorReturned: fmt.Errorf("throttled")}
+
+		_, err := usecases.NewImportMoviesCase(repo, usecases.DefaultImportConfig()).ImportMovies(ctx, &StubMovieImportReader{rows: rows})
+		if err == nil {
+			t.Errorf("No error returned when the ids were not advanced")
+		}
+		if len(repo.imported) != 0 {
+			t.Errorf("Movies imported without advancing the ids: %+v", repo.imported)
+		}
+	})
+
+	t.Run("should stop sending batches once the context is done", func(t *testing.T) {
+		repo := &MockMovieImporterRepository{}
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := usecases.NewImportMoviesCase(repo, usecases.DefaultImportConfig()).ImportMovies(ctx, &StubMovieImportReader{rows: rows})
+		if err == nil {
+			t.Errorf("No error returned when the import was stopped")
+		}
+	})
+}
+
+func rowsOf(errors []dtos.ImportRowErrorDTO) []int {
+	rows := make([]int, len(errors))
+	for index, rowError := range errors {
+		rows[index] = rowError.Row
+	}
+	return rows
+}
+
+// Reads the rows, failing the malformed ones alone and every read from
+// errorAt on, when set.
+type StubMovieImportReader struct {
+	rows []dtos.ImportMovieDTO
+	malformed map[int]bool
+	errorAt int
+	read int
+}
+
+func (reader *StubMovieImportReader) Read() (dtos.ImportMovieDTO, error) {
+	index := reader.read
+	reader.read++
+	switch {
+	case reader.errorAt > 0 && index >= reader.errorAt:
+		return dtos.ImportMovieDTO{}, fmt.Errorf("disk unplugged")
+	case index >= len(reader.rows):
+		return dtos.ImportMovieDTO{}, io.EOF
+	case reader.malformed[index]:
+		return dtos.ImportMovieDTO{}, fmt.Errorf("%w: bad row", ports.ErrMalformedImportRow)
+	}
+	return reader.rows[index], nil
+}
+
+// Imports every movie but the failing ones, recording the batches, and
+// finds the existing ones.
+type MockMovieImporterRepository struct {
+	mutex sync.Mutex
+	imported []domain.Movie
+	batchSizes []int
+	failing map[int]bool
+	existing map[int]bool
+	advances []int
+	errorReturned error
+}
+
+func (repo *MockMovieImporterRepository) ImportBatch(ctx context.Context, movies []domain.Movie) []error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	repo.batchSizes = append(repo.batchSizes, len(movies))
+	errs := make([]error, len(movies))
+	for index, movie := range movies {
+		if repo.failing[movie.ID] {
+			errs[index] = fmt.Errorf("batch write unprocessed")
+			continue
+		}
+		repo.imported = append(repo.imported, movie)
+	}
+	return errs
+}
+
+func (repo *MockMovieImporterRepository) GetMany(ctx context.Context, ids []int) ([]domain.Movie, error) {
+	var found []domain.Movie
+	for _, id := range ids {
+		if repo.existing[id] {
+			found = append(found, domain.Movie{ID: id})
+		}
+	}
+	return found, nil
+}
+
+func (repo *MockMovieImporterRepository) AdvanceIds(ctx context.Context, lastId int) error {
+	repo.advances = append(repo.advances, lastId)
+	return repo.errorReturned
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"time"
+
+	"github.com/google/uuid"
 	
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/ports"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/dtos"
@@ -58,6 +61,68 @@ func (ucase *GetMoviesCase) GetMovies(
 	return
 }
 
+const (
+	// The most distinct ids fetched at once, as many as DynamoDB gets in
+	// a single BatchGetItem.
+	MaxBatchGetMovies = 100
+)
+
+func NewBatchGetMoviesCase(repo ports.MovieManyGetterRepository) *BatchGetMoviesCase {
+	return &BatchGetMoviesCase{
+		repo: repo,
+	}
+}
+
+type BatchGetMoviesCase struct {
+	repo ports.MovieManyGetterRepository
+}
+
+// Returns the movies found, in the order of the ids, and the ids missing,
+// each once. The ids that are not positive are missing without being
+// looked up.
+func (ucase *BatchGetMoviesCase) BatchGetMovies(
+	ctx context.Context, ids []dtos.MovieID,
+) (movies *[]dtos.MovieResponseDTO, missing []dtos.MovieID, err error) {
+	seen := make(map[dtos.MovieID]bool, len(ids))
+	var wanted []int
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if id > 0 {
+			wanted = append(wanted, int(id))
+		}
+	}
+	if len(seen) > MaxBatchGetMovies {
+		err = fmt.Errorf("%w: %d ids, at most %d", ports.ErrTooManyMovieIds, len(seen), MaxBatchGetMovies)
+		return
+	}
+
+	var fetchedMovies []domain.Movie
+	if len(wanted) > 0 {
+		if fetchedMovies, err = ucase.repo.GetMany(ctx, wanted); err != nil {
+			err = fmt.Errorf("error getting movies %w", err)
+			return
+		}
+	}
+
+	found := make(map[dtos.MovieID]bool, len(fetchedMovies))
+	for _, movie := range fetchedMovies {
+		found[dtos.MovieID(movie.ID)] = true
+	}
+	missing = []dtos.MovieID{}
+	for _, id := range ids {
+		if !found[id] && seen[id] {
+			missing = append(missing, id)
+			delete(seen, id)
+		}
+	}
+
+	movies = dtos.MoviesToResponseDTOs(fetchedMovies)
+	return
+}
+
 func NewSaveMovieCase(repo ports.MovieSaverRepository) *SaveMovieCase {
 	return &SaveMovieCase{
 		repo: repo,
@@ -68,11 +133,31 @@ type SaveMovieCase struct {
 	repo ports.MovieSaverRepository
 }
 
-func (ucase *SaveMovieCase) SaveMovie(ctx context.Context, movie dtos.CreateMovieDTO) error {
-	if err := ucase.repo.Save(ctx, movie.ToDomain()); err != nil {
-		return fmt.Errorf("error saving movie %w", err)
+func (ucase *SaveMovieCase) SaveMovie(ctx context.Context, movie dtos.CreateMovieDTO) (*dtos.MovieResponseDTO, error) {
+	saved, err := ucase.repo.Save(ctx, movie.ToDomain(), newMovieEvent(ctx, domain.MovieCreated))
+	if err != nil {
+		return nil, fmt.Errorf("error saving movie %w", err)
+	}
+	return dtos.NewMovieResponseDTOFromDomain(saved), nil
+}
+
+func NewUpdateMovieCase(repo ports.MovieUpdaterRepository) *UpdateMovieCase {
+	return &UpdateMovieCase{
+		repo: repo,
 	}
-	return nil
+}
+
+type UpdateMovieCase struct {
+	repo ports.MovieUpdaterRepository
+}
+
+func (ucase *UpdateMovieCase) UpdateMovie(ctx context.Context, movie dtos.UpdateMovieDTO) (*dtos.MovieResponseDTO, error) {
+	event := newMovieEvent(ctx, domain.MovieUpdated)
+	updated, err := ucase.repo.Update(ctx, movie.ToDomain(), movie.Version, event)
+	if err != nil {
+		return nil, fmt.Errorf("error updating movie %w", err)
+	}
+	return dtos.NewMovieResponseDTOFromDomain(updated), nil
 }
 
 func NewDeleteMovieCase(repo ports.MovieDeleterRepository) *DeleteMovieCase {
@@ -85,10 +170,29 @@ type DeleteMovieCase struct {
 	repo ports.MovieDeleterRepository
 }
 
-func (ucase *DeleteMovieCase) DeleteMovie(ctx context.Context, id dtos.MovieID) error {
-	if err := ucase.repo.Delete(ctx, int(id)); err != nil {
-		return fmt.Errorf("error deleting movie %w", err)
+// Deleting a movie that does not exist does nothing, so nothing is
+// announced, and no movie is returned.
+func (ucase *DeleteMovieCase) DeleteMovie(ctx context.Context, id dtos.MovieID, expectedVersion int) (*dtos.MovieResponseDTO, error) {
+	event := newMovieEvent(ctx, domain.MovieDeleted)
+	deleted, err := ucase.repo.Delete(ctx, int(id), expectedVersion, event)
+	if err != nil {
+		return nil, fmt.Errorf("error deleting movie %w", err)
+	}
+	if deleted.ID == 0 {
+		return nil, nil
 	}
-	return nil
+	return dtos.NewMovieResponseDTOFromDomain(deleted), nil
 }
 
+// The event of a write, without its movie, which the repository sets to
+// the movie the write left when recording it in the outbox.
+func newMovieEvent(ctx context.Context, eventType domain.MovieEventType) domain.MovieEvent {
+	cause := domain.EventCauseFrom(ctx)
+	return domain.MovieEvent{
+		ID: uuid.New().String(),
+		Type: eventType,
+		OccurredAt: time.Now().UnixMilli(),
+		CorrelationID: cause.CorrelationID,
+		CausationID: cause.CausationID,
+	}
+}
@@ -2,6 +2,7 @@ package usecases_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
@@ -33,6 +34,8 @@ func TestGetMovieCase(t *testing.T) {
 				ID: dtos.MovieID(movie.ID),
 				Title: movie.Title,
 				Year: movie.Year,
+				Version: movie.Version,
+				UpdatedAt: movie.UpdatedAt,
 			}
 
 			if !reflect.DeepEqual(expected, *result) {
@@ -132,7 +135,9 @@ func TestGetMoviesCase(t *testing.T) {
 				expected[index] = dtos.MovieResponseDTO{
 					ID: dtos.MovieID(movie.ID),
 					Title: movie.Title,
-					Year: movie.Year,	
+					Year: movie.Year,
+					Version: movie.Version,
+					UpdatedAt: movie.UpdatedAt,
 				}
 			}
 
@@ -188,13 +193,78 @@ func (repo *StubMovieAllGetter) GetAll(
 }
 
 
+func TestBatchGetMoviesCase(t *testing.T) {
+	t.Run("should return the movies found and the ids missing, each once", func (t *testing.T) {
+		repo := &StubMovieManyGetter{
+			moviesReturned: []domain.Movie{{ID: 3, Title: "third", Year: "1995", Version: 2}, {ID: 1, Title: "first", Year: "2001"}},
+		}
+		ucase := usecases.NewBatchGetMoviesCase(repo)
+
+		movies, missing, err := ucase.BatchGetMovies(context.Background(), []dtos.MovieID{3, 7, 1, 3, 0, 7})
+		if err != nil {
+			t.Fatalf("Error found when getting movies %v", err)
+		}
+
+		if !reflect.DeepEqual([]int{3, 7, 1}, repo.idsPassed) {
+			t.Errorf("Ids passed: %v different from Expected: [3 7 1]", repo.idsPassed)
+		}
+		if len(*movies) != 2 || (*movies)[0].ID != 3 || (*movies)[1].ID != 1 {
+			t.Errorf("Unexpected movies returned: %+v", *movies)
+		}
+		if !reflect.DeepEqual([]dtos.MovieID{7, 0}, missing) {
+			t.Errorf("Missing ids: %v different from Expected: [7 0]", missing)
+		}
+	})
+
+	t.Run("should fail with ErrTooManyMovieIds without querying the repository", func (t *testing.T) {
+		ids := make([]dtos.MovieID, usecases.MaxBatchGetMovies + 1)
+		for index := range ids {
+			ids[index] = dtos.MovieID(index + 1)
+		}
+		repo := &StubMovieManyGetter{}
+
+		_, _, err := usecases.NewBatchGetMoviesCase(repo).BatchGetMovies(context.Background(), ids)
+		if !errors.Is(err, ports.ErrTooManyMovieIds) {
+			t.Errorf("Error %v is not ErrTooManyMovieIds", err)
+		}
+		if repo.idsPassed != nil {
+			t.Errorf("Repository queried with %v", repo.idsPassed)
+		}
+	})
+
+	t.Run("should return custom error when receiving an error from the repository", func (t *testing.T) {
+		assertion := func(errorMessage string) bool {
+			err := fmt.Errorf("random error: %s", errorMessage)
+			ucase := usecases.NewBatchGetMoviesCase(&StubMovieManyGetter{errorReturned: err})
+
+			_, _, receivedErr := ucase.BatchGetMovies(context.Background(), []dtos.MovieID{1})
+			return receivedErr != nil && receivedErr != err && errors.Is(receivedErr, err)
+		}
+		if err := quick.Check(assertion, nil); err != nil {
+			t.Errorf("Failed assertion: %v", err)
+		}
+	})
+}
+
+
+type StubMovieManyGetter struct {
+	idsPassed []int
+	moviesReturned []domain.Movie
+	errorReturned error
+}
+
+func (repo *StubMovieManyGetter) GetMany(ctx context.Context, ids []int) ([]domain.Movie, error) {
+	repo.idsPassed = ids
+	return repo.moviesReturned, repo.errorReturned
+}
+
 func TestSaveMovieCase(t *testing.T) {
 	t.Run("should pass domain.Movie to repository", func (t *testing.T) {
 		assertion := func(movie dtos.CreateMovieDTO) bool {
 			repo := &MockMovieSaver{}
 			ucase := usecases.NewSaveMovieCase(repo)
 
-			if err := ucase.SaveMovie(context.Background(), movie); err != nil {
+			if _, err := ucase.SaveMovie(context.Background(), movie); err != nil {
 				t.Logf("Error found when saving movie %v", err)
 				return false
 			}
@@ -218,6 +288,20 @@ func TestSaveMovieCase(t *testing.T) {
 		}
 	})
 
+	t.Run("should return the movie saved by the repository", func (t *testing.T) {
+		saved := domain.Movie{ID: 42, Title: "Fargo", Year: "1996", Version: 1, UpdatedAt: 1700000000000}
+		ucase := usecases.NewSaveMovieCase(&MockMovieSaver{movieReturned: saved})
+
+		movie, err := ucase.SaveMovie(context.Background(), dtos.CreateMovieDTO{Title: "Fargo", Year: "1996"})
+		if err != nil {
+			t.Fatalf("Error found when saving movie %v", err)
+		}
+		expected := dtos.MovieResponseDTO{ID: 42, Title: "Fargo", Year: "1996", Version: 1, UpdatedAt: 1700000000000}
+		if movie == nil || *movie != expected {
+			t.Errorf("Movie returned: %+v different from Expected: %+v", movie, expected)
+		}
+	})
+
 	t.Run("should return custom error when receiving an error from the repository", func (t *testing.T) {
 		assertion := func(errorMessage string, movie dtos.CreateMovieDTO) bool {
 			err := fmt.Errorf("random error: %s", errorMessage)
@@ -226,7 +310,7 @@ func TestSaveMovieCase(t *testing.T) {
 			}
 			ucase := usecases.NewSaveMovieCase(repo)
 
-			receivedErr := ucase.SaveMovie(context.Background(), movie)
+			_, receivedErr := ucase.SaveMovie(context.Background(), movie)
 			if receivedErr == nil {
 				t.Logf("No error return when getting not existent movie.")
 				return false
@@ -247,24 +331,27 @@ func TestSaveMovieCase(t *testing.T) {
 
 
 type MockMovieSaver struct {
+	eventPassed domain.MovieEvent
 	moviePassed domain.Movie
+	movieReturned domain.Movie
 	errorReturned error
 }
 
-func (repo *MockMovieSaver) Save(ctx context.Context, movie domain.Movie) error {
+func (repo *MockMovieSaver) Save(ctx context.Context, movie domain.Movie, event domain.MovieEvent) (domain.Movie, error) {
+	repo.eventPassed = event
 	repo.moviePassed = movie
-	return repo.errorReturned
+	return repo.movieReturned, repo.errorReturned
 }
 
 
 
 func TestDeleteMovieCase(t *testing.T) {
-	t.Run("should pass the id to the repository", func (t *testing.T) {
-		assertion := func(id dtos.MovieID) bool {
+	t.Run("should pass the id and the expected version to the repository", func (t *testing.T) {
+		assertion := func(id dtos.MovieID, version int) bool {
 			repo := &MockMovieDeleter{}
 			ucase := usecases.NewDeleteMovieCase(repo)
 
-			if err := ucase.DeleteMovie(context.Background(), id); err != nil {
+			if _, err := ucase.DeleteMovie(context.Background(), id, version); err != nil {
 				t.Logf("Error found when deleting movie %v", err)
 				return false
 			}
@@ -278,6 +365,11 @@ func TestDeleteMovieCase(t *testing.T) {
 				return false
 			}
 
+			if version != repo.versionPassed {
+				t.Logf("Version passed: %d different from Expected: %d", repo.versionPassed, version)
+				return false
+			}
+
 			return true
 		}
 		if err := quick.Check(assertion, nil); err != nil {
@@ -293,7 +385,7 @@ func TestDeleteMovieCase(t *testing.T) {
 			}
 			ucase := usecases.NewDeleteMovieCase(repo)
 
-			receivedErr := ucase.DeleteMovie(context.Background(), id)
+			_, receivedErr := ucase.DeleteMovie(context.Background(), id, 0)
 			if receivedErr == nil {
 				t.Logf("No error return when getting not existent movie.")
 				return false
@@ -310,18 +402,159 @@ func TestDeleteMovieCase(t *testing.T) {
 			t.Errorf("Failed assertion: %v", err)
 		}
 	})
+
+	t.Run("should return the deleted movie, or nil when there was none", func (t *testing.T) {
+		deleted := domain.Movie{ID: 7, Title: "Heat", Year: "1995", Version: 3}
+		movie, err := usecases.NewDeleteMovieCase(&MockMovieDeleter{movieReturned: deleted}).DeleteMovie(context.Background(), 7, 0)
+		if err != nil {
+			t.Fatalf("Error found when deleting movie %v", err)
+		}
+		if movie == nil || movie.ID != 7 || movie.Version != 3 {
+			t.Errorf("Movie returned: %+v different from Expected: %+v", movie, deleted)
+		}
+
+		movie, err = usecases.NewDeleteMovieCase(&MockMovieDeleter{}).DeleteMovie(context.Background(), 7, 0)
+		if err != nil || movie != nil {
+			t.Errorf("Expected no movie nor error for a missing movie, found %+v and %v", movie, err)
+		}
+	})
+
+	t.Run("should keep ports.ErrVersionMismatch in the chain", func (t *testing.T) {
+		repo := &MockMovieDeleter{errorReturned: ports.ErrVersionMismatch}
+		ucase := usecases.NewDeleteMovieCase(repo)
+
+		_, err := ucase.DeleteMovie(context.Background(), 1, 2)
+		if !errors.Is(err, ports.ErrVersionMismatch) {
+			t.Errorf("Expected ErrVersionMismatch in the chain, found %v", err)
+		}
+	})
 }
 
 
 type MockMovieDeleter struct {
+	eventPassed domain.MovieEvent
 	idPassed int
+	versionPassed int
+	movieReturned domain.Movie
 	errorReturned error
 }
 
-func (repo *MockMovieDeleter) Delete(ctx context.Context, id int) error {
+func (repo *MockMovieDeleter) Delete(ctx context.Context, id int, expectedVersion int, event domain.MovieEvent) (domain.Movie, error) {
+	repo.eventPassed = event
 	repo.idPassed = id
-	return repo.errorReturned
+	repo.versionPassed = expectedVersion
+	return repo.movieReturned, repo.errorReturned
 }
 
 
+func TestUpdateMovieCase(t *testing.T) {
+	t.Run("should pass the movie and the expected version to the repository", func (t *testing.T) {
+		assertion := func(movie dtos.UpdateMovieDTO) bool {
+			repo := &MockMovieUpdater{}
+			ucase := usecases.NewUpdateMovieCase(repo)
 
+			if _, err := ucase.UpdateMovie(context.Background(), movie); err != nil {
+				t.Logf("Error found when updating movie %v", err)
+				return false
+			}
+
+			expected := domain.Movie{
+				ID: int(movie.ID),
+				Title: movie.Title,
+				Year: movie.Year,
+			}
+
+			if !reflect.DeepEqual(expected, repo.moviePassed) {
+				t.Logf("Movie passed: %+v different from Expected: %+v", repo.moviePassed, expected)
+				return false
+			}
+
+			if movie.Version != repo.versionPassed {
+				t.Logf("Version passed: %d different from Expected: %d", repo.versionPassed, movie.Version)
+				return false
+			}
+
+			return true
+		}
+		if err := quick.Check(assertion, nil); err != nil {
+			t.Errorf("Failed assertion: %v", err)
+		}
+	})
+
+	t.Run("should keep the repository errors in the chain", func (t *testing.T) {
+		for _, expected := range []error{ports.ErrMovieNotFound, ports.ErrVersionMismatch} {
+			repo := &MockMovieUpdater{errorReturned: expected}
+			ucase := usecases.NewUpdateMovieCase(repo)
+
+			_, err := ucase.UpdateMovie(context.Background(), dtos.UpdateMovieDTO{ID: 1, Version: 2})
+			if !errors.Is(err, expected) {
+				t.Errorf("Expected %v in the chain, found %v", expected, err)
+			}
+		}
+	})
+}
+
+
+type MockMovieUpdater struct {
+	eventPassed domain.MovieEvent
+	moviePassed domain.Movie
+	versionPassed int
+	movieReturned domain.Movie
+	errorReturned error
+}
+
+func (repo *MockMovieUpdater) Update(ctx context.Context, movie domain.Movie, expectedVersion int, event domain.MovieEvent) (domain.Movie, error) {
+	repo.eventPassed = event
+	repo.moviePassed = movie
+	repo.versionPassed = expectedVersion
+	return repo.movieReturned, repo.errorReturned
+}
+
+
+
+
+
+func TestMovieEvents(t *testing.T) {
+	t.Run("should record an event of each write", func (t *testing.T) {
+		assertion := func(createDto dtos.CreateMovieDTO, updateDto dtos.UpdateMovieDTO, id dtos.MovieID) bool {
+			saver, updater, deleter := &MockMovieSaver{}, &MockMovieUpdater{}, &MockMovieDeleter{}
+			ctx := context.Background()
+
+			usecases.NewSaveMovieCase(saver).SaveMovie(ctx, createDto)
+			usecases.NewUpdateMovieCase(updater).UpdateMovie(ctx, updateDto)
+			usecases.NewDeleteMovieCase(deleter).DeleteMovie(ctx, id, 0)
+
+			events := []domain.MovieEvent{saver.eventPassed, updater.eventPassed, deleter.eventPassed}
+			expected := []domain.MovieEventType{domain.MovieCreated, domain.MovieUpdated, domain.MovieDeleted}
+			for index, event := range events {
+				if event.Type != expected[index] {
+					t.Logf("Event %+v should have type %s", event, expected[index])
+					return false
+				}
+				if event.ID == "" || event.OccurredAt == 0 {
+					t.Logf("Event %+v has no id or timestamp", event)
+					return false
+				}
+			}
+			return events[0].ID != events[1].ID && events[1].ID != events[2].ID
+		}
+		if err := quick.Check(assertion, nil); err != nil {
+			t.Errorf("Failed assertion: %v", err)
+		}
+	})
+
+	t.Run("should carry the cause set in the context", func (t *testing.T) {
+		assertion := func(cause domain.EventCause) bool {
+			repo := &MockMovieSaver{}
+			ctx := domain.WithEventCause(context.Background(), cause)
+
+			usecases.NewSaveMovieCase(repo).SaveMovie(ctx, dtos.CreateMovieDTO{})
+
+			event := repo.eventPassed
+			return event.CorrelationID == cause.CorrelationID && event.CausationID == cause.CausationID
+		}
+		if err := quick.Check(assertion, nil); err != nil {
+			t.Errorf("Failed assertion: %v", err)
+		}
+	})
+}
@@ -0,0 +1,46 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/ports"
+)
+
+func NewRelayMovieEventsCase(outbox ports.MovieEventOutbox, publisher ports.MovieEventPublisher) *RelayMovieEventsCase {
+	return &RelayMovieEventsCase{
+		outbox: outbox,
+		publisher: publisher,
+	}
+}
+
+type RelayMovieEventsCase struct {
+	outbox ports.MovieEventOutbox
+	publisher ports.MovieEventPublisher
+}
+
+// Publishes up to limit pending events, in the order they were recorded,
+// marking them sent, and returns how many were relayed.
+// It stops at the first event that fails to be published, so the events
+// of a movie are never published out of order. An event published but
+// not marked sent is published again later, so the consumers must ignore
+// the event ids they already handled.
+func (ucase *RelayMovieEventsCase) RelayMovieEvents(ctx context.Context, limit int) (int, error) {
+	events, err := ucase.outbox.GetPendingEvents(ctx, limit)
+	if err != nil {
+		return 0, fmt.Errorf("error getting pending events: %w", err)
+	}
+
+	for index, event := range events {
+		if err := ucase.publisher.PublishMovieEvent(ctx, event); err != nil {
+			if markErr := ucase.outbox.MarkEventFailed(ctx, event.ID, err); markErr != nil {
+				err = fmt.Errorf("%w (and failed recording it: %v)", err, markErr)
+			}
+			return index, fmt.Errorf("error publishing event %s: %w", event.ID, err)
+		}
+		if err := ucase.outbox.MarkEventSent(ctx, event.ID); err != nil {
+			return index, fmt.Errorf("error marking event %s as sent: %w", event.ID, err)
+		}
+	}
+	return len(events), nil
+}
@@ -0,0 +1,120 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"testing/quick"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/domain"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/usecases"
+)
+
+func TestRelayMovieEvents(t *testing.T) {
+	t.Run("should publish the pending events in order and mark them sent", func (t *testing.T) {
+		assertion := func(events []domain.MovieEvent) bool {
+			outbox := &FakeMovieEventOutbox{pending: events}
+			publisher := &MockMovieEventPublisher{}
+
+			relayed, err := usecases.NewRelayMovieEventsCase(outbox, publisher).RelayMovieEvents(context.Background(), len(events))
+			if err != nil || relayed != len(events) {
+				t.Logf("Relayed %d events with error %v, expected %d", relayed, err, len(events))
+				return false
+			}
+			if len(events) == 0 {
+				return len(publisher.eventsPassed) == 0
+			}
+			return reflect.DeepEqual(events, publisher.eventsPassed) && len(outbox.pending) == 0
+		}
+		if err := quick.Check(assertion, nil); err != nil {
+			t.Errorf("Failed assertion: %v", err)
+		}
+	})
+
+	t.Run("should relay at most the limit", func (t *testing.T) {
+		outbox := &FakeMovieEventOutbox{pending: []domain.MovieEvent{{ID: "1"}, {ID: "2"}, {ID: "3"}}}
+		publisher := &MockMovieEventPublisher{}
+
+		relayed, err := usecases.NewRelayMovieEventsCase(outbox, publisher).RelayMovieEvents(context.Background(), 2)
+		if err != nil || relayed != 2 {
+			t.Errorf("Relayed %d events with error %v, expected 2", relayed, err)
+		}
+		if len(outbox.pending) != 1 || outbox.pending[0].ID != "3" {
+			t.Errorf("Only the last event should be pending, found %+v", outbox.pending)
+		}
+	})
+
+	t.Run("should stop at the first failure, keeping it and the next events pending", func (t *testing.T) {
+		expected := fmt.Errorf("random error")
+		outbox := &FakeMovieEventOutbox{pending: []domain.MovieEvent{{ID: "1"}, {ID: "2"}, {ID: "3"}}}
+		publisher := &MockMovieEventPublisher{failOn: "2", errorReturned: expected}
+
+		relayed, err := usecases.NewRelayMovieEventsCase(outbox, publisher).RelayMovieEvents(context.Background(), 3)
+		if !errors.Is(err, expected) || relayed != 1 {
+			t.Errorf("Relayed %d events with error %v, expected 1 and %v", relayed, err, expected)
+		}
+		if len(publisher.eventsPassed) != 1 {
+			t.Errorf("Events published after the failure: %+v", publisher.eventsPassed)
+		}
+		if len(outbox.pending) != 2 || outbox.failures["2"] != 1 {
+			t.Errorf("The failed event should be pending with its failure recorded: %+v %+v", outbox.pending, outbox.failures)
+		}
+	})
+
+	t.Run("should fail when the pending events can't be fetched", func (t *testing.T) {
+		expected := fmt.Errorf("random error")
+		outbox := &FakeMovieEventOutbox{errorReturned: expected}
+
+		if _, err := usecases.NewRelayMovieEventsCase(outbox, &MockMovieEventPublisher{}).RelayMovieEvents(context.Background(), 10); !errors.Is(err, expected) {
+			t.Errorf("Expected %v in the chain, found %v", expected, err)
+		}
+	})
+}
+
+
+type FakeMovieEventOutbox struct {
+	pending []domain.MovieEvent
+	failures map[string]int
+	errorReturned error
+}
+
+func (outbox *FakeMovieEventOutbox) GetPendingEvents(ctx context.Context, limit int) ([]domain.MovieEvent, error) {
+	if outbox.errorReturned != nil {
+		return nil, outbox.errorReturned
+	}
+	return outbox.pending[:min(limit, len(outbox.pending))], nil
+}
+
+func (outbox *FakeMovieEventOutbox) MarkEventSent(ctx context.Context, eventID string) error {
+	for index, event := range outbox.pending {
+		if event.ID == eventID {
+			outbox.pending = append(outbox.pending[:index:index], outbox.pending[index+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("event %s is not pending", eventID)
+}
+
+func (outbox *FakeMovieEventOutbox) MarkEventFailed(ctx context.Context, eventID string, cause error) error {
+	if outbox.failures == nil {
+		outbox.failures = map[string]int{}
+	}
+	outbox.failures[eventID]++
+	return nil
+}
+
+type MockMovieEventPublisher struct {
+	eventsPassed []domain.MovieEvent
+	failOn string
+	errorReturned error
+}
+
+func (publisher *MockMovieEventPublisher) PublishMovieEvent(ctx context.Context, event domain.MovieEvent) error {
+	if publisher.errorReturned != nil && event.ID == publisher.failOn {
+		return publisher.errorReturned
+	}
+	publisher.eventsPassed = append(publisher.eventsPassed, event)
+	return nil
+}
@@ -0,0 +1,55 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/domain"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/ports"
+)
+
+// Stops the pages once the limit is reached.
+var errStreamLimitReached = errors.New("stream limit reached")
+
+func NewStreamMoviesCase(repo ports.MovieStreamerRepository) *StreamMoviesCase {
+	return &StreamMoviesCase{
+		repo: repo,
+	}
+}
+
+type StreamMoviesCase struct {
+	repo ports.MovieStreamerRepository
+}
+
+// Calls emit with each movie of the query, in the order of the pages, the
+// next page only fetched once emit returned for every movie of the
+// previous one, so a slow emit holds the reads back. The first error of
+// emit stops the stream.
+func (ucase *StreamMoviesCase) StreamMovies(
+	ctx context.Context, query dtos.StreamMoviesDTO, emit func(movie dtos.MovieResponseDTO) error,
+) (streamed int, err error) {
+	err = ucase.repo.StreamAll(ctx, query.Year, query.Cursor, func(movies []domain.Movie) error {
+		for _, movie := range movies {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := emit(*dtos.NewMovieResponseDTOFromDomain(movie)); err != nil {
+				return fmt.Errorf("error streaming movie %d: %w", movie.ID, err)
+			}
+			streamed++
+			if query.Limit > 0 && streamed >= query.Limit {
+				return errStreamLimitReached
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errStreamLimitReached) {
+		return streamed, nil
+	}
+	if err != nil {
+		return streamed, fmt.Errorf("error streaming movies: %w", err)
+	}
+	return streamed, nil
+}
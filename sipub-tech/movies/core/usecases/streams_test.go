@@ -0,0 +1,116 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/domain"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/usecases"
+)
+
+func TestStreamMoviesCase(t *testing.T) {
+	ctx := context.Background()
+	var movies []domain.Movie
+	for id := 1; id <= 10; id++ {
+		movies = append(movies, domain.Movie{ID: id, Title: fmt.Sprintf("movie %d", id), Year: fmt.Sprint(2000 + id % 2)})
+	}
+
+	t.Run("should emit the movies of the year after the cursor, page by page", func(t *testing.T) {
+		repo := &StubMovieStreamerRepository{movies: movies, pageSize: 2}
+		var ids []int
+
+		streamed, err := usecases.NewStreamMoviesCase(repo).StreamMovies(
+			ctx, dtos.StreamMoviesDTO{Year: "2001", Cursor: 3}, func(movie dtos.MovieResponseDTO) error {
+				ids = append(ids, int(movie.ID))
+				return nil
+			},
+		)
+		if err != nil {
+			t.Fatalf("Error streaming the movies: %v", err)
+		}
+		if expected := []int{5, 7, 9}; streamed != 3 || !reflect.DeepEqual(expected, ids) {
+			t.Errorf("Streamed %d movies %v instead of %v", streamed, ids, expected)
+		}
+		if repo.pages != 2 {
+			t.Errorf("Fetched %d pages instead of 2", repo.pages)
+		}
+	})
+
+	t.Run("should stop fetching pages once the limit is reached", func(t *testing.T) {
+		repo := &StubMovieStreamerRepository{movies: movies, pageSize: 2}
+
+		streamed, err := usecases.NewStreamMoviesCase(repo).StreamMovies(
+			ctx, dtos.StreamMoviesDTO{Limit: 3}, func(movie dtos.MovieResponseDTO) error { return nil },
+		)
+		if err != nil || streamed != 3 {
+			t.Errorf("Streamed %d movies with error %v instead of 3", streamed, err)
+		}
+		if repo.pages != 2 {
+			t.Errorf("Fetched %d pages instead of 2", repo.pages)
+		}
+	})
+
+	t.Run("should stop on the first error of emit", func(t *testing.T) {
+		repo := &StubMovieStreamerRepository{movies: movies, pageSize: 2}
+		canceled := fmt.Errorf("client went away")
+
+		streamed, err := usecases.NewStreamMoviesCase(repo).StreamMovies(
+			ctx, dtos.StreamMoviesDTO{}, func(movie dtos.MovieResponseDTO) error { return canceled },
+		)
+		if !errors.Is(err, canceled) || streamed != 0 || repo.pages != 1 {
+			t.Errorf("Streamed %d movies of %d pages with error %v instead of %v", streamed, repo.pages, err, canceled)
+		}
+	})
+
+	t.Run("should stop once the context is done", func(t *testing.T) {
+		repo := &StubMovieStreamerRepository{movies: movies, pageSize: 2}
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := usecases.NewStreamMoviesCase(repo).StreamMovies(
+			ctx, dtos.StreamMoviesDTO{}, func(movie dtos.MovieResponseDTO) error { return nil },
+		)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Error %v different from Expected: %v", err, context.Canceled)
+		}
+	})
+}
+
+// Emits the movies of the year after lastMovieId, in pages of pageSize,
+// counting the pages fetched.
+type StubMovieStreamerRepository struct {
+	movies []domain.Movie
+	pageSize int
+	pages int
+}
+
+func (repo *StubMovieStreamerRepository) StreamAll(
+	ctx context.Context, year string, lastMovieId int, emit func(movies []domain.Movie) error,
+) error {
+	var page []domain.Movie
+	flush := func() error {
+		repo.pages++
+		err := emit(page)
+		page = nil
+		return err
+	}
+	for _, movie := range repo.movies {
+		if movie.ID <= lastMovieId || (year != "" && movie.Year != year) {
+			continue
+		}
+		page = append(page, movie)
+		if len(page) == repo.pageSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if len(page) > 0 {
+		return flush()
+	}
+	return nil
+}
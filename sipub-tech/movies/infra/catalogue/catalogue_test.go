@@ -0,0 +1,160 @@
+package catalogue_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/infra/catalogue"
+)
+
+// The rows read until io.EOF, or the first error stopping the import,
+// with nil for the malformed ones.
+func readAll(t *testing.T, reader ports.MovieImportReader) ([]*dtos.ImportMovieDTO, error) {
+	var rows []*dtos.ImportMovieDTO
+	for {
+		row, err := reader.Read()
+		switch {
+		case errors.Is(err, io.EOF):
+			return rows, nil
+		case errors.Is(err, ports.ErrMalformedImportRow):
+			rows = append(rows, nil)
+		case err != nil:
+			return rows, err
+		default:
+			rows = append(rows, &row)
+		}
+	}
+}
+
+func TestJSONReader(t *testing.T) {
+	t.Run("should read the movies of the array, failing the mistyped ones alone", func(t *testing.T) {
+		file := `[{"id": 8, "title": "Sneeze", "year": "1894"}, {"id": "ten", "title": "Lumière"}, {"id": 12, "title": "Train", "year": "1896"}]`
+
+		rows, err := readAll(t, catalogue.NewJSONReader(strings.NewReader(file)))
+		require.NoError(t, err)
+		require.Len(t, rows, 3)
+		assert.Equal(t, dtos.ImportMovieDTO{ID: 8, Title: "Sneeze", Year: "1894"}, *rows[0])
+		assert.Nil(t, rows[1])
+		assert.Equal(t, dtos.MovieID(12), rows[2].ID)
+	})
+
+	t.Run("should stop on malformed JSON", func(t *testing.T) {
+		_, err := readAll(t, catalogue.NewJSONReader(strings.NewReader(`[{"id": 8}, {"id": `)))
+		assert.Error(t, err)
+	})
+
+	t.Run("should refuse files other than arrays", func(t *testing.T) {
+		_, err := readAll(t, catalogue.NewJSONReader(strings.NewReader(`{"id": 8}`)))
+		assert.Error(t, err)
+	})
+}
+
+func TestNDJSONReader(t *testing.T) {
+	file := "{\"id\": 8, \"title\": \"Sneeze\", \"year\": \"1894\"}\n\n{malformed}\n{\"id\": 12, \"title\": \"Train\", \"year\": \"1896\"}"
+
+	rows, err := readAll(t, catalogue.NewNDJSONReader(strings.NewReader(file)))
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, dtos.MovieID(8), rows[0].ID)
+	assert.Nil(t, rows[1])
+	assert.Equal(t, dtos.ImportMovieDTO{ID: 12, Title: "Train", Year: "1896"}, *rows[2])
+}
+
+func TestCSVReader(t *testing.T) {
+	t.Run("should read the mapped columns in any order", func(t *testing.T) {
+		columns, err := catalogue.ParseCSVColumns("id=movie_id, year=Released")
+		require.NoError(t, err)
+		file := "\ufefftitle,released,movie_id\n\"Sneeze, the\",1894,8\nTrain,1896,ten\nshort\nArrival,1896,12\n"
+
+		rows, err := readAll(t, catalogue.NewCSVReader(strings.NewReader(file), columns))
+		require.NoError(t, err)
+		require.Len(t, rows, 4)
+		assert.Equal(t, dtos.ImportMovieDTO{ID: 8, Title: "Sneeze, the", Year: "1894"}, *rows[0])
+		assert.Nil(t, rows[1])
+		assert.Nil(t, rows[2])
+		assert.Equal(t, dtos.MovieID(12), rows[3].ID)
+	})
+
+	t.Run("should stop when a column is missing from the header", func(t *testing.T) {
+		_, err := readAll(t, catalogue.NewCSVReader(strings.NewReader("id,name,year\n8,Sneeze,1894\n"), catalogue.DefaultCSVColumns()))
+		assert.Error(t, err)
+	})
+
+	t.Run("should refuse malformed mappings", func(t *testing.T) {
+		for _, mapping := range []string{"id", "rating=stars", "title="} {
+			_, err := catalogue.ParseCSVColumns(mapping)
+			assert.Error(t, err, "mapping %q", mapping)
+		}
+	})
+}
+
+func TestFormatFromPath(t *testing.T) {
+	assert.Equal(t, catalogue.FormatJSON, catalogue.FormatFromPath("data/movies.json"))
+	assert.Equal(t, catalogue.FormatNDJSON, catalogue.FormatFromPath("movies.jsonl"))
+	assert.Equal(t, catalogue.FormatCSV, catalogue.FormatFromPath("movies.CSV"))
+}
+
+func TestWriters(t *testing.T) {
+	movies := []dtos.MovieResponseDTO{
+		{ID: 8, Title: "Sneeze, the", Year: "1894", Version: 2, UpdatedAt: 1700000000},
+		{ID: 1 << 40, Title: "\"Train\"", Year: "1896", Version: 1},
+	}
+	columns, err := catalogue.ParseCSVColumns("id=movie_id")
+	require.NoError(t, err)
+
+	for _, format := range []catalogue.Format{catalogue.FormatJSON, catalogue.FormatNDJSON, catalogue.FormatCSV} {
+		t.Run("should write "+string(format)+" the importer reads back", func(t *testing.T) {
+			var file bytes.Buffer
+			writer, err := catalogue.NewWriter(format, &file, columns)
+			require.NoError(t, err)
+			for _, movie := range movies {
+				require.NoError(t, writer.Write(movie))
+			}
+			require.NoError(t, writer.Close())
+
+			reader, err := catalogue.NewReader(format, &file, columns)
+			require.NoError(t, err)
+			rows, err := readAll(t, reader)
+			require.NoError(t, err)
+			require.Len(t, rows, 2)
+			assert.Equal(t, dtos.ImportMovieDTO{ID: 8, Title: "Sneeze, the", Year: "1894"}, *rows[0])
+			assert.Equal(t, dtos.ImportMovieDTO{ID: 1 << 40, Title: "\"Train\"", Year: "1896"}, *rows[1])
+		})
+
+		t.Run("should write empty "+string(format)+" the importer reads back", func(t *testing.T) {
+			var file bytes.Buffer
+			writer, err := catalogue.NewWriter(format, &file, columns)
+			require.NoError(t, err)
+			require.NoError(t, writer.Close())
+
+			reader, err := catalogue.NewReader(format, &file, columns)
+			require.NoError(t, err)
+			rows, err := readAll(t, reader)
+			require.NoError(t, err)
+			assert.Empty(t, rows)
+		})
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "movies.json.checkpoint")
+
+	missing, err := catalogue.LoadCheckpoint(path)
+	require.NoError(t, err)
+	assert.Zero(t, missing)
+
+	require.NoError(t, catalogue.SaveCheckpoint(path, catalogue.Checkpoint{File: "movies.json", Done: 300}))
+	require.NoError(t, catalogue.SaveCheckpoint(path, catalogue.Checkpoint{File: "movies.json", Done: 400}))
+	loaded, err := catalogue.LoadCheckpoint(path)
+	require.NoError(t, err)
+	assert.Equal(t, catalogue.Checkpoint{File: "movies.json", Done: 400}, loaded)
+}
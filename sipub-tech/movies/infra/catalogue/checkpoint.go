@@ -0,0 +1,47 @@
+package catalogue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// How far an interrupted import of the file went.
+type Checkpoint struct {
+	File string   `json:"file"`
+	// The rows from the start of the file that are done.
+	Done int      `json:"done"`
+}
+
+// Returns a zero Checkpoint when there is no file at path.
+func LoadCheckpoint(path string) (Checkpoint, error) {
+	var checkpoint Checkpoint
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoint, nil
+	} else if err != nil {
+		return checkpoint, fmt.Errorf("failed reading checkpoint %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return checkpoint, fmt.Errorf("malformed checkpoint %s: %w", path, err)
+	}
+	return checkpoint, nil
+}
+
+// Replaces the checkpoint at path at once, so an interruption never leaves
+// it half written.
+func SaveCheckpoint(path string, checkpoint Checkpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("failed marshalling checkpoint %+v: %w", checkpoint, err)
+	}
+	temporary := path + ".tmp"
+	if err := os.WriteFile(temporary, data, 0o644); err != nil {
+		return fmt.Errorf("failed writing checkpoint %s: %w", path, err)
+	}
+	if err := os.Rename(temporary, path); err != nil {
+		return fmt.Errorf("failed replacing checkpoint %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package catalogue
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/ports"
+)
+
+type Format string
+
+const (
+	// An array of objects with the id, title and year of each movie.
+	FormatJSON Format = "json"
+	// An object per line, as in FormatJSON.
+	FormatNDJSON Format = "ndjson"
+	// A header naming the columns, and a movie per record.
+	FormatCSV Format = "csv"
+
+	maxNDJSONLine = 1 << 20
+)
+
+// Guesses the format of the file from its extension, defaulting to JSON.
+func FormatFromPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON
+	case ".csv":
+		return FormatCSV
+	default:
+		return FormatJSON
+	}
+}
+
+func NewReader(format Format, reader io.Reader, columns CSVColumns) (ports.MovieImportReader, error) {
+	switch format {
+	case FormatJSON:
+		return NewJSONReader(reader), nil
+	case FormatNDJSON:
+		return NewNDJSONReader(reader), nil
+	case FormatCSV:
+		return NewCSVReader(reader, columns), nil
+	default:
+		return nil, fmt.Errorf("unknown import format %q", format)
+	}
+}
+
+func malformedRow(err error) error {
+	return fmt.Errorf("%w: %v", ports.ErrMalformedImportRow, err)
+}
+
+// Decodes the movies of the array one at a time. The movies with fields of
+// the wrong type fail alone, while malformed JSON stops the import.
+func NewJSONReader(reader io.Reader) *JSONReader {
+	return &JSONReader{decoder: json.NewDecoder(reader)}
+}
+
+type JSONReader struct {
+	ports.MovieImportReader
+
+	decoder *json.Decoder
+	started bool
+	ended bool
+}
+
+func (reader *JSONReader) Read() (dtos.ImportMovieDTO, error) {
+	var row dtos.ImportMovieDTO
+	if reader.ended {
+		return row, io.EOF
+	}
+	if !reader.started {
+		token, err := reader.decoder.Token()
+		if err == io.EOF {
+			reader.ended = true
+			return row, io.EOF
+		} else if err != nil {
+			return row, fmt.Errorf("failed reading the start of the array: %w", err)
+		}
+		if delim, ok := token.(json.Delim); !ok || delim != '[' {
+			return row, fmt.Errorf("the file must hold an array of movies, found %v", token)
+		}
+		reader.started = true
+	}
+
+	if !reader.decoder.More() {
+		if _, err := reader.decoder.Token(); err != nil {
+			return row, fmt.Errorf("failed reading the end of the array: %w", err)
+		}
+		reader.ended = true
+		return row, io.EOF
+	}
+	err := reader.decoder.Decode(&row)
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) {
+		return row, malformedRow(err)
+	} else if err != nil {
+		return row, fmt.Errorf("failed decoding a movie: %w", err)
+	}
+	return row, nil
+}
+
+// Decodes a movie per line, skipping the blank ones. Each malformed line
+// fails alone.
+func NewNDJSONReader(reader io.Reader) *NDJSONReader {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64 * 1024), maxNDJSONLine)
+	return &NDJSONReader{scanner: scanner}
+}
+
+type NDJSONReader struct {
+	ports.MovieImportReader
+
+	scanner *bufio.Scanner
+}
+
+func (reader *NDJSONReader) Read() (dtos.ImportMovieDTO, error) {
+	var row dtos.ImportMovieDTO
+	for reader.scanner.Scan() {
+		line := bytes.TrimSpace(reader.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(line, &row); err != nil {
+			return row, malformedRow(err)
+		}
+		return row, nil
+	}
+	if err := reader.scanner.Err(); err != nil {
+		return row, fmt.Errorf("failed reading a line: %w", err)
+	}
+	return row, io.EOF
+}
+
+// The names of the header columns holding each field of the movies.
+type CSVColumns struct {
+	ID string
+	Title string
+	Year string
+}
+
+func DefaultCSVColumns() CSVColumns {
+	return CSVColumns{ID: "id", Title: "title", Year: "year"}
+}
+
+// Parses a mapping such as "id=movie_id,year=released", the fields left
+// out keeping their default column.
+func ParseCSVColumns(mapping string) (CSVColumns, error) {
+	columns := DefaultCSVColumns()
+	if strings.TrimSpace(mapping) == "" {
+		return columns, nil
+	}
+	for _, pair := range strings.Split(mapping, ",") {
+		field, column, ok := strings.Cut(pair, "=")
+		field, column = strings.TrimSpace(field), strings.TrimSpace(column)
+		if !ok || column == "" {
+			return columns, fmt.Errorf("malformed column mapping %q, expected field=column", pair)
+		}
+		switch field {
+		case "id":
+			columns.ID = column
+		case "title":
+			columns.Title = column
+		case "year":
+			columns.Year = column
+		default:
+			return columns, fmt.Errorf("unknown movie field %q, expected id, title or year", field)
+		}
+	}
+	return columns, nil
+}
+
+// Reads a movie per record, finding the columns of the fields in the
+// header. Each malformed record fails alone.
+func NewCSVReader(reader io.Reader, columns CSVColumns) *CSVReader {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.ReuseRecord = true
+	return &CSVReader{reader: csvReader, columns: columns}
+}
+
+type CSVReader struct {
+	ports.MovieImportReader
+
+	reader *csv.Reader
+	columns CSVColumns
+	// The positions of the id, title and year columns, once the header is
+	// read.
+	indexes []int
+}
+
+func (reader *CSVReader) Read() (dtos.ImportMovieDTO, error) {
+	var row dtos.ImportMovieDTO
+	if reader.indexes == nil {
+		if err := reader.readHeader(); err != nil {
+			return row, err
+		}
+	}
+
+	record, err := reader.reader.Read()
+	var parseError *csv.ParseError
+	if errors.As(err, &parseError) {
+		return row, malformedRow(err)
+	} else if err == io.EOF {
+		return row, io.EOF
+	} else if err != nil {
+		return row, fmt.Errorf("failed reading a record: %w", err)
+	}
+
+	fields := make([]string, len(reader.indexes))
+	for position, index := range reader.indexes {
+		if index >= len(record) {
+			return row, malformedRow(fmt.Errorf("record with %d fields, missing column %d", len(record), index + 1))
+		}
+		fields[position] = strings.TrimSpace(record[index])
+	}
+	id, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return row, malformedRow(fmt.Errorf("malformed id %q", fields[0]))
+	}
+	return dtos.ImportMovieDTO{ID: dtos.MovieID(id), Title: fields[1], Year: fields[2]}, nil
+}
+
+func (reader *CSVReader) readHeader() error {
+	header, err := reader.reader.Read()
+	if err == io.EOF {
+		return io.EOF
+	} else if err != nil {
+		return fmt.Errorf("failed reading the header: %w", err)
+	}
+
+	positions := make(map[string]int, len(header))
+	for index, name := range header {
+		if index == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		positions[strings.ToLower(strings.TrimSpace(name))] = index
+	}
+	indexes := make([]int, 0, 3)
+	for _, column := range []string{reader.columns.ID, reader.columns.Title, reader.columns.Year} {
+		index, ok := positions[strings.ToLower(column)]
+		if !ok {
+			return fmt.Errorf("column %q missing from the header %v", column, header)
+		}
+		indexes = append(indexes, index)
+	}
+	reader.indexes = indexes
+	return nil
+}
@@ -0,0 +1,151 @@
+package catalogue
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/ports"
+)
+
+func NewWriter(format Format, writer io.Writer, columns CSVColumns) (ports.MovieExportWriter, error) {
+	switch format {
+	case FormatJSON:
+		return NewJSONWriter(writer), nil
+	case FormatNDJSON:
+		return NewNDJSONWriter(writer), nil
+	case FormatCSV:
+		return NewCSVWriter(writer, columns), nil
+	default:
+		return nil, fmt.Errorf("unknown export format %q", format)
+	}
+}
+
+// Writes the movies as the elements of an array, opened on the first write
+// and closed by Close.
+func NewJSONWriter(writer io.Writer) *JSONWriter {
+	return &JSONWriter{writer: bufio.NewWriter(writer)}
+}
+
+type JSONWriter struct {
+	ports.MovieExportWriter
+
+	writer *bufio.Writer
+	written bool
+}
+
+func (writer *JSONWriter) Write(movie dtos.MovieResponseDTO) error {
+	data, err := json.Marshal(movie)
+	if err != nil {
+		return fmt.Errorf("failed encoding movie %d: %w", movie.ID, err)
+	}
+	separator := ",\n"
+	if !writer.written {
+		separator = "[\n"
+		writer.written = true
+	}
+	writer.writer.WriteString(separator)
+	if _, err := writer.writer.Write(data); err != nil {
+		return fmt.Errorf("failed writing movie %d: %w", movie.ID, err)
+	}
+	return nil
+}
+
+func (writer *JSONWriter) Close() error {
+	end := "\n]\n"
+	if !writer.written {
+		end = "[]\n"
+	}
+	writer.writer.WriteString(end)
+	if err := writer.writer.Flush(); err != nil {
+		return fmt.Errorf("failed writing the end of the array: %w", err)
+	}
+	return nil
+}
+
+// Writes a movie per line.
+func NewNDJSONWriter(writer io.Writer) *NDJSONWriter {
+	buffered := bufio.NewWriter(writer)
+	return &NDJSONWriter{writer: buffered, encoder: json.NewEncoder(buffered)}
+}
+
+type NDJSONWriter struct {
+	ports.MovieExportWriter
+
+	writer *bufio.Writer
+	encoder *json.Encoder
+}
+
+func (writer *NDJSONWriter) Write(movie dtos.MovieResponseDTO) error {
+	if err := writer.encoder.Encode(movie); err != nil {
+		return fmt.Errorf("failed writing movie %d: %w", movie.ID, err)
+	}
+	return nil
+}
+
+func (writer *NDJSONWriter) Close() error {
+	if err := writer.writer.Flush(); err != nil {
+		return fmt.Errorf("failed writing the last movies: %w", err)
+	}
+	return nil
+}
+
+// Writes a header naming the columns, then a movie per record. The id,
+// title and year go to their columns, and the version and update time to
+// the version and updated_at columns after them.
+func NewCSVWriter(writer io.Writer, columns CSVColumns) *CSVWriter {
+	return &CSVWriter{writer: csv.NewWriter(writer), columns: columns}
+}
+
+type CSVWriter struct {
+	ports.MovieExportWriter
+
+	writer *csv.Writer
+	columns CSVColumns
+	written bool
+}
+
+func (writer *CSVWriter) Write(movie dtos.MovieResponseDTO) error {
+	if !writer.written {
+		if err := writer.writeHeader(); err != nil {
+			return err
+		}
+	}
+	record := []string{
+		strconv.FormatInt(int64(movie.ID), 10),
+		movie.Title,
+		movie.Year,
+		strconv.Itoa(movie.Version),
+		strconv.FormatInt(movie.UpdatedAt, 10),
+	}
+	if err := writer.writer.Write(record); err != nil {
+		return fmt.Errorf("failed writing movie %d: %w", movie.ID, err)
+	}
+	return nil
+}
+
+func (writer *CSVWriter) Close() error {
+	if !writer.written {
+		if err := writer.writeHeader(); err != nil {
+			return err
+		}
+	}
+	writer.writer.Flush()
+	if err := writer.writer.Error(); err != nil {
+		return fmt.Errorf("failed writing the last movies: %w", err)
+	}
+	return nil
+}
+
+func (writer *CSVWriter) writeHeader() error {
+	writer.written = true
+	header := []string{writer.columns.ID, writer.columns.Title, writer.columns.Year, "version", "updated_at"}
+	if err := writer.writer.Write(header); err != nil {
+		return fmt.Errorf("failed writing the header: %w", err)
+	}
+	return nil
+}
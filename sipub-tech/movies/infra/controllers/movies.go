@@ -2,8 +2,11 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
 	pb "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/grpc/movies"
 	pb_exceptions "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/grpc/exceptions"
 
@@ -16,10 +19,13 @@ type ContextKey string
 
 const (
 	RepoKey ContextKey = "repository"
+	// The publisher of the results of the bulk operations.
+	PublisherKey ContextKey = "publisher"
 )
 
 var (
 	ErrUnsetRespository = fmt.Errorf("repository not set in context or incomplete")
+	ErrUnsetPublisher = fmt.Errorf("operation results publisher not set in context")
 )
 
 type GRPCMovieController struct {
@@ -64,7 +70,154 @@ func (controller *GRPCMovieController) GetMovies(ctx context.Context, req *pb.Ge
 		parsedMovies[index] = controller.responseDtoToPbMovie(&movie)
 	}
 
-	return &pb.Movies{Movies: parsedMovies, Cursor: int32(cursor)}, err
+	return &pb.Movies{Movies: parsedMovies, Cursor: int64(cursor)}, err
+}
+
+func (controller *GRPCMovieController) BatchGetMovies(ctx context.Context, req *pb.BatchGetMoviesRequest) (*pb.MoviesBatch, error) {
+	repo, ok := ctx.Value(RepoKey).(ports.MovieManyGetterRepository)
+	if !ok {
+		return nil, ErrUnsetRespository
+	}
+	usecase := usecases.NewBatchGetMoviesCase(repo)
+
+	ids := make([]dtos.MovieID, len(req.Ids))
+	for index, id := range req.Ids {
+		ids[index] = dtos.MovieID(id)
+	}
+
+	movies, missing, err := usecase.BatchGetMovies(ctx, ids)
+	if errors.Is(err, ports.ErrTooManyMovieIds) {
+		return nil, pb_exceptions.ErrTooManyMovieIds
+	} else if err != nil {
+		return nil, err
+	}
+
+	parsedMovies := make([]*pb.Movie, len(*movies))
+	for index, movie := range *movies {
+		parsedMovies[index] = controller.responseDtoToPbMovie(&movie)
+	}
+	missingIds := make([]int64, len(missing))
+	for index, id := range missing {
+		missingIds[index] = int64(id)
+	}
+
+	return &pb.MoviesBatch{Movies: parsedMovies, MissingIds: missingIds}, nil
+}
+
+// Sends every movie down the stream, ctx carrying the repository.
+func (controller *GRPCMovieController) ExportMovies(
+	ctx context.Context, req *pb.ExportMoviesRequest, stream pb.MovieService_ExportMoviesServer,
+) error {
+	repo, ok := ctx.Value(RepoKey).(ports.MovieSegmentScannerRepository)
+	if !ok {
+		return ErrUnsetRespository
+	}
+	segments := int(req.Segments)
+	if segments <= 0 {
+		segments = usecases.DefaultExportSegments
+	}
+	usecase := usecases.NewExportMoviesCase(repo, segments)
+
+	_, err := usecase.ExportMovies(ctx, func(movie dtos.MovieResponseDTO) error {
+		return stream.Send(controller.responseDtoToPbMovie(&movie))
+	})
+	return err
+}
+
+// Sends the movies of the request down the stream as each page is read,
+// ctx carrying the repository. Send blocks while the client is not
+// reading, holding the next pages back, and the stream stops once the
+// client cancels it.
+func (controller *GRPCMovieController) StreamMovies(
+	ctx context.Context, req *pb.StreamMoviesRequest, stream pb.MovieService_StreamMoviesServer,
+) error {
+	repo, ok := ctx.Value(RepoKey).(ports.MovieStreamerRepository)
+	if !ok {
+		return ErrUnsetRespository
+	}
+	usecase := usecases.NewStreamMoviesCase(repo)
+
+	query := dtos.StreamMoviesDTO{
+		Year: req.Year,
+		Limit: int(req.Limit),
+		Cursor: int(req.Cursor),
+	}
+	_, err := usecase.StreamMovies(ctx, query, func(movie dtos.MovieResponseDTO) error {
+		return stream.Send(controller.responseDtoToPbMovie(&movie))
+	})
+	return err
+}
+
+// Saves the movie before answering, ctx carrying the repository.
+func (controller *GRPCMovieController) CreateMovie(ctx context.Context, req *pb.CreateMovieRequest) (*pb.Movie, error) {
+	repo, ok := ctx.Value(RepoKey).(ports.MovieSaverRepository)
+	if !ok {
+		return nil, ErrUnsetRespository
+	}
+	usecase := usecases.NewSaveMovieCase(repo)
+
+	dto := dtos.CreateMovieDTO{Title: req.Title, Year: req.Year}
+	if err := dto.Validate(); err != nil {
+		return nil, pb_exceptions.NewInvalidMovieError(err)
+	}
+
+	movie, err := usecase.SaveMovie(ctx, dto)
+	if err != nil {
+		return nil, controller.writeError(err)
+	}
+	return controller.responseDtoToPbMovie(movie), nil
+}
+
+// Updates the movie before answering, ctx carrying the repository.
+func (controller *GRPCMovieController) UpdateMovie(ctx context.Context, req *pb.UpdateMovieRequest) (*pb.Movie, error) {
+	repo, ok := ctx.Value(RepoKey).(ports.MovieUpdaterRepository)
+	if !ok {
+		return nil, ErrUnsetRespository
+	}
+	usecase := usecases.NewUpdateMovieCase(repo)
+
+	dto := dtos.UpdateMovieDTO{
+		ID: dtos.MovieID(req.Id),
+		Title: req.Title,
+		Year: req.Year,
+		Version: int(req.ExpectedVersion),
+	}
+	if err := dto.Validate(); err != nil {
+		return nil, pb_exceptions.NewInvalidMovieError(err)
+	}
+
+	movie, err := usecase.UpdateMovie(ctx, dto)
+	if err != nil {
+		return nil, controller.writeError(err)
+	}
+	return controller.responseDtoToPbMovie(movie), nil
+}
+
+// Deletes the movie before answering, ctx carrying the repository.
+func (controller *GRPCMovieController) DeleteMovie(ctx context.Context, req *pb.DeleteMovieRequest) (*pb.DeleteMovieResponse, error) {
+	repo, ok := ctx.Value(RepoKey).(ports.MovieDeleterRepository)
+	if !ok {
+		return nil, ErrUnsetRespository
+	}
+	usecase := usecases.NewDeleteMovieCase(repo)
+
+	movie, err := usecase.DeleteMovie(ctx, dtos.MovieID(req.Id), int(req.ExpectedVersion))
+	if err != nil {
+		return nil, controller.writeError(err)
+	}
+	return &pb.DeleteMovieResponse{Movie: controller.responseDtoToPbMovie(movie)}, nil
+}
+
+// Turns the errors of the writes the clients can act on into statuses.
+func (controller *GRPCMovieController) writeError(err error) error {
+	switch {
+	case errors.Is(err, ports.ErrMovieNotFound):
+		return pb_exceptions.ErrMovieNotFound
+	case errors.Is(err, ports.ErrVersionMismatch):
+		return pb_exceptions.ErrVersionMismatch
+	default:
+		return err
+	}
 }
 
 func (controller *GRPCMovieController) responseDtoToPbMovie(movie *dtos.MovieResponseDTO) *pb.Movie {
@@ -72,9 +225,11 @@ func (controller *GRPCMovieController) responseDtoToPbMovie(movie *dtos.MovieRes
 		return nil
 	}
 	return &pb.Movie{
-		Id: int32(movie.ID),
+		Id: int64(movie.ID),
 		Title: movie.Title,
 		Year: movie.Year,
+		Version: int64(movie.Version),
+		UpdatedAt: timestamppb.New(time.UnixMilli(movie.UpdatedAt)),
 	}
 }
 
@@ -82,26 +237,64 @@ type MessagingMovieController struct {
 
 }
 
-func (controler *MessagingMovieController) SaveMovie(ctx context.Context, movie dtos.CreateMovieDTO) error {
+func (controller *MessagingMovieController) SaveMovie(ctx context.Context, movie dtos.CreateMovieDTO) error {
 	repo, ok := ctx.Value(RepoKey).(ports.MovieSaverRepository)
 	if !ok {
 		return ErrUnsetRespository
 	}
 	usecase := usecases.NewSaveMovieCase(repo)
 
-	return usecase.SaveMovie(ctx, movie)
+	_, err := usecase.SaveMovie(ctx, movie)
+	return err
 }
 
 
-func (controller *MessagingMovieController) DeleteMovie(ctx context.Context, id dtos.MovieID) error {
+func (controller *MessagingMovieController) UpdateMovie(ctx context.Context, movie dtos.UpdateMovieDTO) error {
+	repo, ok := ctx.Value(RepoKey).(ports.MovieUpdaterRepository)
+	if !ok {
+		return ErrUnsetRespository
+	}
+	usecase := usecases.NewUpdateMovieCase(repo)
+
+	_, err := usecase.UpdateMovie(ctx, movie)
+	return err
+}
+
+func (controller *MessagingMovieController) DeleteMovie(ctx context.Context, id dtos.MovieID, expectedVersion int) error {
 	repo, ok := ctx.Value(RepoKey).(ports.MovieDeleterRepository)
 	if !ok {
 		return ErrUnsetRespository
 	}
 	usecase := usecases.NewDeleteMovieCase(repo)
 
-	return usecase.DeleteMovie(ctx, id)
+	_, err := usecase.DeleteMovie(ctx, id, expectedVersion)
+	return err
 }
 
+func (controller *MessagingMovieController) SaveMovies(ctx context.Context, batch dtos.BulkCreateMoviesDTO) error {
+	repo, ok := ctx.Value(RepoKey).(ports.MovieBulkSaverRepository)
+	if !ok {
+		return ErrUnsetRespository
+	}
+	publisher, ok := ctx.Value(PublisherKey).(ports.OperationResultsPublisher)
+	if !ok {
+		return ErrUnsetPublisher
+	}
+	usecase := usecases.NewSaveMoviesCase(repo, publisher)
+
+	return usecase.SaveMovies(ctx, batch)
+}
 
+func (controller *MessagingMovieController) DeleteMovies(ctx context.Context, batch dtos.BulkDeleteMoviesDTO) error {
+	repo, ok := ctx.Value(RepoKey).(ports.MovieBulkDeleterRepository)
+	if !ok {
+		return ErrUnsetRespository
+	}
+	publisher, ok := ctx.Value(PublisherKey).(ports.OperationResultsPublisher)
+	if !ok {
+		return ErrUnsetPublisher
+	}
+	usecase := usecases.NewDeleteMoviesCase(repo, publisher)
 
+	return usecase.DeleteMovies(ctx, batch)
+}
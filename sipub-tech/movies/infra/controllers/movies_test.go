@@ -5,6 +5,12 @@ import (
 	"fmt"
 	"testing"
 	"testing/quick"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	pb "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/grpc/movies"
 	pb_exceptions "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/grpc/exceptions"
@@ -13,6 +19,7 @@ import (
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/domain"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/dtos"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/usecases"
 )
 
 func TestGRPCMovieController(t *testing.T) {
@@ -32,16 +39,18 @@ func TestGRPCMovieController(t *testing.T) {
 				}
 				ctx := context.WithValue(ctx, controllers.RepoKey, repo)
 
-				result, err := controller.GetMovie(ctx, &pb.GetMovieRequest{Id: int32(movie.ID)})
+				result, err := controller.GetMovie(ctx, &pb.GetMovieRequest{Id: int64(movie.ID)})
 				if err != nil {
 					t.Logf("Error found when getting movie %v", err)
 					return false
 				}
 
 				expected := pb.Movie{
-					Id: int32(movie.ID),
+					Id: int64(movie.ID),
 					Title: movie.Title,
 					Year: movie.Year,
+					Version: int64(movie.Version),
+					UpdatedAt: timestamppb.New(time.UnixMilli(movie.UpdatedAt)),
 				}
 				
 				if expected.String() != result.String() {
@@ -61,7 +70,7 @@ func TestGRPCMovieController(t *testing.T) {
 				repo := &StubMovieOneGetter{}
 				ctx := context.WithValue(ctx, controllers.RepoKey, repo)
 
-				_, err := controller.GetMovie(ctx, &pb.GetMovieRequest{Id: int32(id)})
+				_, err := controller.GetMovie(ctx, &pb.GetMovieRequest{Id: int64(id)})
 
 				if err == nil {
 					t.Logf("No error return when getting not existent movie.")
@@ -89,7 +98,7 @@ func TestGRPCMovieController(t *testing.T) {
 				}
 				ctx := context.WithValue(ctx, controllers.RepoKey, repo)
 
-				_, receivedErr := controller.GetMovie(ctx, &pb.GetMovieRequest{Id: int32(id)})
+				_, receivedErr := controller.GetMovie(ctx, &pb.GetMovieRequest{Id: int64(id)})
 				if receivedErr == nil {
 					t.Logf("No error return when getting not existent movie.")
 					return false
@@ -109,7 +118,7 @@ func TestGRPCMovieController(t *testing.T) {
 
 		t.Run("should return error if repository not set in context.", func(t *testing.T) {
 			assertion := func(id dtos.MovieID) bool {
-				_, err := controller.GetMovie(ctx, &pb.GetMovieRequest{Id: int32(id)})
+				_, err := controller.GetMovie(ctx, &pb.GetMovieRequest{Id: int64(id)})
 
 				if err == nil {
 					t.Logf("No error return when checking for repository.")
@@ -148,9 +157,11 @@ func TestGRPCMovieController(t *testing.T) {
 				
 				for index, movie := range(movies) {
 					expected := &pb.Movie{
-						Id: int32(movie.ID),
+						Id: int64(movie.ID),
 						Title: movie.Title,
-						Year: movie.Year,	
+						Year: movie.Year,
+						Version: int64(movie.Version),
+						UpdatedAt: timestamppb.New(time.UnixMilli(movie.UpdatedAt)),
 					}
 					
 					if expected.String() != (*results.Movies[index]).String() {
@@ -214,6 +225,187 @@ func TestGRPCMovieController(t *testing.T) {
 
 		})
 	})
+	t.Run("when executing BatchGetMovies", func(t *testing.T) {
+		t.Run("should return the movies found and the ids missing", func(t *testing.T) {
+			repo := &StubMovieManyGetter{moviesReturned: []domain.Movie{{ID: 2, Title: "second", Year: "1995", Version: 1}}}
+			ctx := context.WithValue(ctx, controllers.RepoKey, repo)
+
+			results, err := controller.BatchGetMovies(ctx, &pb.BatchGetMoviesRequest{Ids: []int64{2, 5}})
+			if err != nil {
+				t.Fatalf("Error found when getting movies %v", err)
+			}
+			if len(results.Movies) != 1 || results.Movies[0].Id != 2 || results.Movies[0].Title != "second" {
+				t.Errorf("Unexpected movies returned: %v", results.Movies)
+			}
+			if len(results.MissingIds) != 1 || results.MissingIds[0] != 5 {
+				t.Errorf("Unexpected missing ids returned: %v", results.MissingIds)
+			}
+		})
+
+		t.Run("should return pb_exceptions.ErrTooManyMovieIds for too many ids", func(t *testing.T) {
+			ids := make([]int64, usecases.MaxBatchGetMovies + 1)
+			for index := range ids {
+				ids[index] = int64(index + 1)
+			}
+			ctx := context.WithValue(ctx, controllers.RepoKey, &StubMovieManyGetter{})
+
+			_, err := controller.BatchGetMovies(ctx, &pb.BatchGetMoviesRequest{Ids: ids})
+			if err != pb_exceptions.ErrTooManyMovieIds {
+				t.Errorf("Error %v is not ErrTooManyMovieIds", err)
+			}
+		})
+
+		t.Run("should return error if repository not set in context.", func(t *testing.T) {
+			if _, err := controller.BatchGetMovies(ctx, &pb.BatchGetMoviesRequest{}); err != controllers.ErrUnsetRespository {
+				t.Errorf("Did not return ErrUnsetRepository when repository was unset: %v", err)
+			}
+		})
+	})
+	t.Run("when executing ExportMovies", func(t *testing.T) {
+		t.Run("should send every movie of every segment", func(t *testing.T) {
+			repo := &StubMovieSegmentScanner{moviesReturned: []domain.Movie{{ID: 2, Title: "second", Year: "1995", Version: 1}}}
+			ctx := context.WithValue(ctx, controllers.RepoKey, repo)
+			stream := &FakeMovieStream{}
+
+			if err := controller.ExportMovies(ctx, &pb.ExportMoviesRequest{Segments: 3}, stream); err != nil {
+				t.Fatalf("Error found when exporting movies %v", err)
+			}
+			if len(stream.sent) != 3 || stream.sent[0].Id != 2 || stream.sent[0].Title != "second" {
+				t.Errorf("Unexpected movies sent: %v", stream.sent)
+			}
+		})
+
+		t.Run("should return the error of the stream", func(t *testing.T) {
+			repo := &StubMovieSegmentScanner{moviesReturned: []domain.Movie{{ID: 2}}}
+			ctx := context.WithValue(ctx, controllers.RepoKey, repo)
+			stream := &FakeMovieStream{errorReturned: fmt.Errorf("client gone")}
+
+			if err := controller.ExportMovies(ctx, &pb.ExportMoviesRequest{}, stream); err == nil {
+				t.Errorf("No error returned when the stream failed")
+			}
+		})
+
+		t.Run("should return error if repository not set in context.", func(t *testing.T) {
+			if err := controller.ExportMovies(ctx, &pb.ExportMoviesRequest{}, &FakeMovieStream{}); err != controllers.ErrUnsetRespository {
+				t.Errorf("Did not return ErrUnsetRepository when repository was unset: %v", err)
+			}
+		})
+	})
+	t.Run("when executing StreamMovies", func(t *testing.T) {
+		t.Run("should send the movies of the request up to the limit", func(t *testing.T) {
+			repo := &MockMovieStreamer{moviesReturned: []domain.Movie{{ID: 4, Title: "fourth", Year: "1995"}, {ID: 5}, {ID: 6}}}
+			ctx := context.WithValue(ctx, controllers.RepoKey, repo)
+			stream := &FakeMovieStream{}
+
+			err := controller.StreamMovies(ctx, &pb.StreamMoviesRequest{Year: "1995", Cursor: 3, Limit: 2}, stream)
+			if err != nil {
+				t.Fatalf("Error found when streaming movies %v", err)
+			}
+			if len(stream.sent) != 2 || stream.sent[0].Id != 4 || stream.sent[0].Title != "fourth" {
+				t.Errorf("Unexpected movies sent: %v", stream.sent)
+			}
+			if repo.yearReceived != "1995" || repo.cursorReceived != 3 {
+				t.Errorf("Streamed the movies of %q after %d instead of 1995 after 3", repo.yearReceived, repo.cursorReceived)
+			}
+		})
+
+		t.Run("should return the error of the stream", func(t *testing.T) {
+			repo := &MockMovieStreamer{moviesReturned: []domain.Movie{{ID: 2}}}
+			ctx := context.WithValue(ctx, controllers.RepoKey, repo)
+			stream := &FakeMovieStream{errorReturned: fmt.Errorf("client gone")}
+
+			if err := controller.StreamMovies(ctx, &pb.StreamMoviesRequest{}, stream); err == nil {
+				t.Errorf("No error returned when the stream failed")
+			}
+		})
+
+		t.Run("should return error if repository not set in context.", func(t *testing.T) {
+			if err := controller.StreamMovies(ctx, &pb.StreamMoviesRequest{}, &FakeMovieStream{}); err != controllers.ErrUnsetRespository {
+				t.Errorf("Did not return ErrUnsetRepository when repository was unset: %v", err)
+			}
+		})
+	})
+	t.Run("when executing CreateMovie", func(t *testing.T) {
+		t.Run("should answer the saved movie", func(t *testing.T) {
+			repo := &MockMovieSaver{}
+			ctx := context.WithValue(ctx, controllers.RepoKey, repo)
+
+			movie, err := controller.CreateMovie(ctx, &pb.CreateMovieRequest{Title: "Fargo", Year: "1996"})
+			if err != nil {
+				t.Fatalf("Error found when creating movie %v", err)
+			}
+			if movie.Title != "Fargo" || movie.Year != "1996" || repo.moviePassed.Title != "Fargo" {
+				t.Errorf("Unexpected movie answered %v after saving %+v", movie, repo.moviePassed)
+			}
+		})
+
+		t.Run("should refuse an invalid movie with InvalidArgument", func(t *testing.T) {
+			repo := &MockMovieSaver{}
+			ctx := context.WithValue(ctx, controllers.RepoKey, repo)
+
+			_, err := controller.CreateMovie(ctx, &pb.CreateMovieRequest{Year: "1600"})
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("Expected InvalidArgument, found %v", err)
+			}
+			if repo.moviePassed != (domain.Movie{}) {
+				t.Errorf("The invalid movie %+v was saved", repo.moviePassed)
+			}
+		})
+
+		t.Run("should return error if repository not set in context.", func(t *testing.T) {
+			if _, err := controller.CreateMovie(ctx, &pb.CreateMovieRequest{}); err != controllers.ErrUnsetRespository {
+				t.Errorf("Did not return ErrUnsetRepository when repository was unset: %v", err)
+			}
+		})
+	})
+	t.Run("when executing UpdateMovie", func(t *testing.T) {
+		t.Run("should answer the updated movie", func(t *testing.T) {
+			repo := &MockMovieUpdater{}
+			ctx := context.WithValue(ctx, controllers.RepoKey, repo)
+
+			movie, err := controller.UpdateMovie(ctx, &pb.UpdateMovieRequest{Id: 3, Title: "Heat", Year: "1995", ExpectedVersion: 2})
+			if err != nil {
+				t.Fatalf("Error found when updating movie %v", err)
+			}
+			if movie.Id != 3 || movie.Title != "Heat" || repo.moviePassed.ID != 3 {
+				t.Errorf("Unexpected movie answered %v after updating %+v", movie, repo.moviePassed)
+			}
+		})
+
+		t.Run("should translate the errors of the repository to statuses", func(t *testing.T) {
+			for err, code := range map[error]codes.Code{
+				ports.ErrMovieNotFound: codes.NotFound,
+				ports.ErrVersionMismatch: codes.FailedPrecondition,
+			} {
+				ctx := context.WithValue(ctx, controllers.RepoKey, &MockMovieUpdater{errorReturned: fmt.Errorf("failed: %w", err)})
+
+				_, received := controller.UpdateMovie(ctx, &pb.UpdateMovieRequest{Id: 3, Title: "Heat", Year: "1995"})
+				if status.Code(received) != code {
+					t.Errorf("Expected %v for %v, found %v", code, err, received)
+				}
+			}
+		})
+	})
+	t.Run("when executing DeleteMovie", func(t *testing.T) {
+		t.Run("should answer the deleted movie", func(t *testing.T) {
+			repo := &MockMovieDeleter{}
+			ctx := context.WithValue(ctx, controllers.RepoKey, repo)
+
+			response, err := controller.DeleteMovie(ctx, &pb.DeleteMovieRequest{Id: 9})
+			if err != nil {
+				t.Fatalf("Error found when deleting movie %v", err)
+			}
+			if response.Movie.GetId() != 9 || repo.idPassed != 9 {
+				t.Errorf("Unexpected movie answered %v after deleting %d", response.Movie, repo.idPassed)
+			}
+		})
+
+		t.Run("should return error if repository not set in context.", func(t *testing.T) {
+			if _, err := controller.DeleteMovie(ctx, &pb.DeleteMovieRequest{}); err != controllers.ErrUnsetRespository {
+				t.Errorf("Did not return ErrUnsetRepository when repository was unset: %v", err)
+			}
+		})
+	})
 }
 
 
@@ -242,6 +434,56 @@ func (repo *StubMovieAllGetter) GetAll(
 }
 
 
+type StubMovieManyGetter struct {
+	moviesReturned []domain.Movie
+	errorReturned error
+}
+
+func (repo *StubMovieManyGetter) GetMany(ctx context.Context, ids []int) ([]domain.Movie, error) {
+	return repo.moviesReturned, repo.errorReturned
+}
+
+
+// Emits the same movies for every segment.
+type StubMovieSegmentScanner struct {
+	moviesReturned []domain.Movie
+}
+
+func (repo *StubMovieSegmentScanner) ScanSegment(
+	ctx context.Context, segment, totalSegments int, emit func(movies []domain.Movie) error,
+) error {
+	return emit(repo.moviesReturned)
+}
+
+
+// Emits the movies in a single page, recording the filter.
+type MockMovieStreamer struct {
+	moviesReturned []domain.Movie
+	yearReceived string
+	cursorReceived int
+}
+
+func (repo *MockMovieStreamer) StreamAll(
+	ctx context.Context, year string, lastMovieId int, emit func(movies []domain.Movie) error,
+) error {
+	repo.yearReceived = year
+	repo.cursorReceived = lastMovieId
+	return emit(repo.moviesReturned)
+}
+
+
+type FakeMovieStream struct {
+	grpc.ServerStreamingServer[pb.Movie]
+
+	sent []*pb.Movie
+	errorReturned error
+}
+
+func (stream *FakeMovieStream) Send(movie *pb.Movie) error {
+	stream.sent = append(stream.sent, movie)
+	return stream.errorReturned
+}
+
 func TestMessagingController(t *testing.T) {
 	controller := &controllers.MessagingMovieController{}
 	ctx := context.Background()
@@ -295,6 +537,38 @@ func TestMessagingController(t *testing.T) {
 		})
 	})
 
+	t.Run("when executing UpdateMovie", func(t *testing.T) {
+		t.Run("should pass the movie to the usecase and return its error", func(t *testing.T) {
+			assertion := func(movie dtos.UpdateMovieDTO, errString string) bool {
+				var err error
+				if errString != "" {
+					err = fmt.Errorf("an error: %s", errString)
+				}
+
+				repo := &MockMovieUpdater{errorReturned: err}
+				ctx := context.WithValue(ctx, controllers.RepoKey, repo)
+
+				resultErr := controller.UpdateMovie(ctx, movie)
+
+				if  (err == nil) != (resultErr == nil) {
+					t.Logf("Expected %v error found %v", err, resultErr)
+					return false
+				}
+
+				return repo.moviePassed.ID == int(movie.ID)
+			}
+			if err := quick.Check(assertion, nil); err != nil {
+				t.Errorf("failed assertion: %v", err)
+			}
+		})
+
+		t.Run("should return error if repository not set in context.", func(t *testing.T) {
+			if err := controller.UpdateMovie(ctx, dtos.UpdateMovieDTO{}); err != controllers.ErrUnsetRespository {
+				t.Errorf("Did not return ErrUnsetRepository when repository was unset, found %v", err)
+			}
+		})
+	})
+
 	t.Run("when executing DeleteMovie", func(t *testing.T) {
 		t.Run("should pass the movie id to the usecase and return its error", func(t *testing.T) {
 			assertion := func(id dtos.MovieID, errString string) bool {
@@ -306,7 +580,7 @@ func TestMessagingController(t *testing.T) {
 				repo := &MockMovieDeleter{errorReturned: err}
 				ctx := context.WithValue(ctx, controllers.RepoKey, repo)
 
-				resultErr := controller.DeleteMovie(ctx, id)
+				resultErr := controller.DeleteMovie(ctx, id, 0)
 
 				if  (err == nil) != (resultErr == nil) {
 					t.Logf("Expected %v error found %v", err, resultErr)
@@ -322,7 +596,7 @@ func TestMessagingController(t *testing.T) {
 
 		t.Run("should return error if repository not set in context.", func(t *testing.T) {
 			assertion := func(id dtos.MovieID) bool {
-				err := controller.DeleteMovie(ctx, id)
+				err := controller.DeleteMovie(ctx, id, 0)
 
 				if err == nil {
 					t.Logf("No error return when checking for repository.")
@@ -349,9 +623,9 @@ type MockMovieSaver struct {
 	errorReturned error
 }
 
-func (repo *MockMovieSaver) Save(ctx context.Context, movie domain.Movie) error {
+func (repo *MockMovieSaver) Save(ctx context.Context, movie domain.Movie, event domain.MovieEvent) (domain.Movie, error) {
 	repo.moviePassed = movie
-	return repo.errorReturned
+	return movie, repo.errorReturned
 }
 
 
@@ -360,9 +634,19 @@ type MockMovieDeleter struct {
 	errorReturned error
 }
 
-func (repo *MockMovieDeleter) Delete(ctx context.Context, id int) error {
+func (repo *MockMovieDeleter) Delete(ctx context.Context, id int, expectedVersion int, event domain.MovieEvent) (domain.Movie, error) {
 	repo.idPassed = id
-	return repo.errorReturned
+	return domain.Movie{ID: id}, repo.errorReturned
+}
+
+type MockMovieUpdater struct {
+	moviePassed domain.Movie
+	errorReturned error
+}
+
+func (repo *MockMovieUpdater) Update(ctx context.Context, movie domain.Movie, expectedVersion int, event domain.MovieEvent) (domain.Movie, error) {
+	repo.moviePassed = movie
+	return movie, repo.errorReturned
 }
 
 
@@ -1,28 +1,120 @@
 package entrypoints
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net"
 	"context"
 	"log"
+	"log/slog"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
 	pb "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/grpc/movies"
 
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/domain"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/ports"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/infra/controllers"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/infra/interceptors"
 )
 
-func NewGRPCEntrypoint(repo ports.MovieQueryRepository, listeningPort int) *GRPCEntrypoint {
+const (
+	// The metadata naming the correlation of a write, recorded in its
+	// event as the one of the messages.
+	CorrelationIDMetadataKey = "x-correlation-id"
+)
+
+type GRPCServerConfig struct {
+	Interceptors interceptors.Config
+	// The largest messages received and sent, in bytes.
+	MaxRecvMsgSize int
+	MaxSendMsgSize int
+	// The connections are closed, after a grace for the calls in flight,
+	// once this old, for the clients to reconnect and spread over the
+	// replicas behind the load balancer. Never when 0.
+	MaxConnectionAge time.Duration
+	// The new calls go to the new connections at once, so the grace only
+	// bounds the streams in flight, as StreamMovies and ExportMovies. It
+	// is long enough for the export of the whole catalogue.
+	MaxConnectionAgeGrace time.Duration
+	// The idle connections are pinged after KeepaliveTime, and closed when
+	// the ping is not answered in KeepaliveTimeout.
+	KeepaliveTime time.Duration
+	KeepaliveTimeout time.Duration
+	// The clients pinging more often are disconnected.
+	KeepaliveMinClientTime time.Duration
+	// Served over TLS, verifying the certificates of the clients when it
+	// has their CAs, or in plaintext when nil.
+	TLS *tls.Config
+	// Lists the services and their messages to the clients, as grpcurl,
+	// so they are called without compiling movies.proto.
+	Reflection bool
+}
+
+func DefaultGRPCServerConfig() GRPCServerConfig {
+	return GRPCServerConfig{
+		Interceptors: interceptors.Config{
+			AccessLog: slog.Default(),
+			DefaultDeadline: 10 * time.Second,
+		},
+		MaxRecvMsgSize: 4 << 20,
+		MaxSendMsgSize: 4 << 20,
+		MaxConnectionAge: 5 * time.Minute,
+		MaxConnectionAgeGrace: time.Hour,
+		KeepaliveTime: time.Minute,
+		KeepaliveTimeout: 20 * time.Second,
+		KeepaliveMinClientTime: 10 * time.Second,
+	}
+}
+
+func (config GRPCServerConfig) serverOptions() []grpc.ServerOption {
+	options := interceptors.Chain(config.Interceptors)
+	if config.TLS != nil {
+		options = append(options, grpc.Creds(credentials.NewTLS(config.TLS)))
+	}
+	return append(
+		options,
+		grpc.MaxRecvMsgSize(config.MaxRecvMsgSize),
+		grpc.MaxSendMsgSize(config.MaxSendMsgSize),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionAge: config.MaxConnectionAge,
+			MaxConnectionAgeGrace: config.MaxConnectionAgeGrace,
+			Time: config.KeepaliveTime,
+			Timeout: config.KeepaliveTimeout,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime: config.KeepaliveMinClientTime,
+			PermitWithoutStream: true,
+		}),
+	)
+}
+
+// Answers the queries from repo and the writes from executor.
+func NewGRPCEntrypoint(
+	repo ports.MovieQueryRepository, executor ports.MovieExecuteRepository, listeningPort int,
+) *GRPCEntrypoint {
 	return &GRPCEntrypoint{
 		listeningPort: listeningPort,
-		server: newGRPCServer(repo, &controllers.GRPCMovieController{}),
+		server: newGRPCServer(repo, executor, &controllers.GRPCMovieController{}),
+		config: DefaultGRPCServerConfig(),
 	}
 }
 
 type GRPCEntrypoint struct {
 	server *gRPCServer
 	listeningPort int
+	config GRPCServerConfig
+	transcodingPort int
+}
+
+// Replaces the default configuration of the server.
+// It must be called before Serve.
+func (entrypoint *GRPCEntrypoint) UseServerConfig(config GRPCServerConfig) {
+	entrypoint.config = config
 }
 
 
@@ -32,9 +124,15 @@ func (entrypoint *GRPCEntrypoint) Serve(ctx context.Context) {
 		panic("movie grpc entrypoint failed to listen to desired port")
 	}
 
-	s := grpc.NewServer()
+	s := grpc.NewServer(entrypoint.config.serverOptions()...)
 
 	pb.RegisterMovieServiceServer(s, entrypoint.server)
+	if entrypoint.config.Reflection {
+		reflection.Register(s)
+	}
+	if entrypoint.transcodingPort != 0 {
+		go entrypoint.serveTranscoding(ctx)
+	}
 	log.Printf("Listening on port %d\n", entrypoint.listeningPort)
 	
 	if err := s.Serve(lis); err != nil {
@@ -43,9 +141,12 @@ func (entrypoint *GRPCEntrypoint) Serve(ctx context.Context) {
 	
 }
 
-func newGRPCServer(repo ports.MovieQueryRepository, controller *controllers.GRPCMovieController) *gRPCServer {
+func newGRPCServer(
+	repo ports.MovieQueryRepository, executor ports.MovieExecuteRepository, controller *controllers.GRPCMovieController,
+) *gRPCServer {
 	return &gRPCServer{
 		repo: repo,
+		executor: executor,
 		controller: controller,
 	}
 }
@@ -54,6 +155,7 @@ type gRPCServer struct {
 	pb.MovieServiceServer
 
 	repo ports.MovieQueryRepository
+	executor ports.MovieExecuteRepository
 	controller *controllers.GRPCMovieController	
 }
 
@@ -67,3 +169,44 @@ func (server *gRPCServer) GetMovies(ctx context.Context, req *pb.GetMoviesReques
 	return server.controller.GetMovies(ctx, req)
 } 
 
+
+func (server *gRPCServer) BatchGetMovies(ctx context.Context, req *pb.BatchGetMoviesRequest) (*pb.MoviesBatch, error) {
+	ctx = context.WithValue(ctx, controllers.RepoKey, server.repo)
+	return server.controller.BatchGetMovies(ctx, req)
+}
+
+func (server *gRPCServer) ExportMovies(req *pb.ExportMoviesRequest, stream pb.MovieService_ExportMoviesServer) error {
+	ctx := context.WithValue(stream.Context(), controllers.RepoKey, server.repo)
+	return server.controller.ExportMovies(ctx, req, stream)
+}
+
+func (server *gRPCServer) StreamMovies(req *pb.StreamMoviesRequest, stream pb.MovieService_StreamMoviesServer) error {
+	ctx := context.WithValue(stream.Context(), controllers.RepoKey, server.repo)
+	return server.controller.StreamMovies(ctx, req, stream)
+}
+
+func (server *gRPCServer) CreateMovie(ctx context.Context, req *pb.CreateMovieRequest) (*pb.Movie, error) {
+	ctx = server.writeContext(ctx)
+	return server.controller.CreateMovie(ctx, req)
+}
+
+func (server *gRPCServer) UpdateMovie(ctx context.Context, req *pb.UpdateMovieRequest) (*pb.Movie, error) {
+	ctx = server.writeContext(ctx)
+	return server.controller.UpdateMovie(ctx, req)
+}
+
+func (server *gRPCServer) DeleteMovie(ctx context.Context, req *pb.DeleteMovieRequest) (*pb.DeleteMovieResponse, error) {
+	ctx = server.writeContext(ctx)
+	return server.controller.DeleteMovie(ctx, req)
+}
+
+// Carries the executor, and the correlation sent by the client for the
+// events of the write.
+func (server *gRPCServer) writeContext(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, controllers.RepoKey, server.executor)
+	var cause domain.EventCause
+	if values := metadata.ValueFromIncomingContext(ctx, CorrelationIDMetadataKey); len(values) > 0 {
+		cause.CorrelationID = values[0]
+	}
+	return domain.WithEventCause(ctx, cause)
+}
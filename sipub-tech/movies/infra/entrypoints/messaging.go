@@ -5,58 +5,114 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/config/tlsconfig"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/messaging/constants"
+	messagingDtos "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/messaging/dtos"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/messaging/rabbitmq"
 	
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/domain"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/dtos"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/ports"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/infra/controllers"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/infra/publishers"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/infra/relays"
 )
 
-func NewMessagingEntrypoint(repo ports.MovieExecuteRepository, connectionUrl string) *MessagingEntrypoint {
+// The events recorded in the outbox are published while serving.
+func NewMessagingEntrypoint(
+	repo ports.MovieExecuteRepository, outbox ports.MovieEventOutbox, connectionUrl string,
+) *MessagingEntrypoint {
 	nodeId := uuid.New().String()
 	return &MessagingEntrypoint{
 		repo: repo,
+		outbox: outbox,
 		client: rabbitmq.NewRabbitMqServer(connectionUrl, nodeId),
 		controller: &controllers.MessagingMovieController{},
+		relayConfig: relays.DefaultConfig(),
 	}
 }
 
-func NewMessagingEntrypointFromClient(repo ports.MovieExecuteRepository, client *rabbitmq.RabbitMqServer)  *MessagingEntrypoint {
+func NewMessagingEntrypointFromClient(
+	repo ports.MovieExecuteRepository, outbox ports.MovieEventOutbox, client *rabbitmq.RabbitMqServer,
+)  *MessagingEntrypoint {
 	return &MessagingEntrypoint{
 		repo: repo,
+		outbox: outbox,
 		client: client,
 		controller: &controllers.MessagingMovieController{},
+		relayConfig: relays.DefaultConfig(),
 	}
 }
 
 type MessagingEntrypoint struct {
 	repo ports.MovieExecuteRepository
+	outbox ports.MovieEventOutbox
 	client *rabbitmq.RabbitMqServer
 	controller *controllers.MessagingMovieController
+	relayConfig relays.Config
+}
+
+// Connects to RabbitMQ over amqps with files. It must be called before
+// Serve.
+func (entrypoint *MessagingEntrypoint) UseTLS(files tlsconfig.Files) error {
+	return entrypoint.client.UseTLS(files)
 }
 
 func (entrypoint *MessagingEntrypoint) Serve(ctx context.Context) {
 	entrypoint.client.Open()
+	publisher := publishers.NewMessagingMovieEventPublisher(entrypoint.client)
+	go relays.NewOutboxRelay(entrypoint.outbox, publisher, entrypoint.relayConfig).Run(ctx)
+	resultsPublisher := publishers.NewMessagingOperationResultsPublisher(entrypoint.client)
 
-	entrypoint.client.RegisterConsumer(constants.MovieCreatorQueueName, nil, nil, func(ctx context.Context, body any) error {
+	rabbitmq.RegisterTypedConsumer(entrypoint.client, constants.MovieCreatorQueueName, nil, nil, func(ctx context.Context, dto dtos.CreateMovieDTO) error {
 		ctx = context.WithValue(ctx, controllers.RepoKey, entrypoint.repo)
-		dto, err := entrypoint.parseCreateDtoMap(body)
-		if err != nil {
-			return fmt.Errorf("couldn't parse body %+v to CreateMovieDTO", body)
+		ctx = domain.WithEventCause(ctx, entrypoint.eventCause(ctx))
+		if err := dto.Validate(); err != nil {
+			return refuse(dto, err)
 		}
 
-		return entrypoint.controller.SaveMovie(ctx, *dto)
+		return entrypoint.controller.SaveMovie(ctx, dto)
+	})
+
+	rabbitmq.RegisterTypedConsumer(entrypoint.client, constants.MovieUpdaterQueueName, nil, nil, func(ctx context.Context, dto dtos.UpdateMovieDTO) error {
+		ctx = context.WithValue(ctx, controllers.RepoKey, entrypoint.repo)
+		ctx = domain.WithEventCause(ctx, entrypoint.eventCause(ctx))
+		if err := dto.Validate(); err != nil {
+			return refuse(dto, err)
+		}
+
+		return entrypoint.controller.UpdateMovie(ctx, dto)
+	})
+
+	rabbitmq.RegisterTypedConsumer(entrypoint.client, constants.MovieDeleterQueueName, nil, nil, func(ctx context.Context, body deleteMovieBody) error {
+		ctx = context.WithValue(ctx, controllers.RepoKey, entrypoint.repo)
+		ctx = domain.WithEventCause(ctx, entrypoint.eventCause(ctx))
+
+		return entrypoint.controller.DeleteMovie(ctx, body.ID, body.Version)
 	})
 
-	entrypoint.client.RegisterConsumer(constants.MovieDeleterQueueName, nil, nil, func(ctx context.Context, body any) error {
+	// The items of the batches are validated one by one by the controller,
+	// only the batch itself is refused here.
+	rabbitmq.RegisterTypedConsumer(entrypoint.client, constants.MovieBulkCreatorQueueName, nil, nil, func(ctx context.Context, batch dtos.BulkCreateMoviesDTO) error {
 		ctx = context.WithValue(ctx, controllers.RepoKey, entrypoint.repo)
-		idMap, ok := body.(map[string]any)
-		if !ok {
-			return fmt.Errorf("couldn't parse body %+v to a map with an id", body)
+		ctx = context.WithValue(ctx, controllers.PublisherKey, resultsPublisher)
+		ctx = domain.WithEventCause(ctx, entrypoint.eventCause(ctx))
+		if err := batch.Validate(); err != nil {
+			return refuse(batch, err)
 		}
 
-		return entrypoint.controller.DeleteMovie(ctx, dtos.MovieID(idMap["id"].(float64)))
+		return entrypoint.controller.SaveMovies(ctx, batch)
+	})
+
+	rabbitmq.RegisterTypedConsumer(entrypoint.client, constants.MovieBulkDeleterQueueName, nil, nil, func(ctx context.Context, batch dtos.BulkDeleteMoviesDTO) error {
+		ctx = context.WithValue(ctx, controllers.RepoKey, entrypoint.repo)
+		ctx = context.WithValue(ctx, controllers.PublisherKey, resultsPublisher)
+		ctx = domain.WithEventCause(ctx, entrypoint.eventCause(ctx))
+		if err := batch.Validate(); err != nil {
+			return refuse(batch, err)
+		}
+
+		return entrypoint.controller.DeleteMovies(ctx, batch)
 	})
 
 	entrypoint.client.Listen(ctx)
@@ -71,22 +127,25 @@ func (entrypoint *MessagingEntrypoint) GetClient() *rabbitmq.RabbitMqServer {
 	return entrypoint.client
 }
 
-func (entrypoint *MessagingEntrypoint) parseCreateDtoMap(rawDto any) (*dtos.CreateMovieDTO, error) {
-	dtoMap, ok := rawDto.(map[string]any)
-	if !ok {
-		return nil, fmt.Errorf("couldn't parse body %+v to a map", rawDto)
+// The events raised by a message share its correlation id and are caused
+// by it.
+func (entrypoint *MessagingEntrypoint) eventCause(ctx context.Context) domain.EventCause {
+	metadata, _ := ctx.Value(rabbitmq.MetadataKey).(messagingDtos.MessageMetadata)
+	return domain.EventCause{
+		CorrelationID: metadata.CorrelationId,
+		CausationID: metadata.MessageId,
 	}
+}
 
-	title, ok := dtoMap["title"]
-	if !ok {
-		return nil, fmt.Errorf("raw DTO did not have title key")
-	}
-	year, ok := dtoMap["year"]
-	if !ok {
-		return nil, fmt.Errorf("raw DTO did not have year key")
-	}
-	return &dtos.CreateMovieDTO{
-		Title: title.(string),
-		Year: year.(string),
-	}, nil
+// The id of the movie to delete. The version is optional, messages
+// without it are not conditioned to any version.
+type deleteMovieBody struct {
+	ID dtos.MovieID  `json:"id"`
+	Version int      `json:"version"`
+}
+
+// Invalid messages are never going to be handled, so they are refused as
+// poison instead of acknowledged.
+func refuse(body any, err error) error {
+	return fmt.Errorf("%w: refusing body %+v: %v", rabbitmq.ErrPoisonMessage, body, err)
 }
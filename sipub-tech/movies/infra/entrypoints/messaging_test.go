@@ -7,7 +7,9 @@ import (
     "context"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"math/rand"
 	"time"
@@ -17,6 +19,7 @@ import (
     "github.com/testcontainers/testcontainers-go"
     "github.com/testcontainers/testcontainers-go/wait"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/messaging/constants"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/messaging/rabbitmq"
 
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/dtos"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/domain"
@@ -62,19 +65,35 @@ func TestMessagingEntrypoint(t *testing.T) {
 
 
 	repository := &MockMovieExecuteRepository{}
-	entrypoint := entrypoints.NewMessagingEntrypoint(repository, connectionUrl)
+	entrypoint := entrypoints.NewMessagingEntrypoint(repository, repository, connectionUrl)
 	client := entrypoint.GetClient()
 
 	t.Run("should be able to create and delete a repository.", func (t *testing.T) {
 		createDto := dtos.CreateMovieDTO{
 			Title: faker.Sentence(),
-			Year: faker.YearString(),
+			Year: strconv.Itoa(dtos.MinimumMovieYear + rand.Intn(time.Now().Year() - dtos.MinimumMovieYear + 1)),
 		}
 		id := rand.Int31()
 
 		entrypoint.Serve(ctx)
 		defer entrypoint.Close()
 
+		changes := make(chan any, 10)
+		subscriber := rabbitmq.NewRabbitMqServer(connectionUrl, "subscriber")
+		subscriber.Open()
+		defer subscriber.Close()
+		subscriber.RegisterExchangeConsumer(
+			constants.MovieEventsExchangeName,
+			rabbitmq.StandardExchangeConfig(rabbitmq.TopicExchange),
+			constants.AllMovieEventsBindingKey,
+			nil,
+			func(ctx context.Context, body any) error {
+				changes <- body
+				return nil
+			},
+		)
+		subscriber.Listen(ctx)
+
 		expected := domain.Movie{
 			Title: createDto.Title,
 			Year: createDto.Year,
@@ -108,25 +127,98 @@ func TestMessagingEntrypoint(t *testing.T) {
 		} else {
 			logSuccess(t, test)
 		}
-		
-		
+
+		test = "The events should have been published with their cause"
+		logTest(t, test)
+		received := map[string]bool{}
+		for len(changes) > 0 {
+			event, _ := (<-changes).(map[string]any)
+			if event["correlation_id"] == "" || event["causation_id"] == "" {
+				logError(t, "Event without cause: %+v", event)
+			}
+			received[fmt.Sprint(event["type"])] = true
+		}
+		if !received[string(domain.MovieCreated)] || !received[string(domain.MovieDeleted)] {
+			logError(t, "Expected created and deleted events, Got: %+v", received)
+		} else {
+			logSuccess(t, test)
+		}
 	})
 }
 
+// Records the events of the writes in an outbox kept in memory.
 type MockMovieExecuteRepository struct {
 	MoviePassed domain.Movie
 	IdPassed int
 	ErrorReturned error
+
+	mutex sync.Mutex
+	pending []domain.MovieEvent
+}
+
+func (repo *MockMovieExecuteRepository) Save(ctx context.Context, movie domain.Movie, event domain.MovieEvent) (domain.Movie, error) {
+	repo.MoviePassed = movie
+	repo.record(event, movie)
+	return movie, repo.ErrorReturned
 }
 
-func (repo *MockMovieExecuteRepository) Save(ctx context.Context, movie domain.Movie) error {
+func (repo *MockMovieExecuteRepository) Update(ctx context.Context, movie domain.Movie, expectedVersion int, event domain.MovieEvent) (domain.Movie, error) {
 	repo.MoviePassed = movie
-	return repo.ErrorReturned
+	repo.record(event, movie)
+	return movie, repo.ErrorReturned
 }
 
-func (repo *MockMovieExecuteRepository) Delete(ctx context.Context, id int) error {
+func (repo *MockMovieExecuteRepository) Delete(ctx context.Context, id int, expectedVersion int, event domain.MovieEvent) (domain.Movie, error) {
 	repo.IdPassed = id
-	return repo.ErrorReturned
+	repo.record(event, domain.Movie{ID: id})
+	return domain.Movie{ID: id}, repo.ErrorReturned
+}
+
+func (repo *MockMovieExecuteRepository) SaveBatch(ctx context.Context, movies []domain.Movie, events []domain.MovieEvent) ([]domain.Movie, []error) {
+	for index, movie := range movies {
+		repo.MoviePassed = movie
+		repo.record(events[index], movie)
+	}
+	return movies, make([]error, len(movies))
+}
+
+func (repo *MockMovieExecuteRepository) DeleteBatch(ctx context.Context, ids []int, events []domain.MovieEvent) ([]domain.Movie, []error) {
+	deleted := make([]domain.Movie, len(ids))
+	for index, id := range ids {
+		repo.IdPassed = id
+		deleted[index] = domain.Movie{ID: id}
+		repo.record(events[index], deleted[index])
+	}
+	return deleted, make([]error, len(ids))
+}
+
+func (repo *MockMovieExecuteRepository) record(event domain.MovieEvent, movie domain.Movie) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	event.Movie = movie
+	repo.pending = append(repo.pending, event)
+}
+
+func (repo *MockMovieExecuteRepository) GetPendingEvents(ctx context.Context, limit int) ([]domain.MovieEvent, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	return append([]domain.MovieEvent{}, repo.pending[:min(limit, len(repo.pending))]...), nil
+}
+
+func (repo *MockMovieExecuteRepository) MarkEventSent(ctx context.Context, eventID string) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+	for index, event := range repo.pending {
+		if event.ID == eventID {
+			repo.pending = append(repo.pending[:index:index], repo.pending[index+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (repo *MockMovieExecuteRepository) MarkEventFailed(ctx context.Context, eventID string, cause error) error {
+	return nil
 }
 
 func insertAuthInfo(endpoint, authInfo string) string {
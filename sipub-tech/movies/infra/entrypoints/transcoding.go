@@ -0,0 +1,115 @@
+package entrypoints
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/textproto"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protojson"
+	pb "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/grpc/movies"
+)
+
+// The header of the HTTP requests naming the correlation of a write, as
+// CorrelationIDMetadataKey of the gRPC calls.
+const CorrelationIDHeader = "X-Correlation-Id"
+
+// How long the requests in flight have to end once the context of Serve
+// is done.
+const transcodingShutdownTimeout = 10 * time.Second
+
+// Answers the routes annotated in movies.proto, under /internal/v1, with
+// JSON, calling the methods through client. The fields are named as in
+// movies.proto, and the streams answer a JSON object per line.
+func NewTranscodingHandler(ctx context.Context, client pb.MovieServiceClient) (http.Handler, error) {
+	mux := runtime.NewServeMux(
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions: protojson.MarshalOptions{UseProtoNames: true},
+			UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+		}),
+		runtime.WithIncomingHeaderMatcher(func(header string) (string, bool) {
+			if textproto.CanonicalMIMEHeaderKey(header) == CorrelationIDHeader {
+				return CorrelationIDMetadataKey, true
+			}
+			return runtime.DefaultHeaderMatcher(header)
+		}),
+	)
+	if err := pb.RegisterMovieServiceHandlerClient(ctx, mux, client); err != nil {
+		return nil, fmt.Errorf("failed registering the transcoding: %w", err)
+	}
+	return mux, nil
+}
+
+// Serves the transcoding on its own port, over TLS as the gRPC server,
+// until the context is done.
+// The calls are made to a plaintext copy of the gRPC server held in
+// memory, so they go through the same interceptors without presenting a
+// client certificate.
+func (entrypoint *GRPCEntrypoint) serveTranscoding(ctx context.Context) {
+	internalConfig := entrypoint.config
+	internalConfig.TLS = nil
+	internal := grpc.NewServer(internalConfig.serverOptions()...)
+	pb.RegisterMovieServiceServer(internal, entrypoint.server)
+	memory := bufconn.Listen(1 << 20)
+	go internal.Serve(memory)
+	defer internal.Stop()
+
+	conn, err := grpc.NewClient(
+		"passthrough:///movies",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return memory.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		log.Printf("Failed connecting the transcoding: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	handler, err := NewTranscodingHandler(ctx, pb.NewMovieServiceClient(conn))
+	if err != nil {
+		log.Printf("Failed starting the transcoding: %v", err)
+		return
+	}
+	lis, err := net.Listen("tcp", fmt.Sprintf("0.0.0.0:%d", entrypoint.transcodingPort))
+	if err != nil {
+		log.Printf("The transcoding failed to listen on port %d: %v", entrypoint.transcodingPort, err)
+		return
+	}
+	if entrypoint.config.TLS != nil {
+		lis = tls.NewListener(lis, entrypoint.config.TLS)
+	}
+	// No write timeout, as the streams answer the whole catalogue.
+	server := &http.Server{
+		Handler: handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout: time.Minute,
+		IdleTimeout: 2 * time.Minute,
+	}
+	stop := context.AfterFunc(ctx, func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), transcodingShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			server.Close()
+		}
+	})
+	defer stop()
+	log.Printf("Serving the transcoding on port %d\n", entrypoint.transcodingPort)
+	if err := server.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("The transcoding stopped: %v", err)
+	}
+}
+
+// Serves the methods as JSON over HTTP on port as well, as
+// NewTranscodingHandler. It must be called before Serve.
+func (entrypoint *GRPCEntrypoint) UseTranscoding(port int) {
+	entrypoint.transcodingPort = port
+}
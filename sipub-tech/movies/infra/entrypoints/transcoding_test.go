@@ -0,0 +1,150 @@
+package entrypoints_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/grpc/movies"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/infra/entrypoints"
+)
+
+func TestTranscodingHandler(t *testing.T) {
+	server := &FakeMovieServer{}
+	handler, err := entrypoints.NewTranscodingHandler(context.Background(), serve(t, server))
+	if err != nil {
+		t.Fatalf("Error found when building the handler %v", err)
+	}
+
+	t.Run("should answer a movie by its id", func(t *testing.T) {
+		response := request(handler, "GET", "/internal/v1/movies/7", "", nil)
+		if response.Code != http.StatusOK {
+			t.Fatalf("Expected 200, found %d: %s", response.Code, response.Body)
+		}
+		movie := map[string]any{}
+		if err := json.Unmarshal(response.Body.Bytes(), &movie); err != nil {
+			t.Fatalf("Malformed movie %s: %v", response.Body, err)
+		}
+		if movie["id"] != "7" || movie["title"] != "Heat" {
+			t.Errorf("Unexpected movie %v", movie)
+		}
+	})
+
+	t.Run("should answer the status of the call as the HTTP status", func(t *testing.T) {
+		response := request(handler, "GET", "/internal/v1/movies/404", "", nil)
+		if response.Code != http.StatusNotFound {
+			t.Errorf("Expected 404, found %d: %s", response.Code, response.Body)
+		}
+	})
+
+	t.Run("should take the query and the body, named as in movies.proto", func(t *testing.T) {
+		response := request(handler, "GET", "/internal/v1/movies?year=1995&limit=2", "", nil)
+		if response.Code != http.StatusOK || server.listed.GetYear() != "1995" || server.listed.GetLimit() != 2 {
+			t.Errorf("Unexpected listing %d %s for %v", response.Code, response.Body, server.listed)
+		}
+
+		response = request(handler, "PUT", "/internal/v1/movies/3", `{"title": "Fargo", "year": "1996", "expected_version": 2}`, map[string]string{
+			entrypoints.CorrelationIDHeader: "tools-1",
+		})
+		if response.Code != http.StatusOK {
+			t.Fatalf("Expected 200, found %d: %s", response.Code, response.Body)
+		}
+		if server.updated.GetId() != 3 || server.updated.GetTitle() != "Fargo" || server.updated.GetExpectedVersion() != 2 {
+			t.Errorf("Unexpected update %v", server.updated)
+		}
+		if server.correlationID != "tools-1" {
+			t.Errorf("Expected the correlation id of the header, found %q", server.correlationID)
+		}
+	})
+
+	t.Run("should stream the movies a JSON object per line", func(t *testing.T) {
+		response := request(handler, "GET", "/internal/v1/movies:stream?year=1995", "", nil)
+		lines := 0
+		scanner := bufio.NewScanner(response.Body)
+		for scanner.Scan() {
+			lines++
+		}
+		if response.Code != http.StatusOK || lines != 2 {
+			t.Errorf("Expected 2 movies, found %d with %d: %s", lines, response.Code, response.Body)
+		}
+	})
+}
+
+func request(handler http.Handler, method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, req)
+	return response
+}
+
+// Records the requests, answering Heat for every id but 404.
+type FakeMovieServer struct {
+	pb.UnimplementedMovieServiceServer
+
+	listed        *pb.GetMoviesRequest
+	updated       *pb.UpdateMovieRequest
+	correlationID string
+}
+
+func (server *FakeMovieServer) GetMovie(ctx context.Context, req *pb.GetMovieRequest) (*pb.Movie, error) {
+	if req.Id == 404 {
+		return nil, status.Error(codes.NotFound, "movie not found")
+	}
+	return &pb.Movie{Id: req.Id, Title: "Heat", Year: "1995", Version: 1}, nil
+}
+
+func (server *FakeMovieServer) GetMovies(ctx context.Context, req *pb.GetMoviesRequest) (*pb.Movies, error) {
+	server.listed = req
+	return &pb.Movies{Movies: []*pb.Movie{{Id: 1, Title: "Heat", Year: req.Year}}}, nil
+}
+
+func (server *FakeMovieServer) UpdateMovie(ctx context.Context, req *pb.UpdateMovieRequest) (*pb.Movie, error) {
+	server.updated = req
+	if values := metadata.ValueFromIncomingContext(ctx, entrypoints.CorrelationIDMetadataKey); len(values) > 0 {
+		server.correlationID = values[0]
+	}
+	return &pb.Movie{Id: req.Id, Title: req.Title, Year: req.Year, Version: req.ExpectedVersion + 1}, nil
+}
+
+func (server *FakeMovieServer) StreamMovies(req *pb.StreamMoviesRequest, stream pb.MovieService_StreamMoviesServer) error {
+	if err := stream.Send(&pb.Movie{Id: 1, Year: req.Year}); err != nil {
+		return err
+	}
+	return stream.Send(&pb.Movie{Id: 2, Year: req.Year})
+}
+
+// Serves server in memory.
+func serve(t *testing.T, server pb.MovieServiceServer) pb.MovieServiceClient {
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	pb.RegisterMovieServiceServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed connecting to the server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewMovieServiceClient(conn)
+}
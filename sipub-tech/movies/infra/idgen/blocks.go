@@ -0,0 +1,62 @@
+package idgen
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/ports"
+)
+
+const (
+	DefaultBlockSize = 100
+)
+
+// Hands out the ids of blocks leased from the shared counter, leasing the
+// next block once the current one runs out. Each replica leases its own
+// blocks, so the counter is only written once per block.
+//
+// The counter is advanced before any id of a block is handed out, so a
+// restart never reuses ids: the ids left in the block are skipped.
+func NewBlockGenerator(leaser ports.IdBlockLeaser, blockSize int) *BlockGenerator {
+	return &BlockGenerator{
+		leaser: leaser,
+		blockSize: max(blockSize, 1),
+		next: 1,
+	}
+}
+
+type BlockGenerator struct {
+	ports.IdGenerator
+
+	leaser ports.IdBlockLeaser
+	blockSize int
+
+	mutex sync.Mutex
+	// The ids left in the current block are next to last.
+	next int
+	last int
+}
+
+// Requests larger than a block lease a block of their size.
+func (generator *BlockGenerator) NextIds(ctx context.Context, count int) ([]int, error) {
+	generator.mutex.Lock()
+	defer generator.mutex.Unlock()
+
+	ids := make([]int, 0, max(count, 0))
+	for len(ids) < count {
+		if generator.next > generator.last {
+			size := max(generator.blockSize, count - len(ids))
+			last, err := generator.leaser.LeaseIds(ctx, size)
+			if err != nil {
+				return nil, fmt.Errorf("error leasing a block of %d ids: %w", size, err)
+			}
+			generator.next, generator.last = last - size + 1, last
+		}
+
+		for ; generator.next <= generator.last && len(ids) < count; generator.next++ {
+			ids = append(ids, generator.next)
+		}
+	}
+	return ids, nil
+}
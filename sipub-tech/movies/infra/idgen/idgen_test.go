@@ -0,0 +1,153 @@
+package idgen_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/infra/idgen"
+)
+
+func TestBlockGenerator(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should hand out the ids of a block before leasing the next one", func(t *testing.T) {
+		leaser := &FakeIdBlockLeaser{counter: 1}
+		generator := idgen.NewBlockGenerator(leaser, 3)
+
+		first, err := generator.NextIds(ctx, 2)
+		require.NoError(t, err)
+		second, err := generator.NextIds(ctx, 2)
+		require.NoError(t, err)
+
+		assert.Equal(t, []int{2, 3}, first)
+		assert.Equal(t, []int{4, 5}, second)
+		assert.Equal(t, []int{3, 3}, leaser.leases)
+	})
+
+	t.Run("should lease a block of the size of the larger requests", func(t *testing.T) {
+		leaser := &FakeIdBlockLeaser{}
+		generator := idgen.NewBlockGenerator(leaser, 2)
+
+		ids, err := generator.NextIds(ctx, 5)
+		require.NoError(t, err)
+
+		assert.Equal(t, []int{1, 2, 3, 4, 5}, ids)
+		assert.Equal(t, []int{5}, leaser.leases)
+	})
+
+	t.Run("should skip the rest of a block on restart", func(t *testing.T) {
+		leaser := &FakeIdBlockLeaser{}
+		_, err := idgen.NewBlockGenerator(leaser, 10).NextIds(ctx, 1)
+		require.NoError(t, err)
+
+		ids, err := idgen.NewBlockGenerator(leaser, 10).NextIds(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []int{11}, ids)
+	})
+
+	t.Run("should return the error of the lease", func(t *testing.T) {
+		leaser := &FakeIdBlockLeaser{errorReturned: fmt.Errorf("throttled")}
+
+		_, err := idgen.NewBlockGenerator(leaser, 10).NextIds(ctx, 1)
+		assert.ErrorIs(t, err, leaser.errorReturned)
+	})
+
+	t.Run("should never hand out an id twice across goroutines", func(t *testing.T) {
+		generator := idgen.NewBlockGenerator(&FakeIdBlockLeaser{}, 7)
+		assertUniqueIds(t, generator.NextIds)
+	})
+}
+
+func TestSnowflakeGenerator(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should refuse nodes out of range", func(t *testing.T) {
+		for _, node := range []int{-1, idgen.MaxSnowflakeNode + 1} {
+			_, err := idgen.NewSnowflakeGenerator(node)
+			assert.Error(t, err, "node %d", node)
+		}
+	})
+
+	t.Run("should generate increasing positive ids within 53 bits, carrying the node", func(t *testing.T) {
+		generator, err := idgen.NewSnowflakeGenerator(5)
+		require.NoError(t, err)
+
+		ids, err := generator.NextIds(ctx, 1000)
+		require.NoError(t, err)
+		require.Len(t, ids, 1000)
+		for index, id := range ids {
+			assert.Positive(t, id)
+			assert.Less(t, id, 1 << 53)
+			assert.Equal(t, 5, id >> 6 & idgen.MaxSnowflakeNode, "node of id %d", id)
+			if index > 0 {
+				assert.Greater(t, id, ids[index-1])
+			}
+		}
+	})
+
+	t.Run("should generate different ids on different nodes", func(t *testing.T) {
+		first, _ := idgen.NewSnowflakeGenerator(1)
+		second, _ := idgen.NewSnowflakeGenerator(2)
+
+		firstIds, _ := first.NextIds(ctx, 100)
+		secondIds, _ := second.NextIds(ctx, 100)
+
+		seen := make(map[int]bool)
+		for _, id := range append(firstIds, secondIds...) {
+			assert.False(t, seen[id], "id %d repeated", id)
+			seen[id] = true
+		}
+	})
+
+	t.Run("should never hand out an id twice across goroutines", func(t *testing.T) {
+		generator, _ := idgen.NewSnowflakeGenerator(0)
+		assertUniqueIds(t, generator.NextIds)
+	})
+}
+
+func assertUniqueIds(t *testing.T, nextIds func(ctx context.Context, count int) ([]int, error)) {
+	var mutex sync.Mutex
+	var group sync.WaitGroup
+	seen := make(map[int]bool)
+	for goroutine := range 8 {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			for count := range 20 {
+				ids, err := nextIds(context.Background(), goroutine + count % 5)
+				assert.NoError(t, err)
+				mutex.Lock()
+				for _, id := range ids {
+					assert.False(t, seen[id], "id %d repeated", id)
+					seen[id] = true
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	group.Wait()
+}
+
+// Leases from an in-memory counter, recording the size of each lease.
+type FakeIdBlockLeaser struct {
+	mutex sync.Mutex
+	counter int
+	leases []int
+	errorReturned error
+}
+
+func (leaser *FakeIdBlockLeaser) LeaseIds(ctx context.Context, count int) (int, error) {
+	leaser.mutex.Lock()
+	defer leaser.mutex.Unlock()
+	if leaser.errorReturned != nil {
+		return 0, leaser.errorReturned
+	}
+	leaser.counter += count
+	leaser.leases = append(leaser.leases, count)
+	return leaser.counter, nil
+}
@@ -0,0 +1,76 @@
+package idgen
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/ports"
+)
+
+// The ids are made of the milliseconds since SnowflakeEpoch, the node and
+// a sequence within the millisecond, in 41, 6 and 6 bits, so they fit in
+// the 53 bits a JSON number holds exactly.
+const (
+	snowflakeNodeBits = 6
+	snowflakeSequenceBits = 6
+
+	MaxSnowflakeNode = 1 << snowflakeNodeBits - 1
+	maxSnowflakeSequence = 1 << snowflakeSequenceBits - 1
+)
+
+var (
+	SnowflakeEpoch = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// Generates the ids locally, without touching the database. Each replica
+// must be given its own node, from 0 to MaxSnowflakeNode.
+//
+// When the sequence of a millisecond runs out, or the clock goes back, the
+// ids borrow from the following milliseconds, so they keep increasing. A
+// replica restarted before its clock catches up with the ids it handed out
+// could repeat them, so the clocks must be kept in sync.
+func NewSnowflakeGenerator(node int) (*SnowflakeGenerator, error) {
+	if node < 0 || node > MaxSnowflakeNode {
+		return nil, fmt.Errorf("snowflake node %d out of range [0, %d]", node, MaxSnowflakeNode)
+	}
+	return &SnowflakeGenerator{node: node, sequence: maxSnowflakeSequence}, nil
+}
+
+type SnowflakeGenerator struct {
+	ports.IdGenerator
+
+	node int
+
+	mutex sync.Mutex
+	lastMillis int64
+	sequence int
+}
+
+func (generator *SnowflakeGenerator) NextIds(ctx context.Context, count int) ([]int, error) {
+	generator.mutex.Lock()
+	defer generator.mutex.Unlock()
+
+	ids := make([]int, 0, max(count, 0))
+	for range count {
+		millis := time.Since(SnowflakeEpoch).Milliseconds()
+		switch {
+		case millis > generator.lastMillis:
+			generator.lastMillis = millis
+			generator.sequence = 0
+		case generator.sequence < maxSnowflakeSequence:
+			generator.sequence++
+		default:
+			generator.lastMillis++
+			generator.sequence = 0
+		}
+
+		ids = append(ids, int(
+			generator.lastMillis << (snowflakeNodeBits + snowflakeSequenceBits) |
+			int64(generator.node) << snowflakeSequenceBits |
+			int64(generator.sequence),
+		))
+	}
+	return ids, nil
+}
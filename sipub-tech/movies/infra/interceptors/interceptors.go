@@ -0,0 +1,63 @@
+// Package interceptors holds the interceptors of the gRPC server of the
+// movie service. Each is given for both the unary and the streaming RPCs,
+// and Chain orders them, the access logs first, so they record the status
+// the others answer, such as the Internal of a recovered panic.
+package interceptors
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+type Config struct {
+	// Logs every call, when set.
+	AccessLog *slog.Logger
+	// The deadline of the unary calls sent without one, none when 0. The
+	// streams, which may carry the whole catalogue, are left unbounded.
+	DefaultDeadline time.Duration
+}
+
+// The interceptors of the unary calls, in the order they run.
+func (config Config) Unary() []grpc.UnaryServerInterceptor {
+	var chain []grpc.UnaryServerInterceptor
+	if config.AccessLog != nil {
+		chain = append(chain, UnaryAccessLog(config.AccessLog))
+	}
+	chain = append(chain, UnaryRecovery())
+	if config.DefaultDeadline > 0 {
+		chain = append(chain, UnaryDefaultDeadline(config.DefaultDeadline))
+	}
+	return append(chain, UnaryValidation())
+}
+
+// The interceptors of the streams, in the order they run.
+func (config Config) Stream() []grpc.StreamServerInterceptor {
+	var chain []grpc.StreamServerInterceptor
+	if config.AccessLog != nil {
+		chain = append(chain, StreamAccessLog(config.AccessLog))
+	}
+	return append(chain, StreamRecovery(), StreamValidation())
+}
+
+// The server options chaining the interceptors of the config.
+func Chain(config Config) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(config.Unary()...),
+		grpc.ChainStreamInterceptor(config.Stream()...),
+	}
+}
+
+// Sets the deadline of the calls sent without one.
+func UnaryDefaultDeadline(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,193 @@
+package interceptors_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/grpc/movies"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/infra/interceptors"
+)
+
+func TestChain(t *testing.T) {
+	logs := &bytes.Buffer{}
+	server := &FakeMovieServer{}
+	client := serve(t, server, interceptors.Config{
+		AccessLog: slog.New(slog.NewJSONHandler(logs, nil)),
+		DefaultDeadline: time.Minute,
+	})
+
+	t.Run("should answer a panic of the handler with Internal and keep serving", func(t *testing.T) {
+		logs.Reset()
+		_, err := client.GetMovie(context.Background(), &pb.GetMovieRequest{Id: panickingId})
+		if status.Code(err) != codes.Internal {
+			t.Errorf("Expected Internal for a panic, found %v", err)
+		}
+		if entry := lastLog(t, logs); entry["code"] != codes.Internal.String() || entry["level"] != "WARN" {
+			t.Errorf("Expected the access log to record the Internal as a warning, found %v", entry)
+		}
+
+		if _, err := client.GetMovie(context.Background(), &pb.GetMovieRequest{Id: 1}); err != nil {
+			t.Errorf("The server did not keep serving after the panic: %v", err)
+		}
+	})
+
+	t.Run("should set the default deadline of the calls sent without one", func(t *testing.T) {
+		if _, err := client.GetMovie(context.Background(), &pb.GetMovieRequest{Id: 1}); err != nil {
+			t.Fatalf("Error found when getting movie %v", err)
+		}
+		if remaining := time.Until(server.deadline); remaining <= 0 || remaining > time.Minute {
+			t.Errorf("Expected the deadline within a minute, found %v", server.deadline)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2 * time.Hour)
+		defer cancel()
+		if _, err := client.GetMovie(ctx, &pb.GetMovieRequest{Id: 1}); err != nil {
+			t.Fatalf("Error found when getting movie %v", err)
+		}
+		if remaining := time.Until(server.deadline); remaining <= time.Minute {
+			t.Errorf("The deadline of the client was replaced, found %v", server.deadline)
+		}
+	})
+
+	t.Run("should refuse the invalid requests before the handler", func(t *testing.T) {
+		server.calls = 0
+		logs.Reset()
+		_, err := client.GetMovie(context.Background(), &pb.GetMovieRequest{Id: -1})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument, found %v", err)
+		}
+		if entry := lastLog(t, logs); entry["code"] != codes.InvalidArgument.String() || entry["level"] != "INFO" {
+			t.Errorf("Expected the access log to record the InvalidArgument, found %v", entry)
+		}
+
+		stream, err := client.StreamMovies(context.Background(), &pb.StreamMoviesRequest{Year: "95"})
+		if err != nil {
+			t.Fatalf("Error found when opening the stream %v", err)
+		}
+		if _, err := stream.Recv(); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for the stream, found %v", err)
+		}
+		if server.calls != 0 {
+			t.Errorf("The handlers were called %d times for invalid requests", server.calls)
+		}
+	})
+
+	t.Run("should log the messages sent by the streams", func(t *testing.T) {
+		logs.Reset()
+		stream, err := client.StreamMovies(context.Background(), &pb.StreamMoviesRequest{Year: "1995"})
+		if err != nil {
+			t.Fatalf("Error found when opening the stream %v", err)
+		}
+		for {
+			if _, err := stream.Recv(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("Error found when streaming %v", err)
+			}
+		}
+
+		entry := lastLog(t, logs)
+		if entry["method"] != pb.MovieService_StreamMovies_FullMethodName || entry["sent"] != 2.0 || entry["received"] != 1.0 {
+			t.Errorf("Unexpected access log of the stream %v", entry)
+		}
+	})
+
+	t.Run("should end a panicking stream with Internal", func(t *testing.T) {
+		stream, err := client.StreamMovies(context.Background(), &pb.StreamMoviesRequest{Cursor: panickingId})
+		if err != nil {
+			t.Fatalf("Error found when opening the stream %v", err)
+		}
+		for {
+			if _, err = stream.Recv(); err != nil {
+				break
+			}
+		}
+		if status.Code(err) != codes.Internal {
+			t.Errorf("Expected Internal for a panic, found %v", err)
+		}
+	})
+}
+
+func TestConfig(t *testing.T) {
+	t.Run("should leave out the access logs and the deadlines when unset", func(t *testing.T) {
+		config := interceptors.Config{}
+		if unary, stream := len(config.Unary()), len(config.Stream()); unary != 2 || stream != 2 {
+			t.Errorf("Expected the recovery and the validation only, found %d unary and %d stream interceptors", unary, stream)
+		}
+	})
+}
+
+// The id panicking the handlers of FakeMovieServer.
+const panickingId = 13
+
+// Records the calls, answering the movie of the id, or two movies down the
+// streams.
+type FakeMovieServer struct {
+	pb.UnimplementedMovieServiceServer
+
+	calls    int
+	deadline time.Time
+}
+
+func (server *FakeMovieServer) GetMovie(ctx context.Context, req *pb.GetMovieRequest) (*pb.Movie, error) {
+	server.calls++
+	server.deadline, _ = ctx.Deadline()
+	if req.Id == panickingId {
+		var movies map[int64]*pb.Movie
+		movies[req.Id] = &pb.Movie{}
+	}
+	return &pb.Movie{Id: req.Id}, nil
+}
+
+func (server *FakeMovieServer) StreamMovies(req *pb.StreamMoviesRequest, stream pb.MovieService_StreamMoviesServer) error {
+	server.calls++
+	if err := stream.Send(&pb.Movie{Id: 1}); err != nil {
+		return err
+	}
+	if req.Cursor == panickingId {
+		panic("stream broke")
+	}
+	return stream.Send(&pb.Movie{Id: 2})
+}
+
+// Serves server with the interceptors of config in memory.
+func serve(t *testing.T, server pb.MovieServiceServer, config interceptors.Config) pb.MovieServiceClient {
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer(interceptors.Chain(config)...)
+	pb.RegisterMovieServiceServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed connecting to the server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewMovieServiceClient(conn)
+}
+
+func lastLog(t *testing.T, logs *bytes.Buffer) map[string]any {
+	lines := bytes.Split(bytes.TrimSpace(logs.Bytes()), []byte("\n"))
+	entry := map[string]any{}
+	if err := json.Unmarshal(lines[len(lines) - 1], &entry); err != nil {
+		t.Fatalf("Malformed access log %q: %v", logs.String(), err)
+	}
+	return entry
+}
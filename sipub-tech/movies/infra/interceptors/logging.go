@@ -0,0 +1,90 @@
+package interceptors
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Logs each call once it is answered, with its method, status code,
+// duration and peer, at the warning level for the server errors.
+func UnaryAccessLog(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		response, err := handler(ctx, req)
+		logCall(ctx, logger, info.FullMethod, start, err)
+		return response, err
+	}
+}
+
+// Logs each stream once it ends, as the calls, along with how many
+// messages were sent and received.
+func StreamAccessLog(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(server any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		counted := &countingStream{ServerStream: stream}
+		err := handler(server, counted)
+		logCall(
+			stream.Context(), logger, info.FullMethod, start, err,
+			slog.Int("sent", counted.sent), slog.Int("received", counted.received),
+		)
+		return err
+	}
+}
+
+type countingStream struct {
+	grpc.ServerStream
+
+	sent     int
+	received int
+}
+
+func (stream *countingStream) SendMsg(message any) error {
+	err := stream.ServerStream.SendMsg(message)
+	if err == nil {
+		stream.sent++
+	}
+	return err
+}
+
+func (stream *countingStream) RecvMsg(message any) error {
+	err := stream.ServerStream.RecvMsg(message)
+	if err == nil {
+		stream.received++
+	}
+	return err
+}
+
+func logCall(ctx context.Context, logger *slog.Logger, method string, start time.Time, err error, attrs ...slog.Attr) {
+	code := status.Code(err)
+	attrs = append(
+		[]slog.Attr{
+			slog.String("method", method),
+			slog.String("code", code.String()),
+			slog.Duration("duration", time.Since(start)),
+		},
+		attrs...,
+	)
+	if client, ok := peer.FromContext(ctx); ok && client.Addr != nil {
+		attrs = append(attrs, slog.String("peer", client.Addr.String()))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	logger.LogAttrs(ctx, levelOf(code), "gRPC call", attrs...)
+}
+
+// The codes of the failures of the service, instead of the requests.
+func levelOf(code codes.Code) slog.Level {
+	switch code {
+	case codes.Internal, codes.Unknown, codes.Unavailable, codes.DataLoss, codes.Unimplemented:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
@@ -0,0 +1,46 @@
+package interceptors
+
+import (
+	"context"
+	"log"
+	"runtime/debug"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Answered for a panic, whose details stay in the log.
+var ErrPanicked = status.Error(codes.Internal, "internal error")
+
+// Answers a panic of the handler with ErrPanicked, logging it with its
+// stack, instead of crashing the service.
+func UnaryRecovery() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (response any, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logPanic(info.FullMethod, recovered)
+				response, err = nil, ErrPanicked
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// Ends the stream with ErrPanicked on a panic of the handler, the
+// messages already sent kept.
+func StreamRecovery() grpc.StreamServerInterceptor {
+	return func(server any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logPanic(info.FullMethod, recovered)
+				err = ErrPanicked
+			}
+		}()
+		return handler(server, stream)
+	}
+}
+
+func logPanic(method string, recovered any) {
+	log.Printf("Recovered from a panic in %s: %v\n%s", method, recovered, debug.Stack())
+}
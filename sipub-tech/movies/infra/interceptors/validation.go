@@ -0,0 +1,55 @@
+package interceptors
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Implemented by the requests with checks of their own.
+type Validator interface {
+	Validate() error
+}
+
+// Refuses the requests that fail their checks with InvalidArgument,
+// before the handler.
+func UnaryValidation() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := validate(req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Refuses the messages received on the streams that fail their checks,
+// as the request of a server stream, with InvalidArgument.
+func StreamValidation() grpc.StreamServerInterceptor {
+	return func(server any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(server, &validatingStream{ServerStream: stream})
+	}
+}
+
+type validatingStream struct {
+	grpc.ServerStream
+}
+
+func (stream *validatingStream) RecvMsg(message any) error {
+	if err := stream.ServerStream.RecvMsg(message); err != nil {
+		return err
+	}
+	return validate(message)
+}
+
+func validate(message any) error {
+	validator, ok := message.(Validator)
+	if !ok {
+		return nil
+	}
+	if err := validator.Validate(); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
@@ -10,8 +10,18 @@ type MovieSaverController interface {
 	SaveMovie(ctx context.Context, movie dtos.CreateMovieDTO)
 }
 
+type MovieUpdaterController interface {
+	UpdateMovie(ctx context.Context, movie dtos.UpdateMovieDTO)
+}
+
 type MovieDeleterController interface {
-	DeleteMovie(ctx context.Context, id dtos.MovieID)
+	DeleteMovie(ctx context.Context, id dtos.MovieID, expectedVersion int)
 }
 
+type MovieBulkSaverController interface {
+	SaveMovies(ctx context.Context, batch dtos.BulkCreateMoviesDTO) error
+}
 
+type MovieBulkDeleterController interface {
+	DeleteMovies(ctx context.Context, batch dtos.BulkDeleteMoviesDTO) error
+}
@@ -0,0 +1,50 @@
+package publishers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/messaging/constants"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/messaging/rabbitmq"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/domain"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/ports"
+)
+
+var (
+	routingKeys = map[domain.MovieEventType]string{
+		domain.MovieCreated: constants.MovieCreatedRoutingKey,
+		domain.MovieUpdated: constants.MovieUpdatedRoutingKey,
+		domain.MovieDeleted: constants.MovieDeletedRoutingKey,
+	}
+)
+
+// Publishes the events to the movie events topic exchange, routed by
+// their type. The client must be open.
+func NewMessagingMovieEventPublisher(client *rabbitmq.RabbitMqServer) *MessagingMovieEventPublisher {
+	return &MessagingMovieEventPublisher{
+		publish: rabbitmq.CreateTypedExchangeProducer[domain.MovieEvent](
+			client,
+			constants.MovieEventsExchangeName,
+			rabbitmq.StandardExchangeConfig(rabbitmq.TopicExchange),
+			nil,
+		),
+	}
+}
+
+type MessagingMovieEventPublisher struct {
+	ports.MovieEventPublisher
+
+	publish rabbitmq.TypedRoutedProducerFunction[domain.MovieEvent]
+}
+
+func (publisher *MessagingMovieEventPublisher) PublishMovieEvent(ctx context.Context, event domain.MovieEvent) error {
+	routingKey, ok := routingKeys[event.Type]
+	if !ok {
+		return fmt.Errorf("no routing key for event type %q", event.Type)
+	}
+	if err := publisher.publish(ctx, routingKey, event); err != nil {
+		return fmt.Errorf("failed publishing event %s: %w", event.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package publishers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/messaging/constants"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/messaging/rabbitmq"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/dtos"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/ports"
+)
+
+// Publishes the results of the bulk operations to the operations topic
+// exchange, for every gateway to track them. The client must be open.
+func NewMessagingOperationResultsPublisher(client *rabbitmq.RabbitMqServer) *MessagingOperationResultsPublisher {
+	return &MessagingOperationResultsPublisher{
+		publish: rabbitmq.CreateTypedExchangeProducer[dtos.OperationResultsDTO](
+			client,
+			constants.MovieOperationsExchangeName,
+			rabbitmq.StandardExchangeConfig(rabbitmq.TopicExchange),
+			nil,
+		),
+	}
+}
+
+type MessagingOperationResultsPublisher struct {
+	ports.OperationResultsPublisher
+
+	publish rabbitmq.TypedRoutedProducerFunction[dtos.OperationResultsDTO]
+}
+
+func (publisher *MessagingOperationResultsPublisher) PublishOperationResults(
+	ctx context.Context, results dtos.OperationResultsDTO,
+) error {
+	if err := publisher.publish(ctx, constants.OperationResultsRoutingKey, results); err != nil {
+		return fmt.Errorf("failed publishing results of operation %s: %w", results.OperationID, err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package relays
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/config/backoff"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/usecases"
+)
+
+type Config struct {
+	// How long to wait for new events once the outbox is empty.
+	PollInterval time.Duration
+	// How many events are fetched from the outbox at a time.
+	BatchSize int
+	// The wait after the first failure, doubled on each consecutive one up
+	// to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		PollInterval: 500 * time.Millisecond,
+		BatchSize: 100,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff: 30 * time.Second,
+	}
+}
+
+// How long to wait after the given number of consecutive failures.
+func (config Config) Backoff(failures int) time.Duration {
+	return backoff.Exponential{Initial: config.InitialBackoff, Max: config.MaxBackoff}.Delay(failures)
+}
+
+// Publishes the events recorded in the outbox, as they are recorded.
+func NewOutboxRelay(outbox ports.MovieEventOutbox, publisher ports.MovieEventPublisher, config Config) *OutboxRelay {
+	return &OutboxRelay{
+		usecase: usecases.NewRelayMovieEventsCase(outbox, publisher),
+		config: config,
+	}
+}
+
+type OutboxRelay struct {
+	usecase *usecases.RelayMovieEventsCase
+	config Config
+}
+
+// Relays the events until the context is done. Full batches are followed
+// right away by the next one, while failures are retried with an
+// exponential backoff.
+func (relay *OutboxRelay) Run(ctx context.Context) {
+	failures := 0
+	for {
+		relayed, err := relay.usecase.RelayMovieEvents(ctx, relay.config.BatchSize)
+
+		var wait time.Duration
+		if err != nil {
+			failures++
+			wait = relay.config.Backoff(failures)
+			log.Printf("Failed relaying movie events, retrying in %s: %v", wait, err)
+		} else {
+			failures = 0
+			if relayed < relay.config.BatchSize {
+				wait = relay.config.PollInterval
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
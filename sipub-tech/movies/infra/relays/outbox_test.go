@@ -0,0 +1,155 @@
+package relays_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/domain"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/infra/relays"
+)
+
+func TestBackoff(t *testing.T) {
+	config := relays.Config{InitialBackoff: time.Second, MaxBackoff: 10 * time.Second}
+
+	assert.Equal(t, time.Second, config.Backoff(1))
+	assert.Equal(t, 2 * time.Second, config.Backoff(2))
+	assert.Equal(t, 8 * time.Second, config.Backoff(4))
+	assert.Equal(t, 10 * time.Second, config.Backoff(5))
+	assert.Equal(t, 10 * time.Second, config.Backoff(1000))
+}
+
+func TestOutboxRelay(t *testing.T) {
+	config := relays.Config{
+		PollInterval: time.Millisecond,
+		BatchSize: 2,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff: 4 * time.Millisecond,
+	}
+
+	t.Run("should publish every event in order, retrying the failures", func(t *testing.T) {
+		events := []domain.MovieEvent{{ID: "1"}, {ID: "2"}, {ID: "3"}, {ID: "4"}, {ID: "5"}}
+		outbox := &FakeMovieEventOutbox{pending: events}
+		publisher := &FlakyMovieEventPublisher{failures: map[string]int{"2": 3, "4": 1}}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go relays.NewOutboxRelay(outbox, publisher, config).Run(ctx)
+
+		assert.Eventually(t, func() bool { return outbox.pendingCount() == 0 }, time.Second, time.Millisecond)
+		assert.True(t, reflect.DeepEqual(events, publisher.published()), "Published %+v", publisher.published())
+		assert.Equal(t, map[string]int{"2": 3, "4": 1}, outbox.failureCounts())
+	})
+
+	t.Run("should publish the events recorded later", func(t *testing.T) {
+		outbox := &FakeMovieEventOutbox{}
+		publisher := &FlakyMovieEventPublisher{}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go relays.NewOutboxRelay(outbox, publisher, config).Run(ctx)
+		time.Sleep(5 * time.Millisecond)
+		outbox.record(domain.MovieEvent{ID: "1"})
+
+		assert.Eventually(t, func() bool { return len(publisher.published()) == 1 }, time.Second, time.Millisecond)
+	})
+
+	t.Run("should stop when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+
+		go func() {
+			relays.NewOutboxRelay(&FakeMovieEventOutbox{}, &FlakyMovieEventPublisher{}, config).Run(ctx)
+			close(done)
+		}()
+		cancel()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Error("Relay still running after the context was canceled")
+		}
+	})
+}
+
+
+type FakeMovieEventOutbox struct {
+	mutex sync.Mutex
+	pending []domain.MovieEvent
+	failures map[string]int
+}
+
+func (outbox *FakeMovieEventOutbox) record(event domain.MovieEvent) {
+	outbox.mutex.Lock()
+	defer outbox.mutex.Unlock()
+	outbox.pending = append(outbox.pending, event)
+}
+
+func (outbox *FakeMovieEventOutbox) pendingCount() int {
+	outbox.mutex.Lock()
+	defer outbox.mutex.Unlock()
+	return len(outbox.pending)
+}
+
+func (outbox *FakeMovieEventOutbox) failureCounts() map[string]int {
+	outbox.mutex.Lock()
+	defer outbox.mutex.Unlock()
+	return outbox.failures
+}
+
+func (outbox *FakeMovieEventOutbox) GetPendingEvents(ctx context.Context, limit int) ([]domain.MovieEvent, error) {
+	outbox.mutex.Lock()
+	defer outbox.mutex.Unlock()
+	return append([]domain.MovieEvent{}, outbox.pending[:min(limit, len(outbox.pending))]...), nil
+}
+
+func (outbox *FakeMovieEventOutbox) MarkEventSent(ctx context.Context, eventID string) error {
+	outbox.mutex.Lock()
+	defer outbox.mutex.Unlock()
+	for index, event := range outbox.pending {
+		if event.ID == eventID {
+			outbox.pending = append(outbox.pending[:index:index], outbox.pending[index+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("event %s is not pending", eventID)
+}
+
+func (outbox *FakeMovieEventOutbox) MarkEventFailed(ctx context.Context, eventID string, cause error) error {
+	outbox.mutex.Lock()
+	defer outbox.mutex.Unlock()
+	if outbox.failures == nil {
+		outbox.failures = map[string]int{}
+	}
+	outbox.failures[eventID]++
+	return nil
+}
+
+// Fails each event the given number of times before publishing it.
+type FlakyMovieEventPublisher struct {
+	mutex sync.Mutex
+	failures map[string]int
+	events []domain.MovieEvent
+}
+
+func (publisher *FlakyMovieEventPublisher) published() []domain.MovieEvent {
+	publisher.mutex.Lock()
+	defer publisher.mutex.Unlock()
+	return publisher.events
+}
+
+func (publisher *FlakyMovieEventPublisher) PublishMovieEvent(ctx context.Context, event domain.MovieEvent) error {
+	publisher.mutex.Lock()
+	defer publisher.mutex.Unlock()
+	if publisher.failures[event.ID] > 0 {
+		publisher.failures[event.ID]--
+		return fmt.Errorf("broker unavailable")
+	}
+	publisher.events = append(publisher.events, event)
+	return nil
+}
@@ -23,6 +23,15 @@ type getParserFunction func(response *dynamodb.GetItemOutput) (Item, error)
 const (
 	idTableName = "idCounter"
 	idItemName = "current"
+
+	// The limits of DynamoDB for each BatchWriteItem and BatchGetItem.
+	maxBatchWriteItems = 25
+	maxBatchGetItems = 100
+
+	// The unprocessed items of a batch are retried with an exponential
+	// backoff, as DynamoDB leaves them out when throttling.
+	maxBatchAttempts = 5
+	batchRetryBackoff = 50 * time.Millisecond
 )
 
 var (
@@ -222,22 +231,321 @@ func (repo *baseRepository) scanItems(
 	return 
 }
 
-func (repo *MovieRepository) deleteItem(
-	ctx context.Context, tableName string, item Item,
+// Fetches every page of the items with the key, from the cursor on,
+// calling emit with the items of each page before fetching the next.
+func (repo *baseRepository) queryPages(
+	ctx context.Context, tableName, indexName, key string, value any, cursor map[string]types.AttributeValue,
+	emit func(items []any) error,
 ) error {
-	_, err := repo.client.DeleteItem(
+	var index *string
+	if indexName != "" {
+		index = aws.String(indexName)
+	}
+
+	keyEx := expression.Key(key).Equal(expression.Value(value))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyEx).Build()
+	if err != nil {
+		return fmt.Errorf("couldn't build expression for query. Here's why: %w", err)
+	}
+
+	queryPaginator := dynamodb.NewQueryPaginator(repo.client, &dynamodb.QueryInput{
+		TableName:                 aws.String(tableName),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		KeyConditionExpression:    expr.KeyCondition(),
+		IndexName:                 index,
+		ExclusiveStartKey:         cursor,
+	})
+
+	for queryPaginator.HasMorePages() {
+		response, err := queryPaginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("couldn't query for %s with key: %q and value: %+v. Here's why: %w", tableName, key, value, err)
+		}
+		items, err := repo.queryUnmarshallers[tableName](response)
+		if err != nil {
+			return fmt.Errorf("couldn't parse items: %w", err)
+		}
+		if err := emit(items); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Scans every page of the table from the cursor on, calling emit with the
+// items of each page before fetching the next.
+func (repo *baseRepository) scanPages(
+	ctx context.Context, tableName string, cursor map[string]types.AttributeValue, emit func(items []any) error,
+) error {
+	scanPaginator := dynamodb.NewScanPaginator(
+		repo.client,
+		&dynamodb.ScanInput{
+			TableName: aws.String(tableName),
+			ExclusiveStartKey: cursor,
+		},
+	)
+
+	for scanPaginator.HasMorePages() {
+		response, err := scanPaginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("couldn't scan for %s. Here's why: %w", tableName, err)
+		}
+		items, err := repo.scanUnmarshallers[tableName](response)
+		if err != nil {
+			return fmt.Errorf("couldn't parse items: %w", err)
+		}
+		if err := emit(items); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Scans every page of a segment of the table, one of totalSegments that
+// together cover it, calling emit with the items of each page.
+func (repo *baseRepository) scanSegment(
+	ctx context.Context, tableName string, segment, totalSegments int, emit func(items []any) error,
+) error {
+	scanPaginator := dynamodb.NewScanPaginator(
+		repo.client,
+		&dynamodb.ScanInput{
+			TableName: aws.String(tableName),
+			Segment: aws.Int32(int32(segment)),
+			TotalSegments: aws.Int32(int32(totalSegments)),
+		},
+	)
+
+	for scanPaginator.HasMorePages() {
+		response, err := scanPaginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("couldn't scan segment %d of %s. Here's why: %w", segment, tableName, err)
+		}
+		items, err := repo.scanUnmarshallers[tableName](response)
+		if err != nil {
+			return fmt.Errorf("couldn't parse items: %w", err)
+		}
+		if err := emit(items); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Deletes the item, returning its attributes, which are empty if it did
+// not exist. When the condition is not nil, the item is only deleted if it
+// holds.
+func (repo *baseRepository) deleteItem(
+	ctx context.Context, tableName string, item Item, condition *expression.ConditionBuilder,
+) (map[string]types.AttributeValue, error) {
+	input := &dynamodb.DeleteItemInput{
+		TableName: aws.String(tableName),
+		Key: item.GetKey(),
+		ReturnValues: types.ReturnValueAllOld,
+	}
+	if condition != nil {
+		expr, err := expression.NewBuilder().WithCondition(*condition).Build()
+		if err != nil {
+			return nil, fmt.Errorf("couldn't build condition to delete %+v. Here's why: %w", item, err)
+		}
+		input.ConditionExpression = expr.Condition()
+		input.ExpressionAttributeNames = expr.Names()
+		input.ExpressionAttributeValues = expr.Values()
+		input.ReturnValuesOnConditionCheckFailure = types.ReturnValuesOnConditionCheckFailureAllOld
+	}
+
+	response, err := repo.client.DeleteItem(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't delete %+v from table. Here's why: %w", item, err)
+	}
+	return response.Attributes, nil
+}
+
+// Updates the item only if the condition holds, returning its new
+// attributes. On failure, the types.ConditionalCheckFailedException
+// carries the current item.
+func (repo *baseRepository) updateItem(
+	ctx context.Context,
+	tableName string,
+	item Item,
+	update expression.UpdateBuilder,
+	condition expression.ConditionBuilder,
+) (map[string]types.AttributeValue, error) {
+	expr, err := expression.NewBuilder().WithUpdate(update).WithCondition(condition).Build()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't build expression to update %+v. Here's why: %w", item, err)
+	}
+
+	response, err := repo.client.UpdateItem(
 		ctx,
-		&dynamodb.DeleteItemInput{
-	TableName: aws.String(tableName),
-	Key: item.GetKey(),
+		&dynamodb.UpdateItemInput{
+			TableName:                           aws.String(tableName),
+			Key:                                 item.GetKey(),
+			ExpressionAttributeNames:            expr.Names(),
+			ExpressionAttributeValues:           expr.Values(),
+			UpdateExpression:                    expr.Update(),
+			ConditionExpression:                 expr.Condition(),
+			ReturnValues:                        types.ReturnValueAllNew,
+			ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
 		},
 	)
 	if err != nil {
-		return fmt.Errorf("couldn't delete %+v from table. Here's why: %w", item, err)
+		return nil, fmt.Errorf("couldn't update %+v. Here's why: %w", item, err)
+	}
+	return response.Attributes, nil
+}
+
+// Writes all the items or none of them. On failure, the
+// types.TransactionCanceledException carries the reason of each write,
+// with the current item of those whose condition failed.
+func (repo *baseRepository) transactWriteItems(ctx context.Context, items ...types.TransactWriteItem) error {
+	if _, err := repo.client.TransactWriteItems(
+		ctx,
+		&dynamodb.TransactWriteItemsInput{
+			TransactItems: items,
+		},
+	); err != nil {
+		return fmt.Errorf("couldn't write %d items in a transaction. Here's why: %w", len(items), err)
 	}
 	return nil
 }
 
+// Writes up to maxBatchWriteItems requests, by table, retrying those
+// DynamoDB leaves unprocessed. Each write is applied on its own, so the
+// requests still unprocessed after the last attempt are returned, which
+// are all of them when the error is not nil.
+func (repo *baseRepository) batchWriteItems(
+	ctx context.Context, requests map[string][]types.WriteRequest,
+) (map[string][]types.WriteRequest, error) {
+	backoff := batchRetryBackoff
+	for attempt := 1; len(requests) > 0; attempt++ {
+		response, err := repo.client.BatchWriteItem(
+			ctx,
+			&dynamodb.BatchWriteItemInput{
+				RequestItems: requests,
+			},
+		)
+		if err != nil {
+			return requests, fmt.Errorf("couldn't write a batch of items. Here's why: %w", err)
+		}
+		requests = response.UnprocessedItems
+		if len(requests) == 0 || attempt == maxBatchAttempts {
+			break
+		}
+		if err := sleepContext(ctx, backoff); err != nil {
+			return requests, fmt.Errorf("stopped retrying the unprocessed items: %w", err)
+		}
+		backoff *= 2
+	}
+	return requests, nil
+}
+
+// Gets the items with the keys, in batches of maxBatchGetItems, retrying
+// the keys DynamoDB leaves unprocessed. The missing items are left out, and
+// the items found come in no particular order.
+func (repo *baseRepository) batchGetItems(
+	ctx context.Context, tableName string, items []Item,
+) ([]map[string]types.AttributeValue, error) {
+	var found []map[string]types.AttributeValue
+	for start := 0; start < len(items); start += maxBatchGetItems {
+		batch := items[start:min(start + maxBatchGetItems, len(items))]
+		keys := make([]map[string]types.AttributeValue, len(batch))
+		for index, item := range batch {
+			keys[index] = item.GetKey()
+		}
+
+		requests := map[string]types.KeysAndAttributes{tableName: {Keys: keys}}
+		backoff := batchRetryBackoff
+		for attempt := 1; len(requests) > 0; attempt++ {
+			response, err := repo.client.BatchGetItem(
+				ctx,
+				&dynamodb.BatchGetItemInput{
+					RequestItems: requests,
+				},
+			)
+			if err != nil {
+				return nil, fmt.Errorf("couldn't get a batch of items from %s. Here's why: %w", tableName, err)
+			}
+			found = append(found, response.Responses[tableName]...)
+
+			requests = response.UnprocessedKeys
+			if len(requests) == 0 {
+				break
+			}
+			if attempt == maxBatchAttempts {
+				return nil, fmt.Errorf(
+					"couldn't get %d items from %s after %d attempts", len(requests[tableName].Keys), tableName, attempt,
+				)
+			}
+			if err := sleepContext(ctx, backoff); err != nil {
+				return nil, fmt.Errorf("stopped retrying the unprocessed keys: %w", err)
+			}
+			backoff *= 2
+		}
+	}
+	return found, nil
+}
+
+// Puts the item within a batch, unconditionally.
+func (repo *baseRepository) putBatchItem(item Item) (types.WriteRequest, error) {
+	marshalled, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return types.WriteRequest{}, fmt.Errorf("couldn't marshal %+v. Here's why: %w", item, err)
+	}
+	return types.WriteRequest{PutRequest: &types.PutRequest{Item: marshalled}}, nil
+}
+
+// Deletes the item within a batch, unconditionally.
+func (repo *baseRepository) deleteBatchItem(item Item) types.WriteRequest {
+	return types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: item.GetKey()}}
+}
+
+// Puts the item within a transaction, only if the condition holds.
+func (repo *baseRepository) putTransactItem(
+	tableName string, item Item, condition expression.ConditionBuilder,
+) (types.TransactWriteItem, error) {
+	marshalled, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return types.TransactWriteItem{}, fmt.Errorf("couldn't marshal %+v. Here's why: %w", item, err)
+	}
+	expr, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		return types.TransactWriteItem{}, fmt.Errorf("couldn't build condition to put %+v. Here's why: %w", item, err)
+	}
+
+	return types.TransactWriteItem{
+		Put: &types.Put{
+			TableName:                           aws.String(tableName),
+			Item:                                marshalled,
+			ConditionExpression:                 expr.Condition(),
+			ExpressionAttributeNames:            expr.Names(),
+			ExpressionAttributeValues:           expr.Values(),
+			ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+		},
+	}, nil
+}
+
+// Deletes the item within a transaction, only if the condition holds.
+func (repo *baseRepository) deleteTransactItem(
+	tableName string, item Item, condition expression.ConditionBuilder,
+) (types.TransactWriteItem, error) {
+	expr, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		return types.TransactWriteItem{}, fmt.Errorf("couldn't build condition to delete %+v. Here's why: %w", item, err)
+	}
+
+	return types.TransactWriteItem{
+		Delete: &types.Delete{
+			TableName:                           aws.String(tableName),
+			Key:                                 item.GetKey(),
+			ConditionExpression:                 expr.Condition(),
+			ExpressionAttributeNames:            expr.Names(),
+			ExpressionAttributeValues:           expr.Values(),
+			ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+		},
+	}, nil
+}
+
 func (repo *baseRepository) createTable(ctx context.Context, tableCfg *tableConfig) (*dynamodb.CreateTableOutput, error) {	
 	attrDefs, keySchema := repo.genTablePrimaryIndex(tableCfg.TableAttributes)
 	globalSecondaryIndexes := repo.genTableSecondaryIndexes(tableCfg.GlobalSecondaryIndexes)
@@ -270,6 +578,20 @@ func (repo *baseRepository) createTable(ctx context.Context, tableCfg *tableConf
 	return table, nil
 }
 
+// The projection of the index of the table, as described by DynamoDB.
+func (repo *baseRepository) indexProjection(ctx context.Context, tableName, indexName string) (projectionType, error) {
+	response, err := repo.client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(tableName)})
+	if err != nil {
+		return "", fmt.Errorf("couldn't describe table %s. Here's why: %w", tableName, err)
+	}
+	for _, index := range response.Table.GlobalSecondaryIndexes {
+		if aws.ToString(index.IndexName) == indexName && index.Projection != nil {
+			return projectionType(index.Projection.ProjectionType), nil
+		}
+	}
+	return "", fmt.Errorf("table %s has no index %s", tableName, indexName)
+}
+
 func (repo *baseRepository) createIdTable(ctx context.Context) (error) {
 	table, err := repo.createTable(ctx, &tableConfig{
 		TableName: idTableName,
@@ -304,8 +626,10 @@ func (repo *baseRepository) createCurrentId(ctx context.Context) error {
 }
 
 
-func (repo *baseRepository) getNextId(ctx context.Context) (int, error) {
-	update := expression.Set(expression.Name("id"), expression.Name("id").Plus(expression.Value(1)))
+// Leases a block of count ids in a single write, returning the last one,
+// so the block goes from last - count + 1 to last.
+func (repo *baseRepository) LeaseIds(ctx context.Context, count int) (int, error) {
+	update := expression.Set(expression.Name("id"), expression.Name("id").Plus(expression.Value(count)))
 	expr, err := expression.NewBuilder().WithUpdate(update).Build()
 	if err != nil {
 		return 0, fmt.Errorf("could't build expression to upgrade id: %w", err)
@@ -336,6 +660,34 @@ func (repo *baseRepository) getNextId(ctx context.Context) (int, error) {
 }
 
 
+// Advances the id counter to lastId, unless it is already there or past
+// it, so the ids leased next are greater.
+func (repo *baseRepository) AdvanceIds(ctx context.Context, lastId int) error {
+	_, err := repo.updateItem(
+		ctx,
+		idTableName,
+		IdCounter{Name: idItemName},
+		expression.Set(expression.Name("id"), expression.Value(lastId)),
+		expression.Name("id").LessThan(expression.Value(lastId)),
+	)
+	var conditionFailed *types.ConditionalCheckFailedException
+	if err != nil && !errors.As(err, &conditionFailed) {
+		return fmt.Errorf("couldn't advance id to %d: %w", lastId, err)
+	}
+	return nil
+}
+
+func sleepContext(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 func (repo *baseRepository) waitTables(ctx context.Context) error {
 	for _, waiter := range repo.tableWaiters {
 		if err := waiter(ctx); err != nil {
@@ -0,0 +1,297 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/domain"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/ports"
+)
+
+const (
+	// Each movie write goes with the record of its event in the same
+	// batch.
+	maxBatchMovieWrites = maxBatchWriteItems / 2
+)
+
+var (
+	ErrBatchWriteUnprocessed = fmt.Errorf("write left unprocessed by the batch")
+)
+
+// The ids of the movies are allocated at once. BatchWriteItem has no
+// conditions, which the new ids do not need.
+func (repo *MovieRepository) SaveBatch(
+	ctx context.Context, movies []domain.Movie, events []domain.MovieEvent,
+) ([]domain.Movie, []error) {
+	saved := make([]domain.Movie, len(movies))
+	errs := make([]error, len(movies))
+
+	ids, err := repo.ids.NextIds(ctx, len(movies))
+	if err != nil {
+		return saved, repeatError(len(movies), fmt.Errorf("error getting the ids of new movies: %w", err))
+	}
+
+	now := time.Now().UnixMilli()
+	writes := make([]movieBatchWrite, len(movies))
+	for index, movie := range movies {
+		movie.ID = ids[index]
+		movie.Version = 1
+		movie.UpdatedAt = now
+		saved[index] = movie
+
+		writes[index], errs[index] = repo.newMovieBatchWrite(movie, events[index], true)
+	}
+
+	repo.writeMovieBatches(ctx, writes, errs)
+	for index, err := range errs {
+		if err != nil {
+			errs[index] = fmt.Errorf("failed saving movie %+v: %w", saved[index], err)
+		}
+	}
+	return saved, errs
+}
+
+// The movies are read in batches first, for the events to carry them,
+// and deleted whatever their version.
+func (repo *MovieRepository) DeleteBatch(
+	ctx context.Context, ids []int, events []domain.MovieEvent,
+) ([]domain.Movie, []error) {
+	deleted := make([]domain.Movie, len(ids))
+	errs := make([]error, len(ids))
+
+	rawMovies, err := repo.batchGetItems(ctx, movieTableName, movieKeys(ids))
+	if err != nil {
+		return deleted, repeatError(len(ids), fmt.Errorf("failed reading the movies to delete: %w", err))
+	}
+	var fetched []DBMovie
+	if err := attributevalue.UnmarshalListOfMaps(rawMovies, &fetched); err != nil {
+		return deleted, repeatError(len(ids), fmt.Errorf("failed parsing the movies to delete: %w", err))
+	}
+	current := make(map[int]domain.Movie, len(fetched))
+	for _, movie := range fetched {
+		current[movie.Id] = movie.ToDomain()
+	}
+
+	var writes []movieBatchWrite
+	var positions []int
+	for index, id := range ids {
+		movie, ok := current[id]
+		if !ok {
+			errs[index] = ports.ErrMovieNotFound
+			continue
+		}
+		// Repeated ids are deleted once, the repetitions are not found.
+		delete(current, id)
+		deleted[index] = movie
+
+		write, err := repo.newMovieBatchWrite(movie, events[index], false)
+		if err != nil {
+			errs[index] = err
+			continue
+		}
+		writes = append(writes, write)
+		positions = append(positions, index)
+	}
+
+	writeErrs := make([]error, len(writes))
+	repo.writeMovieBatches(ctx, writes, writeErrs)
+	for position, err := range writeErrs {
+		if err != nil {
+			index := positions[position]
+			errs[index] = fmt.Errorf("failed deleting movie with id %d: %w", ids[index], err)
+		}
+	}
+	return deleted, errs
+}
+
+// A write of a movie with the record of its event, and how to undo the
+// write of the movie. BatchWriteItem is not transactional, so when only
+// one of them is applied it is undone, leaving neither.
+type movieBatchWrite struct {
+	movieId int
+	eventId string
+	write types.WriteRequest
+	undo types.WriteRequest
+	record types.WriteRequest
+}
+
+// The write saves the movie when saving, and deletes it otherwise.
+func (repo *MovieRepository) newMovieBatchWrite(
+	movie domain.Movie, event domain.MovieEvent, saving bool,
+) (movieBatchWrite, error) {
+	event.Movie = movie
+	record, err := repo.putBatchItem(NewDBMovieEvent(&event))
+	if err != nil {
+		return movieBatchWrite{}, fmt.Errorf("failed recording event %+v: %w", event, err)
+	}
+	put, err := repo.putBatchItem(NewDBMovie(&movie, movie.ID))
+	if err != nil {
+		return movieBatchWrite{}, err
+	}
+	remove := repo.deleteBatchItem(DBMovie{Id: movie.ID})
+
+	write := movieBatchWrite{movieId: movie.ID, eventId: event.ID, record: record, write: put, undo: remove}
+	if !saving {
+		write.write, write.undo = remove, put
+	}
+	return write, nil
+}
+
+// Applies the writes in batches, setting the error of each one that
+// was not applied, and skipping those already failed.
+func (repo *MovieRepository) writeMovieBatches(ctx context.Context, writes []movieBatchWrite, errs []error) {
+	var batch []int
+	for index := range writes {
+		if errs[index] != nil {
+			continue
+		}
+		batch = append(batch, index)
+		if len(batch) == maxBatchMovieWrites {
+			repo.writeMovieBatch(ctx, writes, batch, errs)
+			batch = nil
+		}
+	}
+	if len(batch) > 0 {
+		repo.writeMovieBatch(ctx, writes, batch, errs)
+	}
+}
+
+func (repo *MovieRepository) writeMovieBatch(ctx context.Context, writes []movieBatchWrite, batch []int, errs []error) {
+	requests := map[string][]types.WriteRequest{}
+	for _, index := range batch {
+		requests[movieTableName] = append(requests[movieTableName], writes[index].write)
+		requests[outboxTableName] = append(requests[outboxTableName], writes[index].record)
+	}
+
+	unprocessed, err := repo.batchWriteItems(ctx, requests)
+	if err == nil {
+		err = ErrBatchWriteUnprocessed
+	}
+	movieIds, eventIds := unprocessedIds(unprocessed)
+
+	var undos []types.WriteRequest
+	for _, index := range batch {
+		write := writes[index]
+		movieLeft, eventLeft := movieIds[write.movieId], eventIds[write.eventId]
+		switch {
+		case !movieLeft && !eventLeft:
+			continue
+		case !movieLeft:
+			undos = append(undos, write.undo)
+		case !eventLeft:
+			undos = append(undos, repo.deleteBatchItem(DBMovieEvent{Id: write.eventId}))
+		}
+		errs[index] = err
+	}
+	if len(undos) == 0 {
+		return
+	}
+
+	// Best effort, the undos are logged for them to be fixed by hand when
+	// they are not applied either.
+	undoRequests := map[string][]types.WriteRequest{}
+	for _, undo := range undos {
+		table := movieTableName
+		if undo.DeleteRequest != nil && undo.DeleteRequest.Key["event_id"] != nil {
+			table = outboxTableName
+		}
+		undoRequests[table] = append(undoRequests[table], undo)
+	}
+	if left, err := repo.batchWriteItems(ctx, undoRequests); err != nil || len(left) > 0 {
+		log.Printf("Failed undoing the partial writes %+v: %v", left, err)
+	}
+}
+
+// Writes the movies with their ids, overwriting the existing ones, without
+// recording any event. Movies without a version are saved on the first
+// one, changed now.
+func (repo *MovieRepository) ImportBatch(ctx context.Context, movies []domain.Movie) []error {
+	errs := make([]error, len(movies))
+	now := time.Now().UnixMilli()
+	for start := 0; start < len(movies); start += maxBatchWriteItems {
+		var requests []types.WriteRequest
+		var batch []int
+		for index := start; index < min(start + maxBatchWriteItems, len(movies)); index++ {
+			movie := movies[index]
+			if movie.Version == 0 {
+				movie.Version = 1
+				movie.UpdatedAt = now
+			}
+			request, err := repo.putBatchItem(NewDBMovie(&movie, movie.ID))
+			if err != nil {
+				errs[index] = fmt.Errorf("failed importing movie %+v: %w", movie, err)
+				continue
+			}
+			requests = append(requests, request)
+			batch = append(batch, index)
+		}
+		if len(requests) == 0 {
+			continue
+		}
+
+		unprocessed, err := repo.batchWriteItems(ctx, map[string][]types.WriteRequest{movieTableName: requests})
+		if err == nil {
+			err = ErrBatchWriteUnprocessed
+		}
+		movieIds, _ := unprocessedIds(unprocessed)
+		for _, index := range batch {
+			if movieIds[movies[index].ID] {
+				errs[index] = fmt.Errorf("failed importing movie %+v: %w", movies[index], err)
+			}
+		}
+	}
+	return errs
+}
+
+// The ids of the movies and events whose writes were left unprocessed.
+func unprocessedIds(unprocessed map[string][]types.WriteRequest) (map[int]bool, map[string]bool) {
+	movieIds := map[int]bool{}
+	for _, request := range unprocessed[movieTableName] {
+		var movie DBMovie
+		if err := attributevalue.UnmarshalMap(writtenKey(request), &movie); err == nil {
+			movieIds[movie.Id] = true
+		}
+	}
+	eventIds := map[string]bool{}
+	for _, request := range unprocessed[outboxTableName] {
+		var event DBMovieEvent
+		if err := attributevalue.UnmarshalMap(writtenKey(request), &event); err == nil {
+			eventIds[event.Id] = true
+		}
+	}
+	return movieIds, eventIds
+}
+
+func writtenKey(request types.WriteRequest) map[string]types.AttributeValue {
+	if request.PutRequest != nil {
+		return request.PutRequest.Item
+	}
+	return request.DeleteRequest.Key
+}
+
+// The keys of the movies, once each, as BatchGetItem refuses repeated
+// keys.
+func movieKeys(ids []int) []Item {
+	seen := make(map[int]bool, len(ids))
+	keys := make([]Item, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			keys = append(keys, DBMovie{Id: id})
+		}
+	}
+	return keys
+}
+
+func repeatError(count int, err error) []error {
+	errs := make([]error, count)
+	for index := range errs {
+		errs[index] = err
+	}
+	return errs
+}
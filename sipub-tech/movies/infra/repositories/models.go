@@ -18,13 +18,27 @@ func NewDBMovie(movie *domain.Movie, id int) *DBMovie {
 		Id: id,
 		Year: movie.Year,
 		Title: movie.Title,
+		Version: movie.Version,
+		UpdatedAt: movie.UpdatedAt,
 	}
 }
 
 type DBMovie struct {
-	Title string `dynamodbav:"title"`
-	Year string  `dynamodbav:"year"`
-	Id int       `dynamodbav:"id"`
+	Title string      `dynamodbav:"title"`
+	Year string       `dynamodbav:"year"`
+	Id int            `dynamodbav:"id"`
+	Version int       `dynamodbav:"version"`
+	UpdatedAt int64   `dynamodbav:"updated_at"`
+}
+
+func (movie DBMovie) ToDomain() domain.Movie {
+	return domain.Movie{
+		ID: movie.Id,
+		Title: movie.Title,
+		Year: movie.Year,
+		Version: movie.Version,
+		UpdatedAt: movie.UpdatedAt,
+	}
 }
 
 func (movie DBMovie) GetKey() map[string]types.AttributeValue {
@@ -35,6 +49,57 @@ func (movie DBMovie) GetKey() map[string]types.AttributeValue {
 	return map[string]types.AttributeValue{"id": id}
 }
 
+const (
+	// Only pending events have a status, so the sent ones leave the
+	// pending events index.
+	outboxStatusPending = "PENDING"
+)
+
+// A movie event in the outbox.
+func NewDBMovieEvent(event *domain.MovieEvent) *DBMovieEvent {
+	return &DBMovieEvent{
+		Id: event.ID,
+		Status: outboxStatusPending,
+		Type: string(event.Type),
+		OccurredAt: event.OccurredAt,
+		CorrelationId: event.CorrelationID,
+		CausationId: event.CausationID,
+		Movie: *NewDBMovie(&event.Movie, event.Movie.ID),
+	}
+}
+
+type DBMovieEvent struct {
+	Id string              `dynamodbav:"event_id"`
+	Status string          `dynamodbav:"status,omitempty"`
+	Type string            `dynamodbav:"type"`
+	OccurredAt int64       `dynamodbav:"occurred_at"`
+	CorrelationId string   `dynamodbav:"correlation_id"`
+	CausationId string     `dynamodbav:"causation_id"`
+	Movie DBMovie          `dynamodbav:"movie"`
+	Attempts int           `dynamodbav:"attempts"`
+	LastError string       `dynamodbav:"last_error,omitempty"`
+	SentAt int64           `dynamodbav:"sent_at,omitempty"`
+}
+
+func (event DBMovieEvent) ToDomain() domain.MovieEvent {
+	return domain.MovieEvent{
+		ID: event.Id,
+		Type: domain.MovieEventType(event.Type),
+		OccurredAt: event.OccurredAt,
+		CorrelationID: event.CorrelationId,
+		CausationID: event.CausationId,
+		Movie: event.Movie.ToDomain(),
+	}
+}
+
+func (event DBMovieEvent) GetKey() map[string]types.AttributeValue {
+	id, err := attributevalue.Marshal(event.Id)
+	if err != nil {
+		panic(fmt.Errorf("failed marshalling event id: %w", err))
+	}
+	return map[string]types.AttributeValue{"event_id": id}
+}
+
 type IdCounter struct {
 	Name string `dynamodbav:"name"`
 	Id int      `dynamodbav:"id"`
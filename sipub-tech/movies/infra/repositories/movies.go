@@ -2,23 +2,34 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/domain"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/infra/idgen"
 )
 
 const (
 	movieTableName = "movies"
 	searchMoviesByYearIndex = "search-by-year-index"
+
+	conditionalCheckFailed = "ConditionalCheckFailed"
+	maxWriteAttempts = 3
 )
 
+// The ids of the new movies are leased in blocks of idgen.DefaultBlockSize
+// from the id counter, unless another generator is used.
 func NewMovieRepository(config *RepositoryConfig) *MovieRepository {
-	return &MovieRepository{
+	repo := &MovieRepository{
 		baseRepository: *newBaseRepository(
 			config.endpoint,
 			config.region,
@@ -26,11 +37,17 @@ func NewMovieRepository(config *RepositoryConfig) *MovieRepository {
 				movieTableName: func(response *dynamodb.QueryOutput) ([]any, error) {
 					return queryUnmarshaller[DBMovie](response)
 				},
+				outboxTableName: func(response *dynamodb.QueryOutput) ([]any, error) {
+					return queryUnmarshaller[DBMovieEvent](response)
+				},
 			},
 			map[string]getParserFunction{
 				movieTableName: func(response *dynamodb.GetItemOutput) (Item, error) {
 					return getUnmarshaller[DBMovie](response)
 				},
+				outboxTableName: func(response *dynamodb.GetItemOutput) (Item, error) {
+					return getUnmarshaller[DBMovieEvent](response)
+				},
 			},
 			map[string]scanParserFunction{
 				movieTableName: func(response *dynamodb.ScanOutput) ([]any, error) {
@@ -39,10 +56,24 @@ func NewMovieRepository(config *RepositoryConfig) *MovieRepository {
 			},
 		),
 	}
+	repo.ids = idgen.NewBlockGenerator(repo, idgen.DefaultBlockSize)
+	return repo
 }
 
 type MovieRepository struct {
 	baseRepository
+
+	ids ports.IdGenerator
+	// Set when the year index only projects the keys, as in the tables
+	// created before the versions, so the movies listed by year are read
+	// again from the table for their version and time of change.
+	yearIndexKeysOnly bool
+}
+
+// Allocates the ids of the new movies with generator. It must be called
+// before any movie is saved.
+func (repo *MovieRepository) UseIdGenerator(generator ports.IdGenerator) {
+	repo.ids = generator
 }
 
 
@@ -50,8 +81,29 @@ func (repo *MovieRepository) CreateTables(ctx context.Context) error {
 	if err := repo.createMovieTable(ctx); err != nil {
 		return fmt.Errorf("failed creating movie table: %w", err)
 	}
+	if err := repo.createOutboxTable(ctx); err != nil {
+		return fmt.Errorf("failed creating outbox table: %w", err)
+	}
 
-	return repo.createAllTables(ctx)
+	if err := repo.createAllTables(ctx); err != nil {
+		return err
+	}
+	return repo.checkYearIndex(ctx)
+}
+
+// The projection of an index cannot be changed once it is created, so
+// the year index of the older tables is kept and the movies listed
+// through it are completed from the table instead.
+func (repo *MovieRepository) checkYearIndex(ctx context.Context) error {
+	projection, err := repo.indexProjection(ctx, movieTableName, searchMoviesByYearIndex)
+	if err != nil {
+		return fmt.Errorf("failed checking the year index: %w", err)
+	}
+	repo.yearIndexKeysOnly = projection != projectionTypeAll
+	if repo.yearIndexKeysOnly {
+		log.Printf("The %s index projects %s, the movies listed by year are read again from the table.", searchMoviesByYearIndex, projection)
+	}
+	return nil
 }
 
 func (repo *MovieRepository) GetOne(ctx context.Context, id int) (movie domain.Movie, err error) {
@@ -70,6 +122,80 @@ func (repo *MovieRepository) GetOne(ctx context.Context, id int) (movie domain.M
 	return
 }
 
+// The movies come in the order of their first id, and the missing ones
+// are left out.
+func (repo *MovieRepository) GetMany(ctx context.Context, ids []int) ([]domain.Movie, error) {
+	rawMovies, err := repo.batchGetItems(ctx, movieTableName, movieKeys(ids))
+	if err != nil {
+		return nil, fmt.Errorf("error getting movies with ids %v: %w", ids, err)
+	}
+	var fetched []DBMovie
+	if err := attributevalue.UnmarshalListOfMaps(rawMovies, &fetched); err != nil {
+		return nil, fmt.Errorf("failed parsing movies with ids %v: %w", ids, err)
+	}
+	byId := make(map[int]domain.Movie, len(fetched))
+	for _, movie := range fetched {
+		byId[movie.Id] = movie.ToDomain()
+	}
+
+	movies := make([]domain.Movie, 0, len(byId))
+	for _, id := range ids {
+		if movie, ok := byId[id]; ok {
+			movies = append(movies, movie)
+			delete(byId, id)
+		}
+	}
+	return movies, nil
+}
+
+func (repo *MovieRepository) ScanSegment(
+	ctx context.Context, segment, totalSegments int, emit func(movies []domain.Movie) error,
+) error {
+	return repo.scanSegment(ctx, movieTableName, segment, totalSegments, func(items []any) error {
+		movies := make([]domain.Movie, len(items))
+		for index, item := range items {
+			movie, ok := item.(DBMovie)
+			if !ok {
+				return fmt.Errorf("unexpected item %+v scanning movies", item)
+			}
+			movies[index] = movie.ToDomain()
+		}
+		return emit(movies)
+	})
+}
+
+func (repo *MovieRepository) StreamAll(
+	ctx context.Context, year string, lastMovieId int, emit func(movies []domain.Movie) error,
+) error {
+	var cursor map[string]types.AttributeValue
+	if lastMovieId != 0 {
+		cursor = DBMovie{Id: lastMovieId}.GetKey()
+	}
+	emitMovies := func(items []any) error {
+		movies, err := repo.parseMovies(items)
+		if err != nil {
+			return err
+		}
+		if year != "" {
+			if movies, err = repo.completeIndexedMovies(ctx, movies); err != nil {
+				return err
+			}
+		}
+		return emit(movies)
+	}
+
+	if year == "" {
+		if err := repo.scanPages(ctx, movieTableName, cursor, emitMovies); err != nil {
+			return fmt.Errorf("failed streaming movies: %w", err)
+		}
+		return nil
+	}
+	if err := repo.queryPages(ctx, movieTableName, searchMoviesByYearIndex, "year", year, cursor, emitMovies); err != nil {
+		return fmt.Errorf("failed streaming movies of %s: %w", year, err)
+	}
+	return nil
+}
+
 func (repo *MovieRepository) GetAll(
 	ctx context.Context, year string, limit int, lastMovieId int,
 ) (movies []domain.Movie, cursor int, err error) {
@@ -101,43 +227,171 @@ func (repo *MovieRepository) GetAll(
 		}
 
 		movies, cursor, err = repo.parseFetches(cursorMap, fetchedMovies)
+		if err == nil {
+			movies, err = repo.completeIndexedMovies(ctx, movies)
+		}
 	}
 
 	return
 }
 
+// Reads the movies listed through the year index again from the table
+// when the index does not project their version, keeping their order and
+// leaving out the ones deleted in between.
+func (repo *MovieRepository) completeIndexedMovies(ctx context.Context, movies []domain.Movie) ([]domain.Movie, error) {
+	if !repo.yearIndexKeysOnly || len(movies) == 0 {
+		return movies, nil
+	}
+	ids := make([]int, len(movies))
+	for index, movie := range movies {
+		ids[index] = movie.ID
+	}
+	completed, err := repo.GetMany(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed completing the movies of the year index: %w", err)
+	}
+	return completed, nil
+}
+
 
-func (repo *MovieRepository) Save(ctx context.Context, movie domain.Movie) error {
-	id, err := repo.getNextId(ctx)
+func (repo *MovieRepository) Save(ctx context.Context, movie domain.Movie, event domain.MovieEvent) (domain.Movie, error) {
+	ids, err := repo.ids.NextIds(ctx, 1)
 	if err != nil {
-		return fmt.Errorf("error getting the id of new movie %w", err)
+		return domain.Movie{}, fmt.Errorf("error getting the id of new movie %w", err)
 	}
 
-	movie.ID = id
+	movie.ID = ids[0]
+	movie.Version = 1
+	movie.UpdatedAt = time.Now().UnixMilli()
+
+	saveMovie, err := repo.putTransactItem(
+		movieTableName, NewDBMovie(&movie, movie.ID), expression.AttributeNotExists(expression.Name("id")),
+	)
+	if err != nil {
+		return domain.Movie{}, fmt.Errorf("failed saving movie %+v: %w", movie, err)
+	}
+	if err := repo.writeWithEvent(ctx, saveMovie, event, movie); err != nil {
+		return domain.Movie{}, fmt.Errorf("failed saving movie %+v: %w", movie, err)
+	}
+	return movie, nil
+}
 
-	return repo.SaveWithId(ctx, movie)
+// The movie is read before being replaced, so the event carries the
+// whole movie. The replacement is conditioned to the version read, and
+// read again when another write got in between, unless the caller
+// expected a version.
+func (repo *MovieRepository) Update(
+	ctx context.Context, movie domain.Movie, expectedVersion int, event domain.MovieEvent,
+) (domain.Movie, error) {
+	for attempt := 1; ; attempt++ {
+		current, err := repo.GetOne(ctx, movie.ID)
+		if err != nil {
+			return domain.Movie{}, fmt.Errorf("failed updating movie %+v: %w", movie, err)
+		}
+		if expectedVersion != 0 && current.Version != expectedVersion {
+			return domain.Movie{}, fmt.Errorf("failed updating movie %+v: %w", movie, ports.ErrVersionMismatch)
+		}
+
+		updated := current
+		updated.Title = movie.Title
+		updated.Year = movie.Year
+		updated.Version++
+		updated.UpdatedAt = time.Now().UnixMilli()
+
+		replaceMovie, err := repo.putTransactItem(
+			movieTableName, NewDBMovie(&updated, updated.ID), repo.versionCondition(current.Version),
+		)
+		if err != nil {
+			return domain.Movie{}, fmt.Errorf("failed updating movie %+v: %w", movie, err)
+		}
+		err = repo.conditionError(repo.writeWithEvent(ctx, replaceMovie, event, updated))
+		if err == nil {
+			return updated, nil
+		}
+		if !repo.shouldRetry(err, expectedVersion, attempt) {
+			return domain.Movie{}, fmt.Errorf("failed updating movie %+v: %w", movie, err)
+		}
+	}
 }
 
-func (repo *MovieRepository) SaveWithId(ctx context.Context, movie domain.Movie) error {
-	parsedMovie := DBMovie{
-		Id: movie.ID,
-		Title: movie.Title,
-		Year: movie.Year,
+// Like the updates, the movie is read first for the event to carry it.
+func (repo *MovieRepository) Delete(
+	ctx context.Context, id int, expectedVersion int, event domain.MovieEvent,
+) (domain.Movie, error) {
+	for attempt := 1; ; attempt++ {
+		current, err := repo.GetOne(ctx, id)
+		if errors.Is(err, ports.ErrMovieNotFound) && expectedVersion == 0 {
+			return domain.Movie{}, nil
+		} else if err != nil {
+			return domain.Movie{}, fmt.Errorf("failed deleting movie with id %d: %w", id, err)
+		}
+		if expectedVersion != 0 && current.Version != expectedVersion {
+			return domain.Movie{}, fmt.Errorf("failed deleting movie with id %d: %w", id, ports.ErrVersionMismatch)
+		}
+
+		deleteMovie, err := repo.deleteTransactItem(movieTableName, DBMovie{Id: id}, repo.versionCondition(current.Version))
+		if err != nil {
+			return domain.Movie{}, fmt.Errorf("failed deleting movie with id %d: %w", id, err)
+		}
+		err = repo.conditionError(repo.writeWithEvent(ctx, deleteMovie, event, current))
+		if err == nil {
+			return current, nil
+		}
+		if errors.Is(err, ports.ErrMovieNotFound) && expectedVersion == 0 {
+			return domain.Movie{}, nil
+		}
+		if !repo.shouldRetry(err, expectedVersion, attempt) {
+			return domain.Movie{}, fmt.Errorf("failed deleting movie with id %d: %w", id, err)
+		}
 	}
+}
 
-	if err := repo.addItem(ctx, movieTableName, parsedMovie); err != nil {
-		return fmt.Errorf("failed saving movie %+v: %w", movie, err)
+// Writes the movie and records its event in the same transaction, so
+// neither is stored without the other.
+func (repo *MovieRepository) writeWithEvent(
+	ctx context.Context, write types.TransactWriteItem, event domain.MovieEvent, movie domain.Movie,
+) error {
+	record, err := repo.recordEvent(event, movie)
+	if err != nil {
+		return fmt.Errorf("failed recording event %+v: %w", event, err)
 	}
+	return repo.transactWriteItems(ctx, write, record)
+}
 
-	return nil
+// Writes are only retried when another write changed the movie after it
+// was read and the caller did not expect any version.
+func (repo *MovieRepository) shouldRetry(err error, expectedVersion int, attempt int) bool {
+	return errors.Is(err, ports.ErrVersionMismatch) && expectedVersion == 0 && attempt < maxWriteAttempts
 }
 
-func (repo *MovieRepository) Delete(ctx context.Context, id int) error {
-	query := DBMovie{Id: id}
-	if err := repo.deleteItem(ctx, movieTableName, query); err != nil {
-		return fmt.Errorf("failed deleting movie with id %d: %w", id, err)
+// The movies stored before the versions have no version attribute, and
+// are read on the version 0.
+func (repo *MovieRepository) versionCondition(version int) expression.ConditionBuilder {
+	if version == 0 {
+		return expression.And(
+			expression.AttributeExists(expression.Name("id")),
+			expression.AttributeNotExists(expression.Name("version")),
+		)
 	}
-	return nil
+	return expression.Name("version").Equal(expression.Value(version))
+}
+
+// Tells apart a missing movie from a movie on another version when the
+// condition of the movie write of a transaction fails. It is nil when
+// err is.
+func (repo *MovieRepository) conditionError(err error) error {
+	var canceled *types.TransactionCanceledException
+	if !errors.As(err, &canceled) || len(canceled.CancellationReasons) == 0 {
+		return err
+	}
+	reason := canceled.CancellationReasons[0]
+	if aws.ToString(reason.Code) != conditionalCheckFailed {
+		return err
+	}
+	if len(reason.Item) == 0 {
+		return ports.ErrMovieNotFound
+	}
+	return ports.ErrVersionMismatch
 }
 
 func (repo *MovieRepository) createMovieTable(ctx context.Context) (error) {
@@ -175,7 +429,9 @@ func (repo *MovieRepository) createMovieTable(ctx context.Context) (error) {
 						KeyType: keyTypeSorting,
 					},
 				},
-				ProjectionType: projectionTypeKeysOnly,
+				// The version must be projected for the listings to
+				// answer with it.
+				ProjectionType: projectionTypeAll,
 			},
 		},
 	})
@@ -194,11 +450,7 @@ func (repo *MovieRepository) parseMovies(fetchedMovies []any) ([]domain.Movie, e
 		if !ok {
 			return nil, fmt.Errorf("failed parsing movie: %+v", movie)
 		}
-		movies[index] = domain.Movie{
-			ID: parsedMovie.Id,
-			Title: parsedMovie.Title,
-			Year: parsedMovie.Year,
-		}
+		movies[index] = parsedMovie.ToDomain()
 	}
 
 	return movies, nil
@@ -211,11 +463,7 @@ func (repo *MovieRepository) parseMovie(rawMovie Item) (movie domain.Movie, err
 		return 
 	}
 
-	movie = domain.Movie{
-		ID: dbMovie.Id,
-		Title: dbMovie.Title,
-		Year: dbMovie.Year,
-	}
+	movie = dbMovie.ToDomain()
 
 	return
 }
@@ -4,11 +4,19 @@ package repositories_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"reflect"
+	"strconv"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
     "github.com/stretchr/testify/require"
     "github.com/testcontainers/testcontainers-go"
     "github.com/testcontainers/testcontainers-go/wait"
@@ -82,8 +90,10 @@ func TestMovieRepository(t *testing.T) {
 			Title: faker.Sentence(),
 			Year: faker.YearString(),
 		}
-		if err := repo.Save(ctx, movie); err != nil {
+		if saved, err := repo.Save(ctx, movie, newEvent(domain.MovieCreated)); err != nil {
 			logError(t, "Error saving movie %+v: %v", movie, err)
+		} else if saved.ID == 0 || saved.Version != 1 || saved.Title != movie.Title {
+			logError(t, "Saved movie %+v should have an id, the version 1 and the title %q", saved, movie.Title)
 		} else {
 			logSuccess(t, test)
 		}
@@ -128,7 +138,7 @@ func TestMovieRepository(t *testing.T) {
 		for secondMovie.Year == movie.Year {
 			secondMovie.Year = faker.YearString()
 		}
-		if err := repo.Save(ctx, secondMovie); err != nil {
+		if _, err := repo.Save(ctx, secondMovie, newEvent(domain.MovieCreated)); err != nil {
 			logError(t, "Error saving movie %+v: %v", movie, err)
 		}
 
@@ -175,10 +185,54 @@ func TestMovieRepository(t *testing.T) {
 			logSuccess(t, test)
 		}
 
+		test = "Should start movies on the first version"
+		logTest(t, test)
+		if gottenMovie.Version != 1 || gottenMovie.UpdatedAt == 0 {
+			logError(t, "Movie %+v should be on version 1 with the update time set", gottenMovie)
+		} else {
+			logSuccess(t, test)
+		}
+
+		test = "Should be able to update a movie on the expected version, incrementing it"
+		logTest(t, test)
+		updatedMovie := domain.Movie{ID: movieId, Title: faker.Sentence(), Year: movie.Year}
+		if updated, err := repo.Update(ctx, updatedMovie, 1, newEvent(domain.MovieUpdated)); err != nil {
+			logError(t, "Error updating movie %+v: %v", updatedMovie, err)
+		} else if updated.Title != updatedMovie.Title || updated.Version != 2 {
+			logError(t, "Updated movie %+v should have title %q and version 2", updated, updatedMovie.Title)
+		} else if fetched, err := repo.GetOne(ctx, movieId); err != nil {
+			logError(t, "Error getting movie: %v", err)
+		} else if fetched.Title != updatedMovie.Title || fetched.Version != 2 {
+			logError(t, "Movie %+v should have title %q and version 2", fetched, updatedMovie.Title)
+		} else {
+			logSuccess(t, test)
+		}
+
+		test = "Should refuse updates and deletes on an outdated version"
+		logTest(t, test)
+		if _, err := repo.Update(ctx, updatedMovie, 1, newEvent(domain.MovieUpdated)); !errors.Is(err, ports.ErrVersionMismatch) {
+			logError(t, "Update on an outdated version returned %v instead of ErrVersionMismatch", err)
+		} else if _, err := repo.Delete(ctx, movieId, 1, newEvent(domain.MovieDeleted)); !errors.Is(err, ports.ErrVersionMismatch) {
+			logError(t, "Delete on an outdated version returned %v instead of ErrVersionMismatch", err)
+		} else {
+			logSuccess(t, test)
+		}
+
+		test = "Should refuse updates of missing movies"
+		logTest(t, test)
+		missingMovie := domain.Movie{ID: movieId + 1000, Title: faker.Sentence(), Year: movie.Year}
+		if _, err := repo.Update(ctx, missingMovie, 0, newEvent(domain.MovieUpdated)); !errors.Is(err, ports.ErrMovieNotFound) {
+			logError(t, "Update of a missing movie returned %v instead of ErrMovieNotFound", err)
+		} else {
+			logSuccess(t, test)
+		}
+
 		test = "Should be able to delete a movie, and then not be able to fetch it again"
 		logTest(t, test)
-		if err := repo.Delete(ctx, movieId); err != nil {
+		if deleted, err := repo.Delete(ctx, movieId, 2, newEvent(domain.MovieDeleted)); err != nil {
 			logError(t, "Error deleting movie: %v", err)
+		} else if deleted.ID != movieId {
+			logError(t, "Deleted movie %+v should be the movie %d", deleted, movieId)
 		}
 		if _, err := repo.GetOne(ctx, movieId); err == nil {
 			logError(t, "Repository did not return an error after fetching a deleted movie.")
@@ -187,9 +241,263 @@ func TestMovieRepository(t *testing.T) {
 		} else {
 			logSuccess(t, test)
 		}
+
+		test = "Should return no movie when deleting a missing movie"
+		logTest(t, test)
+		if deleted, err := repo.Delete(ctx, movieId, 0, newEvent(domain.MovieDeleted)); err != nil || deleted.ID != 0 {
+			logError(t, "Deleting a missing movie returned %+v and %v", deleted, err)
+		} else {
+			logSuccess(t, test)
+		}
+
+		test = "Should have recorded the events of the applied writes only, in order"
+		logTest(t, test)
+		expectedTypes := []domain.MovieEventType{
+			domain.MovieCreated, domain.MovieCreated, domain.MovieUpdated, domain.MovieDeleted,
+		}
+		pending, err := repo.GetPendingEvents(ctx, 10)
+		if err != nil {
+			logError(t, "Error getting pending events: %v", err)
+		} else if len(pending) != len(expectedTypes) {
+			logError(t, "Expected %d pending events, found %+v", len(expectedTypes), pending)
+		} else {
+			for index, event := range pending {
+				if event.Type != expectedTypes[index] {
+					logError(t, "Event %+v should have type %s", event, expectedTypes[index])
+				}
+			}
+			updated, deleted := pending[2].Movie, pending[3].Movie
+			if updated.ID != movieId || updated.Version != 2 || updated.Title != updatedMovie.Title {
+				logError(t, "Update event should carry the updated movie, found %+v", updated)
+			} else if deleted.ID != movieId || deleted.Version != 2 {
+				logError(t, "Delete event should carry the deleted movie, found %+v", deleted)
+			} else {
+				logSuccess(t, test)
+			}
+		}
+
+		test = "Should save movies in bulk with consecutive ids, get them in batch, and delete them in bulk"
+		logTest(t, test)
+		bulkMovies := []domain.Movie{
+			{Title: faker.Sentence(), Year: faker.YearString()},
+			{Title: faker.Sentence(), Year: faker.YearString()},
+		}
+		saved, errs := repo.SaveBatch(ctx, bulkMovies, []domain.MovieEvent{newEvent(domain.MovieCreated), newEvent(domain.MovieCreated)})
+		if errs[0] != nil || errs[1] != nil {
+			logError(t, "Error saving movies in bulk: %v", errs)
+		} else if saved[1].ID != saved[0].ID + 1 || saved[0].ID <= movieId || saved[0].Version != 1 {
+			logError(t, "Movies saved in bulk %+v should have consecutive new ids and the version 1", saved)
+		} else if fetched, err := repo.GetOne(ctx, saved[1].ID); err != nil || fetched.Title != bulkMovies[1].Title {
+			logError(t, "Movie saved in bulk should be fetched, got %+v and %v", fetched, err)
+		} else if many, err := repo.GetMany(ctx, []int{saved[1].ID, movieId, saved[0].ID, saved[1].ID}); err != nil ||
+			len(many) != 2 || many[0].ID != saved[1].ID || many[1].ID != saved[0].ID {
+			logError(t, "Movies fetched in batch should be the saved ones in order, once each, got %+v and %v", many, err)
+		} else {
+			ids := []int{saved[0].ID, movieId, saved[1].ID}
+			deletions := []domain.MovieEvent{newEvent(domain.MovieDeleted), newEvent(domain.MovieDeleted), newEvent(domain.MovieDeleted)}
+			deleted, errs := repo.DeleteBatch(ctx, ids, deletions)
+			if errs[0] != nil || errs[2] != nil || !errors.Is(errs[1], ports.ErrMovieNotFound) {
+				logError(t, "Deleting in bulk should only miss the deleted movie, got %v", errs)
+			} else if deleted[2].Title != bulkMovies[1].Title {
+				logError(t, "Deleted movie %+v should be the one saved", deleted[2])
+			} else if _, err := repo.GetOne(ctx, saved[0].ID); err != ports.ErrMovieNotFound {
+				logError(t, "Movie deleted in bulk should not be found, got %v", err)
+			} else if events, err := repo.GetPendingEvents(ctx, 20); err != nil || len(events) != len(pending) + 4 {
+				logError(t, "The applied bulk writes should have recorded 4 events, found %+v and %v", events, err)
+			} else {
+				logSuccess(t, test)
+			}
+		}
+
+		test = "Should keep the failed events pending and drop the sent ones"
+		logTest(t, test)
+		if len(pending) == 0 {
+			logError(t, "No pending events to relay")
+		} else if err := repo.MarkEventFailed(ctx, pending[0].ID, fmt.Errorf("broker unavailable")); err != nil {
+			logError(t, "Error marking event as failed: %v", err)
+		} else if stillPending, err := repo.GetPendingEvents(ctx, 20); err != nil || len(stillPending) != len(pending) + 4 {
+			logError(t, "Failed event should still be pending, found %+v and %v", stillPending, err)
+		} else if err := repo.MarkEventSent(ctx, pending[0].ID); err != nil {
+			logError(t, "Error marking event as sent: %v", err)
+		} else if remaining, err := repo.GetPendingEvents(ctx, 20); err != nil || len(remaining) != len(pending) + 3 {
+			logError(t, "Sent event should not be pending, found %+v and %v", remaining, err)
+		} else if remaining[0].ID != pending[1].ID {
+			logError(t, "Pending events should keep their order, found %+v", remaining)
+		} else {
+			logSuccess(t, test)
+		}
+
+		test = "Should import movies with their ids and advance the id counter past them"
+		logTest(t, test)
+		imported := []domain.Movie{
+			{ID: saved[1].ID + 1000, Title: faker.Sentence(), Year: faker.YearString()},
+			{ID: saved[1].ID + 1001, Title: faker.Sentence(), Year: faker.YearString()},
+		}
+		if errs := repo.ImportBatch(ctx, imported); errs[0] != nil || errs[1] != nil {
+			logError(t, "Error importing movies: %v", errs)
+		} else if fetched, err := repo.GetOne(ctx, imported[1].ID); err != nil || fetched.Title != imported[1].Title || fetched.Version != 1 {
+			logError(t, "Imported movie should be fetched on the version 1, got %+v and %v", fetched, err)
+		} else if err := repo.AdvanceIds(ctx, imported[1].ID); err != nil {
+			logError(t, "Error advancing the ids: %v", err)
+		} else if err := repo.AdvanceIds(ctx, imported[0].ID); err != nil {
+			logError(t, "Advancing the ids backwards should be ignored, got %v", err)
+		} else if last, err := repo.LeaseIds(ctx, 1); err != nil || last != imported[1].ID + 1 {
+			logError(t, "The next id leased should follow the imported ones, got %d and %v", last, err)
+		} else {
+			logSuccess(t, test)
+		}
+
+		test = "Should scan every movie once across the segments"
+		logTest(t, test)
+		scanned := map[int]int{}
+		for segment := range 3 {
+			err := repo.ScanSegment(ctx, segment, 3, func(movies []domain.Movie) error {
+				for _, movie := range movies {
+					scanned[movie.ID]++
+				}
+				return nil
+			})
+			if err != nil {
+				logError(t, "Error scanning segment %d: %v", segment, err)
+			}
+		}
+		if scanned[imported[0].ID] != 1 || scanned[imported[1].ID] != 1 {
+			logError(t, "Imported movies should be scanned once each, got %v", scanned)
+		} else {
+			logSuccess(t, test)
+		}
+
+		test = "Should stream every page of the movies of the year"
+		logTest(t, test)
+		streamed := map[int]int{}
+		err = repo.StreamAll(ctx, imported[0].Year, 0, func(movies []domain.Movie) error {
+			for _, movie := range movies {
+				if movie.Year != imported[0].Year {
+					return fmt.Errorf("movie %+v of another year streamed", movie)
+				}
+				streamed[movie.ID]++
+			}
+			return nil
+		})
+		if err != nil {
+			logError(t, "Error streaming the movies of %s: %v", imported[0].Year, err)
+		} else if streamed[imported[0].ID] != 1 {
+			logError(t, "Imported movie should be streamed once, got %v", streamed)
+		} else {
+			logSuccess(t, test)
+		}
+
+		test = "Should stop streaming on the first error of emit"
+		logTest(t, test)
+		stopped := fmt.Errorf("client gone")
+		if err := repo.StreamAll(ctx, "", 0, func(movies []domain.Movie) error { return stopped }); !errors.Is(err, stopped) {
+			logError(t, "Error %v different from Expected: %v", err, stopped)
+		} else {
+			logSuccess(t, test)
+		}
+	})
+
+	t.Run("should update and delete the movies stored without a version", func(t *testing.T) {
+		client := newDynamoDBClient(t, endpoint)
+		year := faker.YearString()
+		for _, id := range []int{900001, 900002} {
+			_, err := client.PutItem(ctx, &dynamodb.PutItemInput{
+				TableName: aws.String("movies"),
+				Item: map[string]types.AttributeValue{
+					"id": &types.AttributeValueMemberN{Value: strconv.Itoa(id)},
+					"title": &types.AttributeValueMemberS{Value: faker.Sentence()},
+					"year": &types.AttributeValueMemberS{Value: year},
+				},
+			})
+			if err != nil {
+				t.Fatalf("Error putting the movie %d: %v", id, err)
+			}
+		}
+
+		updatedMovie := domain.Movie{ID: 900001, Title: faker.Sentence(), Year: year}
+		if updated, err := repo.Update(ctx, updatedMovie, 0, newEvent(domain.MovieUpdated)); err != nil {
+			logError(t, "Error updating the movie without a version: %v", err)
+		} else if updated.Version != 1 || updated.Title != updatedMovie.Title {
+			logError(t, "Updated movie %+v should have the title %q and the version 1", updated, updatedMovie.Title)
+		}
+		if deleted, err := repo.Delete(ctx, 900002, 0, newEvent(domain.MovieDeleted)); err != nil || deleted.ID != 900002 {
+			logError(t, "Deleting the movie without a version returned %+v and %v", deleted, err)
+		} else if _, err := repo.GetOne(ctx, 900002); err != ports.ErrMovieNotFound {
+			logError(t, "Deleted movie without a version should not be found, got %v", err)
+		}
+	})
+
+	t.Run("should read the version of the movies listed through a year index of the keys only", func(t *testing.T) {
+		client := newDynamoDBClient(t, endpoint)
+		// The movies table as created before the versions.
+		if _, err := client.DeleteTable(ctx, &dynamodb.DeleteTableInput{TableName: aws.String("movies")}); err != nil {
+			t.Fatalf("Error deleting the movies table: %v", err)
+		}
+		_, err := client.CreateTable(ctx, &dynamodb.CreateTableInput{
+			TableName: aws.String("movies"),
+			BillingMode: types.BillingModePayPerRequest,
+			AttributeDefinitions: []types.AttributeDefinition{
+				{AttributeName: aws.String("id"), AttributeType: types.ScalarAttributeTypeN},
+				{AttributeName: aws.String("year"), AttributeType: types.ScalarAttributeTypeS},
+				{AttributeName: aws.String("title"), AttributeType: types.ScalarAttributeTypeS},
+			},
+			KeySchema: []types.KeySchemaElement{{AttributeName: aws.String("id"), KeyType: types.KeyTypeHash}},
+			GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{{
+				IndexName: aws.String("search-by-year-index"),
+				KeySchema: []types.KeySchemaElement{
+					{AttributeName: aws.String("year"), KeyType: types.KeyTypeHash},
+					{AttributeName: aws.String("title"), KeyType: types.KeyTypeRange},
+				},
+				Projection: &types.Projection{ProjectionType: types.ProjectionTypeKeysOnly},
+			}},
+		})
+		if err != nil {
+			t.Fatalf("Error creating the movies table: %v", err)
+		}
+		movie := domain.Movie{ID: 7, Title: faker.Sentence(), Year: faker.YearString(), Version: 3, UpdatedAt: time.Now().UnixMilli()}
+		item, _ := attributevalue.MarshalMap(repositories.NewDBMovie(&movie, movie.ID))
+		if _, err := client.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String("movies"), Item: item}); err != nil {
+			t.Fatalf("Error putting the movie: %v", err)
+		}
+
+		if err := repo.CreateTables(ctx); err != nil {
+			logError(t, "Error when checking the tables: %v", err)
+		}
+		if listed, _, err := repo.GetAll(ctx, movie.Year, 10, 0); err != nil || len(listed) != 1 || listed[0] != movie {
+			logError(t, "Listing by year should answer %+v, got %+v and %v", movie, listed, err)
+		}
+		var streamed []domain.Movie
+		err = repo.StreamAll(ctx, movie.Year, 0, func(movies []domain.Movie) error {
+			streamed = append(streamed, movies...)
+			return nil
+		})
+		if err != nil || len(streamed) != 1 || streamed[0] != movie {
+			logError(t, "Streaming by year should answer %+v, got %+v and %v", movie, streamed, err)
+		}
 	})
 }
 
+func newDynamoDBClient(t *testing.T, endpoint string) *dynamodb.Client {
+	awsConfig, err := config.LoadDefaultConfig(context.Background(),
+		config.WithRegion(awsRegion),
+		config.WithBaseEndpoint(endpoint),
+	)
+	if err != nil {
+		t.Fatalf("Cannot load the AWS configs: %v", err)
+	}
+	return dynamodb.NewFromConfig(awsConfig)
+}
+
+func newEvent(eventType domain.MovieEventType) domain.MovieEvent {
+	// Spaced so the events keep the order they were created in.
+	time.Sleep(2 * time.Millisecond)
+	return domain.MovieEvent{
+		ID: faker.UUIDHyphenated(),
+		Type: eventType,
+		OccurredAt: time.Now().UnixMilli(),
+	}
+}
+
 func logTest(t testing.TB, msg string, args ...any) {
 	log := fmt.Sprintf("[TEST]: %s", msg)
 	t.Logf(log, args...)
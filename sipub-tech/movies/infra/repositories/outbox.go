@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/domain"
+)
+
+const (
+	outboxTableName = "movieEventsOutbox"
+	pendingEventsIndex = "pending-events-index"
+)
+
+func (repo *MovieRepository) GetPendingEvents(ctx context.Context, limit int) ([]domain.MovieEvent, error) {
+	fetchedEvents, _, err := repo.queryItems(
+		ctx, outboxTableName, pendingEventsIndex, "status", outboxStatusPending, limit, nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying for pending events: %w", err)
+	}
+
+	events := make([]domain.MovieEvent, len(fetchedEvents))
+	for index, event := range fetchedEvents {
+		parsedEvent, ok := event.(DBMovieEvent)
+		if !ok {
+			return nil, fmt.Errorf("failed parsing event: %+v", event)
+		}
+		events[index] = parsedEvent.ToDomain()
+	}
+	return events, nil
+}
+
+// Sent events are kept for auditing, out of the pending events index.
+func (repo *MovieRepository) MarkEventSent(ctx context.Context, eventID string) error {
+	update := expression.Set(expression.Name("sent_at"), expression.Value(time.Now().UnixMilli())).
+		Remove(expression.Name("status"))
+
+	if _, err := repo.updateItem(
+		ctx, outboxTableName, DBMovieEvent{Id: eventID}, update, expression.AttributeExists(expression.Name("event_id")),
+	); err != nil {
+		return fmt.Errorf("failed marking event %s as sent: %w", eventID, err)
+	}
+	return nil
+}
+
+func (repo *MovieRepository) MarkEventFailed(ctx context.Context, eventID string, cause error) error {
+	update := expression.Set(expression.Name("last_error"), expression.Value(cause.Error())).
+		Add(expression.Name("attempts"), expression.Value(1))
+
+	if _, err := repo.updateItem(
+		ctx, outboxTableName, DBMovieEvent{Id: eventID}, update, expression.AttributeExists(expression.Name("event_id")),
+	); err != nil {
+		return fmt.Errorf("failed recording the failure of event %s: %w", eventID, err)
+	}
+	return nil
+}
+
+// The write recording the event with the movie, to go in the same
+// transaction as the write of the movie.
+func (repo *MovieRepository) recordEvent(event domain.MovieEvent, movie domain.Movie) (types.TransactWriteItem, error) {
+	event.Movie = movie
+	return repo.putTransactItem(
+		outboxTableName, NewDBMovieEvent(&event), expression.AttributeNotExists(expression.Name("event_id")),
+	)
+}
+
+func (repo *MovieRepository) createOutboxTable(ctx context.Context) (error) {
+	table, err := repo.createTable(ctx, &tableConfig{
+		TableName: outboxTableName,
+		TableAttributes: []tableAttribute{
+			{
+				Name: "event_id",
+				AttrType: attributeTypeString,
+				KeyType: keyTypePartition,
+			},
+			{
+				Name: "status",
+				AttrType: attributeTypeString,
+				KeyType: keyTypeNone,
+			},
+			{
+				Name: "occurred_at",
+				AttrType: attributeTypeNumber,
+				KeyType: keyTypeNone,
+			},
+		},
+		GlobalSecondaryIndexes: []globalSecondaryIndex{
+			{
+				IndexName: pendingEventsIndex,
+				IndexAttributes: []tableAttribute{
+					{
+						Name: "status",
+						AttrType: attributeTypeString,
+						KeyType: keyTypePartition,
+					},
+					{
+						Name: "occurred_at",
+						AttrType: attributeTypeNumber,
+						KeyType: keyTypeSorting,
+					},
+				},
+				ProjectionType: projectionTypeAll,
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error creating outbox table: %w", err)
+	}
+
+	repo.addWaiter(outboxTableName, table)
+	return nil
+}
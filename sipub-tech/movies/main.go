@@ -3,35 +3,61 @@ package main
 import (
 	"context"
 	"fmt"
+	"log"
 	"os"
-	"strconv"
-	
+
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/config"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/core/ports"
+	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/infra/idgen"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/infra/repositories"
 	"github.com/EdmilsonRodrigues/teste-sipub-tech/sipub-tech/movies/infra/entrypoints"
 )
 
 func main() {
-	awsRegion := os.Getenv("MOVIE_SERVICE_AWS_REGION")
-	dynamoDBEndpoint := os.Getenv("MOVIE_SERVICE_DYNAMO_DB_ENDPOINT")
-	grpcListeningPort := os.Getenv("MOVIE_SERVICE_GRPC_LISTENING_PORT")
-	rabbitmqConnectionURL := os.Getenv("MOVIE_SERVICE_RABBITMQ_CONNECTION_URL")
-	listeningPort, err := strconv.Atoi(grpcListeningPort)
-	if err != nil {
-		panic("malformed grpc listening port configuration")
+	settings := DefaultConfig()
+	config.NewLoader("movies_service", EnvPrefix).MustLoad(&settings, os.Args[1:])
+	log.Printf("Configuration:")
+	if err := config.Print(log.Writer(), &settings); err != nil {
+		log.Printf("Failed printing the configuration: %v", err)
 	}
-	
+
 	ctx := context.Background()
-	repo := repositories.NewMovieRepository(repositories.NewRepositoryConfig(awsRegion, dynamoDBEndpoint))
+	repo := repositories.NewMovieRepository(repositories.NewRepositoryConfig(settings.DynamoDB.Region, settings.DynamoDB.Endpoint))
+	repo.UseIdGenerator(newIdGenerator(repo, settings.IDs))
 	repo.Open()
 	if err := repo.CreateTables(ctx); err != nil {
 		panic(fmt.Sprintf("Failed to create tables: %v", err))
 	}
 
-	grpcEntrypoint := entrypoints.NewGRPCEntrypoint(repo, listeningPort)
-	messagingEntrypoint := entrypoints.NewMessagingEntrypoint(repo, rabbitmqConnectionURL)
+	grpcEntrypoint := entrypoints.NewGRPCEntrypoint(repo, repo, settings.GRPCPort)
+	serverConfig, err := settings.GRPC.ServerConfig()
+	if err != nil {
+		panic(fmt.Sprintf("Invalid gRPC server configuration: %v", err))
+	}
+	grpcEntrypoint.UseServerConfig(serverConfig)
+	grpcEntrypoint.UseTranscoding(settings.TranscodingPort)
+	messagingEntrypoint := entrypoints.NewMessagingEntrypoint(repo, repo, settings.RabbitMQURL)
+	if settings.RabbitMQTLS.IsSet() {
+		if err := messagingEntrypoint.UseTLS(settings.RabbitMQTLS.Files()); err != nil {
+			panic(fmt.Sprintf("Invalid RabbitMQ TLS configuration: %v", err))
+		}
+	}
 
 	messagingEntrypoint.Serve(ctx)
 	defer messagingEntrypoint.Close()
 
 	grpcEntrypoint.Serve(ctx)
 }
+
+// Leases blocks of ids from the repository, unless the generator is
+// "snowflake", in which case each replica needs its own node.
+func newIdGenerator(repo *repositories.MovieRepository, settings IDsConfig) ports.IdGenerator {
+	if settings.Generator == "snowflake" {
+		generator, err := idgen.NewSnowflakeGenerator(settings.Node)
+		if err != nil {
+			panic(fmt.Sprintf("Invalid id node configuration: %v", err))
+		}
+		return generator
+	}
+	return idgen.NewBlockGenerator(repo, settings.BlockSize)
+}